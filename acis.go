@@ -0,0 +1,704 @@
+package dxf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The ACIS payload of a modeler geometry entity is stored as SAT text.  Before R2004 each printable character of the
+// text is rotated with `159 - c`; from R2013 the payload is binary SAB data kept in the ACDSDATA section instead.
+
+// acisEncryptedBefore is the first version that stores the SAT text unencrypted.
+const acisEncryptedBefore = R2004
+
+// rotateAcisText applies the character rotation used to encrypt SAT text.  The rotation is its own inverse.
+func rotateAcisText(s string) string {
+	rotated := []byte(s)
+	for i, c := range rotated {
+		if c > 32 && c < 127 {
+			rotated[i] = 159 - c
+		}
+	}
+	return string(rotated)
+}
+
+// isEncryptedSat reports whether the first line of a payload is an encrypted header; a plain header starts with the
+// numeric version.
+func isEncryptedSat(firstLine string) bool {
+	trimmed := strings.TrimSpace(firstLine)
+	return len(trimmed) > 0 && !unicode.IsDigit(rune(trimmed[0]))
+}
+
+// AcisSatText returns the ACIS payload of an entity as plain SAT text, decrypting text data or converting the binary
+// data of R2013 and later files as needed.
+func AcisSatText(g ModelerGeometry) (string, error) {
+	_, _, _, binaryData := g.modelerData()
+	lines := g.AcisLines()
+	if len(lines) == 0 && len(*binaryData) > 0 {
+		file, err := readSab(*binaryData)
+		if err != nil {
+			return "", err
+		}
+		return file.satText(), nil
+	}
+
+	if len(lines) > 0 && isEncryptedSat(lines[0]) {
+		for i := range lines {
+			lines[i] = rotateAcisText(lines[i])
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// SetAcisSatText stores plain SAT text as the ACIS payload of an entity in the form used by `version`.  Any binary data
+// is cleared so a stale copy isn't written to the ACDSDATA section; R2013 and later files keep the text form instead.
+func SetAcisSatText(g ModelerGeometry, sat string, version AcadVersion) {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(sat, "\r\n", "\n"), "\n"), "\n")
+	if version < acisEncryptedBefore {
+		for i := range lines {
+			lines[i] = rotateAcisText(lines[i])
+		}
+	}
+	_, _, _, binaryData := g.modelerData()
+	*binaryData = []byte{}
+	g.SetAcisLines(lines)
+}
+
+// ParseAcis parses the ACIS payload of an entity.
+func ParseAcis(g ModelerGeometry) (*AcisModel, error) {
+	_, _, _, binaryData := g.modelerData()
+	if len(g.AcisLines()) == 0 && len(*binaryData) > 0 {
+		return ParseSab(*binaryData)
+	}
+
+	sat, err := AcisSatText(g)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSat(sat)
+}
+
+//
+// tokens
+//
+
+type acisTokenKind int
+
+const (
+	acisWord acisTokenKind = iota // keywords such as `forward` or the `I` and `F` interval markers
+	acisNumber
+	acisPointer
+	acisString
+	acisBool // only in SAB, where the word depends on the record
+	acisEnum // only in SAB
+	acisSubtypeStart
+	acisSubtypeEnd
+)
+
+type acisToken struct {
+	kind   acisTokenKind
+	text   string
+	number float64
+}
+
+type acisRecord struct {
+	entityType string
+	tokens     []acisToken
+}
+
+// acisFile is the token level content of a SAT or SAB payload.
+type acisFile struct {
+	version      int
+	recordCount  int
+	bodyCount    int
+	flags        int
+	productID    string
+	acisVersion  string
+	date         string
+	units        float64
+	resabs       float64
+	resnor       float64
+	records      []acisRecord
+	endOfFileTag string
+}
+
+//
+// SAT text
+//
+
+// tokenizeSat splits SAT text into tokens; `@n` introduces a string of `n` characters.
+func tokenizeSat(text string) (tokens []acisToken, err error) {
+	i := 0
+	for i < len(text) {
+		if unicode.IsSpace(rune(text[i])) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(text) && !unicode.IsSpace(rune(text[i])) {
+			i++
+		}
+		word := text[start:i]
+		switch {
+		case word[0] == '@' && len(word) > 1:
+			length, parseErr := strconv.Atoi(word[1:])
+			if parseErr != nil {
+				return nil, fmt.Errorf("invalid string length '%s'", word)
+			}
+			i++ // the separating space
+			if i+length > len(text) {
+				return nil, errors.New("string extends past the end of the data")
+			}
+			tokens = append(tokens, acisToken{kind: acisString, text: text[i : i+length]})
+			i += length
+		case word[0] == '$':
+			index, parseErr := strconv.Atoi(word[1:])
+			if parseErr != nil {
+				return nil, fmt.Errorf("invalid pointer '%s'", word)
+			}
+			tokens = append(tokens, acisToken{kind: acisPointer, number: float64(index)})
+		case word == "{":
+			tokens = append(tokens, acisToken{kind: acisSubtypeStart})
+		case word == "}":
+			tokens = append(tokens, acisToken{kind: acisSubtypeEnd})
+		default:
+			if number, parseErr := strconv.ParseFloat(word, 64); parseErr == nil {
+				tokens = append(tokens, acisToken{kind: acisNumber, number: number})
+			} else {
+				tokens = append(tokens, acisToken{kind: acisWord, text: word})
+			}
+		}
+	}
+	return
+}
+
+func readSatText(text string) (*acisFile, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	headerLines := strings.SplitN(text, "\n", 4)
+	if len(headerLines) < 4 {
+		return nil, errors.New("incomplete SAT header")
+	}
+
+	file := &acisFile{endOfFileTag: "End-of-ACIS-data"}
+	counts := strings.Fields(headerLines[0])
+	if len(counts) < 1 {
+		return nil, errors.New("missing SAT version")
+	}
+	values := make([]int, 4)
+	for i := 0; i < len(counts) && i < 4; i++ {
+		value, err := strconv.Atoi(counts[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid SAT header value '%s'", counts[i])
+		}
+		values[i] = value
+	}
+	file.version, file.recordCount, file.bodyCount, file.flags = values[0], values[1], values[2], values[3]
+
+	productTokens, err := tokenizeSat(headerLines[1])
+	if err != nil {
+		return nil, err
+	}
+	headerStrings := []string{}
+	for _, token := range productTokens {
+		if token.kind == acisString {
+			headerStrings = append(headerStrings, token.text)
+		}
+	}
+	for len(headerStrings) < 3 {
+		headerStrings = append(headerStrings, "")
+	}
+	file.productID, file.acisVersion, file.date = headerStrings[0], headerStrings[1], headerStrings[2]
+
+	tolerances := strings.Fields(headerLines[2])
+	if len(tolerances) >= 3 {
+		file.units, _ = strconv.ParseFloat(tolerances[0], 64)
+		file.resabs, _ = strconv.ParseFloat(tolerances[1], 64)
+		file.resnor, _ = strconv.ParseFloat(tolerances[2], 64)
+	}
+
+	tokens, err := tokenizeSat(headerLines[3])
+	if err != nil {
+		return nil, err
+	}
+	var record *acisRecord
+	for _, token := range tokens {
+		if record == nil {
+			if token.kind == acisWord && (strings.HasPrefix(token.text, "End-of-") || strings.HasPrefix(token.text, "Begin-of-")) {
+				// the rest is history data
+				file.endOfFileTag = token.text
+				break
+			}
+			if token.kind == acisNumber && token.number <= 0.0 && file.version >= 700 {
+				// optional record index
+				continue
+			}
+			record = &acisRecord{entityType: token.text}
+			continue
+		}
+		if token.kind == acisWord && token.text == "#" {
+			file.records = append(file.records, *record)
+			record = nil
+			continue
+		}
+		record.tokens = append(record.tokens, token)
+	}
+	if record != nil {
+		return nil, fmt.Errorf("unterminated %s record", record.entityType)
+	}
+	return file, nil
+}
+
+// acisBoolWords gives the words written for the booleans of a record, in order.  Booleans past the listed ones and
+// those of unlisted records are interval markers.
+var acisBoolWords = map[string][][2]string{
+	"face":           {{"reversed", "forward"}, {"double", "single"}, {"in", "out"}},
+	"coedge":         {{"reversed", "forward"}},
+	"edge":           {{"reversed", "forward"}},
+	"plane-surface":  {{"reversed_v", "forward_v"}},
+	"sphere-surface": {{"reversed_v", "forward_v"}},
+	"torus-surface":  {{"reversed_v", "forward_v"}},
+	"cone-surface":   {{"F", "I"}, {"F", "I"}, {"reversed", "forward"}},
+	"transform":      {{"rotate", "no_rotate"}, {"reflect", "no_reflect"}, {"shear", "no_shear"}},
+}
+
+func formatAcisNumber(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func formatAcisString(value string) string {
+	return fmt.Sprintf("@%d %s", len(value), value)
+}
+
+// satText writes the tokens as SAT text.
+func (f *acisFile) satText() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%d %d %d %d\n", f.version, f.recordCount, f.bodyCount, f.flags))
+	builder.WriteString(fmt.Sprintf("%s %s %s\n", formatAcisString(f.productID), formatAcisString(f.acisVersion), formatAcisString(f.date)))
+	builder.WriteString(fmt.Sprintf("%s %s %s\n", formatAcisNumber(f.units), formatAcisNumber(f.resabs), formatAcisNumber(f.resnor)))
+	for index, record := range f.records {
+		if f.version >= 700 {
+			builder.WriteString(fmt.Sprintf("-%d ", index))
+		}
+		builder.WriteString(record.entityType)
+		boolWords := acisBoolWords[record.entityType]
+		boolIndex := 0
+		for _, token := range record.tokens {
+			builder.WriteString(" ")
+			switch token.kind {
+			case acisWord:
+				builder.WriteString(token.text)
+			case acisNumber, acisEnum:
+				builder.WriteString(formatAcisNumber(token.number))
+			case acisPointer:
+				builder.WriteString(fmt.Sprintf("$%d", int(token.number)))
+			case acisString:
+				builder.WriteString(formatAcisString(token.text))
+			case acisBool:
+				words := [2]string{"F", "I"}
+				if boolIndex < len(boolWords) {
+					words = boolWords[boolIndex]
+				}
+				boolIndex++
+				if token.number != 0.0 {
+					builder.WriteString(words[0])
+				} else {
+					builder.WriteString(words[1])
+				}
+			case acisSubtypeStart:
+				builder.WriteString("{")
+			case acisSubtypeEnd:
+				builder.WriteString("}")
+			}
+		}
+		builder.WriteString(" #\n")
+	}
+	builder.WriteString(f.endOfFileTag)
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+//
+// SAB binary
+//
+
+const sabSignature = "ACIS BinaryFile"
+
+const (
+	sabChar          = 0x02
+	sabShort         = 0x03
+	sabInt           = 0x04
+	sabFloat         = 0x05
+	sabDouble        = 0x06
+	sabString        = 0x07
+	sabString16      = 0x08
+	sabString32      = 0x09
+	sabTrue          = 0x0A
+	sabFalse         = 0x0B
+	sabPointer       = 0x0C
+	sabEntityType    = 0x0D
+	sabEntityTypeEx  = 0x0E
+	sabSubtypeStart  = 0x0F
+	sabSubtypeEnd    = 0x10
+	sabRecordEnd     = 0x11
+	sabLiteralString = 0x12
+	sabLocation      = 0x13
+	sabDirection     = 0x14
+	sabEnum          = 0x15
+	sabInt64         = 0x16
+)
+
+type sabReader struct {
+	data  []byte
+	index int
+}
+
+func (r *sabReader) bytes(count int) ([]byte, error) {
+	if count < 0 || r.index+count > len(r.data) {
+		return nil, errors.New("unexpected end of SAB data")
+	}
+	value := r.data[r.index : r.index+count]
+	r.index += count
+	return value, nil
+}
+
+func (r *sabReader) uint(size int) (uint64, error) {
+	raw, err := r.bytes(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(raw[0]), nil
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(raw)), nil
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(raw)), nil
+	default:
+		return binary.LittleEndian.Uint64(raw), nil
+	}
+}
+
+func (r *sabReader) int32() (int, error) {
+	value, err := r.uint(4)
+	return int(int32(value)), err
+}
+
+func (r *sabReader) double() (float64, error) {
+	value, err := r.uint(8)
+	return math.Float64frombits(value), err
+}
+
+func (r *sabReader) string(lengthSize int) (string, error) {
+	length, err := r.uint(lengthSize)
+	if err != nil {
+		return "", err
+	}
+	raw, err := r.bytes(int(length))
+	return string(raw), err
+}
+
+// token reads one tagged value; the entity type tags are returned as words.
+func (r *sabReader) token() (tag byte, tokens []acisToken, err error) {
+	raw, err := r.bytes(1)
+	if err != nil {
+		return
+	}
+	tag = raw[0]
+	var number float64
+	var text string
+	switch tag {
+	case sabChar:
+		var value uint64
+		value, err = r.uint(1)
+		tokens = []acisToken{{kind: acisNumber, number: float64(int8(value))}}
+	case sabShort:
+		var value uint64
+		value, err = r.uint(2)
+		tokens = []acisToken{{kind: acisNumber, number: float64(int16(value))}}
+	case sabInt:
+		var value int
+		value, err = r.int32()
+		tokens = []acisToken{{kind: acisNumber, number: float64(value)}}
+	case sabFloat:
+		var value uint64
+		value, err = r.uint(4)
+		tokens = []acisToken{{kind: acisNumber, number: float64(math.Float32frombits(uint32(value)))}}
+	case sabDouble:
+		number, err = r.double()
+		tokens = []acisToken{{kind: acisNumber, number: number}}
+	case sabInt64:
+		var value uint64
+		value, err = r.uint(8)
+		tokens = []acisToken{{kind: acisNumber, number: float64(int64(value))}}
+	case sabString, sabEntityType, sabEntityTypeEx:
+		text, err = r.string(1)
+		tokens = []acisToken{{kind: acisString, text: text}}
+	case sabString16:
+		text, err = r.string(2)
+		tokens = []acisToken{{kind: acisString, text: text}}
+	case sabString32, sabLiteralString:
+		text, err = r.string(4)
+		tokens = []acisToken{{kind: acisString, text: text}}
+	case sabTrue:
+		tokens = []acisToken{{kind: acisBool, number: 1.0}}
+	case sabFalse:
+		tokens = []acisToken{{kind: acisBool, number: 0.0}}
+	case sabPointer:
+		var value int
+		value, err = r.int32()
+		tokens = []acisToken{{kind: acisPointer, number: float64(value)}}
+	case sabEnum:
+		var value int
+		value, err = r.int32()
+		tokens = []acisToken{{kind: acisEnum, number: float64(value)}}
+	case sabSubtypeStart:
+		tokens = []acisToken{{kind: acisSubtypeStart}}
+	case sabSubtypeEnd:
+		tokens = []acisToken{{kind: acisSubtypeEnd}}
+	case sabRecordEnd:
+	case sabLocation, sabDirection:
+		for i := 0; i < 3 && err == nil; i++ {
+			number, err = r.double()
+			tokens = append(tokens, acisToken{kind: acisNumber, number: number})
+		}
+	default:
+		err = fmt.Errorf("unsupported SAB tag 0x%02X at offset %d", tag, r.index-1)
+	}
+	return
+}
+
+func (r *sabReader) headerString() (string, error) {
+	_, tokens, err := r.token()
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) != 1 || tokens[0].kind != acisString {
+		return "", errors.New("expected a SAB header string")
+	}
+	return tokens[0].text, nil
+}
+
+func (r *sabReader) headerDouble() (float64, error) {
+	_, tokens, err := r.token()
+	if err != nil {
+		return 0.0, err
+	}
+	if len(tokens) != 1 || tokens[0].kind != acisNumber {
+		return 0.0, errors.New("expected a SAB header number")
+	}
+	return tokens[0].number, nil
+}
+
+func readSab(data []byte) (*acisFile, error) {
+	if !bytes.HasPrefix(data, []byte(sabSignature)) {
+		return nil, errors.New("missing SAB signature")
+	}
+	r := &sabReader{data: data, index: len(sabSignature)}
+	file := &acisFile{endOfFileTag: "End-of-ASM-data"}
+	var err error
+	for _, value := range []*int{&file.version, &file.recordCount, &file.bodyCount, &file.flags} {
+		if *value, err = r.int32(); err != nil {
+			return nil, err
+		}
+	}
+	for _, value := range []*string{&file.productID, &file.acisVersion, &file.date} {
+		if *value, err = r.headerString(); err != nil {
+			return nil, err
+		}
+	}
+	for _, value := range []*float64{&file.units, &file.resabs, &file.resnor} {
+		if *value, err = r.headerDouble(); err != nil {
+			return nil, err
+		}
+	}
+
+	for r.index < len(r.data) {
+		// the entity type is any number of prefixes ending with the type itself
+		typeParts := []string{}
+		for {
+			tag, tokens, err := r.token()
+			if err != nil {
+				return nil, err
+			}
+			if tag != sabEntityType && tag != sabEntityTypeEx {
+				return nil, fmt.Errorf("expected a SAB entity type at offset %d", r.index)
+			}
+			typeParts = append(typeParts, tokens[0].text)
+			if tag == sabEntityType {
+				break
+			}
+		}
+		entityType := strings.Join(typeParts, "-")
+		if strings.HasPrefix(entityType, "End-of-") || strings.HasPrefix(entityType, "Begin-of-") {
+			file.endOfFileTag = entityType
+			break
+		}
+
+		record := acisRecord{entityType: entityType}
+		for {
+			tag, tokens, err := r.token()
+			if err != nil {
+				return nil, err
+			}
+			if tag == sabRecordEnd {
+				break
+			}
+			if tag == sabEntityTypeEx || tag == sabEntityType {
+				// nested type names within sub-types are written as words
+				tokens[0].kind = acisWord
+			}
+			record.tokens = append(record.tokens, tokens...)
+		}
+		file.records = append(file.records, record)
+	}
+	return file, nil
+}
+
+//
+// ACDSDATA section
+//
+
+// readAcdsData collects the SAB data of each entity from the ACDSDATA section of R2013 and later files.
+func readAcdsData(np CodePair, reader codePairReader) (data map[Handle][]byte, nextPair CodePair, err error) {
+	data = map[Handle][]byte{}
+	nextPair = np
+	for err == nil && !nextPair.isEndSection() {
+		isRecord := nextPair.Code == 0 && nextPair.Value.(StringCodePairValue).Value == "ACDSRECORD"
+		nextPair, err = reader.readCodePair()
+
+		var handle Handle
+		var chunks []string
+		readingHandle := false
+		readingData := false
+		for err == nil && nextPair.Code != 0 {
+			if isRecord {
+				switch nextPair.Code {
+				case 2:
+					name := nextPair.Value.(StringCodePairValue).Value
+					readingHandle = name == "AcDbDs::ID"
+					readingData = name == "ASM_Data"
+				case 320:
+					if readingHandle {
+						handle = handleFromString(nextPair.Value.(StringCodePairValue).Value)
+					}
+				case 310:
+					if readingData {
+						chunks = append(chunks, nextPair.Value.(StringCodePairValue).Value)
+					}
+				}
+			}
+			nextPair, err = reader.readCodePair()
+		}
+
+		if handle != 0 && len(chunks) > 0 {
+			data[handle] = stringsToBytes(chunks)
+		}
+	}
+	return
+}
+
+// applyAcdsData attaches the SAB data to the entities that own it and adds the equivalent SAT text so the payload is
+// kept when the drawing is written.
+func applyAcdsData(d *Drawing, data map[Handle][]byte) {
+	apply := func(entities []Entity) {
+		for _, e := range entities {
+			if g, ok := e.(ModelerGeometry); ok {
+				if sab, ok := data[e.Handle()]; ok {
+					_, _, _, binaryData := g.modelerData()
+					if len(g.AcisLines()) == 0 {
+						if file, err := readSab(sab); err == nil {
+							SetAcisSatText(g, file.satText(), d.Header.Version)
+						}
+					}
+					*binaryData = sab
+				}
+			}
+		}
+	}
+	apply(d.Entities)
+	for i := range d.Blocks {
+		apply(d.Blocks[i].Entities)
+	}
+}
+
+// acdsChunkLength is the number of bytes written with each 310 code pair of an ACDSDATA record.
+const acdsChunkLength = 127
+
+// acdsDataCodePairs returns the records that store the SAB data of each entity, or nothing if no entity has any.
+func acdsDataCodePairs(d *Drawing) (pairs []CodePair) {
+	var records []CodePair
+	collect := func(entities []Entity) {
+		for _, e := range entities {
+			g, ok := e.(ModelerGeometry)
+			if !ok {
+				continue
+			}
+			_, _, _, binaryData := g.modelerData()
+			if len(*binaryData) == 0 || e.Handle() == 0 {
+				continue
+			}
+			records = append(records, NewStringCodePair(0, "ACDSRECORD"))
+			records = append(records, NewIntCodePair(90, 0))
+			records = append(records, NewStringCodePair(2, "AcDbDs::ID"))
+			records = append(records, NewShortCodePair(280, 10))
+			records = append(records, NewStringCodePair(320, stringFromHandle(e.Handle())))
+			records = append(records, NewStringCodePair(2, "ASM_Data"))
+			records = append(records, NewShortCodePair(280, 15))
+			records = append(records, NewIntCodePair(94, len(*binaryData)))
+			for start := 0; start < len(*binaryData); start += acdsChunkLength {
+				end := start + acdsChunkLength
+				if end > len(*binaryData) {
+					end = len(*binaryData)
+				}
+				records = append(records, NewStringCodePair(310, bytesToStrings((*binaryData)[start:end])[0]))
+			}
+		}
+	}
+	collect(d.Entities)
+	for i := range d.Blocks {
+		collect(d.Blocks[i].Entities)
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	pairs = append(pairs, NewShortCodePair(70, 2))
+	pairs = append(pairs, NewShortCodePair(71, 1))
+	pairs = append(pairs, NewStringCodePair(0, "ACDSSCHEMA"))
+	pairs = append(pairs, NewIntCodePair(90, 0))
+	pairs = append(pairs, NewStringCodePair(1, "AcDb3DSolid_ASM_Data"))
+	pairs = append(pairs, NewStringCodePair(2, "AcDbDs::ID"))
+	pairs = append(pairs, NewShortCodePair(280, 10))
+	pairs = append(pairs, NewIntCodePair(91, 8))
+	pairs = append(pairs, NewStringCodePair(2, "ASM_Data"))
+	pairs = append(pairs, NewShortCodePair(280, 15))
+	pairs = append(pairs, NewIntCodePair(91, 0))
+	pairs = append(pairs, records...)
+	return
+}
+
+// writeAcdsDataSection writes the SAB data of R2013 and later files, since the entities themselves don't carry it.
+func writeAcdsDataSection(d *Drawing, writer codePairWriter) error {
+	pairs := acdsDataCodePairs(d)
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	err := writeSectionStart(writer, "ACDSDATA")
+	if err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		err = writer.writeCodePair(pair)
+		if err != nil {
+			return err
+		}
+	}
+	return writeSectionEnd(writer)
+}
@@ -0,0 +1,293 @@
+package dxf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+const sphereSat = `700 0 1 0
+@8 unittest @11 ACIS 7.0 NT @24 Thu Jan 01 00:00:00 2009
+1 9.9999999999999995e-007 1e-010
+body $-1 -1 $-1 $1 $-1 $-1 #
+lump $-1 -1 $-1 $-1 $2 $0 #
+shell $-1 -1 $-1 $-1 $-1 $3 $-1 $1 #
+face $-1 -1 $-1 $-1 $-1 $2 $-1 $4 forward single #
+sphere-surface $-1 -1 $-1 1 2 3 5 1 0 0 0 0 1 forward_v I I I I #
+End-of-ACIS-data`
+
+const diskSat = `700 0 1 0
+@8 unittest @11 ACIS 7.0 NT @24 Thu Jan 01 00:00:00 2009
+1 9.9999999999999995e-007 1e-010
+-0 body $-1 -1 $-1 $1 $-1 $2 #
+-1 lump $-1 -1 $-1 $-1 $3 $0 #
+-2 transform $-1 -1 1 0 0 0 1 0 0 0 1 10 0 0 1 no_rotate no_reflect no_shear #
+-3 shell $-1 -1 $-1 $-1 $-1 $4 $-1 $1 #
+-4 face $-1 -1 $-1 $-1 $5 $3 $-1 $6 reversed single #
+-5 loop $-1 -1 $-1 $-1 $7 $4 #
+-6 plane-surface $-1 -1 $-1 0 0 0 0 0 1 1 0 0 forward_v I I I I #
+-7 coedge $-1 -1 $-1 $7 $7 $-1 $8 forward $5 $-1 #
+-8 edge $-1 -1 $-1 $9 0 $9 6.2831853071795862 $7 $10 forward @7 unknown #
+-9 vertex $-1 -1 $-1 $8 $11 #
+-10 ellipse-curve $-1 -1 $-1 0 0 0 0 0 1 2 0 0 1 I I #
+-11 point $-1 -1 $-1 2 0 0 #
+End-of-ACIS-data`
+
+func TestAcisTextRotationIsReversible(t *testing.T) {
+	plain := "body $-1 -1 $-1 $1 #"
+	encrypted := rotateAcisText(plain)
+	assert(t, encrypted != plain, "expected the text to change")
+	assertEqString(t, "=0;& {rn rn {rn {n |", encrypted)
+	assertEqString(t, plain, rotateAcisText(encrypted))
+}
+
+func TestAcisSatTextDecryptsOlderVersions(t *testing.T) {
+	body := NewBody()
+	SetAcisSatText(body, sphereSat, R2000)
+	assert(t, isEncryptedSat(body.AcisLines()[0]), "expected encrypted lines")
+	sat, err := AcisSatText(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqString(t, sphereSat, sat)
+
+	SetAcisSatText(body, sphereSat, R2004)
+	assertEqString(t, "700 0 1 0", body.AcisLines()[0])
+	sat, err = AcisSatText(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqString(t, sphereSat, sat)
+}
+
+func TestParseSatSphere(t *testing.T) {
+	model, err := ParseSat(sphereSat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 700, model.Version)
+	assertEqInt(t, 1, len(model.Bodies))
+	assertEqInt(t, 1, len(model.Bodies[0].Faces))
+	sphere := model.Bodies[0].Faces[0].Surface.(AcisSphereSurface)
+	assertEqPoint(t, Point{X: 1.0, Y: 2.0, Z: 3.0}, sphere.Center)
+	assertEqFloat64(t, 5.0, sphere.Radius)
+	bounds := model.Bounds()
+	assertEqPoint(t, Point{X: -4.0, Y: -3.0, Z: -2.0}, bounds.Min)
+	assertEqPoint(t, Point{X: 6.0, Y: 7.0, Z: 8.0}, bounds.Max)
+}
+
+func TestParseSatFaceBoundsFollowEdgesAndTransform(t *testing.T) {
+	model, err := ParseSat(diskSat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face := model.Bodies[0].Faces[0]
+	assert(t, face.IsReversed, "expected reversed face")
+	plane := face.Surface.(AcisPlaneSurface)
+	assertEqVector(t, Vector{X: 0.0, Y: 0.0, Z: 1.0}, plane.Normal)
+	assertNearPoint(t, Point{X: 8.0, Y: -2.0, Z: 0.0}, face.Bounds.Min)
+	assertNearPoint(t, Point{X: 12.0, Y: 2.0, Z: 0.0}, face.Bounds.Max)
+}
+
+func TestBoundingBoxOfModelerGeometry(t *testing.T) {
+	solid := NewSolid3D()
+	SetAcisSatText(solid, sphereSat, R12)
+	b := BoundingBox(solid)
+	assertEqPoint(t, Point{X: -4.0, Y: -3.0, Z: -2.0}, b.Min)
+
+	solid.SetAcisLines([]string{"not acis"})
+	assert(t, BoundingBox(solid).IsEmpty(), "expected an empty box")
+}
+
+// sabTestWriter writes the tagged values of a SAB payload.
+type sabTestWriter struct {
+	bytes.Buffer
+}
+
+func (w *sabTestWriter) int32(tag byte, value int) {
+	w.WriteByte(tag)
+	binary.Write(w, binary.LittleEndian, int32(value))
+}
+
+func (w *sabTestWriter) double(value float64) {
+	w.WriteByte(sabDouble)
+	binary.Write(w, binary.LittleEndian, math.Float64bits(value))
+}
+
+func (w *sabTestWriter) string(tag byte, value string) {
+	w.WriteByte(tag)
+	w.WriteByte(byte(len(value)))
+	w.WriteString(value)
+}
+
+func (w *sabTestWriter) record(entityType string, values func()) {
+	parts := strings.Split(entityType, "-")
+	for _, prefix := range parts[:len(parts)-1] {
+		w.string(sabEntityTypeEx, prefix)
+	}
+	w.string(sabEntityType, parts[len(parts)-1])
+	w.int32(sabPointer, -1) // attributes
+	w.int32(sabInt, -1)
+	w.int32(sabPointer, -1)
+	values()
+	w.WriteByte(sabRecordEnd)
+}
+
+func sphereSab() []byte {
+	w := &sabTestWriter{}
+	w.WriteString(sabSignature)
+	for _, value := range []int{700, 0, 1, 0} {
+		binary.Write(w, binary.LittleEndian, int32(value))
+	}
+	w.string(sabString, "unittest")
+	w.string(sabString, "ACIS 7.0 NT")
+	w.string(sabString, "Thu Jan 01 00:00:00 2009")
+	w.double(1.0)
+	w.double(0.000001)
+	w.double(1e-10)
+	w.record("body", func() {
+		for _, pointer := range []int{1, -1, -1} {
+			w.int32(sabPointer, pointer)
+		}
+	})
+	w.record("lump", func() {
+		for _, pointer := range []int{-1, 2, 0} {
+			w.int32(sabPointer, pointer)
+		}
+	})
+	w.record("shell", func() {
+		for _, pointer := range []int{-1, -1, 3, -1, 1} {
+			w.int32(sabPointer, pointer)
+		}
+	})
+	w.record("face", func() {
+		for _, pointer := range []int{-1, -1, 2, -1, 4} {
+			w.int32(sabPointer, pointer)
+		}
+		w.WriteByte(sabFalse)
+		w.WriteByte(sabFalse)
+	})
+	w.record("sphere-surface", func() {
+		w.WriteByte(sabLocation)
+		for _, value := range []float64{1.0, 2.0, 3.0} {
+			binary.Write(w, binary.LittleEndian, math.Float64bits(value))
+		}
+		w.double(5.0)
+		w.WriteByte(sabFalse)
+	})
+	w.string(sabEntityTypeEx, "End")
+	w.string(sabEntityTypeEx, "of")
+	w.string(sabEntityTypeEx, "ASM")
+	w.string(sabEntityType, "data")
+	return w.Bytes()
+}
+
+func TestParseSab(t *testing.T) {
+	model, err := ParseSab(sphereSab())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(model.Bodies))
+	sphere := model.Bodies[0].Faces[0].Surface.(AcisSphereSurface)
+	assertEqPoint(t, Point{X: 1.0, Y: 2.0, Z: 3.0}, sphere.Center)
+	assertEqFloat64(t, 5.0, sphere.Radius)
+	assert(t, !model.Bodies[0].Faces[0].IsReversed, "expected forward face")
+}
+
+func TestAcisSatTextFromBinaryData(t *testing.T) {
+	region := NewRegion()
+	region.AcisBinaryData = sphereSab()
+	sat, err := AcisSatText(region)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(sat, "\n")
+	assertEqString(t, "700 0 1 0", lines[0])
+	assertEqString(t, "@8 unittest @11 ACIS 7.0 NT @24 Thu Jan 01 00:00:00 2009", lines[1])
+	assertEqString(t, "-4 sphere-surface $-1 -1 $-1 1 2 3 5 forward_v #", lines[7])
+	assertEqString(t, "End-of-ASM-data", lines[8])
+}
+
+func TestReadAcdsDataSection(t *testing.T) {
+	sab := sphereSab()
+	drawing := parseFromCodePairs(t,
+		NewStringCodePair(0, "SECTION"),
+		NewStringCodePair(2, "HEADER"),
+		NewStringCodePair(9, "$ACADVER"),
+		NewStringCodePair(1, "AC1027"),
+		NewStringCodePair(0, "ENDSEC"),
+		NewStringCodePair(0, "SECTION"),
+		NewStringCodePair(2, "ENTITIES"),
+		NewStringCodePair(0, "3DSOLID"),
+		NewStringCodePair(5, "2A"),
+		NewStringCodePair(100, "AcDbModelerGeometry"),
+		NewShortCodePair(70, 2),
+		NewStringCodePair(0, "ENDSEC"),
+		NewStringCodePair(0, "SECTION"),
+		NewStringCodePair(2, "ACDSDATA"),
+		NewShortCodePair(70, 2),
+		NewShortCodePair(71, 2),
+		NewStringCodePair(0, "ACDSSCHEMA"),
+		NewIntCodePair(90, 0),
+		NewStringCodePair(1, "AcDb3DSolid_ASM_Data"),
+		NewStringCodePair(0, "ACDSRECORD"),
+		NewIntCodePair(90, 0),
+		NewStringCodePair(2, "AcDbDs::ID"),
+		NewShortCodePair(280, 10),
+		NewStringCodePair(320, "2A"),
+		NewStringCodePair(2, "ASM_Data"),
+		NewShortCodePair(280, 15),
+		NewIntCodePair(94, len(sab)),
+		NewStringCodePair(310, bytesToStrings(sab[:40])[0]),
+		NewStringCodePair(310, bytesToStrings(sab[40:])[0]),
+		NewStringCodePair(0, "ENDSEC"),
+		NewStringCodePair(0, "EOF"),
+	)
+	solid := drawing.Entities[0].(*Solid3D)
+	assertEqByteArray(t, sab, solid.AcisBinaryData)
+	assertEqString(t, "700 0 1 0", solid.AcisLines()[0])
+	model, err := ParseAcis(solid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqFloat64(t, 5.0, model.Bodies[0].Faces[0].Surface.(AcisSphereSurface).Radius)
+}
+
+func TestWriteAcdsDataSection(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2013
+	solid := NewSolid3D()
+	solid.AcisBinaryData = sphereSab()
+	drawing.Entities = append(drawing.Entities, solid)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "SECTION"),
+		NewStringCodePair(2, "ACDSDATA"),
+	}, drawingCodePairs(t, drawing))
+
+	drawing = roundTripDrawing(t, &drawing)
+	rt := drawing.Entities[0].(*Solid3D)
+	assertEqByteArray(t, sphereSab(), rt.AcisBinaryData)
+}
+
+func TestNoAcdsDataSectionBeforeR2013(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2010
+	solid := NewSolid3D()
+	solid.AcisBinaryData = sphereSab()
+	drawing.Entities = append(drawing.Entities, solid)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(2, "ACDSDATA"),
+	}, drawingCodePairs(t, drawing))
+}
+
+func TestCloneKeepsAcisBinaryData(t *testing.T) {
+	solid := NewSolid3D()
+	solid.AcisBinaryData = sphereSab()
+	clone, err := cloneEntity(solid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqByteArray(t, sphereSab(), clone.(*Solid3D).AcisBinaryData)
+}
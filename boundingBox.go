@@ -0,0 +1,520 @@
+package dxf
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// textCharacterWidth approximates the advance of a single character relative to the text height.
+const textCharacterWidth = 1.0
+
+// splineSamplesPerSpan is the number of evaluations per knot span used to bound a spline.
+const splineSamplesPerSpan = 32
+
+// Bounds represents an axis aligned box in world coordinates.  The zero value is an empty box.
+type Bounds struct {
+	Min      Point
+	Max      Point
+	hasValue bool
+}
+
+// NewBounds creates an empty bounding box.
+func NewBounds() *Bounds {
+	return &Bounds{}
+}
+
+// IsEmpty returns true if no points have been added to the box.
+func (b Bounds) IsEmpty() bool {
+	return !b.hasValue
+}
+
+// AddPoint grows the box to include `p`.
+func (b *Bounds) AddPoint(p Point) {
+	if !b.hasValue {
+		b.Min = p
+		b.Max = p
+		b.hasValue = true
+		return
+	}
+
+	b.Min = Point{X: math.Min(b.Min.X, p.X), Y: math.Min(b.Min.Y, p.Y), Z: math.Min(b.Min.Z, p.Z)}
+	b.Max = Point{X: math.Max(b.Max.X, p.X), Y: math.Max(b.Max.Y, p.Y), Z: math.Max(b.Max.Z, p.Z)}
+}
+
+// Union grows the box to include `other`.
+func (b *Bounds) Union(other Bounds) {
+	if other.hasValue {
+		b.AddPoint(other.Min)
+		b.AddPoint(other.Max)
+	}
+}
+
+// extrude grows the box to include itself offset by `v`, covering geometry extruded by a thickness.
+func (b *Bounds) extrude(v Vector) {
+	if b.hasValue && !v.IsZero(0.0) {
+		b.AddPoint(b.Min.Add(v))
+		b.AddPoint(b.Max.Add(v))
+	}
+}
+
+// addEllipticalArc adds the curve `center + u*cos(t) + v*sin(t)` for `t` from `start` to `end` radians, using the
+// exact extremes along each axis.
+func (b *Bounds) addEllipticalArc(center Point, u, v Vector, start, end float64) {
+	for end < start {
+		end += 2.0 * math.Pi
+	}
+	pointAt := func(t float64) Point {
+		return center.Add(u.Scale(math.Cos(t)).Add(v.Scale(math.Sin(t))))
+	}
+	b.AddPoint(pointAt(start))
+	b.AddPoint(pointAt(end))
+
+	// each coordinate is extreme where its derivative `-u*sin(t) + v*cos(t)` vanishes
+	for _, extreme := range []float64{math.Atan2(v.X, u.X), math.Atan2(v.Y, u.Y), math.Atan2(v.Z, u.Z)} {
+		for _, t := range []float64{extreme, extreme + math.Pi} {
+			for t < start {
+				t += 2.0 * math.Pi
+			}
+			for t-2.0*math.Pi >= start {
+				t -= 2.0 * math.Pi
+			}
+			if t <= end {
+				b.AddPoint(pointAt(t))
+			}
+		}
+	}
+}
+
+// addOcsArc adds an arc expressed in the OCS of `normal` with angles in radians.
+func (b *Bounds) addOcsArc(center Point, radius float64, normal Vector, start, end float64) {
+	xAxis, yAxis := normal.ArbitraryAxes()
+	b.addEllipticalArc(center.OcsToWcs(normal), xAxis.Scale(radius), yAxis.Scale(radius), start, end)
+}
+
+// addBulgePolyline adds the OCS polyline described by `points` and `bulges`.
+func (b *Bounds) addBulgePolyline(points []Point, bulges []float64, closed bool, normal Vector) {
+	for i, p := range points {
+		b.AddPoint(p.OcsToWcs(normal))
+		if i == len(points)-1 && !closed {
+			break
+		}
+		next := points[(i+1)%len(points)]
+		if bulges[i] != 0.0 && p.DistanceTo(next) > 0.0 {
+			center, radius, startAngle, sweep := bulgeArc(p, next, bulges[i])
+			if sweep < 0.0 {
+				startAngle += sweep
+				sweep = -sweep
+			}
+			b.addOcsArc(center, radius, normal, startAngle, startAngle+sweep)
+		}
+	}
+}
+
+// addOcsRectangle adds a rectangle anchored at the OCS point `origin` that is rotated by `rotation` degrees and spans
+// [x0, x1] along its baseline and [y0, y1] perpendicular to it.
+func (b *Bounds) addOcsRectangle(origin Point, normal Vector, rotation, x0, x1, y0, y1 float64) {
+	radians := rotation * math.Pi / 180.0
+	cos := math.Cos(radians)
+	sin := math.Sin(radians)
+	for _, corner := range [][2]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}} {
+		p := Point{
+			X: origin.X + corner[0]*cos - corner[1]*sin,
+			Y: origin.Y + corner[0]*sin + corner[1]*cos,
+			Z: origin.Z,
+		}
+		b.AddPoint(p.OcsToWcs(normal))
+	}
+}
+
+// addHatchBounds adds the boundary paths of a hatch.
+func addHatchBounds(b *Bounds, h *Hatch) {
+	elevation := h.ElevationPoint.Z
+	xAxis, yAxis := h.Normal.ArbitraryAxes()
+	toWcs := func(p Point) Point {
+		return Point{X: p.X, Y: p.Y, Z: elevation}.OcsToWcs(h.Normal)
+	}
+	for _, path := range h.BoundaryPaths {
+		if path.IsPolyline() {
+			points := make([]Point, len(path.Vertices))
+			bulges := make([]float64, len(path.Vertices))
+			for i, v := range path.Vertices {
+				points[i] = Point{X: v.X, Y: v.Y, Z: elevation}
+				bulges[i] = v.Bulge
+			}
+			b.addBulgePolyline(points, bulges, path.IsClosed, h.Normal)
+			continue
+		}
+
+		for _, edge := range path.Edges {
+			switch e := edge.(type) {
+			case *HatchLineEdge:
+				b.AddPoint(toWcs(e.Start))
+				b.AddPoint(toWcs(e.End))
+			case *HatchArcEdge:
+				start, end := hatchEdgeRange(e.StartAngle, e.EndAngle, e.IsCounterClockwise)
+				b.addOcsArc(Point{X: e.Center.X, Y: e.Center.Y, Z: elevation}, e.Radius, h.Normal, start, end)
+			case *HatchEllipseEdge:
+				start, end := hatchEdgeRange(e.StartAngle, e.EndAngle, e.IsCounterClockwise)
+				u := xAxis.Scale(e.MajorAxis.X).Add(yAxis.Scale(e.MajorAxis.Y))
+				v := h.Normal.Normalize().Cross(u).Scale(e.MinorAxisRatio)
+				b.addEllipticalArc(toWcs(e.Center), u, v, start, end)
+			case *HatchSplineEdge:
+				spline := NewSpline()
+				spline.DegreeOfCurve = e.Degree
+				spline.KnotValues = e.Knots
+				spline.ControlPoints = e.ControlPoints
+				spline.FitPoints = e.FitPoints
+				for _, p := range spline.samplePoints(splineSamplesPerSpan) {
+					b.AddPoint(toWcs(p))
+				}
+			}
+		}
+	}
+}
+
+// addMLeaderBounds adds the leader lines, the mtext content and the location of the block content of an mleader.
+func addMLeaderBounds(b *Bounds, l *MLeader) {
+	c := &l.Context
+	for _, root := range c.Roots {
+		if root.HasConnectionPoint {
+			b.AddPoint(root.ConnectionPoint)
+		}
+		for _, line := range root.Lines {
+			for _, p := range line.Vertices {
+				b.AddPoint(p)
+			}
+		}
+	}
+	if c.HasMText {
+		text := NewMText()
+		text.Text = c.MText.Text
+		text.InsertionPoint = c.MText.Location
+		text.InitialTextHeight = c.TextHeight
+		text.ReferenceRectangleWidth = c.MText.BoundaryWidth
+		text.AttachmentPoint = AttachmentPoint(l.TextAttachmentPoint)
+		text.ExtrusionDirection = c.MText.Normal
+		text.XAxisDirection = c.MText.Direction
+		addMTextBounds(b, text)
+	}
+	if c.HasBlock {
+		b.AddPoint(c.Block.Location)
+	}
+}
+
+// BoundingBox returns the world coordinate extents of an entity.  Curves are bounded exactly, splines by evaluation
+// and text is approximated from its height and width factor.  Infinite entities and entities with opaque geometry
+// return an empty box.  An `Insert` can't be resolved without its drawing, so only its location is included; use
+// `Drawing.BoundingBox` to include the block contents.
+func BoundingBox(e Entity) Bounds {
+	b := *NewBounds()
+	switch ent := e.(type) {
+	case *Line:
+		b.AddPoint(ent.P1)
+		b.AddPoint(ent.P2)
+		b.extrude(ent.ExtrusionDirection.Normalize().Scale(ent.Thickness))
+	case *ModelPoint:
+		b.AddPoint(ent.Location)
+		b.extrude(ent.ExtrusionDirection.Normalize().Scale(ent.Thickness))
+	case *Face:
+		b.AddPoint(ent.FirstCorner)
+		b.AddPoint(ent.SecondCorner)
+		b.AddPoint(ent.ThirdCorner)
+		b.AddPoint(ent.FourthCorner)
+	case *Solid:
+		for _, p := range []Point{ent.FirstCorner, ent.SecondCorner, ent.ThirdCorner, ent.FourthCorner} {
+			b.AddPoint(p.OcsToWcs(ent.ExtrusionDirection))
+		}
+		b.extrude(ent.ExtrusionDirection.Normalize().Scale(ent.Thickness))
+	case *Trace:
+		for _, p := range []Point{ent.FirstCorner, ent.SecondCorner, ent.ThirdCorner, ent.FourthCorner} {
+			b.AddPoint(p.OcsToWcs(ent.ExtrusionDirection))
+		}
+		b.extrude(ent.ExtrusionDirection.Normalize().Scale(ent.Thickness))
+	case *Circle:
+		b.addOcsArc(ent.Center, ent.Radius, ent.Normal, 0.0, 2.0*math.Pi)
+		b.extrude(ent.Normal.Normalize().Scale(ent.Thickness))
+	case *Arc:
+		b.addOcsArc(ent.Center, ent.Radius, ent.Normal, ent.StartAngle*math.Pi/180.0, ent.EndAngle*math.Pi/180.0)
+		b.extrude(ent.Normal.Normalize().Scale(ent.Thickness))
+	case *Ellipse:
+		minorAxis := ent.Normal.Normalize().Cross(ent.MajorAxis).Scale(ent.MinorAxisRatio)
+		b.addEllipticalArc(ent.Center, ent.MajorAxis, minorAxis, ent.StartAngle, ent.EndAngle)
+	case *LWPolyline:
+		points := make([]Point, len(ent.Vertices))
+		bulges := make([]float64, len(ent.Vertices))
+		for i, v := range ent.Vertices {
+			points[i] = Point{X: v.X, Y: v.Y, Z: ent.Elevation()}
+			bulges[i] = v.Bulge
+		}
+		b.addBulgePolyline(points, bulges, ent.IsClosed(), ent.ExtrusionDirection)
+		b.extrude(ent.ExtrusionDirection.Normalize().Scale(ent.Thickness))
+	case *Polyline:
+		if ent.Is3DPolyline() || ent.Is3DPolygonMesh() || ent.IsPolyfaceMesh() {
+			for _, v := range ent.Vertices {
+				b.AddPoint(v.Location)
+			}
+			break
+		}
+		points := make([]Point, len(ent.Vertices))
+		bulges := make([]float64, len(ent.Vertices))
+		for i, v := range ent.Vertices {
+			points[i] = Point{X: v.Location.X, Y: v.Location.Y, Z: ent.Location.Z}
+			bulges[i] = v.Bulge
+		}
+		b.addBulgePolyline(points, bulges, ent.IsClosed(), ent.Normal)
+		b.extrude(ent.Normal.Normalize().Scale(ent.Thickness))
+	case *Vertex:
+		b.AddPoint(ent.Location)
+	case *Spline:
+		for _, p := range ent.samplePoints(splineSamplesPerSpan) {
+			b.AddPoint(p)
+		}
+	case *Helix:
+		axis := ent.AxisVector.Normalize()
+		radius := ent.StartPoint.Sub(ent.AxisBasePoint).Sub(axis.Scale(ent.StartPoint.Sub(ent.AxisBasePoint).Dot(axis))).Length()
+		top := ent.AxisBasePoint.Add(axis.Scale(ent.NumberOfTurns * ent.TurnHeight))
+		for _, center := range []Point{ent.AxisBasePoint, top} {
+			b.addOcsArc(center.WcsToOcs(axis), radius, axis, 0.0, 2.0*math.Pi)
+		}
+	case *Hatch:
+		addHatchBounds(&b, ent)
+	case *MLeader:
+		addMLeaderBounds(&b, ent)
+	case *Viewport:
+		half := Vector{X: ent.Width / 2.0, Y: ent.Height / 2.0}
+		b.AddPoint(ent.Center.Add(half.Scale(-1.0)))
+		b.AddPoint(ent.Center.Add(half))
+	case *Leader:
+		for _, p := range ent.Vertices {
+			b.AddPoint(p)
+		}
+	case *MLine:
+		for _, p := range ent.Vertices {
+			b.AddPoint(p)
+		}
+	case *Text:
+		addTextBounds(&b, ent.Value, ent.Location, ent.SecondAlignmentPoint, ent.Normal, ent.Height, ent.Rotation,
+			ent.RelativeXScaleFactor, ent.HorizontalTextJustification, ent.VerticalTextJustification)
+	case *Attribute:
+		if !ent.IsInvisible() {
+			addTextBounds(&b, ent.Value, ent.Location, ent.SecondAlignmentPoint, ent.Normal, ent.TextHeight, ent.Rotation,
+				ent.RelativeXScaleFactor, ent.HorizontalTextJustification, ent.VerticalTextJustification)
+		}
+	case *AttributeDefinition:
+		addTextBounds(&b, ent.TextTag, ent.Location, ent.SecondAlignmentPoint, ent.Normal, ent.TextHeight, ent.Rotation,
+			ent.RelativeXScaleFactor, ent.HorizontalTextJustification, ent.VerticalTextJustification)
+	case *MText:
+		addMTextBounds(&b, ent)
+	case *Shape:
+		b.AddPoint(ent.Location.OcsToWcs(ent.ExtrusionDirection))
+	case *RText:
+		b.AddPoint(ent.InsertionPoint.OcsToWcs(ent.ExtrusionDirection))
+	case *Tolerance:
+		b.AddPoint(ent.InsertionPoint)
+	case *ArcAlignedText:
+		b.addOcsArc(ent.CenterPoint, ent.ArcRadius, ent.ExtrusionDirection, ent.StartAngle*math.Pi/180.0, ent.EndAngle*math.Pi/180.0)
+	case Dimension:
+		b.AddPoint(ent.DefinitionPoint1())
+		b.AddPoint(ent.TextMidPoint().OcsToWcs(ent.Normal()))
+	case *Insert:
+		b.AddPoint(ent.Location.OcsToWcs(ent.ExtrusionDirection))
+	case RasterImage:
+		size := ent.ImageSize()
+		u := ent.UVector().Scale(size.X)
+		v := ent.VVector().Scale(size.Y)
+		b.AddPoint(ent.Location())
+		b.AddPoint(ent.Location().Add(u))
+		b.AddPoint(ent.Location().Add(v))
+		b.AddPoint(ent.Location().Add(u).Add(v))
+	case Underlay:
+		b.AddPoint(ent.InsertionPoint())
+	case *Light:
+		b.AddPoint(ent.Position)
+	case *Ole2Frame:
+		b.AddPoint(ent.UpperLeftCorner)
+		b.AddPoint(ent.LowerRightCorner)
+	case *Table:
+		right, down := ent.tableAxes()
+		width, height := 0.0, 0.0
+		for _, w := range ent.ColumnWidths {
+			width += w
+		}
+		for _, h := range ent.RowHeights {
+			height += h
+		}
+		b.AddPoint(ent.InsertionPoint)
+		b.AddPoint(ent.InsertionPoint.Add(right.Scale(width)))
+		b.AddPoint(ent.InsertionPoint.Add(down.Scale(height)))
+		b.AddPoint(ent.InsertionPoint.Add(right.Scale(width)).Add(down.Scale(height)))
+	case ModelerGeometry:
+		// a payload that can't be parsed leaves the box empty
+		if model, err := ParseAcis(ent); err == nil {
+			b = model.Bounds()
+		}
+	}
+
+	return b
+}
+
+func addTextBounds(b *Bounds, value string, location, secondAlignment Point, normal Vector, height, rotation, widthFactor float64, horizontal HorizontalTextJustification, vertical VerticalTextJustification) {
+	if widthFactor == 0.0 {
+		widthFactor = 1.0
+	}
+	width := float64(utf8.RuneCountInString(value)) * height * widthFactor * textCharacterWidth
+	anchor := location
+	if horizontal == HorizontalTextJustificationAligned || horizontal == HorizontalTextJustificationFit {
+		// the text fills the baseline between the two alignment points
+		delta := secondAlignment.Sub(location)
+		width = math.Hypot(delta.X, delta.Y)
+		rotation = math.Atan2(delta.Y, delta.X) * 180.0 / math.Pi
+	} else if horizontal != HorizontalTextJustificationLeft || vertical != VerticalTextJustificationBaseline {
+		anchor = secondAlignment
+	}
+
+	x0 := 0.0
+	switch horizontal {
+	case HorizontalTextJustificationCenter, HorizontalTextJustificationMiddle:
+		x0 = -width / 2.0
+	case HorizontalTextJustificationRight:
+		x0 = -width
+	}
+	y0 := 0.0
+	switch vertical {
+	case VerticalTextJustificationMiddle:
+		y0 = -height / 2.0
+	case VerticalTextJustificationTop:
+		y0 = -height
+	}
+	if horizontal == HorizontalTextJustificationMiddle && vertical == VerticalTextJustificationBaseline {
+		y0 = -height / 2.0
+	}
+
+	b.addOcsRectangle(anchor, normal, rotation, x0, x0+width, y0, y0+height)
+}
+
+func addMTextBounds(b *Bounds, t *MText) {
+	lines := 1
+	longest := 0
+	current := 0
+	text := t.Text
+	for _, s := range t.ExtendedText {
+		text += s
+	}
+	for _, r := range text {
+		if r == '\n' {
+			lines++
+			current = 0
+			continue
+		}
+		current++
+		if current > longest {
+			longest = current
+		}
+	}
+
+	height := t.InitialTextHeight * (1.0 + float64(lines-1)*5.0/3.0)
+	width := t.ReferenceRectangleWidth
+	if width <= 0.0 {
+		width = float64(longest) * t.InitialTextHeight * textCharacterWidth
+	}
+
+	// attachment points are numbered left to right, then top to bottom
+	attachment := int(t.AttachmentPoint) - 1
+	if attachment < 0 || attachment > 8 {
+		attachment = 0
+	}
+	x0 := -width * float64(attachment%3) / 2.0
+	y1 := height * float64(attachment/3) / 2.0
+
+	normal := t.ExtrusionDirection
+	rotation := ocsAngle(t.xAxis(), normal) * 180.0 / math.Pi
+	b.addOcsRectangle(t.InsertionPoint.WcsToOcs(normal), normal, rotation, x0, x0+width, y1-height, y1)
+}
+
+// BoundingBox returns the world coordinate extents of an entity in the drawing.  Unlike the `BoundingBox` function
+// the contents of the blocks referenced by inserts, dimensions and the block content of mleaders are included.
+func (d *Drawing) BoundingBox(e Entity) Bounds {
+	b := BoundingBox(e)
+	switch ent := e.(type) {
+	case *Insert:
+		entities, err := d.ExplodeInsert(ent)
+		if err == nil {
+			for _, exploded := range entities {
+				b.Union(BoundingBox(exploded))
+			}
+		}
+	case Dimension:
+		// dimension blocks are already positioned in world coordinates
+		if block := d.findBlock(ent.BlockName()); block != nil && len(ent.BlockName()) > 0 {
+			for _, blockEntity := range block.Entities {
+				if _, isAttribute := blockEntity.(*AttributeDefinition); !isAttribute {
+					b.Union(d.BoundingBox(blockEntity))
+				}
+			}
+		}
+	case *MLeader:
+		if block := d.mleaderBlock(ent); block != nil {
+			for _, blockEntity := range block.Entities {
+				if _, isAttribute := blockEntity.(*AttributeDefinition); isAttribute {
+					continue
+				}
+				clone, err := cloneEntity(blockEntity)
+				if err != nil {
+					continue
+				}
+				if clone, err = Transform(clone, ent.Context.Block.TransformationMatrix); err == nil {
+					b.Union(d.BoundingBox(clone))
+				}
+			}
+		}
+	}
+
+	return b
+}
+
+// mleaderBlock returns the block shown as the content of an mleader or nil if it has no block content.
+func (d *Drawing) mleaderBlock(l *MLeader) *Block {
+	if !l.Context.HasBlock || l.Context.Block.BlockHandle == 0 {
+		return nil
+	}
+	for _, record := range d.BlockRecords {
+		if record.handle == l.Context.Block.BlockHandle {
+			return d.findBlock(record.Name)
+		}
+	}
+	return nil
+}
+
+// Extents returns the world coordinate extents of the model space entities.
+func (d *Drawing) Extents() Bounds {
+	return d.spaceExtents(false)
+}
+
+// PaperSpaceExtents returns the world coordinate extents of the paper space entities.
+func (d *Drawing) PaperSpaceExtents() Bounds {
+	return d.spaceExtents(true)
+}
+
+func (d *Drawing) spaceExtents(paperSpace bool) Bounds {
+	b := *NewBounds()
+	for _, e := range d.Entities {
+		if e.IsInPaperSpace() == paperSpace {
+			b.Union(d.BoundingBox(e))
+		}
+	}
+	return b
+}
+
+// UpdateExtents sets the `$EXTMIN`/`$EXTMAX` and `$PEXTMIN`/`$PEXTMAX` header variables from the current entities.
+// Empty extents are written as an inverted box, matching what AutoCAD writes for an empty drawing.
+func (d *Drawing) UpdateExtents() {
+	d.Header.MinimumDrawingExtents, d.Header.MaximumDrawingExtents = headerExtents(d.Extents())
+	d.Header.PaperspaceMinimumDrawingExtents, d.Header.PaperspaceMaximumDrawingExtents = headerExtents(d.PaperSpaceExtents())
+}
+
+func headerExtents(b Bounds) (min, max Point) {
+	if b.IsEmpty() {
+		return Point{X: 1.0e20, Y: 1.0e20, Z: 1.0e20}, Point{X: -1.0e20, Y: -1.0e20, Z: -1.0e20}
+	}
+	return b.Min, b.Max
+}
@@ -0,0 +1,178 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+func assertNearBounds(t *testing.T, expectedMin, expectedMax Point, actual Bounds) {
+	assert(t, !actual.IsEmpty(), "expected a non-empty bounding box")
+	assertNearPoint(t, expectedMin, actual.Min)
+	assertNearPoint(t, expectedMax, actual.Max)
+}
+
+func TestBoundingBoxArc(t *testing.T) {
+	arc := NewArc()
+	arc.Center = Point{1.0, 1.0, 0.0}
+	arc.Radius = 2.0
+	arc.StartAngle = 45.0
+	arc.EndAngle = 135.0
+	s := math.Sqrt(2.0)
+	assertNearBounds(t, Point{1.0 - s, 1.0 + s, 0.0}, Point{1.0 + s, 3.0, 0.0}, BoundingBox(arc))
+
+	// the arc wraps through 0 degrees
+	arc.StartAngle = 270.0
+	arc.EndAngle = 90.0
+	assertNearBounds(t, Point{1.0, -1.0, 0.0}, Point{3.0, 3.0, 0.0}, BoundingBox(arc))
+}
+
+func TestBoundingBoxMirroredArc(t *testing.T) {
+	arc := NewArc()
+	arc.Radius = 1.0
+	arc.Normal = Vector{0.0, 0.0, -1.0}
+	arc.StartAngle = 0.0
+	arc.EndAngle = 90.0
+	assertNearBounds(t, Point{-1.0, 0.0, 0.0}, Point{0.0, 1.0, 0.0}, BoundingBox(arc))
+}
+
+func TestBoundingBoxRotatedEllipse(t *testing.T) {
+	ellipse := NewEllipse()
+	ellipse.MajorAxis = Vector{1.0, 1.0, 0.0}
+	ellipse.MinorAxisRatio = 0.5
+	// the extremes along x and y of an ellipse with semi-axes a and b rotated by 45 degrees
+	a := math.Sqrt(2.0)
+	b := a / 2.0
+	extent := math.Sqrt((a*a + b*b) / 2.0)
+	assertNearBounds(t, Point{-extent, -extent, 0.0}, Point{extent, extent, 0.0}, BoundingBox(ellipse))
+}
+
+func TestBoundingBoxBulgedLWPolyline(t *testing.T) {
+	poly := NewLWPolyline()
+	poly.Vertices = []LwVertex{{X: 0.0, Y: 0.0, Bulge: 1.0}, {X: 2.0, Y: 0.0}}
+	assertNearBounds(t, Point{0.0, -1.0, 0.0}, Point{2.0, 0.0, 0.0}, BoundingBox(poly))
+	poly.Vertices[0].Bulge = -1.0
+	assertNearBounds(t, Point{0.0, 0.0, 0.0}, Point{2.0, 1.0, 0.0}, BoundingBox(poly))
+}
+
+func TestBoundingBoxSpline(t *testing.T) {
+	spline := NewSpline()
+	spline.DegreeOfCurve = 2
+	spline.KnotValues = []float64{0.0, 0.0, 0.0, 1.0, 1.0, 1.0}
+	spline.ControlPoints = []ControlPoint{
+		{Point{0.0, 0.0, 0.0}, 1.0},
+		{Point{1.0, 2.0, 0.0}, 1.0},
+		{Point{2.0, 0.0, 0.0}, 1.0},
+	}
+	assertNearBounds(t, Point{0.0, 0.0, 0.0}, Point{2.0, 1.0, 0.0}, BoundingBox(spline))
+}
+
+func halfDiskHatch(isCounterClockwise bool) *Hatch {
+	hatch := NewHatch()
+	path := *NewHatchEdgeBoundaryPath([]HatchEdge{
+		&HatchLineEdge{Start: Point{-1.0, 0.0, 0.0}, End: Point{1.0, 0.0, 0.0}},
+		&HatchArcEdge{Radius: 1.0, StartAngle: 0.0, EndAngle: 180.0, IsCounterClockwise: isCounterClockwise},
+	})
+	hatch.BoundaryPaths = append(hatch.BoundaryPaths, path)
+	return hatch
+}
+
+func TestBoundingBoxHatch(t *testing.T) {
+	assertNearBounds(t, Point{-1.0, 0.0, 0.0}, Point{1.0, 1.0, 0.0}, BoundingBox(halfDiskHatch(true)))
+
+	// clockwise angles are measured clockwise
+	assertNearBounds(t, Point{-1.0, -1.0, 0.0}, Point{1.0, 0.0, 0.0}, BoundingBox(halfDiskHatch(false)))
+
+	hatch := NewHatch()
+	hatch.ElevationPoint = Point{0.0, 0.0, 2.0}
+	vertices := []HatchVertex{{X: 0.0, Y: 0.0, Bulge: 1.0}, {X: 2.0, Y: 0.0}}
+	hatch.BoundaryPaths = append(hatch.BoundaryPaths, *NewHatchPolylineBoundaryPath(vertices, true))
+	assertNearBounds(t, Point{0.0, -1.0, 2.0}, Point{2.0, 0.0, 2.0}, BoundingBox(hatch))
+}
+
+func TestBoundingBoxText(t *testing.T) {
+	text := NewText()
+	text.Location = Point{1.0, 1.0, 0.0}
+	text.Height = 2.0
+	text.RelativeXScaleFactor = 0.5
+	text.Value = "abc"
+	assertNearBounds(t, Point{1.0, 1.0, 0.0}, Point{4.0, 3.0, 0.0}, BoundingBox(text))
+}
+
+func TestDrawingExtentsIncludesInsertContents(t *testing.T) {
+	d := NewDrawing()
+	block := *NewBlock()
+	block.Name = "B"
+	circle := NewCircle()
+	circle.Radius = 1.0
+	block.Entities = append(block.Entities, circle)
+	d.Blocks = append(d.Blocks, block)
+	insert := NewInsert()
+	insert.Name = "B"
+	insert.Location = Point{10.0, 10.0, 0.0}
+	insert.XScaleFactor = 2.0
+	insert.YScaleFactor = 2.0
+	d.Entities = append(d.Entities, insert)
+	paperLine := NewLine()
+	paperLine.SetIsInPaperSpace(true)
+	paperLine.P2 = Point{5.0, 5.0, 0.0}
+	d.Entities = append(d.Entities, paperLine)
+
+	assertNearBounds(t, Point{8.0, 8.0, 0.0}, Point{12.0, 12.0, 0.0}, d.Extents())
+	assertNearBounds(t, Point{0.0, 0.0, 0.0}, Point{5.0, 5.0, 0.0}, d.PaperSpaceExtents())
+}
+
+func TestUpdateExtentsOnSave(t *testing.T) {
+	d := NewDrawing()
+	line := NewLine()
+	line.P1 = Point{-1.0, -2.0, 0.0}
+	line.P2 = Point{3.0, 4.0, 0.0}
+	d.Entities = append(d.Entities, line)
+	d.UpdateExtentsOnSave = true
+	actual := d.String()
+	assertContains(t, join("  9", "$EXTMIN", " 10", "-1.0", " 20", "-2.0"), actual)
+	assertContains(t, join("  9", "$EXTMAX", " 10", "3.0", " 20", "4.0"), actual)
+	assertEqPoint(t, Point{1.0e20, 1.0e20, 1.0e20}, d.Header.PaperspaceMinimumDrawingExtents)
+}
+
+func TestDrawingExtentsIncludesHatch(t *testing.T) {
+	d := NewDrawing()
+	d.Entities = append(d.Entities, halfDiskHatch(true))
+	assertNearBounds(t, Point{-1.0, 0.0, 0.0}, Point{1.0, 1.0, 0.0}, d.Extents())
+}
+
+func TestBoundingBoxMLeader(t *testing.T) {
+	l := NewMLeader()
+	l.Context.TextHeight = 1.0
+	l.Context.MText.Text = "ab"
+	l.Context.MText.BoundaryWidth = 4.0
+	l.Context.MText.Location = Point{5.0, 2.0, 0.0}
+	l.Context.Roots = append(l.Context.Roots, MLeaderRoot{
+		HasConnectionPoint: true,
+		ConnectionPoint:    Point{4.0, 1.0, 0.0},
+		Lines:              []MLeaderLine{{Vertices: []Point{{0.0, -1.0, 0.0}, {3.0, 1.0, 0.0}}}},
+	})
+	assertNearBounds(t, Point{0.0, -1.0, 0.0}, Point{9.0, 2.0, 0.0}, BoundingBox(l))
+}
+
+func TestDrawingBoundingBoxIncludesMLeaderBlockContent(t *testing.T) {
+	d := NewDrawing()
+	record := *NewBlockRecord()
+	record.Name = "TAG"
+	record.handle = Handle(0x50)
+	d.BlockRecords = append(d.BlockRecords, record)
+	block := *NewBlock()
+	block.Name = "TAG"
+	circle := NewCircle()
+	circle.Radius = 1.0
+	block.Entities = append(block.Entities, circle)
+	d.Blocks = append(d.Blocks, block)
+
+	l := NewMLeader()
+	l.Context.HasMText = false
+	l.Context.HasBlock = true
+	l.Context.Block.BlockHandle = Handle(0x50)
+	l.Context.Block.Location = Point{10.0, 0.0, 0.0}
+	l.Context.Block.TransformationMatrix = *NewTranslationMatrix4(Vector{10.0, 0.0, 0.0})
+	assertNearBounds(t, Point{9.0, -1.0, 0.0}, Point{11.0, 1.0, 0.0}, d.BoundingBox(l))
+	assertNearBounds(t, Point{10.0, 0.0, 0.0}, Point{10.0, 0.0, 0.0}, BoundingBox(l))
+}
@@ -0,0 +1,405 @@
+package dxf
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Contour is a sequence of connected curves found by ChainContours.
+type Contour struct {
+	// Entities are listed in the order they're travelled.
+	Entities []Entity
+
+	// Reversed indicates which entities are travelled from their end back to their start.
+	Reversed []bool
+
+	// IsClosed is true when the contour returns to its starting point.
+	IsClosed bool
+
+	start    Point
+	end      Point
+	minIndex int
+}
+
+// Start returns the world point where the contour begins.
+func (c *Contour) Start() Point {
+	return c.start
+}
+
+// End returns the world point where the contour finishes; a closed contour finishes at its start.
+func (c *Contour) End() Point {
+	return c.end
+}
+
+// ToLWPolyline joins the contour into a single lightweight polyline.  The contour may only contain lines, arcs and
+// polylines lying in one plane.
+func (c *Contour) ToLWPolyline(tolerance float64) (*LWPolyline, error) {
+	var pieces []Entity
+	for i, e := range c.Entities {
+		var exploded []Entity
+		switch ent := e.(type) {
+		case *Line, *Arc:
+			pieces = append(pieces, e)
+			continue
+		case *LWPolyline:
+			exploded = ent.Explode()
+		case *Polyline:
+			var err error
+			if exploded, err = ent.Explode(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unable to convert entity of type %s to a polyline segment", e.typeString())
+		}
+		if c.Reversed[i] {
+			for j, k := 0, len(exploded)-1; j < k; j, k = j+1, k-1 {
+				exploded[j], exploded[k] = exploded[k], exploded[j]
+			}
+		}
+		pieces = append(pieces, exploded...)
+	}
+
+	poly, err := JoinLWPolyline(pieces, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	if c.IsClosed {
+		poly.Close()
+	}
+	return poly, nil
+}
+
+// ContourIssueType identifies a problem found while chaining contours.
+type ContourIssueType int
+
+const (
+	// ContourIssueGap marks the unconnected ends of open contours, paired with the nearest other unconnected end within
+	// the maximum gap.
+	ContourIssueGap ContourIssueType = iota
+
+	// ContourIssueOverlap marks an entity that duplicates the path of another between the same points; the duplicate
+	// is left out of every contour.
+	ContourIssueOverlap
+
+	// ContourIssueBranch marks a point where more than two entities meet.  Contours end at branches.
+	ContourIssueBranch
+
+	// ContourIssueDegenerate marks an entity whose start and end coincide without enclosing anything.
+	ContourIssueDegenerate
+
+	// ContourIssueUnsupported marks an entity that isn't a curve that can be chained.
+	ContourIssueUnsupported
+)
+
+func (t ContourIssueType) String() string {
+	switch t {
+	case ContourIssueGap:
+		return "gap"
+	case ContourIssueOverlap:
+		return "overlap"
+	case ContourIssueBranch:
+		return "branch"
+	case ContourIssueDegenerate:
+		return "degenerate"
+	case ContourIssueUnsupported:
+		return "unsupported"
+	default:
+		return fmt.Sprintf("ContourIssueType(%d)", int(t))
+	}
+}
+
+// ContourIssue describes a problem found while chaining contours.
+type ContourIssue struct {
+	Type ContourIssueType
+
+	// Location is the world point where the problem was found.
+	Location Point
+
+	// Entities are the entities involved.
+	Entities []Entity
+
+	// Distance is the size of a gap.
+	Distance float64
+}
+
+func (i ContourIssue) String() string {
+	if i.Type == ContourIssueGap {
+		return fmt.Sprintf("%s of %v at %v", i.Type, i.Distance, i.Location)
+	}
+	return fmt.Sprintf("%s at %v", i.Type, i.Location)
+}
+
+// contourPiece is an entity that can be chained along with its end points and their nodes.
+type contourPiece struct {
+	entity    Entity
+	index     int
+	path      []Point
+	startNode int
+	endNode   int
+	used      bool
+}
+
+// ChainContours orders and orients lines, arcs, ellipses, splines, circles and polylines into contours whose
+// consecutive entities meet within `tolerance`.  Closed contours run counter-clockwise when viewed from above their
+// plane and start with the entity that appears first in `entities`; open contours start from whichever of their end
+// entities appears first.  Contours are returned in the order of their first entity, along with any problems found;
+// unconnected ends are only reported as gaps when another unconnected end is within `maxGap`.
+func ChainContours(entities []Entity, tolerance, maxGap float64) (contours []Contour, issues []ContourIssue) {
+	var pieces []*contourPiece
+	for i, e := range entities {
+		var paths [][]Point
+		switch e.(type) {
+		case *Line, *Arc, *Circle, *Ellipse, *Spline, *LWPolyline, *Polyline:
+			paths = Tessellate(e, tolerance)
+		}
+		if len(paths) != 1 || len(paths[0]) == 0 {
+			issue := ContourIssue{Type: ContourIssueUnsupported, Entities: []Entity{e}}
+			if len(paths) == 1 {
+				issue.Type = ContourIssueDegenerate
+			}
+			issues = append(issues, issue)
+			continue
+		}
+
+		path := paths[0]
+		piece := &contourPiece{entity: e, index: i, path: path}
+		if path[0].DistanceTo(path[len(path)-1]) <= tolerance {
+			if pathLength(path) <= 2.0*tolerance {
+				issues = append(issues, ContourIssue{Type: ContourIssueDegenerate, Location: path[0], Entities: []Entity{e}})
+				continue
+			}
+
+			// closed curves are contours by themselves
+			contour := Contour{Entities: []Entity{e}, Reversed: []bool{false}, IsClosed: true, minIndex: i}
+			contour.orient([]*contourPiece{piece})
+			contours = append(contours, contour)
+			continue
+		}
+		pieces = append(pieces, piece)
+	}
+
+	// merge the end points that are within the tolerance into nodes
+	var nodes []Point
+	nodeFor := func(p Point) int {
+		for i, node := range nodes {
+			if node.DistanceTo(p) <= tolerance {
+				return i
+			}
+		}
+		nodes = append(nodes, p)
+		return len(nodes) - 1
+	}
+	for _, piece := range pieces {
+		piece.startNode = nodeFor(piece.path[0])
+		piece.endNode = nodeFor(piece.path[len(piece.path)-1])
+	}
+
+	// duplicated paths between the same nodes can't both be part of a contour
+	var remaining []*contourPiece
+	for _, piece := range pieces {
+		duplicate := false
+		for _, other := range remaining {
+			sameEnds := (piece.startNode == other.startNode && piece.endNode == other.endNode) ||
+				(piece.startNode == other.endNode && piece.endNode == other.startNode)
+			if sameEnds && pathMidpoint(piece.path).DistanceTo(pathMidpoint(other.path)) <= tolerance {
+				issues = append(issues, ContourIssue{Type: ContourIssueOverlap, Location: pathMidpoint(piece.path), Entities: []Entity{other.entity, piece.entity}})
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			remaining = append(remaining, piece)
+		}
+	}
+	pieces = remaining
+
+	incident := make([][]*contourPiece, len(nodes))
+	for _, piece := range pieces {
+		incident[piece.startNode] = append(incident[piece.startNode], piece)
+		incident[piece.endNode] = append(incident[piece.endNode], piece)
+	}
+	for i, node := range nodes {
+		if len(incident[i]) > 2 {
+			var branchEntities []Entity
+			for _, piece := range incident[i] {
+				branchEntities = append(branchEntities, piece.entity)
+			}
+			issues = append(issues, ContourIssue{Type: ContourIssueBranch, Location: node, Entities: branchEntities})
+		}
+	}
+
+	// follow the pieces from a node until the path ends, branches, or returns to where it started
+	walk := func(first *contourPiece, fromNode int) Contour {
+		contour := Contour{minIndex: first.index}
+		var chain []*contourPiece
+		piece := first
+		node := fromNode
+		for piece != nil && !piece.used {
+			piece.used = true
+			reversed := piece.startNode != node
+			chain = append(chain, piece)
+			contour.Entities = append(contour.Entities, piece.entity)
+			contour.Reversed = append(contour.Reversed, reversed)
+			if piece.index < contour.minIndex {
+				contour.minIndex = piece.index
+			}
+			node = piece.endNode
+			if reversed {
+				node = piece.startNode
+			}
+
+			next := piece
+			piece = nil
+			if len(incident[node]) == 2 {
+				for _, candidate := range incident[node] {
+					if candidate != next {
+						piece = candidate
+					}
+				}
+			}
+		}
+		contour.IsClosed = node == fromNode && len(incident[node]) == 2
+		contour.orient(chain)
+		return contour
+	}
+
+	// open contours start at dangling ends or branches, then whatever remains is a loop
+	sort.SliceStable(pieces, func(i, j int) bool { return pieces[i].index < pieces[j].index })
+	for _, piece := range pieces {
+		for _, node := range []int{piece.startNode, piece.endNode} {
+			if !piece.used && len(incident[node]) != 2 {
+				contours = append(contours, walk(piece, node))
+			}
+		}
+	}
+	for _, piece := range pieces {
+		if !piece.used {
+			contours = append(contours, walk(piece, piece.startNode))
+		}
+	}
+
+	sort.SliceStable(contours, func(i, j int) bool { return contours[i].minIndex < contours[j].minIndex })
+	issues = append(issues, contourGaps(contours, tolerance, maxGap)...)
+	return
+}
+
+// orient sets the contour's end points and makes closed contours run counter-clockwise from their first entity.
+func (c *Contour) orient(chain []*contourPiece) {
+	pathFor := func(i int) []Point {
+		path := chain[i].path
+		if c.Reversed[i] {
+			reversed := make([]Point, len(path))
+			for j, p := range path {
+				reversed[len(path)-1-j] = p
+			}
+			path = reversed
+		}
+		return path
+	}
+
+	if c.IsClosed {
+		var points []Point
+		for i := range chain {
+			points = append(points, pathFor(i)...)
+		}
+		if newellNormal(points).Z < 0.0 {
+			c.reverse(chain)
+		}
+
+		// begin with the entity that came first
+		first := 0
+		for i, piece := range chain {
+			if piece.index < chain[first].index {
+				first = i
+			}
+		}
+		c.Entities = append(c.Entities[first:], c.Entities[:first]...)
+		c.Reversed = append(c.Reversed[first:], c.Reversed[:first]...)
+		chain = append(chain[first:], chain[:first]...)
+	}
+
+	first := pathFor(0)
+	last := pathFor(len(chain) - 1)
+	c.start = first[0]
+	c.end = last[len(last)-1]
+	if c.IsClosed {
+		c.end = c.start
+	}
+}
+
+// reverse changes the direction of travel along the contour.
+func (c *Contour) reverse(chain []*contourPiece) {
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+		c.Entities[i], c.Entities[j] = c.Entities[j], c.Entities[i]
+		c.Reversed[i], c.Reversed[j] = c.Reversed[j], c.Reversed[i]
+	}
+	for i := range c.Reversed {
+		c.Reversed[i] = !c.Reversed[i]
+	}
+}
+
+// contourGaps pairs each unconnected end of an open contour with the nearest other unconnected end no further than
+// `maxGap` away, which may be the other end of the same contour.
+func contourGaps(contours []Contour, tolerance, maxGap float64) (issues []ContourIssue) {
+	type end struct {
+		point  Point
+		entity Entity
+	}
+	var ends []end
+	for _, contour := range contours {
+		if !contour.IsClosed {
+			ends = append(ends, end{contour.start, contour.Entities[0]}, end{contour.end, contour.Entities[len(contour.Entities)-1]})
+		}
+	}
+
+	reported := map[[2]int]bool{}
+	for i, e := range ends {
+		nearest := -1
+		for j, other := range ends {
+			distance := e.point.DistanceTo(other.point)
+			if i != j && distance <= maxGap && (nearest < 0 || distance < e.point.DistanceTo(ends[nearest].point)) {
+				nearest = j
+			}
+		}
+		if nearest < 0 || e.point.DistanceTo(ends[nearest].point) <= tolerance {
+			// ends that meet are branches rather than gaps
+			continue
+		}
+		key := [2]int{i, nearest}
+		if nearest < i {
+			key = [2]int{nearest, i}
+		}
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+		issues = append(issues, ContourIssue{
+			Type:     ContourIssueGap,
+			Location: e.point.Lerp(ends[nearest].point, 0.5),
+			Entities: []Entity{e.entity, ends[nearest].entity},
+			Distance: e.point.DistanceTo(ends[nearest].point),
+		})
+	}
+	return
+}
+
+func pathLength(points []Point) (length float64) {
+	for i := 1; i < len(points); i++ {
+		length += points[i].DistanceTo(points[i-1])
+	}
+	return
+}
+
+// pathMidpoint returns the point halfway along the path.
+func pathMidpoint(points []Point) Point {
+	remaining := pathLength(points) / 2.0
+	for i := 1; i < len(points); i++ {
+		segment := points[i].DistanceTo(points[i-1])
+		if segment >= remaining && segment > 0.0 {
+			return points[i-1].Lerp(points[i], remaining/segment)
+		}
+		remaining -= segment
+	}
+	return points[len(points)-1]
+}
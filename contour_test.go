@@ -0,0 +1,147 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+func contourLine(x1, y1, x2, y2 float64) *Line {
+	line := NewLine()
+	line.P1 = Point{x1, y1, 0.0}
+	line.P2 = Point{x2, y2, 0.0}
+	return line
+}
+
+func TestChainContoursClosedProfile(t *testing.T) {
+	arc := NewArc()
+	arc.Center = Point{2.0, 1.0, 0.0}
+	arc.Radius = 1.0
+	arc.StartAngle = 270.0
+	arc.EndAngle = 90.0
+
+	// shuffled and with mixed directions
+	entities := []Entity{
+		contourLine(0.0, 2.0, 2.0, 2.0),
+		contourLine(0.0, 0.0, 2.0, 0.0),
+		arc,
+		contourLine(0.0, 0.0, 0.0, 2.0),
+	}
+	contours, issues := ChainContours(entities, 1.0e-6, 1.0)
+	assertEqInt(t, 0, len(issues))
+	assertEqInt(t, 1, len(contours))
+	contour := contours[0]
+	assert(t, contour.IsClosed, "expected a closed contour")
+	assertEqInt(t, 4, len(contour.Entities))
+
+	// counter-clockwise, starting with the first entity
+	assert(t, contour.Entities[0] == entities[0], "expected the contour to start with the first entity")
+	assert(t, contour.Reversed[0], "expected the top line to be reversed")
+	assertNearPoint(t, Point{2.0, 2.0, 0.0}, contour.Start())
+	assert(t, contour.Entities[1] == entities[3], "expected the left line to follow")
+	assert(t, contour.Entities[3] == entities[2], "expected the arc to finish the contour")
+	assert(t, !contour.Reversed[3], "expected the arc in its own direction")
+
+	poly, err := contour.ToLWPolyline(1.0e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, poly.IsClosed(), "expected a closed polyline")
+	assertNearFloat64(t, 4.0+math.Pi/2.0, poly.Area())
+}
+
+func TestChainContoursSeparatesOpenAndClosed(t *testing.T) {
+	circle := NewCircle()
+	circle.Radius = 1.0
+	circle.Normal = Vector{0.0, 0.0, -1.0}
+	spline := NewSpline()
+	spline.DegreeOfCurve = 1
+	spline.KnotValues = []float64{0.0, 0.0, 1.0, 1.0}
+	spline.ControlPoints = []ControlPoint{{Point{5.0, 0.0, 0.0}, 1.0}, {Point{6.0, 0.0, 0.0}, 1.0}}
+	entities := []Entity{
+		contourLine(7.0, 0.0, 6.0, 0.0),
+		circle,
+		spline,
+	}
+	contours, issues := ChainContours(entities, 1.0e-6, 3.0)
+	assertEqInt(t, 2, len(contours))
+
+	open := contours[0]
+	assert(t, !open.IsClosed, "expected an open contour")
+	assertEqInt(t, 2, len(open.Entities))
+	assertNearPoint(t, Point{7.0, 0.0, 0.0}, open.Start())
+	assertNearPoint(t, Point{5.0, 0.0, 0.0}, open.End())
+	assert(t, open.Reversed[1], "expected the spline to be reversed")
+
+	closed := contours[1]
+	assert(t, closed.IsClosed, "expected a closed contour")
+	assert(t, closed.Reversed[0], "expected the clockwise circle to be reversed")
+
+	// the open contour's ends are reported as a gap
+	assertEqInt(t, 1, len(issues))
+	assertEqString(t, "gap", issues[0].Type.String())
+	assertNearFloat64(t, 2.0, issues[0].Distance)
+}
+
+func TestChainContoursGapsBeyondMaximum(t *testing.T) {
+	// the ends of a single line are too far apart to be a gap
+	_, issues := ChainContours([]Entity{contourLine(0.0, 0.0, 100.0, 0.0)}, 1.0e-6, 1.0)
+	assertEqInt(t, 0, len(issues))
+
+	// as are the ends of parallel lines
+	entities := []Entity{
+		contourLine(0.0, 0.0, 100.0, 0.0),
+		contourLine(0.0, 50.0, 100.0, 50.0),
+	}
+	_, issues = ChainContours(entities, 1.0e-6, 1.0)
+	assertEqInt(t, 0, len(issues))
+
+	// ends of different contours within the maximum are paired
+	entities = append(entities, contourLine(100.5, 0.0, 200.0, 0.0))
+	_, issues = ChainContours(entities, 1.0e-6, 1.0)
+	assertEqInt(t, 1, len(issues))
+	assertNearFloat64(t, 0.5, issues[0].Distance)
+	assertNearPoint(t, Point{100.25, 0.0, 0.0}, issues[0].Location)
+}
+
+func TestChainContoursWithinTolerance(t *testing.T) {
+	entities := []Entity{
+		contourLine(0.0, 0.0, 1.0, 0.0),
+		contourLine(1.0005, 0.0, 1.0, 1.0),
+		contourLine(1.0, 1.0, 0.0, 0.0004),
+	}
+	contours, issues := ChainContours(entities, 0.001, 0.01)
+	assertEqInt(t, 0, len(issues))
+	assertEqInt(t, 1, len(contours))
+	assert(t, contours[0].IsClosed, "expected a closed contour")
+
+	contours, issues = ChainContours(entities, 0.0001, 0.01)
+	assertEqInt(t, 2, len(contours))
+	assertEqInt(t, 2, len(issues))
+	for _, issue := range issues {
+		assertEqString(t, "gap", issue.Type.String())
+		assert(t, issue.Distance < 0.001, "expected small gaps")
+	}
+}
+
+func TestChainContoursBranchesAndOverlaps(t *testing.T) {
+	entities := []Entity{
+		contourLine(0.0, 0.0, 1.0, 0.0),
+		contourLine(1.0, 0.0, 2.0, 0.0),
+		contourLine(1.0, 0.0, 1.0, 1.0),
+		contourLine(2.0, 0.0, 1.0, 0.0),
+		NewText(),
+	}
+	contours, issues := ChainContours(entities, 1.0e-6, 1.0)
+	assertEqInt(t, 3, len(contours))
+	types := map[string]int{}
+	for _, issue := range issues {
+		types[issue.Type.String()]++
+	}
+	assertEqInt(t, 1, types["overlap"])
+	assertEqInt(t, 1, types["branch"])
+	assertEqInt(t, 1, types["unsupported"])
+	for _, contour := range contours {
+		assertEqInt(t, 1, len(contour.Entities))
+		assert(t, contour.Entities[0] != entities[3], "expected the duplicate to be left out")
+	}
+}
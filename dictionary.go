@@ -0,0 +1,289 @@
+package dxf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dictionary is the DICTIONARY object that maps names to other objects.  The root dictionary is the first object of
+// a drawing and has no owner; it names the dictionaries of each kind of object, such as ACAD_MLEADERSTYLE.
+type Dictionary struct {
+	handle       Handle
+	pointerOwner pointer
+
+	IsHardOwner             bool
+	DuplicateRecordHandling int16
+	Entries                 []DictionaryEntry
+	readingDictionaryData   bool
+}
+
+// DictionaryEntry is a single named item of a Dictionary.
+type DictionaryEntry struct {
+	Name         string
+	pointerValue pointer
+}
+
+// NewDictionary creates a new, empty Dictionary.
+func NewDictionary() *Dictionary {
+	return &Dictionary{
+		handle:                  0,
+		IsHardOwner:             false,
+		DuplicateRecordHandling: 1,
+		Entries:                 []DictionaryEntry{},
+	}
+}
+
+// Value gets the item named by the entry.
+func (e *DictionaryEntry) Value() *DrawingItem {
+	return e.pointerValue.value
+}
+
+// SetValue sets the item named by the entry.
+func (e *DictionaryEntry) SetValue(val *DrawingItem) {
+	e.pointerValue.value = val
+	e.pointerValue.handle = 0
+}
+
+// Get returns the item stored under `name`, compared case-insensitively, or nil.
+func (d *Dictionary) Get(name string) *DrawingItem {
+	if i := d.entryIndex(name); i >= 0 {
+		return d.Entries[i].Value()
+	}
+	return nil
+}
+
+// Set stores `item` under `name`, replacing any item already stored under that name.
+func (d *Dictionary) Set(name string, item DrawingItem) {
+	i := d.entryIndex(name)
+	if i < 0 {
+		d.Entries = append(d.Entries, DictionaryEntry{Name: name})
+		i = len(d.Entries) - 1
+	}
+	d.Entries[i].SetValue(&item)
+}
+
+func (d *Dictionary) entryIndex(name string) int {
+	for i := range d.Entries {
+		if strings.EqualFold(d.Entries[i].Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Handle gets the handle of the object.
+func (d *Dictionary) Handle() Handle {
+	return d.handle
+}
+
+// SetHandle sets the handle of the object.
+func (d *Dictionary) SetHandle(val Handle) {
+	d.handle = val
+}
+
+// Owner gets the owner of the object.
+func (d *Dictionary) Owner() *DrawingItem {
+	return d.pointerOwner.value
+}
+
+// SetOwner sets the owner of the object.
+func (d *Dictionary) SetOwner(val *DrawingItem) {
+	d.pointerOwner.value = val
+}
+
+func (d *Dictionary) getOwnerPointer() pointer {
+	return d.pointerOwner
+}
+
+func (d *Dictionary) setOwnerPointerHandle(h Handle) {
+	d.pointerOwner.handle = h
+}
+
+func (d *Dictionary) pointers() (pointers []*pointer) {
+	pointers = append(pointers, &d.pointerOwner)
+	for i := range d.Entries {
+		pointers = append(pointers, &d.Entries[i].pointerValue)
+	}
+	return
+}
+
+func (d *Dictionary) typeString() string {
+	return "DICTIONARY"
+}
+
+func (d *Dictionary) minVersion() AcadVersion {
+	return R13
+}
+
+func (d *Dictionary) maxVersion() AcadVersion {
+	return R2018
+}
+
+func (d *Dictionary) tryApplyCodePair(codePair CodePair) {
+	if !d.readingDictionaryData {
+		// the owner and reactors come before the subclass marker
+		if codePair.Code == 100 {
+			d.readingDictionaryData = codePair.Value.(StringCodePairValue).Value == "AcDbDictionary"
+			return
+		}
+		if tryApplyCodePairForObject(d, codePair) {
+			return
+		}
+	}
+
+	switch codePair.Code {
+	case 280:
+		d.IsHardOwner = boolFromShort(codePair.Value.(ShortCodePairValue).Value)
+	case 281:
+		d.DuplicateRecordHandling = codePair.Value.(ShortCodePairValue).Value
+	case 3:
+		d.Entries = append(d.Entries, DictionaryEntry{Name: codePair.Value.(StringCodePairValue).Value})
+	case 350, 360:
+		if len(d.Entries) > 0 {
+			d.Entries[len(d.Entries)-1].pointerValue.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+		}
+	}
+}
+
+func (d *Dictionary) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, codePairsForObject(d, version)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbDictionary"))
+	if version >= R2000 {
+		if d.IsHardOwner {
+			pairs = append(pairs, NewShortCodePair(280, shortFromBool(d.IsHardOwner)))
+		}
+		pairs = append(pairs, NewShortCodePair(281, d.DuplicateRecordHandling))
+	}
+	valueCode := 350
+	if d.IsHardOwner {
+		valueCode = 360
+	}
+	for _, entry := range d.Entries {
+		if entry.pointerValue.value != nil {
+			// the object is only written for some versions
+			if object, ok := (*entry.pointerValue.value).(Object); ok && (version < object.minVersion() || version > object.maxVersion()) {
+				continue
+			}
+		}
+		pairs = append(pairs, NewStringCodePair(3, entry.Name))
+		pairs = append(pairs, handleCodePair(valueCode, entry.pointerValue.handle))
+	}
+	return
+}
+
+// rootDictionary returns the dictionary that owns the named object dictionaries, adding it as the first object if the
+// drawing doesn't have one.
+func (d *Drawing) rootDictionary() *Dictionary {
+	for i, o := range d.Objects {
+		if dictionary, ok := o.(*Dictionary); ok && dictionary.getOwnerPointer().handle == 0 && dictionary.Owner() == nil {
+			if i > 0 {
+				// the root dictionary must be the first object
+				copy(d.Objects[1:i+1], d.Objects[:i])
+				d.Objects[0] = dictionary
+			}
+			return dictionary
+		}
+	}
+
+	root := NewDictionary()
+	d.Objects = append([]Object{root}, d.Objects...)
+	return root
+}
+
+// namedObjectDictionary returns the dictionary the root dictionary stores under `name`, adding it if it's missing.
+func (d *Drawing) namedObjectDictionary(name string) *Dictionary {
+	root := d.rootDictionary()
+	if item := root.Get(name); item != nil {
+		if dictionary, ok := (*item).(*Dictionary); ok {
+			return dictionary
+		}
+	}
+
+	dictionary := NewDictionary()
+	var owner DrawingItem = root
+	dictionary.SetOwner(&owner)
+	d.Objects = append(d.Objects, dictionary)
+	root.Set(name, dictionary)
+	return dictionary
+}
+
+// updateObjectDictionaries adds the root dictionary and names every MLeaderStyle in the ACAD_MLEADERSTYLE dictionary.
+// Dictionary entries that refer to nothing are dropped; those that keep a handle the drawing doesn't resolve are kept.
+func (d *Drawing) updateObjectDictionaries() {
+	if len(d.Objects) == 0 {
+		return
+	}
+
+	var styles []*MLeaderStyle
+	for _, o := range d.Objects {
+		if style, ok := o.(*MLeaderStyle); ok {
+			styles = append(styles, style)
+		}
+	}
+	if len(styles) > 0 {
+		dictionary := d.namedObjectDictionary("ACAD_MLEADERSTYLE")
+		var owner DrawingItem = dictionary
+		for _, style := range styles {
+			dictionary.setObjectEntry(style, style.Name, "Standard")
+			if style.Owner() == nil || *style.Owner() != owner {
+				style.setOwnerPointerHandle(0)
+				style.SetOwner(&owner)
+			}
+		}
+	}
+
+	for _, o := range d.Objects {
+		if dictionary, ok := o.(*Dictionary); ok {
+			entries := dictionary.Entries[:0]
+			for _, entry := range dictionary.Entries {
+				if entry.Value() != nil || entry.pointerValue.handle != 0 {
+					entries = append(entries, entry)
+				}
+			}
+			dictionary.Entries = entries
+		}
+	}
+}
+
+// setObjectEntry makes sure `object` is stored in the dictionary.  An existing entry is renamed to `name` if it isn't
+// empty; a new entry uses `name` or, if that's empty, `defaultName` made unique.
+func (d *Dictionary) setObjectEntry(object Object, name, defaultName string) {
+	var item DrawingItem = object
+	for i := range d.Entries {
+		entry := &d.Entries[i]
+		if (entry.Value() != nil && *entry.Value() == item) || (object.Handle() != 0 && entry.pointerValue.handle == object.Handle()) {
+			if len(name) > 0 {
+				entry.Name = name
+			}
+			entry.SetValue(&item)
+			return
+		}
+	}
+
+	if len(name) == 0 {
+		name = defaultName
+		for i := 1; d.entryIndex(name) >= 0; i++ {
+			name = fmt.Sprintf("%s_%d", defaultName, i)
+		}
+	}
+	d.Entries = append(d.Entries, DictionaryEntry{Name: name})
+	d.Entries[len(d.Entries)-1].SetValue(&item)
+}
+
+// applyDictionaryNames names the objects that are known by the name of their dictionary entry.
+func applyDictionaryNames(d *Drawing) {
+	for _, o := range d.Objects {
+		dictionary, ok := o.(*Dictionary)
+		if !ok {
+			continue
+		}
+		for _, entry := range dictionary.Entries {
+			if entry.Value() == nil {
+				continue
+			}
+			if style, ok := (*entry.Value()).(*MLeaderStyle); ok {
+				style.Name = entry.Name
+			}
+		}
+	}
+}
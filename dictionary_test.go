@@ -0,0 +1,72 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func TestRoundTripUnsupportedObjects(t *testing.T) {
+	drawing := parseFromCodePairs(t,
+		NewStringCodePair(0, "SECTION"),
+		NewStringCodePair(2, "HEADER"),
+		NewStringCodePair(9, "$ACADVER"),
+		NewStringCodePair(1, "AC1015"),
+		NewStringCodePair(0, "ENDSEC"),
+		NewStringCodePair(0, "SECTION"),
+		NewStringCodePair(2, "OBJECTS"),
+		NewStringCodePair(0, "DICTIONARY"),
+		NewStringCodePair(5, "C"),
+		NewStringCodePair(100, "AcDbDictionary"),
+		NewStringCodePair(3, "ACAD_LAYOUT"),
+		NewStringCodePair(350, "D"),
+		NewStringCodePair(3, "ACAD_PLOTSETTINGS"),
+		NewStringCodePair(350, "FF"),
+		NewStringCodePair(0, "DICTIONARY"),
+		NewStringCodePair(5, "D"),
+		NewStringCodePair(330, "C"),
+		NewStringCodePair(100, "AcDbDictionary"),
+		NewStringCodePair(3, "Layout1"),
+		NewStringCodePair(350, "1E"),
+		NewStringCodePair(0, "LAYOUT"),
+		NewStringCodePair(5, "1E"),
+		NewStringCodePair(102, "{ACAD_REACTORS"),
+		NewStringCodePair(330, "D"),
+		NewStringCodePair(102, "}"),
+		NewStringCodePair(330, "D"),
+		NewStringCodePair(100, "AcDbPlotSettings"),
+		NewStringCodePair(1, ""),
+		NewStringCodePair(100, "AcDbLayout"),
+		NewStringCodePair(1, "Layout1"),
+		NewShortCodePair(70, 1),
+		NewStringCodePair(0, "ENDSEC"),
+		NewStringCodePair(0, "EOF"),
+	)
+	assertEqInt(t, 3, len(drawing.Objects))
+	layout := drawing.Objects[2]
+	assertEqString(t, "LAYOUT", layout.typeString())
+	assertEqUInt64(t, 0xD, uint64(layout.getOwnerPointer().handle))
+
+	actual := drawingCodePairs(t, drawing)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(3, "ACAD_PLOTSETTINGS"),
+		NewStringCodePair(350, "FF"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "LAYOUT"),
+		NewStringCodePair(5, "1E"),
+		NewStringCodePair(102, "{ACAD_REACTORS"),
+		NewStringCodePair(330, "D"),
+		NewStringCodePair(102, "}"),
+		NewStringCodePair(330, "D"),
+		NewStringCodePair(100, "AcDbPlotSettings"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbLayout"),
+		NewStringCodePair(1, "Layout1"),
+		NewShortCodePair(70, 1),
+	}, actual)
+
+	drawing = roundTripDrawing(t, &drawing)
+	assertEqInt(t, 3, len(drawing.Objects))
+	layouts := (*drawing.Objects[0].(*Dictionary).Get("ACAD_LAYOUT")).(*Dictionary)
+	assertEqString(t, "LAYOUT", (*layouts.Get("Layout1")).(Object).typeString())
+}
@@ -0,0 +1,203 @@
+package dxf
+
+import "strings"
+
+// dimStyleOverrideIgnoredCodes are the DIMSTYLE group codes that can't be overridden per dimension: the entry's
+// own name and flags, and the codes this table maps onto fields already written with another code.
+var dimStyleOverrideIgnoredCodes = map[int]bool{
+	2:   true,
+	70:  true,
+	100: true,
+	105: true,
+	281: true, // DIMSD1 is read as DIMSE1
+	282: true, // DIMSD2 is read as DIMSE2
+	287: true, // DIMFIT is read as DIMATFIT
+}
+
+// dimStyleFromHeader returns a dimension style holding the current $DIM* values of the header.
+func dimStyleFromHeader(h *Header) DimStyle {
+	style := *NewDimStyle()
+	style.DimensioningSuffix = h.DimensioningSuffix
+	style.AlternateDimensioningSuffix = h.AlternateDimensioningSuffix
+	style.ArrowBlockName = h.ArrowBlockName
+	style.FirstArrowBlockName = h.FirstArrowBlockName
+	style.SecondArrowBlockName = h.SecondArrowBlockName
+	style.DimensioningScaleFactor = h.DimensioningScaleFactor
+	style.DimensioningArrowSize = h.DimensioningArrowSize
+	style.DimensionExtensionLineOffset = h.DimensionExtensionLineOffset
+	style.DimensionLineIncrement = h.DimensionLineIncrement
+	style.DimensionExtensionLineIncrement = h.DimensionExtensionLineExtension
+	style.DimensionDistanceRoundingValue = h.DimensionDistanceRoundingValue
+	style.DimensionLineExtension = h.DimensionLineExtension
+	style.DimensionPlusTolerance = h.DimensionPlusTolerance
+	style.DimensionMinusTolerance = h.DimensionMinusTolerance
+	style.GenerateDimensionTolerances = h.GenerateDimensionTolerances
+	style.GenerateDimensionLimits = h.GenerateDimensionLimits
+	style.DimensionTextInsideHorizontal = h.DimensionTextInsideHorizontal
+	style.DimensionTextOutsideHorizontal = h.DimensionTextOutsideHorizontal
+	style.SuppressFirstDimensionExtensionLine = h.SuppressFirstDimensionExtensionLine
+	style.SuppressSecondDimensionExtensionLine = h.SuppressSecondDimensionExtensionLine
+	style.TextAboveDimensionLine = h.TextAboveDimensionLine
+	style.DimensionUnitZeroSuppression = h.DimensionUnitZeroSuppression
+	style.DimensionAngleZeroSuppression = h.DimensionAngleZeroSuppression
+	style.DimensioningTextHeight = h.DimensioningTextHeight
+	style.CenterMarkSize = h.CenterMarkSize
+	style.DimensioningTickSize = h.DimensioningTickSize
+	style.AlternateDimensioningScaleFactor = h.AlternateDimensioningScaleFactor
+	style.DimensionLinearMeasurementScaleFactor = h.DimensionLinearMeasurementsScaleFactor
+	style.DimensionVerticalTextPosition = h.DimensionVerticalTextPosition
+	style.DimensionToleranceDisplaceScaleFactor = h.DimensionToleranceDisplayScaleFactor
+	style.DimensionLineGap = h.DimensionLineGap
+	style.AlternateDimensioningUnitRounding = h.AlternateDimensioningUnitRounding
+	style.UseAlternateDimensioning = h.UseAlternateDimensioning
+	style.AlternateDimensioningDecimalPlaces = h.AlternateDimensioningDecimalPlaces
+	style.ForceDimensionLineExtensionsOutsideIfTextExists = h.ForceDimensionLineExtensionsOutsideIfTextIs
+	style.UseSeparateArrowBlocksForDimensions = h.UseSeparateArrowBlocksForDimensions
+	style.ForceDimensionTextInsideExtensions = h.ForceDimensionTextInsideExtensions
+	style.SuppressOutsideExtensionDimensionLines = h.SuppressOutsideExtensionDimensionLines
+	style.DimensionLineColor = h.DimensionLineColor
+	style.DimensionExtensionLineColor = h.DimensionExtensionLineColor
+	style.DimensionTextColor = h.DimensionTextColor
+	style.AngularDimensionPrecision = h.AngularDimensionPrecision
+	style.DimensionUnitFormat = h.DimensionUnitFormat
+	style.DimensionUnitToleranceDecimalPlaces = h.DimensionUnitToleranceDecimalPlaces
+	style.DimensionToleraceDecimalPlaces = h.DimensionToleranceDecimalPlaces
+	style.AlternateDimensioningUnits = h.AlternateDimensioningUnits
+	style.AlternateDimensioningToleranceDecimalPlaces = h.AlternateDimensioningToleranceDecimalPlaces
+	style.DimensioningAngleFormat = h.DimensioningAngleFormat
+	style.DimensionNonAngularUnits = h.DimensionNonAngularUnits
+	style.DimensionDecilamSeparatorChar = h.DimensionDecimalSeparatorRune
+	style.DimensionTextMovementRule = h.DimensionTextMovementRule
+	style.DimensionTextJustification = h.DimensionTextJustification
+	style.DimensionToleranceVerticalJustification = h.DimensionToleranceVerticalJustification
+	style.DimensionToleranceZeroSuppression = h.DimensionToleranceZeroSuppression
+	style.AlternateDimensioningZeroSuppression = h.AlternateDimensioningZeroSupression
+	style.AlternateDimensioningToleranceZeroSuppression = h.AlternateDimensioningToleranceZeroSupression
+	style.DimensionTextAndArrowPlacement = h.DimensionTextAndArrowPlacement
+	style.DimensionCursorControlsTextPosition = h.DimensionCursorControlsTextPosition
+	style.DimensionLeaderBlockName = h.DimensionLeaderBlockName
+	style.DimensionLineWeight = h.DimensionLineWeight
+	style.DimensionExtensionLineWeight = h.DimensionExtensionLineWeight
+	return style
+}
+
+// dimensionStyle returns the dimension style named `name`, or one built from the header's $DIM* values when the
+// drawing has no such style.
+func (d *Drawing) dimensionStyle(name string) DimStyle {
+	for _, style := range d.DimStyles {
+		if strings.EqualFold(style.Name, name) {
+			return style
+		}
+	}
+	style := dimStyleFromHeader(&d.Header)
+	style.Name = name
+	return style
+}
+
+// EffectiveDimStyle returns the dimension style used to display `dim`: the style named by the dimension, or the
+// header's $DIM* values if there is none, with the overrides of the dimension applied.
+func (d *Drawing) EffectiveDimStyle(dim Dimension) DimStyle {
+	style := d.dimensionStyle(dim.DimensionStyleName())
+	for _, pair := range dim.DimStyleOverrides() {
+		if !dimStyleOverrideIgnoredCodes[pair.Code] && pair.Value != nil && codeTypeName(pair.Code) == codePairValueTypeName(pair.Value) {
+			style.tryApplyCodePair(pair)
+		}
+	}
+	return style
+}
+
+// OverrideDimStyle stores the variables where `style` differs from the dimension's own style as overrides of
+// `dim`; passing the unmodified style removes all overrides.
+func (d *Drawing) OverrideDimStyle(dim Dimension, style DimStyle) {
+	base := map[int]CodePairValue{}
+	baseStyle := d.dimensionStyle(dim.DimensionStyleName())
+	for _, pair := range baseStyle.codePairs(R2018) {
+		base[pair.Code] = pair.Value
+	}
+
+	overrides := []CodePair{}
+	for _, pair := range style.codePairs(R2018) {
+		if !dimStyleOverrideIgnoredCodes[pair.Code] && base[pair.Code] != pair.Value {
+			overrides = append(overrides, pair)
+		}
+	}
+	dim.SetDimStyleOverrides(overrides)
+}
+
+func codePairValueTypeName(value CodePairValue) string {
+	switch value.(type) {
+	case BoolCodePairValue:
+		return "Bool"
+	case DoubleCodePairValue:
+		return "Double"
+	case IntCodePairValue:
+		return "Int"
+	case LongCodePairValue:
+		return "Long"
+	case ShortCodePairValue:
+		return "Short"
+	}
+	return "String"
+}
+
+// dimStyleOverrideCodePairs returns the overrides of `dim` as the ACAD application's DSTYLE XDATA, each variable
+// written as its DIMSTYLE group code followed by the value.
+func dimStyleOverrideCodePairs(dim Dimension) (pairs []CodePair) {
+	overrides := dim.DimStyleOverrides()
+	if len(overrides) == 0 {
+		return
+	}
+
+	pairs = append(pairs, NewStringCodePair(1001, "ACAD"))
+	pairs = append(pairs, NewStringCodePair(1000, "DSTYLE"))
+	pairs = append(pairs, NewStringCodePair(1002, "{"))
+	for _, override := range overrides {
+		var value CodePair
+		switch v := override.Value.(type) {
+		case DoubleCodePairValue:
+			value = NewDoubleCodePair(1040, v.Value)
+		case ShortCodePairValue:
+			value = NewShortCodePair(1070, v.Value)
+		case StringCodePairValue:
+			if between(override.Code, 320, 369) {
+				value = NewStringCodePair(1005, v.Value)
+			} else {
+				value = NewStringCodePair(1000, v.Value)
+			}
+		default:
+			continue
+		}
+		pairs = append(pairs, NewShortCodePair(1070, int16(override.Code)))
+		pairs = append(pairs, value)
+	}
+	pairs = append(pairs, NewStringCodePair(1002, "}"))
+	return
+}
+
+// readDimStyleOverrides sets the overrides of `dim` from the DSTYLE data in its XDATA.
+func readDimStyleOverrides(dim Dimension, extendedData []CodePair) {
+	start := -1
+	for i := 0; i+2 < len(extendedData); i++ {
+		if extendedData[i].Code == 1001 && extendedData[i].Value.(StringCodePairValue).Value == "ACAD" &&
+			extendedData[i+1].Code == 1000 && extendedData[i+1].Value.(StringCodePairValue).Value == "DSTYLE" &&
+			extendedData[i+2].Code == 1002 {
+			start = i + 3
+			break
+		}
+	}
+	if start < 0 {
+		return
+	}
+
+	overrides := []CodePair{}
+	for i := start; i+1 < len(extendedData) && extendedData[i].Code == 1070; i += 2 {
+		code := int(extendedData[i].Value.(ShortCodePairValue).Value)
+		value := extendedData[i+1]
+		if codeTypeName(code) != codePairValueTypeName(value.Value) {
+			// a value this library can't map back to the style, e.g., a later variable
+			continue
+		}
+		overrides = append(overrides, CodePair{Code: code, Value: value.Value})
+	}
+	dim.SetDimStyleOverrides(overrides)
+}
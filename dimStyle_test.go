@@ -0,0 +1,102 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func TestEffectiveDimStyleFallsBackToHeader(t *testing.T) {
+	drawing := NewDrawing()
+	drawing.Header.DimensioningArrowSize = 0.3
+	drawing.Header.DimensionLinearMeasurementsScaleFactor = 2.0
+	dim := NewAlignedDimension()
+	style := drawing.EffectiveDimStyle(dim)
+	assertEqString(t, "STANDARD", style.Name)
+	assertEqFloat64(t, 0.3, style.DimensioningArrowSize)
+	assertEqFloat64(t, 2.0, style.DimensionLinearMeasurementScaleFactor)
+
+	named := *NewDimStyle()
+	named.Name = "Standard"
+	named.DimensioningArrowSize = 0.5
+	drawing.DimStyles = append(drawing.DimStyles, named)
+	assertEqFloat64(t, 0.5, drawing.EffectiveDimStyle(dim).DimensioningArrowSize)
+}
+
+func TestReadDimStyleOverrides(t *testing.T) {
+	dim := parseEntity(t, "DIMENSION",
+		NewStringCodePair(100, "AcDbDimension"),
+		NewShortCodePair(70, 1),
+		NewStringCodePair(3, "STANDARD"),
+		NewStringCodePair(100, "AcDbAlignedDimension"),
+		NewStringCodePair(1001, "OTHER_APP"),
+		NewShortCodePair(1070, 41),
+		NewStringCodePair(1001, "ACAD"),
+		NewStringCodePair(1000, "DSTYLE"),
+		NewStringCodePair(1002, "{"),
+		NewShortCodePair(1070, 41),
+		NewDoubleCodePair(1040, 0.25),
+		NewShortCodePair(1070, 77),
+		NewShortCodePair(1070, 1),
+		NewShortCodePair(1070, 3),
+		NewStringCodePair(1000, "<> mm"),
+		NewShortCodePair(1070, 140),
+		NewShortCodePair(1070, 1), // mismatched type is skipped
+		NewStringCodePair(1002, "}"),
+	).(*AlignedDimension)
+	assertEqInt(t, 3, len(dim.DimStyleOverrides()))
+
+	drawing := NewDrawing()
+	style := drawing.EffectiveDimStyle(dim)
+	assertEqFloat64(t, 0.25, style.DimensioningArrowSize)
+	assert(t, style.TextAboveDimensionLine, "expected text above the dimension line")
+	assertEqString(t, "<> mm", style.DimensioningSuffix)
+	assertEqFloat64(t, 0.18, style.DimensioningTextHeight)
+}
+
+func TestWriteDimStyleOverrides(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Normalize()
+	dim := NewRotatedDimension()
+	style := drawing.EffectiveDimStyle(dim)
+	style.DimensioningArrowSize = 0.25
+	style.DimensioningTextHeight = 0.5
+	drawing.OverrideDimStyle(dim, style)
+	assertEqInt(t, 2, len(dim.DimStyleOverrides()))
+
+	actual := allCodePairs(dim, R2000)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(1001, "ACAD"),
+		NewStringCodePair(1000, "DSTYLE"),
+		NewStringCodePair(1002, "{"),
+		NewShortCodePair(1070, 41),
+		NewDoubleCodePair(1040, 0.25),
+		NewShortCodePair(1070, 140),
+		NewDoubleCodePair(1040, 0.5),
+		NewStringCodePair(1002, "}"),
+	}, actual)
+
+	drawing.Entities = append(drawing.Entities, dim)
+	drawing = roundTripDrawing(t, &drawing)
+	rt := drawing.Entities[0].(*RotatedDimension)
+	assertEqFloat64(t, 0.25, drawing.EffectiveDimStyle(rt).DimensioningArrowSize)
+	assertEqFloat64(t, 0.5, drawing.EffectiveDimStyle(rt).DimensioningTextHeight)
+
+	// the unmodified style removes the overrides
+	drawing.OverrideDimStyle(rt, drawing.dimensionStyle(rt.DimensionStyleName()))
+	assertEqInt(t, 0, len(rt.DimStyleOverrides()))
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(1000, "DSTYLE"),
+	}, allCodePairs(rt, R2000))
+}
+
+func TestDimensionBlockUsesOverrides(t *testing.T) {
+	drawing := NewDrawing()
+	drawing.Normalize()
+	dim := NewAlignedDimension()
+	dim.DefinitionPoint3 = Point{X: 1.0, Y: 0.0, Z: 0.0}
+	dim.SetDimStyleOverrides([]CodePair{NewDoubleCodePair(140, 0.5)})
+	for _, e := range drawing.DimensionBlock(dim).Entities {
+		if text, ok := e.(*MText); ok {
+			assertEqFloat64(t, 0.5, text.InitialTextHeight)
+		}
+	}
+}
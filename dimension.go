@@ -0,0 +1,545 @@
+package dxf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// dimensionTypeMask selects the dimension type from code 70; the remaining bits are flags.
+const dimensionTypeMask = 0x0F
+
+func (d *ArcDimension) tryApplyCodePair(codePair CodePair) {
+	if codePair.Code == 100 {
+		d.readingArcData = codePair.Value.(StringCodePairValue).Value == "AcDbArcDimension"
+	}
+	if !d.readingArcData {
+		if !tryApplyCodePairForDimension(d, codePair) {
+			tryApplyCodePairForEntity(d, codePair)
+		}
+		return
+	}
+
+	switch codePair.Code {
+	case 13:
+		d.DefinitionPoint2.X = codePair.Value.(DoubleCodePairValue).Value
+	case 23:
+		d.DefinitionPoint2.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 33:
+		d.DefinitionPoint2.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 14:
+		d.DefinitionPoint3.X = codePair.Value.(DoubleCodePairValue).Value
+	case 24:
+		d.DefinitionPoint3.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 34:
+		d.DefinitionPoint3.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 15:
+		d.ArcCenter.X = codePair.Value.(DoubleCodePairValue).Value
+	case 25:
+		d.ArcCenter.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 35:
+		d.ArcCenter.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 40:
+		d.StartAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 41:
+		d.EndAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 70:
+		d.IsPartial = boolFromShort(codePair.Value.(ShortCodePairValue).Value)
+	case 71:
+		d.HasLeader = boolFromShort(codePair.Value.(ShortCodePairValue).Value)
+	case 16:
+		d.LeaderPoint1.X = codePair.Value.(DoubleCodePairValue).Value
+	case 26:
+		d.LeaderPoint1.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 36:
+		d.LeaderPoint1.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 17:
+		d.LeaderPoint2.X = codePair.Value.(DoubleCodePairValue).Value
+	case 27:
+		d.LeaderPoint2.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 37:
+		d.LeaderPoint2.Z = codePair.Value.(DoubleCodePairValue).Value
+	}
+}
+
+const (
+	// dimensionFlagOrdinateX marks an ordinate dimension measuring along X instead of Y.
+	dimensionFlagOrdinateX = 64
+	// dimensionFlagUserTextLocation marks a text location chosen by the user rather than the default one.
+	dimensionFlagUserTextLocation = 128
+
+	// DIMZIN bits suppressing the zeros of decimal values
+	dimensionZeroSuppressionLeading  = 4
+	dimensionZeroSuppressionTrailing = 8
+
+	// DIMAZIN bits suppressing the zeros of angular values
+	dimensionAngleZeroSuppressionLeading  = 1
+	dimensionAngleZeroSuppressionTrailing = 2
+)
+
+// DimensionMeasurement returns the value measured by `dim`: a distance, or an angle in radians for angular
+// dimensions.  Dimension types without measurement support return 0.
+func DimensionMeasurement(dim Dimension) float64 {
+	switch ent := dim.(type) {
+	case *AlignedDimension:
+		return ent.DefinitionPoint2.DistanceTo(ent.DefinitionPoint3)
+	case *RotatedDimension:
+		direction := rotatedDimensionDirection(ent)
+		return math.Abs(ent.DefinitionPoint3.Sub(ent.DefinitionPoint2).Dot(direction))
+	case *RadialDimension:
+		return ent.DefinitionPoint1().DistanceTo(ent.DefinitionPoint2)
+	case *DiameterDimension:
+		return ent.DefinitionPoint1().DistanceTo(ent.DefinitionPoint2)
+	case *AngularThreePointDimension:
+		_, _, _, sweep := angularDimensionArc(ent)
+		return sweep
+	case *OrdinateDimension:
+		offset := ent.DefinitionPoint2.WcsToOcs(ent.Normal()).Sub(ent.DefinitionPoint1().WcsToOcs(ent.Normal()))
+		if ent.DimensionType()&dimensionFlagOrdinateX != 0 {
+			return math.Abs(offset.X)
+		}
+		return math.Abs(offset.Y)
+	}
+	return 0.0
+}
+
+func rotatedDimensionDirection(dim *RotatedDimension) Vector {
+	angle := dim.RotationAngle * math.Pi / 180.0
+	return Vector{X: math.Cos(angle), Y: math.Sin(angle), Z: 0.0}.OcsToWcs(dim.Normal())
+}
+
+// angularDimensionArc returns the vertex, the radius, and the OCS start and sweep angles in radians of the arc
+// drawn by `dim`; the arc passes through the dimension line location and may measure the reflex angle.
+func angularDimensionArc(dim *AngularThreePointDimension) (vertex Point, radius, start, sweep float64) {
+	normal := dim.Normal()
+	vertex = dim.DefinitionPoint4
+	angleTo := func(p Point) float64 {
+		v := p.Sub(vertex).WcsToOcs(normal)
+		return math.Atan2(v.Y, v.X)
+	}
+	ccw := func(from, to float64) float64 {
+		return math.Mod(math.Mod(to-from, 2.0*math.Pi)+2.0*math.Pi, 2.0*math.Pi)
+	}
+
+	radius = vertex.DistanceTo(dim.DefinitionPoint1())
+	start = angleTo(dim.DefinitionPoint2)
+	end := angleTo(dim.DefinitionPoint3)
+	sweep = ccw(start, end)
+	if ccw(start, angleTo(dim.DefinitionPoint1())) > sweep {
+		start = end
+		sweep = 2.0*math.Pi - sweep
+	}
+	return
+}
+
+// formatDimensionNumber formats `value` with `precision` decimal places, optionally suppressing its leading and
+// trailing zeros.
+func formatDimensionNumber(value float64, precision int16, suppressLeading, suppressTrailing bool, separator rune) string {
+	if precision < 0 {
+		precision = 0
+	}
+	s := strconv.FormatFloat(value, 'f', int(precision), 64)
+	if strings.Trim(s, "-0.") == "" {
+		s = strings.TrimPrefix(s, "-")
+	}
+	if suppressTrailing && strings.Contains(s, ".") {
+		s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	}
+	if suppressLeading {
+		if strings.HasPrefix(s, "0.") {
+			s = s[1:]
+		} else if strings.HasPrefix(s, "-0.") {
+			s = "-" + s[2:]
+		}
+	}
+	if separator != 0 && separator != '.' {
+		s = strings.Replace(s, ".", string(separator), 1)
+	}
+	return s
+}
+
+// formatDimensionText returns the text displayed by `dim` for `measurement`, applying the style's units and the
+// text override of the dimension, where `<>` stands for the measured value.
+func formatDimensionText(dim Dimension, measurement float64, style DimStyle) string {
+	var value string
+	switch dim.(type) {
+	case *AngularThreePointDimension:
+		precision := style.AngularDimensionPrecision
+		if precision < 0 {
+			precision = style.DimensionUnitToleranceDecimalPlaces
+		}
+		zeroSuppression := int(style.DimensionAngleZeroSuppression)
+		value = formatDimensionNumber(measurement*180.0/math.Pi, precision,
+			zeroSuppression&dimensionAngleZeroSuppressionLeading != 0, zeroSuppression&dimensionAngleZeroSuppressionTrailing != 0,
+			style.DimensionDecilamSeparatorChar) + "%%d"
+	default:
+		distance := measurement * style.DimensionLinearMeasurementScaleFactor
+		if style.DimensionDistanceRoundingValue > 0.0 {
+			distance = math.Round(distance/style.DimensionDistanceRoundingValue) * style.DimensionDistanceRoundingValue
+		}
+		zeroSuppression := int(style.DimensionUnitZeroSuppression)
+		value = formatDimensionNumber(distance, style.DimensionUnitToleranceDecimalPlaces,
+			zeroSuppression&dimensionZeroSuppressionLeading != 0, zeroSuppression&dimensionZeroSuppressionTrailing != 0,
+			style.DimensionDecilamSeparatorChar)
+		switch dim.(type) {
+		case *RadialDimension:
+			value = "R" + value
+		case *DiameterDimension:
+			value = "%%c" + value
+		}
+		if strings.Contains(style.DimensioningSuffix, "<>") {
+			value = strings.Replace(style.DimensioningSuffix, "<>", value, 1)
+		} else {
+			value += style.DimensioningSuffix
+		}
+	}
+
+	override := dim.Text()
+	switch {
+	case override == "" || override == "<>":
+		return value
+	case override == " ":
+		// a single space suppresses the text
+		return ""
+	default:
+		return strings.Replace(override, "<>", value, 1)
+	}
+}
+
+// dimensionBlockBuilder draws the entities of a dimension block in world coordinates.
+type dimensionBlockBuilder struct {
+	style  DimStyle
+	normal Vector
+	scale  float64
+	text   string
+	block  *Block
+}
+
+func (b *dimensionBlockBuilder) size(value float64) float64 {
+	return value * b.scale
+}
+
+func (b *dimensionBlockBuilder) textHeight() float64 {
+	return b.size(b.style.DimensioningTextHeight)
+}
+
+// textHalfWidth approximates half the width of the dimension text.
+func (b *dimensionBlockBuilder) textHalfWidth() float64 {
+	return 0.5 * float64(utf8.RuneCountInString(b.text)) * b.textHeight() * textCharacterWidth
+}
+
+// ocsXAxis is the horizontal direction of the dimension plane.
+func (b *dimensionBlockBuilder) ocsXAxis() Vector {
+	return NewXAxis().OcsToWcs(b.normal)
+}
+
+// readable flips `direction` so text along it doesn't read upside down.
+func (b *dimensionBlockBuilder) readable(direction Vector) Vector {
+	ocs := direction.WcsToOcs(b.normal)
+	if ocs.X < -1e-9 || (math.Abs(ocs.X) <= 1e-9 && ocs.Y < 0.0) {
+		return direction.Scale(-1.0)
+	}
+	return direction
+}
+
+func (b *dimensionBlockBuilder) line(p1, p2 Point, color Color) {
+	if p1.DistanceTo(p2) < 1e-12 {
+		return
+	}
+	line := NewLine()
+	line.P1 = p1
+	line.P2 = p2
+	line.ExtrusionDirection = b.normal
+	line.SetColor(color)
+	b.block.Entities = append(b.block.Entities, line)
+}
+
+// extensionLine draws the extension line from the measured point `from` to the dimension line at `to`.
+func (b *dimensionBlockBuilder) extensionLine(from, to Point, suppressed bool) {
+	offset := to.Sub(from)
+	if suppressed || offset.Length() < 1e-12 {
+		return
+	}
+	u := offset.Normalize()
+	b.line(from.Add(u.Scale(b.size(b.style.DimensionExtensionLineOffset))), to.Add(u.Scale(b.size(b.style.DimensionExtensionLineIncrement))), b.style.DimensionExtensionLineColor)
+}
+
+// arrow draws an arrowhead, or an oblique tick when DIMTSZ is set, with its tip at `tip` pointing along `direction`.
+func (b *dimensionBlockBuilder) arrow(tip Point, direction Vector) {
+	direction = direction.Normalize()
+	side := b.normal.Cross(direction).Normalize()
+	if b.style.DimensioningTickSize > 0.0 {
+		diagonal := direction.Add(side).Normalize().Scale(b.size(b.style.DimensioningTickSize))
+		b.line(tip.Add(diagonal.Scale(-1.0)), tip.Add(diagonal), b.style.DimensionLineColor)
+		return
+	}
+
+	size := b.size(b.style.DimensioningArrowSize)
+	base := tip.Add(direction.Scale(-size))
+	halfWidth := side.Scale(size / 6.0)
+	solid := NewSolid()
+	solid.FirstCorner = tip.WcsToOcs(b.normal)
+	solid.SecondCorner = base.Add(halfWidth).WcsToOcs(b.normal)
+	solid.ThirdCorner = base.Add(halfWidth.Scale(-1.0)).WcsToOcs(b.normal)
+	solid.FourthCorner = solid.ThirdCorner
+	solid.ExtrusionDirection = b.normal
+	solid.SetColor(b.style.DimensionLineColor)
+	b.block.Entities = append(b.block.Entities, solid)
+}
+
+// dimensionLine draws the dimension line from `p1` to `p2`, leaving a gap for text placed on it.
+func (b *dimensionBlockBuilder) dimensionLine(p1, p2, textLocation Point) {
+	length := p1.DistanceTo(p2)
+	if length < 1e-12 {
+		return
+	}
+	u := p2.Sub(p1).Normalize()
+	along := textLocation.Sub(p1).Dot(u)
+	across := textLocation.Sub(p1.Add(u.Scale(along))).Length()
+	if len(b.text) == 0 || across > b.textHeight()*0.5 {
+		b.line(p1, p2, b.style.DimensionLineColor)
+		return
+	}
+
+	gap := b.textHalfWidth() + b.size(b.style.DimensionLineGap)
+	if before := math.Min(along-gap, length); before > 0.0 {
+		b.line(p1, p1.Add(u.Scale(before)), b.style.DimensionLineColor)
+	}
+	if after := math.Max(along+gap, 0.0); after < length {
+		b.line(p1.Add(u.Scale(after)), p2, b.style.DimensionLineColor)
+	}
+}
+
+// textAbove offsets a default text location on a line along `direction` when DIMTAD places text above it.
+func (b *dimensionBlockBuilder) textAbove(location Point, direction Vector) Point {
+	if !b.style.TextAboveDimensionLine {
+		return location
+	}
+	up := b.normal.Cross(b.readable(direction)).Normalize()
+	return location.Add(up.Scale(b.size(b.style.DimensionLineGap) + b.textHeight()*0.5))
+}
+
+func (b *dimensionBlockBuilder) addText(location Point, direction Vector) {
+	if len(b.text) == 0 {
+		return
+	}
+	text := NewMText()
+	text.InsertionPoint = location
+	text.InitialTextHeight = b.textHeight()
+	text.AttachmentPoint = AttachmentPointMiddleCenter
+	text.XAxisDirection = direction.Normalize()
+	text.ExtrusionDirection = b.normal
+	text.Text = b.text
+	text.SetColor(b.style.DimensionTextColor)
+	b.block.Entities = append(b.block.Entities, text)
+}
+
+// textDirection returns the direction of text for a dimension line along `direction`.
+func (b *dimensionBlockBuilder) textDirection(direction Vector) Vector {
+	if b.style.DimensionTextInsideHorizontal {
+		return b.ocsXAxis()
+	}
+	return b.readable(direction)
+}
+
+func (b *dimensionBlockBuilder) centerMark(center Point) {
+	size := b.size(math.Abs(b.style.CenterMarkSize))
+	if size == 0.0 {
+		return
+	}
+	x := b.ocsXAxis().Scale(size)
+	y := b.normal.Cross(b.ocsXAxis()).Normalize().Scale(size)
+	b.line(center.Add(x.Scale(-1.0)), center.Add(x), b.style.DimensionLineColor)
+	b.line(center.Add(y.Scale(-1.0)), center.Add(y), b.style.DimensionLineColor)
+}
+
+// DimensionBlock builds the anonymous block displaying `dim` from its effective dimension style; the dimension itself
+// isn't changed.  Dimension types without rendering support return nil.
+func (d *Drawing) DimensionBlock(dim Dimension) *Block {
+	style := d.EffectiveDimStyle(dim)
+	scale := style.DimensioningScaleFactor
+	if scale <= 0.0 {
+		scale = 1.0
+	}
+	measurement := DimensionMeasurement(dim)
+	b := &dimensionBlockBuilder{
+		style:  style,
+		normal: dim.Normal().Normalize(),
+		scale:  scale,
+		text:   formatDimensionText(dim, measurement, style),
+		block:  NewBlock(),
+	}
+	b.block.Name = dim.BlockName()
+
+	// the text goes to its default location unless the user moved it
+	userLocation := dim.DimensionType()&dimensionFlagUserTextLocation != 0
+	textLocation := func(defaultLocation Point) Point {
+		if userLocation {
+			return dim.TextMidPoint().OcsToWcs(b.normal)
+		}
+		return defaultLocation
+	}
+
+	switch ent := dim.(type) {
+	case *AlignedDimension:
+		direction := ent.DefinitionPoint3.Sub(ent.DefinitionPoint2)
+		if direction.IsZero(1e-12) {
+			direction = b.ocsXAxis()
+		}
+		b.linearDimension(ent.DefinitionPoint2, ent.DefinitionPoint3, ent.DefinitionPoint1(), direction.Normalize(), textLocation)
+	case *RotatedDimension:
+		b.linearDimension(ent.DefinitionPoint2, ent.DefinitionPoint3, ent.DefinitionPoint1(), rotatedDimensionDirection(ent), textLocation)
+	case *RadialDimension:
+		center, onArc := ent.DefinitionPoint1(), ent.DefinitionPoint2
+		location := textLocation(b.textAbove(center.Lerp(onArc, 0.5), onArc.Sub(center)))
+		b.centerMark(center)
+		b.dimensionLine(center, onArc, location)
+		b.arrow(onArc, onArc.Sub(center))
+		b.addText(location, b.textDirection(onArc.Sub(center)))
+	case *DiameterDimension:
+		p1, p2 := ent.DefinitionPoint1(), ent.DefinitionPoint2
+		center := p1.Lerp(p2, 0.5)
+		location := textLocation(b.textAbove(center, p2.Sub(p1)))
+		b.centerMark(center)
+		b.dimensionLine(p1, p2, location)
+		b.arrow(p1, p1.Sub(p2))
+		b.arrow(p2, p2.Sub(p1))
+		b.addText(location, b.textDirection(p2.Sub(p1)))
+	case *AngularThreePointDimension:
+		b.angularDimension(ent, textLocation)
+	case *OrdinateDimension:
+		b.ordinateDimension(ent, textLocation)
+	default:
+		return nil
+	}
+	return b.block
+}
+
+// linearDimension draws the dimension between `p1` and `p2` measured along `direction` with the dimension line
+// through `lineLocation`.
+func (b *dimensionBlockBuilder) linearDimension(p1, p2, lineLocation Point, direction Vector, textLocation func(Point) Point) {
+	d1 := lineLocation.Add(direction.Scale(p1.Sub(lineLocation).Dot(direction)))
+	d2 := lineLocation.Add(direction.Scale(p2.Sub(lineLocation).Dot(direction)))
+	b.extensionLine(p1, d1, b.style.SuppressFirstDimensionExtensionLine)
+	b.extensionLine(p2, d2, b.style.SuppressSecondDimensionExtensionLine)
+
+	lineDirection := d2.Sub(d1)
+	if lineDirection.IsZero(1e-12) {
+		lineDirection = direction
+	}
+	lineDirection = lineDirection.Normalize()
+	location := textLocation(b.textAbove(d1.Lerp(d2, 0.5), lineDirection))
+
+	// ticks let the dimension line run past the extension lines
+	extension := Vector{}
+	if b.style.DimensioningTickSize > 0.0 {
+		extension = lineDirection.Scale(b.size(b.style.DimensionLineExtension))
+	}
+	b.dimensionLine(d1.Add(extension.Scale(-1.0)), d2.Add(extension), location)
+	b.arrow(d1, lineDirection.Scale(-1.0))
+	b.arrow(d2, lineDirection)
+	b.addText(location, b.textDirection(lineDirection))
+}
+
+func (b *dimensionBlockBuilder) angularDimension(dim *AngularThreePointDimension, textLocation func(Point) Point) {
+	vertex, radius, start, sweep := angularDimensionArc(dim)
+	center := vertex.WcsToOcs(b.normal)
+	at := func(angle, distance float64) Point {
+		return Point{X: center.X + distance*math.Cos(angle), Y: center.Y + distance*math.Sin(angle), Z: center.Z}.OcsToWcs(b.normal)
+	}
+	tangent := func(angle float64) Vector {
+		return Vector{X: -math.Sin(angle), Y: math.Cos(angle), Z: 0.0}.OcsToWcs(b.normal)
+	}
+
+	// extension lines only reach out to an arc beyond the measured points
+	for i, p := range []Point{dim.DefinitionPoint2, dim.DefinitionPoint3} {
+		angle := start
+		suppressed := b.style.SuppressFirstDimensionExtensionLine
+		if i == 1 {
+			angle = start + sweep
+			suppressed = b.style.SuppressSecondDimensionExtensionLine
+		}
+		if distance := vertex.DistanceTo(p); radius > distance {
+			b.extensionLine(at(angle, distance), at(angle, radius), suppressed)
+		}
+	}
+
+	middle := start + sweep*0.5
+	textRadius := radius
+	if b.style.TextAboveDimensionLine {
+		textRadius += b.size(b.style.DimensionLineGap) + b.textHeight()*0.5
+	}
+	location := textLocation(at(middle, textRadius))
+
+	// leave a gap in the arc for text placed on it
+	arcs := [][2]float64{{start, start + sweep}}
+	if len(b.text) > 0 && radius > 0.0 && math.Abs(location.DistanceTo(vertex)-radius) <= b.textHeight()*0.5 {
+		textVector := location.Sub(vertex).WcsToOcs(b.normal)
+		textAngle := start + math.Mod(math.Mod(math.Atan2(textVector.Y, textVector.X)-start, 2.0*math.Pi)+2.0*math.Pi, 2.0*math.Pi)
+		gap := (b.textHalfWidth() + b.size(b.style.DimensionLineGap)) / radius
+		arcs = [][2]float64{{start, math.Min(textAngle-gap, start+sweep)}, {math.Max(textAngle+gap, start), start + sweep}}
+	}
+	for _, span := range arcs {
+		if span[1]-span[0] <= 1e-12 {
+			continue
+		}
+		arc := NewArc()
+		arc.Center = center
+		arc.Radius = radius
+		arc.Normal = b.normal
+		arc.StartAngle = span[0] * 180.0 / math.Pi
+		arc.EndAngle = span[1] * 180.0 / math.Pi
+		arc.SetColor(b.style.DimensionLineColor)
+		b.block.Entities = append(b.block.Entities, arc)
+	}
+
+	b.arrow(at(start, radius), tangent(start).Scale(-1.0))
+	b.arrow(at(start+sweep, radius), tangent(start+sweep))
+	b.addText(location, b.textDirection(tangent(middle)))
+}
+
+func (b *dimensionBlockBuilder) ordinateDimension(dim *OrdinateDimension, textLocation func(Point) Point) {
+	feature, leaderEnd := dim.DefinitionPoint2, dim.DefinitionPoint3
+
+	// an X datum is labeled along a vertical leader; the horizontal text extends half its height past the leader
+	axis := b.ocsXAxis()
+	textExtent := b.textHalfWidth()
+	if dim.DimensionType()&dimensionFlagOrdinateX != 0 {
+		axis = b.normal.Cross(axis).Normalize()
+		textExtent = b.textHeight() * 0.5
+	}
+	if leaderEnd.Sub(feature).Dot(axis) < 0.0 {
+		axis = axis.Scale(-1.0)
+	}
+
+	b.line(feature.Add(axis.Scale(b.size(b.style.DimensionExtensionLineOffset))), leaderEnd, b.style.DimensionExtensionLineColor)
+	location := textLocation(leaderEnd.Add(axis.Scale(b.size(b.style.DimensionLineGap) + textExtent)))
+	b.addText(location, b.ocsXAxis())
+}
+
+// updateDimensionBlocks adds a block for each dimension that doesn't reference one when the drawing is saved, and fills
+// in the actual measurement of those dimensions.  Blocks that already exist, such as those read from a file, are never
+// replaced.
+func (d *Drawing) updateDimensionBlocks() {
+	for _, e := range d.Entities {
+		dim, ok := e.(Dimension)
+		if !ok {
+			continue
+		}
+		switch dim.(type) {
+		case *AlignedDimension, *RotatedDimension, *RadialDimension, *DiameterDimension, *AngularThreePointDimension, *OrdinateDimension:
+		default:
+			continue
+		}
+		// new dimensions default to the model space block
+		name := strings.ToUpper(dim.BlockName())
+		if len(name) == 0 || name == "*MODEL_SPACE" || name == "*PAPER_SPACE" {
+			dim.SetBlockName(d.nextAnonymousBlockName("*D"))
+		} else if d.findBlock(dim.BlockName()) != nil {
+			continue
+		}
+		d.Blocks = append(d.Blocks, *d.DimensionBlock(dim))
+		dim.SetActualMeasurement(DimensionMeasurement(dim))
+	}
+}
@@ -0,0 +1,330 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestReadDimensionUsesTypeFromCode70(t *testing.T) {
+	rotated := parseEntity(t, "DIMENSION",
+		NewStringCodePair(100, "AcDbDimension"),
+		NewShortCodePair(70, 32), // rotated, block referenced only by this dimension
+		NewStringCodePair(100, "AcDbAlignedDimension"),
+		NewDoubleCodePair(50, 30.0),
+	).(*RotatedDimension)
+	assertEqFloat64(t, 30.0, rotated.RotationAngle)
+	assertEqInt(t, 32, int(rotated.DimensionType()))
+
+	angular := parseEntity(t, "DIMENSION",
+		NewStringCodePair(100, "AcDbDimension"),
+		NewShortCodePair(70, 2),
+		NewStringCodePair(100, "AcDb2LineAngularDimension"),
+		NewDoubleCodePair(13, 1.0),
+		NewDoubleCodePair(14, 2.0),
+		NewDoubleCodePair(15, 3.0),
+		NewDoubleCodePair(16, 4.0),
+	).(*AngularTwoLineDimension)
+	assertEqFloat64(t, 1.0, angular.DefinitionPoint2.X)
+	assertEqFloat64(t, 2.0, angular.DefinitionPoint3.X)
+	assertEqFloat64(t, 3.0, angular.DefinitionPoint4.X)
+	assertEqFloat64(t, 4.0, angular.DefinitionPoint5.X)
+}
+
+func TestWriteDimensionTypeFromConstructor(t *testing.T) {
+	assertContainsCodePairs(t, []CodePair{
+		NewShortCodePair(70, 0),
+	}, allCodePairs(NewRotatedDimension(), R14))
+	assertContainsCodePairs(t, []CodePair{
+		NewShortCodePair(70, 2),
+		NewShortCodePair(71, 1),
+	}, allCodePairs(NewAngularTwoLineDimension(), R2000))
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDb2LineAngularDimension"),
+	}, allCodePairs(NewAngularTwoLineDimension(), R2000))
+}
+
+func TestReadArcDimension(t *testing.T) {
+	dim := parseEntity(t, "ARC_DIMENSION",
+		NewStringCodePair(100, "AcDbEntity"),
+		NewStringCodePair(100, "AcDbDimension"),
+		NewStringCodePair(2, "*D1"),
+		NewShortCodePair(70, 8+32),
+		NewShortCodePair(71, 5),
+		NewStringCodePair(100, "AcDbArcDimension"),
+		NewDoubleCodePair(13, 1.0),
+		NewDoubleCodePair(14, 2.0),
+		NewDoubleCodePair(15, 3.0),
+		NewDoubleCodePair(40, 0.5),
+		NewDoubleCodePair(41, 1.5),
+		NewShortCodePair(70, 1),
+		NewShortCodePair(71, 1),
+		NewDoubleCodePair(16, 4.0),
+		NewDoubleCodePair(17, 5.0),
+	).(*ArcDimension)
+	assertEqString(t, "*D1", dim.BlockName())
+	assert(t, dim.DimensionType()&dimensionTypeMask == DimensionTypeArc, "expected an arc dimension type")
+	assert(t, dim.AttachmentPoint() == AttachmentPointMiddleCenter, "expected the dimension attachment point")
+	assertEqFloat64(t, 1.0, dim.DefinitionPoint2.X)
+	assertEqFloat64(t, 2.0, dim.DefinitionPoint3.X)
+	assertEqFloat64(t, 3.0, dim.ArcCenter.X)
+	assertEqFloat64(t, 0.5, dim.StartAngle)
+	assertEqFloat64(t, 1.5, dim.EndAngle)
+	assert(t, dim.IsPartial, "expected a partial arc")
+	assert(t, dim.HasLeader, "expected a leader")
+	assertEqFloat64(t, 4.0, dim.LeaderPoint1.X)
+	assertEqFloat64(t, 5.0, dim.LeaderPoint2.X)
+}
+
+func TestReadLargeRadialDimension(t *testing.T) {
+	dim := parseEntity(t, "LARGE_RADIAL_DIMENSION",
+		NewStringCodePair(100, "AcDbDimension"),
+		NewShortCodePair(70, 9),
+		NewStringCodePair(100, "AcDbRadialDimensionLarge"),
+		NewDoubleCodePair(13, 1.0),
+		NewDoubleCodePair(14, 2.0),
+		NewDoubleCodePair(15, 3.0),
+		NewDoubleCodePair(40, 4.0),
+		NewDoubleCodePair(50, 45.0),
+	).(*LargeRadialDimension)
+	assertEqFloat64(t, 1.0, dim.OverrideCenter.X)
+	assertEqFloat64(t, 2.0, dim.JogPoint.X)
+	assertEqFloat64(t, 3.0, dim.DefinitionPoint2.X)
+	assertEqFloat64(t, 4.0, dim.LeaderLength)
+	assertEqFloat64(t, 45.0, dim.JogAngle)
+}
+
+func TestRoundTripNewDimensionTypes(t *testing.T) {
+	arc := NewArcDimension()
+	arc.ArcCenter = Point{X: 1.0, Y: 2.0, Z: 0.0}
+	arc.IsPartial = true
+	large := NewLargeRadialDimension()
+	large.JogAngle = 45.0
+	rotated := NewRotatedDimension()
+	rotated.RotationAngle = 90.0
+
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2004
+	drawing.Entities = append(drawing.Entities, arc, large, rotated, NewAngularTwoLineDimension())
+	drawing = roundTripDrawing(t, &drawing)
+	assertEqInt(t, 4, len(drawing.Entities))
+	rtArc := drawing.Entities[0].(*ArcDimension)
+	assertEqPoint(t, Point{X: 1.0, Y: 2.0, Z: 0.0}, rtArc.ArcCenter)
+	assert(t, rtArc.IsPartial, "expected a partial arc")
+	assert(t, rtArc.DimensionType() == DimensionTypeArc, "expected an arc dimension type")
+	assertEqFloat64(t, 45.0, drawing.Entities[1].(*LargeRadialDimension).JogAngle)
+	assertEqFloat64(t, 90.0, drawing.Entities[2].(*RotatedDimension).RotationAngle)
+	_ = drawing.Entities[3].(*AngularTwoLineDimension)
+
+	// not written before they were introduced
+	drawing.Header.Version = R2000
+	pairs := drawingCodePairs(t, drawing)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "ARC_DIMENSION"),
+	}, pairs)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "LARGE_RADIAL_DIMENSION"),
+	}, pairs)
+}
+
+func TestTransformArcDimension(t *testing.T) {
+	dim := NewArcDimension()
+	dim.ArcCenter = Point{X: 1.0, Y: 0.0, Z: 0.0}
+	dim.StartAngle = 0.0
+	dim.EndAngle = 0.5
+	matrix := *NewIdentityMatrix4()
+	matrix[0][3] = 10.0
+	transformed, err := Transform(dim, matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := transformed.(*ArcDimension)
+	assertEqPoint(t, Point{X: 11.0, Y: 0.0, Z: 0.0}, rt.ArcCenter)
+	assertNearFloat64(t, 0.5, rt.EndAngle)
+}
+
+func TestFormatDimensionNumber(t *testing.T) {
+	assertEqString(t, "0.50", formatDimensionNumber(0.5, 2, false, false, '.'))
+	assertEqString(t, "0.5", formatDimensionNumber(0.5, 2, false, true, '.'))
+	assertEqString(t, ".50", formatDimensionNumber(0.5, 2, true, false, '.'))
+	assertEqString(t, "2", formatDimensionNumber(2.0, 3, false, true, '.'))
+	assertEqString(t, "1,25", formatDimensionNumber(1.25, 2, false, false, ','))
+	assertEqString(t, "0.0", formatDimensionNumber(-0.01, 1, false, false, '.'))
+}
+
+func TestFormatDimensionTextZeroSuppression(t *testing.T) {
+	style := *NewDimStyle()
+	style.DimensionUnitToleranceDecimalPlaces = 2
+	style.AngularDimensionPrecision = 2
+	rotated := NewRotatedDimension()
+	angular := NewAngularThreePointDimension()
+	halfDegree := 0.5 * math.Pi / 180.0
+
+	style.DimensionUnitZeroSuppression = UnitZeroSuppression(dimensionZeroSuppressionLeading)
+	style.DimensionAngleZeroSuppression = 0
+	assertEqString(t, ".50", formatDimensionText(rotated, 0.5, style))
+	assertEqString(t, "0.50%%d", formatDimensionText(angular, halfDegree, style))
+	style.DimensionUnitZeroSuppression = UnitZeroSuppression(dimensionZeroSuppressionTrailing)
+	assertEqString(t, "0.5", formatDimensionText(rotated, 0.5, style))
+	assertEqString(t, "0.50%%d", formatDimensionText(angular, halfDegree, style))
+
+	// angles use the DIMAZIN bits instead
+	style.DimensionUnitZeroSuppression = 0
+	style.DimensionAngleZeroSuppression = UnitZeroSuppression(dimensionAngleZeroSuppressionLeading)
+	assertEqString(t, ".50%%d", formatDimensionText(angular, halfDegree, style))
+	assertEqString(t, "0.50", formatDimensionText(rotated, 0.5, style))
+	style.DimensionAngleZeroSuppression = UnitZeroSuppression(dimensionAngleZeroSuppressionTrailing)
+	assertEqString(t, "0.5%%d", formatDimensionText(angular, halfDegree, style))
+	assertEqString(t, "0.50", formatDimensionText(rotated, 0.5, style))
+}
+
+func TestDimensionMeasurement(t *testing.T) {
+	rotated := NewRotatedDimension()
+	rotated.DefinitionPoint2 = Point{X: 0.0, Y: 0.0, Z: 0.0}
+	rotated.DefinitionPoint3 = Point{X: 3.0, Y: 4.0, Z: 0.0}
+	rotated.RotationAngle = 90.0
+	assertNearFloat64(t, 4.0, DimensionMeasurement(rotated))
+
+	// the arc location on the far side measures the reflex angle
+	angular := NewAngularThreePointDimension()
+	angular.DefinitionPoint2 = Point{X: 1.0, Y: 0.0, Z: 0.0}
+	angular.DefinitionPoint3 = Point{X: 0.0, Y: 1.0, Z: 0.0}
+	angular.SetDefinitionPoint1(Point{X: 2.0, Y: 2.0, Z: 0.0})
+	assertNearFloat64(t, math.Pi/2.0, DimensionMeasurement(angular))
+	angular.SetDefinitionPoint1(Point{X: -2.0, Y: -2.0, Z: 0.0})
+	assertNearFloat64(t, 3.0*math.Pi/2.0, DimensionMeasurement(angular))
+
+	ordinate := NewOrdinateDimension()
+	ordinate.DefinitionPoint2 = Point{X: 3.0, Y: -4.0, Z: 0.0}
+	assertNearFloat64(t, 4.0, DimensionMeasurement(ordinate))
+	ordinate.SetDimensionType(DimensionTypeOrdinate | dimensionFlagOrdinateX)
+	assertNearFloat64(t, 3.0, DimensionMeasurement(ordinate))
+}
+
+func TestAlignedDimensionBlock(t *testing.T) {
+	drawing := NewDrawing()
+	style := *NewDimStyle()
+	style.Name = "STANDARD"
+	style.DimensionUnitToleranceDecimalPlaces = 2
+	style.DimensionUnitZeroSuppression = UnitZeroSuppression(dimensionZeroSuppressionTrailing)
+	style.DimensioningSuffix = "<> mm"
+	drawing.DimStyles = append(drawing.DimStyles, style)
+
+	dim := NewAlignedDimension()
+	dim.DefinitionPoint2 = Point{X: 0.0, Y: 0.0, Z: 0.0}
+	dim.DefinitionPoint3 = Point{X: 10.0, Y: 0.0, Z: 0.0}
+	dim.SetDefinitionPoint1(Point{X: 10.0, Y: 2.0, Z: 0.0})
+	block := drawing.DimensionBlock(dim)
+
+	lines, solids := 0, 0
+	var text *MText
+	for _, e := range block.Entities {
+		switch ent := e.(type) {
+		case *Line:
+			lines++
+		case *Solid:
+			solids++
+		case *MText:
+			text = ent
+		}
+	}
+	// two extension lines and a dimension line split around the text
+	assertEqInt(t, 4, lines)
+	assertEqInt(t, 2, solids)
+	assertEqString(t, "10 mm", text.Text)
+	assertNearPoint(t, Point{X: 5.0, Y: 2.0, Z: 0.0}, text.InsertionPoint)
+	// the measurement is filled in when the block is generated on save
+	drawing.Entities = append(drawing.Entities, dim)
+	drawing.updateDimensionBlocks()
+	assertEqFloat64(t, 10.0, dim.ActualMeasurement())
+	assertNearPoint(t, Point{X: 0.0, Y: 0.0, Z: 0.0}, dim.TextMidPoint())
+
+	// a user placed text location is kept
+	dim.SetTextMidPoint(Point{X: 1.0, Y: 5.0, Z: 0.0})
+	dim.SetDimensionType(DimensionTypeAligned | dimensionFlagUserTextLocation)
+	dim.SetText("<> typ.")
+	block = drawing.DimensionBlock(dim)
+	for _, e := range block.Entities {
+		if mtext, ok := e.(*MText); ok {
+			text = mtext
+		}
+	}
+	assertEqString(t, "10 mm typ.", text.Text)
+	assertNearPoint(t, Point{X: 1.0, Y: 5.0, Z: 0.0}, text.InsertionPoint)
+}
+
+func TestAngularDimensionBlockText(t *testing.T) {
+	drawing := NewDrawing()
+	style := *NewDimStyle()
+	style.Name = "ANGLES"
+	style.AngularDimensionPrecision = 0
+	style.DimensioningTickSize = 0.1
+	drawing.DimStyles = append(drawing.DimStyles, style)
+
+	dim := NewAngularThreePointDimension()
+	dim.SetDimensionStyleName("angles")
+	dim.DefinitionPoint2 = Point{X: 1.0, Y: 0.0, Z: 0.0}
+	dim.DefinitionPoint3 = Point{X: 0.0, Y: 1.0, Z: 0.0}
+	dim.SetDefinitionPoint1(Point{X: 2.0, Y: 0.0, Z: 0.0})
+	block := drawing.DimensionBlock(dim)
+	arcs, ticks := 0, 0
+	for _, e := range block.Entities {
+		switch ent := e.(type) {
+		case *Arc:
+			arcs++
+			assertEqFloat64(t, 2.0, ent.Radius)
+		case *Line:
+			ticks++
+		case *MText:
+			assertEqString(t, "90%%d", ent.Text)
+		}
+	}
+	assertEqInt(t, 2, arcs)
+	// the ticks and the extension lines out to the arc
+	assertEqInt(t, 4, ticks)
+}
+
+func TestDimensionBlocksWrittenOnSave(t *testing.T) {
+	drawing := *NewDrawing()
+	radial := NewRadialDimension()
+	radial.DefinitionPoint2 = Point{X: 2.0, Y: 0.0, Z: 0.0}
+	named := NewRadialDimension()
+	named.SetBlockName("MY_DIMENSION")
+	drawing.Entities = append(drawing.Entities, radial, named, NewArcDimension())
+	pairs := drawingCodePairs(t, drawing)
+	assertEqString(t, "*D1", radial.BlockName())
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "BLOCK"),
+	}, pairs)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(2, "*D1"),
+	}, pairs)
+	// a missing block is added under the referenced name
+	assertEqString(t, "MY_DIMENSION", named.BlockName())
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(2, "MY_DIMENSION"),
+		NewShortCodePair(70, 0),
+	}, pairs)
+
+	// existing blocks are kept
+	drawing.updateDimensionBlocks()
+	drawing.updateDimensionBlocks()
+	assertEqString(t, "*D1", radial.BlockName())
+	assertEqInt(t, 2, len(drawing.Blocks))
+}
+
+func TestDimensionBlockReadFromFileIsKept(t *testing.T) {
+	drawing := *NewDrawing()
+	block := *NewBlock()
+	block.Name = "*D1"
+	block.Entities = append(block.Entities, NewLine())
+	drawing.Blocks = append(drawing.Blocks, block)
+	dim := NewRadialDimension()
+	dim.DefinitionPoint2 = Point{X: 2.0, Y: 0.0, Z: 0.0}
+	dim.SetBlockName("*D1")
+	dim.SetActualMeasurement(3.0)
+	drawing.Entities = append(drawing.Entities, dim)
+	drawing.updateDimensionBlocks()
+	assertEqInt(t, 1, len(drawing.Blocks))
+	assertEqInt(t, 1, len(drawing.Blocks[0].Entities))
+	assertEqFloat64(t, 3.0, dim.ActualMeasurement())
+}
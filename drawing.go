@@ -30,6 +30,11 @@ type Drawing struct {
 
 	Entities []Entity
 
+	Objects []Object
+
+	// UpdateExtentsOnSave recomputes the header extents from the entities each time the drawing is saved.
+	UpdateExtentsOnSave bool
+
 	appIdTableHandle       Handle
 	blockRecordTableHandle Handle
 	dimStyleTableHandle    Handle
@@ -63,6 +68,15 @@ func (d *Drawing) GetItemByHandle(h Handle) (item *DrawingItem, err error) {
 		}
 	}
 
+	for i := range d.Objects {
+		o := &d.Objects[i]
+		if (*o).Handle() == h {
+			di := (*o).(DrawingItem)
+			item = &di
+			return
+		}
+	}
+
 	err = fmt.Errorf("Unable to find item with handle '%d'", h)
 	return
 }
@@ -201,7 +215,8 @@ func (d *Drawing) SaveFileBinary(path string) error {
 	return d.SaveToWriterBinary(f)
 }
 
-// SaveToWriter writes the current drawing to the specified io.Writer.
+// SaveToWriter writes the current drawing to the specified io.Writer.  Entities and objects that `Header.Version`
+// doesn't support are left out; R12 drawings, for example, lose their hatches and lightweight polylines.
 func (d *Drawing) SaveToWriter(writer io.Writer) error {
 	codePairWriter := newTextCodePairWriter(writer, d.Header.Version)
 	return d.saveToCodePairWriter(codePairWriter)
@@ -242,8 +257,15 @@ func (d *Drawing) saveToCodePairWriter(writer codePairWriter) error {
 	}
 
 	d.Normalize()
+	d.updateTableBlocks()
+	d.updateDimensionBlocks()
+	d.updateObjectDictionaries()
+	if d.UpdateExtentsOnSave {
+		d.UpdateExtents()
+	}
 	assignHandles(d)
 	assignPointers(d)
+	d.updateViewportFrozenLayers()
 
 	err = d.Header.writeHeaderSection(writer)
 	if err != nil {
@@ -265,6 +287,20 @@ func (d *Drawing) saveToCodePairWriter(writer codePairWriter) error {
 		return err
 	}
 
+	if d.Header.Version >= R13 && len(d.Objects) > 0 {
+		err = writeObjectsSection(d.Objects, writer, d.Header.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Header.Version >= R2013 {
+		err = writeAcdsDataSection(d, writer)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = writer.writeCodePair(NewStringCodePair(0, "EOF"))
 	return err
 }
@@ -306,6 +342,7 @@ func ParseDrawingFromCodePairs(codePairs ...CodePair) (Drawing, error) {
 
 func readFromCodePairReader(reader codePairReader) (Drawing, error) {
 	drawing := *NewDrawing()
+	acisData := map[Handle][]byte{}
 
 	// read sections
 	nextPair, err := reader.readCodePair()
@@ -331,10 +368,14 @@ func readFromCodePairReader(reader codePairReader) (Drawing, error) {
 			switch sectionType {
 			case "ENTITIES":
 				drawing.Entities, nextPair, err = readEntities(nextPair, reader)
+			case "OBJECTS":
+				drawing.Objects, nextPair, err = readObjects(nextPair, reader)
 			case "HEADER":
 				drawing.Header, nextPair, err = readHeader(nextPair, reader)
 			case "TABLES":
 				nextPair, err = readTables(&drawing, nextPair, reader)
+			case "ACDSDATA":
+				acisData, nextPair, err = readAcdsData(nextPair, reader)
 			default:
 				// swallow unsupported section
 				for err == nil && !nextPair.isEndSection() {
@@ -362,12 +403,26 @@ func readFromCodePairReader(reader codePairReader) (Drawing, error) {
 		return drawing, errors.New("expected 0/EOF")
 	}
 
+	applyAcdsData(&drawing, acisData)
 	bindPointers(&drawing)
+	applyDictionaryNames(&drawing)
 	return drawing, nil
 }
 
 func assignHandles(d *Drawing) {
-	nextHandle := uint32(1)
+	// new handles start past those kept from a file or an earlier save
+	largest := maxTableItemHandle(d)
+	for _, e := range d.Entities {
+		if e.Handle() > largest {
+			largest = e.Handle()
+		}
+	}
+	for _, o := range d.Objects {
+		if o.Handle() > largest {
+			largest = o.Handle()
+		}
+	}
+	nextHandle := uint32(largest) + 1
 	nextHandle = uint32(assignTableHandles(d, Handle(nextHandle)))
 
 	for i := range d.Blocks {
@@ -383,29 +438,45 @@ func assignHandles(d *Drawing) {
 		}
 	}
 
+	for i := range d.Objects {
+		o := &d.Objects[i]
+		if (*o).Handle() == 0 {
+			(*o).SetHandle(Handle(nextHandle))
+			nextHandle++
+		}
+	}
+
 	d.Header.NextAvailableHandle = Handle(nextHandle)
 }
 
 func assignPointers(d *Drawing) {
+	var pointers []*pointer
 	for i := range d.Entities {
-		e := &d.Entities[i]
-		for _, p := range (*e).pointers() {
-			if p.handle == 0 && p.value != nil {
-				p.handle = (*p.value).Handle()
-			}
+		pointers = append(pointers, d.Entities[i].pointers()...)
+	}
+	for i := range d.Objects {
+		pointers = append(pointers, d.Objects[i].pointers()...)
+	}
+	for _, p := range pointers {
+		if p.handle == 0 && p.value != nil {
+			p.handle = (*p.value).Handle()
 		}
 	}
 }
 
 func bindPointers(d *Drawing) {
+	var pointers []*pointer
 	for i := range d.Entities {
-		e := &d.Entities[i]
-		for _, p := range (*e).pointers() {
-			if p.handle != 0 {
-				o, err := d.GetItemByHandle(p.handle)
-				if err == nil {
-					p.value = o
-				}
+		pointers = append(pointers, d.Entities[i].pointers()...)
+	}
+	for i := range d.Objects {
+		pointers = append(pointers, d.Objects[i].pointers()...)
+	}
+	for _, p := range pointers {
+		if p.handle != 0 {
+			o, err := d.GetItemByHandle(p.handle)
+			if err == nil {
+				p.value = o
 			}
 		}
 	}
@@ -0,0 +1,804 @@
+package dxf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ImportConflictStrategy specifies how `Drawing.Import` resolves a table entry, block or mleader style name that
+// already exists in the target drawing.
+type ImportConflictStrategy int
+
+const (
+	// ImportConflictKeepExisting keeps the existing item; imported references bind to it.
+	ImportConflictKeepExisting ImportConflictStrategy = iota
+	// ImportConflictOverwrite replaces the existing item with the imported one.
+	ImportConflictOverwrite
+	// ImportConflictRename imports the item under a new name built from `RenamePrefix` and `RenameSuffix`.
+	ImportConflictRename
+)
+
+// ImportOptions controls how `Drawing.Import` merges one drawing into another.
+type ImportOptions struct {
+	ConflictStrategy ImportConflictStrategy
+	RenamePrefix     string
+	RenameSuffix     string
+	AsBlock          bool   // wrap the imported entities in a new block and reference it with an `Insert`
+	BlockName        string // name of the new block; required when `AsBlock` is set
+	InsertionPoint   Point  // location of the returned `Insert`
+}
+
+// NewImportOptions returns the default import options; existing items win all name conflicts and the imported
+// entities are placed directly in the target drawing.
+func NewImportOptions() *ImportOptions {
+	return &ImportOptions{
+		ConflictStrategy: ImportConflictKeepExisting,
+		RenamePrefix:     "",
+		RenameSuffix:     "",
+		AsBlock:          false,
+		BlockName:        "",
+		InsertionPoint:   *NewOrigin(),
+	}
+}
+
+// Import copies the entities, blocks, table entries and objects of `other` into the drawing.  Name conflicts are resolved
+// according to `opts.ConflictStrategy` and every reference in the imported items is updated to match.  Imported items
+// receive new handles and their pointers are remapped; a pointer to an item that isn't imported keeps referring to it
+// through a newly reserved handle, so it can't collide with an item of the drawing.  If `opts.AsBlock` is set the
+// imported entities are placed in a new block and the `Insert` that references it is both added to the drawing and
+// returned, otherwise the returned `Insert` is nil.  Dictionaries and objects of unsupported types aren't copied; the
+// imported objects are added to the dictionaries of the drawing when it's saved.  A nil `opts` uses the defaults from `NewImportOptions`.
+func (d *Drawing) Import(other *Drawing, opts *ImportOptions) (insert *Insert, err error) {
+	if other == nil {
+		err = errors.New("no drawing to import")
+		return
+	}
+	if opts == nil {
+		opts = NewImportOptions()
+	}
+	if opts.AsBlock {
+		if len(opts.BlockName) == 0 {
+			err = errors.New("a block name is required to import as a block")
+			return
+		}
+		if findImportName(d.blockNames(), opts.BlockName) >= 0 {
+			err = fmt.Errorf("block '%s' already exists", opts.BlockName)
+			return
+		}
+	}
+
+	imp := newDrawingImporter(d, opts)
+	imp.resolveNames(other)
+	if opts.AsBlock {
+		for _, block := range other.Blocks {
+			name, include := imp.blockName(block.Name)
+			if include && !isLayoutBlockName(block.Name) && strings.EqualFold(name, opts.BlockName) {
+				err = fmt.Errorf("block '%s' is also imported", opts.BlockName)
+				return
+			}
+		}
+	}
+
+	// clone everything before touching the target drawing so a failure leaves it unchanged
+	entities, err := imp.cloneEntities(other.Entities)
+	if err != nil {
+		return
+	}
+	blocks := make([]Block, 0)
+	for _, block := range other.Blocks {
+		name, include := imp.blockName(block.Name)
+		if !include || isLayoutBlockName(block.Name) {
+			continue
+		}
+		copied := block
+		copied.handle = 0
+		copied.endBlockHandle = 0
+		copied.Name = name
+		copied.Layer = imp.layerName(block.Layer)
+		copied.Entities, err = imp.cloneEntities(block.Entities)
+		if err != nil {
+			return
+		}
+		blocks = append(blocks, copied)
+	}
+
+	imp.importTables(other)
+	imp.importObjects(other)
+	imp.remapPointers()
+	existingBlocks := d.blockNames()
+	for _, block := range blocks {
+		index := findImportName(existingBlocks, block.Name)
+		if index >= 0 {
+			d.Blocks[index] = block
+		} else {
+			d.Blocks = append(d.Blocks, block)
+		}
+	}
+
+	if opts.AsBlock {
+		block := *NewBlock()
+		block.Name = opts.BlockName
+		block.BasePoint = other.Header.InsertionBase
+		block.Entities = entities
+		d.Blocks = append(d.Blocks, block)
+
+		insert = NewInsert()
+		insert.Name = opts.BlockName
+		insert.Location = opts.InsertionPoint
+		d.Entities = append(d.Entities, insert)
+	} else {
+		d.Entities = append(d.Entities, entities...)
+	}
+
+	d.Header.NextAvailableHandle = imp.nextHandle
+	bindPointers(d)
+	return
+}
+
+// isLayoutBlockName returns true for the blocks that represent model and paper space; their contents are imported
+// through `Drawing.Entities` instead.
+func isLayoutBlockName(name string) bool {
+	upper := strings.ToUpper(name)
+	return strings.HasPrefix(upper, "*MODEL_SPACE") || strings.HasPrefix(upper, "*PAPER_SPACE")
+}
+
+// isReservedTableName returns true for names that every drawing shares and that are never renamed or replaced.
+func isReservedTableName(name string) bool {
+	switch strings.ToUpper(name) {
+	case "0", "BYLAYER", "BYBLOCK", "CONTINUOUS", "STANDARD", "ANNOTATIVE", "*ACTIVE", "ACAD":
+		return true
+	}
+	return isLayoutBlockName(name)
+}
+
+// findImportName returns the index of `name` in `names` using DXF's case-insensitive name comparison, or -1.
+func findImportName(names []string, name string) int {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+type drawingImporter struct {
+	target     *Drawing
+	opts       *ImportOptions
+	nextHandle Handle
+	handles    map[Handle]Handle
+	clones     []Entity
+	objects    []Object
+
+	// renamed items, keyed by the upper-cased original name
+	appIds        map[string]string
+	blocks        map[string]string
+	dimStyles     map[string]string
+	layers        map[string]string
+	lineTypes     map[string]string
+	mleaderStyles map[string]string
+	styles        map[string]string
+	ucss          map[string]string
+	views         map[string]string
+	viewPorts     map[string]string
+	skippedNames  map[string]bool
+}
+
+func newDrawingImporter(target *Drawing, opts *ImportOptions) *drawingImporter {
+	return &drawingImporter{
+		target:        target,
+		opts:          opts,
+		nextHandle:    target.maxHandle() + 1,
+		handles:       make(map[Handle]Handle),
+		appIds:        make(map[string]string),
+		blocks:        make(map[string]string),
+		dimStyles:     make(map[string]string),
+		layers:        make(map[string]string),
+		lineTypes:     make(map[string]string),
+		mleaderStyles: make(map[string]string),
+		styles:        make(map[string]string),
+		ucss:          make(map[string]string),
+		views:         make(map[string]string),
+		viewPorts:     make(map[string]string),
+		skippedNames:  make(map[string]bool),
+	}
+}
+
+// resolveName decides what happens to an imported item named `name` that conflicts with one of the `existing` names
+// and records the outcome: either the item is skipped in favor of the existing one, or it is renamed.  A new name
+// avoids the `incoming` names of the other imported items as well.
+func (imp *drawingImporter) resolveName(name string, existing, incoming []string, renames map[string]string, kind string) {
+	if findImportName(existing, name) < 0 {
+		return
+	}
+
+	switch {
+	case isReservedTableName(name) || imp.opts.ConflictStrategy == ImportConflictKeepExisting:
+		imp.skippedNames[kind+":"+strings.ToUpper(name)] = true
+	case imp.opts.ConflictStrategy == ImportConflictOverwrite:
+		// nothing to rename
+	case imp.opts.ConflictStrategy == ImportConflictRename:
+		candidate := imp.opts.RenamePrefix + name + imp.opts.RenameSuffix
+		for i := 1; findImportName(existing, candidate) >= 0 || findImportName(incoming, candidate) >= 0 || findImportName(mapValues(renames), candidate) >= 0; i++ {
+			candidate = fmt.Sprintf("%s%s%s_%d", imp.opts.RenamePrefix, name, imp.opts.RenameSuffix, i)
+		}
+		renames[strings.ToUpper(name)] = candidate
+	}
+}
+
+func mapValues(m map[string]string) (values []string) {
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return
+}
+
+func renamed(renames map[string]string, name string) string {
+	if n, ok := renames[strings.ToUpper(name)]; ok {
+		return n
+	}
+	return name
+}
+
+func (imp *drawingImporter) blockName(name string) (newName string, include bool) {
+	newName, include, _ = imp.tableItemTarget(name, imp.target.blockNames(), imp.blocks, "BLOCK")
+	return
+}
+
+func (imp *drawingImporter) layerName(name string) string {
+	return renamed(imp.layers, name)
+}
+
+// resolveNames computes the rename maps for every table and block before anything is copied so that references can be
+// rewritten regardless of the order in which items are imported.
+func (imp *drawingImporter) resolveNames(other *Drawing) {
+	d := imp.target
+	for _, item := range other.AppIds {
+		imp.resolveName(item.Name, d.appIdNames(), other.appIdNames(), imp.appIds, "APPID")
+	}
+	for _, item := range other.Blocks {
+		if !isLayoutBlockName(item.Name) {
+			imp.resolveName(item.Name, d.blockNames(), other.blockNames(), imp.blocks, "BLOCK")
+		}
+	}
+	for _, item := range other.DimStyles {
+		imp.resolveName(item.Name, d.dimStyleNames(), other.dimStyleNames(), imp.dimStyles, "DIMSTYLE")
+	}
+	for _, item := range other.Layers {
+		imp.resolveName(item.Name, d.layerNames(), other.layerNames(), imp.layers, "LAYER")
+	}
+	for _, item := range other.LineTypes {
+		imp.resolveName(item.Name, d.lineTypeNames(), other.lineTypeNames(), imp.lineTypes, "LTYPE")
+	}
+	for _, item := range other.Styles {
+		imp.resolveName(item.Name, d.styleNames(), other.styleNames(), imp.styles, "STYLE")
+	}
+	for _, item := range other.Ucss {
+		imp.resolveName(item.Name, d.ucsNames(), other.ucsNames(), imp.ucss, "UCS")
+	}
+	for _, item := range other.Views {
+		imp.resolveName(item.Name, d.viewNames(), other.viewNames(), imp.views, "VIEW")
+	}
+	for _, item := range other.ViewPorts {
+		imp.resolveName(item.Name, d.viewPortNames(), other.viewPortNames(), imp.viewPorts, "VPORT")
+	}
+	for _, item := range other.mleaderStyles() {
+		if len(item.Name) > 0 {
+			imp.resolveName(item.Name, d.mleaderStyleNames(), other.mleaderStyleNames(), imp.mleaderStyles, "MLEADERSTYLE")
+		}
+	}
+}
+
+// tableItemTarget returns the name an imported table entry is stored under and the index of the existing entry it
+// replaces, or -1 to append; `include` is false if the entry is not imported at all.
+func (imp *drawingImporter) tableItemTarget(name string, existing []string, renames map[string]string, kind string) (newName string, include bool, replace int) {
+	newName = renamed(renames, name)
+	include = !imp.skippedNames[kind+":"+strings.ToUpper(name)]
+	replace = findImportName(existing, newName)
+	return
+}
+
+func (imp *drawingImporter) importTables(other *Drawing) {
+	d := imp.target
+	// entries are only replaced by index among those the drawing had before the import
+	var existing []string
+	existing = d.appIdNames()
+	for _, item := range other.AppIds {
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.appIds, "APPID")
+		if include {
+			item.Name = name
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.AppIds[replace].handle)
+				d.AppIds[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.AppIds = append(d.AppIds, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.AppIds[replace].handle)
+		}
+	}
+	existing = d.blockRecordNames()
+	for _, item := range other.BlockRecords {
+		if isLayoutBlockName(item.Name) {
+			continue
+		}
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.blocks, "BLOCK")
+		if include {
+			item.Name = name
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.BlockRecords[replace].handle)
+				d.BlockRecords[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.BlockRecords = append(d.BlockRecords, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.BlockRecords[replace].handle)
+		}
+	}
+	existing = d.dimStyleNames()
+	for _, item := range other.DimStyles {
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.dimStyles, "DIMSTYLE")
+		if include {
+			item.Name = name
+			item.DimensionTextStyle = renamed(imp.styles, item.DimensionTextStyle)
+			item.ArrowBlockName = renamed(imp.blocks, item.ArrowBlockName)
+			item.FirstArrowBlockName = renamed(imp.blocks, item.FirstArrowBlockName)
+			item.SecondArrowBlockName = renamed(imp.blocks, item.SecondArrowBlockName)
+			item.DimensionLeaderBlockName = renamed(imp.blocks, item.DimensionLeaderBlockName)
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.DimStyles[replace].handle)
+				d.DimStyles[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.DimStyles = append(d.DimStyles, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.DimStyles[replace].handle)
+		}
+	}
+	existing = d.layerNames()
+	for _, item := range other.Layers {
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.layers, "LAYER")
+		if include {
+			item.Name = name
+			item.LineTypeName = renamed(imp.lineTypes, item.LineTypeName)
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.Layers[replace].handle)
+				d.Layers[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.Layers = append(d.Layers, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.Layers[replace].handle)
+		}
+	}
+	existing = d.lineTypeNames()
+	for _, item := range other.LineTypes {
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.lineTypes, "LTYPE")
+		if include {
+			item.Name = name
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.LineTypes[replace].handle)
+				d.LineTypes[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.LineTypes = append(d.LineTypes, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.LineTypes[replace].handle)
+		}
+	}
+	existing = d.styleNames()
+	for _, item := range other.Styles {
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.styles, "STYLE")
+		if include {
+			item.Name = name
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.Styles[replace].handle)
+				d.Styles[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.Styles = append(d.Styles, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.Styles[replace].handle)
+		}
+	}
+	existing = d.ucsNames()
+	for _, item := range other.Ucss {
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.ucss, "UCS")
+		if include {
+			item.Name = name
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.Ucss[replace].handle)
+				d.Ucss[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.Ucss = append(d.Ucss, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.Ucss[replace].handle)
+		}
+	}
+	existing = d.viewNames()
+	for _, item := range other.Views {
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.views, "VIEW")
+		if include {
+			item.Name = name
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.Views[replace].handle)
+				d.Views[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.Views = append(d.Views, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.Views[replace].handle)
+		}
+	}
+	existing = d.viewPortNames()
+	for _, item := range other.ViewPorts {
+		name, include, replace := imp.tableItemTarget(item.Name, existing, imp.viewPorts, "VPORT")
+		if include {
+			item.Name = name
+			if replace >= 0 {
+				item.handle = imp.mapHandle(item.handle, &d.ViewPorts[replace].handle)
+				d.ViewPorts[replace] = item
+			} else {
+				item.handle = imp.newHandle(item.handle)
+				d.ViewPorts = append(d.ViewPorts, item)
+			}
+		} else if replace >= 0 {
+			imp.mapHandle(item.handle, &d.ViewPorts[replace].handle)
+		}
+	}
+}
+
+// importObjects copies the objects other than dictionaries.  Named objects resolve conflicts like table entries.
+func (imp *drawingImporter) importObjects(other *Drawing) {
+	d := imp.target
+	existingNames := d.mleaderStyleNames()
+	existing := d.mleaderStyleIndices()
+	for _, o := range other.Objects {
+		switch object := o.(type) {
+		case *MLeaderStyle:
+			name, include, replace := imp.tableItemTarget(object.Name, existingNames, imp.mleaderStyles, "MLEADERSTYLE")
+			if len(object.Name) == 0 {
+				// an unnamed style can't conflict with anything
+				replace = -1
+			}
+			if include {
+				copied := *object
+				copied.Name = name
+				copied.pointerOwner = pointer{}
+				if replace >= 0 {
+					copied.handle = imp.mapHandle(object.handle, &d.Objects[existing[replace]].(*MLeaderStyle).handle)
+					d.Objects[existing[replace]] = &copied
+				} else {
+					copied.handle = imp.newHandle(object.handle)
+					d.Objects = append(d.Objects, &copied)
+				}
+				imp.objects = append(imp.objects, &copied)
+			} else if replace >= 0 {
+				imp.mapHandle(object.handle, &d.Objects[existing[replace]].(*MLeaderStyle).handle)
+			}
+		}
+	}
+}
+
+// cloneEntities copies the entities, gives them new handles and rewrites their name references.
+func (imp *drawingImporter) cloneEntities(entities []Entity) (clones []Entity, err error) {
+	clones = make([]Entity, 0, len(entities))
+	for _, e := range entities {
+		var clone Entity
+		clone, err = cloneEntity(e)
+		if err != nil {
+			return
+		}
+		imp.assignHandle(clone)
+		imp.renameReferences(clone)
+		clones = append(clones, clone)
+	}
+
+	imp.clones = append(imp.clones, clones...)
+	return
+}
+
+// pointerHolder is the part of entities and objects that refers to other items.
+type pointerHolder interface {
+	getOwnerPointer() pointer
+	setOwnerPointerHandle(h Handle)
+	pointers() []*pointer
+}
+
+// remapPointers updates the pointers of every cloned entity and object; this can only happen once every imported item
+// has its new handle.
+func (imp *drawingImporter) remapPointers() {
+	for _, object := range imp.objects {
+		imp.remapItemPointers(object)
+		if style, ok := object.(*MLeaderStyle); ok {
+			style.LeaderLineTypeHandle = imp.remapHandle(style.LeaderLineTypeHandle)
+			style.ArrowheadHandle = imp.remapHandle(style.ArrowheadHandle)
+			style.TextStyleHandle = imp.remapHandle(style.TextStyleHandle)
+			style.BlockContentHandle = imp.remapHandle(style.BlockContentHandle)
+		}
+	}
+	for _, clone := range imp.clones {
+		imp.remapItemPointers(clone)
+		if hatch, ok := clone.(*Hatch); ok {
+			for i := range hatch.BoundaryPaths {
+				handles := hatch.BoundaryPaths[i].SourceBoundaryHandles
+				for j := range handles {
+					handles[j] = imp.remapHandle(handles[j])
+				}
+			}
+		}
+	}
+}
+
+func (imp *drawingImporter) remapItemPointers(item pointerHolder) {
+	if _, ok := imp.handles[item.getOwnerPointer().handle]; !ok {
+		// the owner is set by the container the item is added to
+		item.setOwnerPointerHandle(Handle(0))
+	}
+	for _, p := range item.pointers() {
+		if p.handle == 0 {
+			p.value = nil
+			continue
+		}
+		if h, ok := imp.handles[p.handle]; ok {
+			p.handle = h
+			p.value = nil
+		} else {
+			p.handle = imp.newHandle(p.handle)
+		}
+	}
+}
+
+// remapHandle returns the handle that replaces `old` in the drawing, reserving a new one if the item it refers to
+// wasn't imported.
+func (imp *drawingImporter) remapHandle(old Handle) Handle {
+	if h, ok := imp.handles[old]; ok {
+		return h
+	}
+	return imp.newHandle(old)
+}
+
+func (imp *drawingImporter) newHandle(old Handle) Handle {
+	if old == 0 {
+		return 0
+	}
+	h := imp.nextHandle
+	imp.nextHandle++
+	imp.handles[old] = h
+	return h
+}
+
+// mapHandle records that the imported table entry with handle `old` is represented by an existing entry and returns
+// that entry's handle, assigning one if it has none yet.
+func (imp *drawingImporter) mapHandle(old Handle, existing *Handle) Handle {
+	if *existing == 0 && old != 0 {
+		*existing = imp.nextHandle
+		imp.nextHandle++
+	}
+	if old != 0 {
+		imp.handles[old] = *existing
+	}
+	return *existing
+}
+
+func (imp *drawingImporter) assignHandle(e Entity) {
+	e.SetHandle(imp.newHandle(e.Handle()))
+	switch ent := e.(type) {
+	case *Insert:
+		for i := range ent.Attributes {
+			ent.Attributes[i].SetHandle(imp.newHandle(ent.Attributes[i].Handle()))
+		}
+		ent.seqend.SetHandle(imp.newHandle(ent.seqend.Handle()))
+	case *Polyline:
+		for i := range ent.Vertices {
+			ent.Vertices[i].SetHandle(imp.newHandle(ent.Vertices[i].Handle()))
+		}
+		ent.seqend.SetHandle(imp.newHandle(ent.seqend.Handle()))
+	}
+}
+
+// renameReferences updates the layer, line type, text style, dimension style and block names used by an imported
+// entity.
+func (imp *drawingImporter) renameReferences(e Entity) {
+	e.SetLayer(renamed(imp.layers, e.Layer()))
+	e.SetLineTypeName(renamed(imp.lineTypes, e.LineTypeName()))
+	switch ent := e.(type) {
+	case *Attribute:
+		ent.TextStyleName = renamed(imp.styles, ent.TextStyleName)
+	case *AttributeDefinition:
+		ent.TextStyleName = renamed(imp.styles, ent.TextStyleName)
+	case *Insert:
+		ent.Name = renamed(imp.blocks, ent.Name)
+		for i := range ent.Attributes {
+			imp.renameReferences(&ent.Attributes[i])
+		}
+	case *Leader:
+		ent.DimensionStyleName = renamed(imp.dimStyles, ent.DimensionStyleName)
+	case *MText:
+		ent.TextStyleName = renamed(imp.styles, ent.TextStyleName)
+	case *Polyline:
+		for i := range ent.Vertices {
+			imp.renameReferences(&ent.Vertices[i])
+		}
+	case *Text:
+		ent.TextStyleName = renamed(imp.styles, ent.TextStyleName)
+	case *Tolerance:
+		ent.DimensionStyleName = renamed(imp.dimStyles, ent.DimensionStyleName)
+	case Dimension:
+		ent.SetDimensionStyleName(renamed(imp.dimStyles, ent.DimensionStyleName()))
+		ent.SetBlockName(renamed(imp.blocks, ent.BlockName()))
+	}
+}
+
+// maxHandle returns the largest handle currently used by the drawing.
+func (d *Drawing) maxHandle() Handle {
+	max := d.Header.NextAvailableHandle
+	if max > 0 {
+		max--
+	}
+	check := func(h Handle) {
+		if h > max {
+			max = h
+		}
+	}
+	checkEntities := func(entities []Entity) {
+		for _, e := range entities {
+			check(e.Handle())
+			switch ent := e.(type) {
+			case *Insert:
+				for i := range ent.Attributes {
+					check(ent.Attributes[i].Handle())
+				}
+				check(ent.seqend.Handle())
+			case *Polyline:
+				for i := range ent.Vertices {
+					check(ent.Vertices[i].Handle())
+				}
+				check(ent.seqend.Handle())
+			}
+		}
+	}
+
+	checkEntities(d.Entities)
+	for i := range d.Blocks {
+		check(d.Blocks[i].handle)
+		check(d.Blocks[i].endBlockHandle)
+		checkEntities(d.Blocks[i].Entities)
+	}
+	for i := range d.AppIds {
+		check(d.AppIds[i].handle)
+	}
+	for i := range d.BlockRecords {
+		check(d.BlockRecords[i].handle)
+	}
+	for i := range d.DimStyles {
+		check(d.DimStyles[i].handle)
+	}
+	for i := range d.Layers {
+		check(d.Layers[i].handle)
+	}
+	for i := range d.LineTypes {
+		check(d.LineTypes[i].handle)
+	}
+	for i := range d.Styles {
+		check(d.Styles[i].handle)
+	}
+	for i := range d.Ucss {
+		check(d.Ucss[i].handle)
+	}
+	for i := range d.Views {
+		check(d.Views[i].handle)
+	}
+	for i := range d.ViewPorts {
+		check(d.ViewPorts[i].handle)
+	}
+	for _, o := range d.Objects {
+		check(o.Handle())
+	}
+	return max
+}
+
+func (d *Drawing) appIdNames() (names []string) {
+	for _, item := range d.AppIds {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) blockNames() (names []string) {
+	for _, item := range d.Blocks {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) blockRecordNames() (names []string) {
+	for _, item := range d.BlockRecords {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) dimStyleNames() (names []string) {
+	for _, item := range d.DimStyles {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) layerNames() (names []string) {
+	for _, item := range d.Layers {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) lineTypeNames() (names []string) {
+	for _, item := range d.LineTypes {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) mleaderStyles() (styles []*MLeaderStyle) {
+	for _, o := range d.Objects {
+		if style, ok := o.(*MLeaderStyle); ok {
+			styles = append(styles, style)
+		}
+	}
+	return
+}
+
+// mleaderStyleIndices returns the index in `Objects` of each MLeaderStyle, in the order of `mleaderStyleNames`.
+func (d *Drawing) mleaderStyleIndices() (indices []int) {
+	for i, o := range d.Objects {
+		if _, ok := o.(*MLeaderStyle); ok {
+			indices = append(indices, i)
+		}
+	}
+	return
+}
+
+func (d *Drawing) mleaderStyleNames() (names []string) {
+	for _, style := range d.mleaderStyles() {
+		names = append(names, style.Name)
+	}
+	return
+}
+
+func (d *Drawing) styleNames() (names []string) {
+	for _, item := range d.Styles {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) ucsNames() (names []string) {
+	for _, item := range d.Ucss {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) viewNames() (names []string) {
+	for _, item := range d.Views {
+		names = append(names, item.Name)
+	}
+	return
+}
+
+func (d *Drawing) viewPortNames() (names []string) {
+	for _, item := range d.ViewPorts {
+		names = append(names, item.Name)
+	}
+	return
+}
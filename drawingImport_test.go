@@ -0,0 +1,322 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func importTestDrawings() (target, source *Drawing) {
+	target = NewDrawing()
+	layer := *NewLayer()
+	layer.Name = "parts"
+	layer.Color = Color(1)
+	target.Layers = append(target.Layers, layer)
+
+	source = NewDrawing()
+	layer = *NewLayer()
+	layer.Name = "PARTS"
+	layer.Color = Color(3)
+	source.Layers = append(source.Layers, layer)
+	line := NewLine()
+	line.SetLayer("PARTS")
+	line.P2 = Point{1.0, 2.0, 3.0}
+	source.Entities = append(source.Entities, line)
+	return
+}
+
+func TestImportKeepExisting(t *testing.T) {
+	target, source := importTestDrawings()
+	insert, err := target.Import(source, NewImportOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, insert == nil, "expected no insert")
+	assertEqInt(t, 1, len(target.Layers))
+	assertEqShort(t, 1, int16(target.Layers[0].Color))
+	assertEqInt(t, 1, len(target.Entities))
+	line := target.Entities[0].(*Line)
+	assertEqString(t, "PARTS", line.Layer())
+	assertEqPoint(t, Point{1.0, 2.0, 3.0}, line.P2)
+}
+
+func TestImportWithoutOptionsKeepsExisting(t *testing.T) {
+	target, source := importTestDrawings()
+	_, err := target.Import(source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(target.Layers))
+	assertEqShort(t, 1, int16(target.Layers[0].Color))
+}
+
+func TestImportOverwrite(t *testing.T) {
+	target, source := importTestDrawings()
+	opts := NewImportOptions()
+	opts.ConflictStrategy = ImportConflictOverwrite
+	_, err := target.Import(source, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(target.Layers))
+	assertEqString(t, "PARTS", target.Layers[0].Name)
+	assertEqShort(t, 3, int16(target.Layers[0].Color))
+}
+
+func TestImportRenameUpdatesReferences(t *testing.T) {
+	target, source := importTestDrawings()
+	block := *NewBlock()
+	block.Name = "bolt"
+	block.Entities = append(block.Entities, NewCircle())
+	target.Blocks = append(target.Blocks, block)
+	block = *NewBlock()
+	block.Name = "BOLT"
+	circle := NewCircle()
+	circle.SetLayer("PARTS")
+	block.Entities = append(block.Entities, circle)
+	source.Blocks = append(source.Blocks, block)
+	insert := NewInsert()
+	insert.Name = "BOLT"
+	source.Entities = append(source.Entities, insert)
+
+	opts := NewImportOptions()
+	opts.ConflictStrategy = ImportConflictRename
+	opts.RenamePrefix = "part-"
+	_, err := target.Import(source, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 2, len(target.Layers))
+	assertEqString(t, "part-PARTS", target.Layers[1].Name)
+	assertEqString(t, "part-PARTS", target.Entities[0].Layer())
+	assertEqString(t, "part-BOLT", target.Entities[1].(*Insert).Name)
+	assertEqInt(t, 2, len(target.Blocks))
+	assertEqString(t, "part-BOLT", target.Blocks[1].Name)
+	assertEqString(t, "part-PARTS", target.Blocks[1].Entities[0].Layer())
+}
+
+func TestImportRenameAvoidsExistingNames(t *testing.T) {
+	target, source := importTestDrawings()
+	layer := *NewLayer()
+	layer.Name = "PARTS-2"
+	target.Layers = append(target.Layers, layer)
+	opts := NewImportOptions()
+	opts.ConflictStrategy = ImportConflictRename
+	opts.RenameSuffix = "-2"
+	_, err := target.Import(source, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqString(t, "PARTS-2_1", target.Entities[0].Layer())
+}
+
+func TestImportRenameAvoidsImportedNames(t *testing.T) {
+	target, source := importTestDrawings()
+	layer := *NewLayer()
+	layer.Name = "PARTS-2"
+	layer.Color = Color(5)
+	source.Layers = append(source.Layers, layer)
+	line := NewLine()
+	line.SetLayer("PARTS-2")
+	source.Entities = append(source.Entities, line)
+	opts := NewImportOptions()
+	opts.ConflictStrategy = ImportConflictRename
+	opts.RenameSuffix = "-2"
+	_, err := target.Import(source, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqString(t, "PARTS-2_1", target.Entities[0].Layer())
+	assertEqString(t, "PARTS-2", target.Entities[1].Layer())
+
+	// every imported layer is kept
+	colors := map[string]Color{}
+	for _, l := range target.Layers {
+		colors[l.Name] = l.Color
+	}
+	assertEqInt(t, 1, int(colors["parts"]))
+	assertEqInt(t, 3, int(colors["PARTS-2_1"]))
+	assertEqInt(t, 5, int(colors["PARTS-2"]))
+}
+
+func TestImportAsBlock(t *testing.T) {
+	target, source := importTestDrawings()
+	source.Header.InsertionBase = Point{5.0, 5.0, 0.0}
+	opts := NewImportOptions()
+	opts.AsBlock = true
+	opts.BlockName = "SHEET1"
+	opts.InsertionPoint = Point{10.0, 20.0, 0.0}
+	insert, err := target.Import(source, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqString(t, "SHEET1", insert.Name)
+	assertEqPoint(t, Point{10.0, 20.0, 0.0}, insert.Location)
+	assertEqInt(t, 1, len(target.Entities))
+	assert(t, target.Entities[0] == insert, "expected the insert to be added to the drawing")
+	assertEqInt(t, 1, len(target.Blocks))
+	assertEqPoint(t, Point{5.0, 5.0, 0.0}, target.Blocks[0].BasePoint)
+	assertEqInt(t, 1, len(target.Blocks[0].Entities))
+
+	_, err = target.Import(source, opts)
+	assert(t, err != nil, "expected an error when the block already exists")
+}
+
+func TestImportAsBlockConflictsWithImportedBlock(t *testing.T) {
+	target, source := importTestDrawings()
+	block := *NewBlock()
+	block.Name = "SHEET"
+	source.Blocks = append(source.Blocks, block)
+	opts := NewImportOptions()
+	opts.AsBlock = true
+	opts.BlockName = "sheet"
+	insert, err := target.Import(source, opts)
+	assert(t, err != nil, "expected an error when an imported block has the same name")
+	assert(t, insert == nil, "expected no insert")
+	assertEqInt(t, 0, len(target.Blocks))
+
+	// a renamed block doesn't conflict, but one renamed to the block name does
+	target.Blocks = append(target.Blocks, block)
+	opts.ConflictStrategy = ImportConflictRename
+	opts.RenameSuffix = "_NEW"
+	opts.BlockName = "SHEET_NEW"
+	_, err = target.Import(source, opts)
+	assert(t, err != nil, "expected an error when a renamed block has the same name")
+	opts.BlockName = "SHEET"
+	_, err = target.Import(source, opts)
+	assert(t, err != nil, "expected an error when the block already exists")
+	opts.BlockName = "IMPORTED"
+	_, err = target.Import(source, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, len(target.Blocks))
+}
+
+func TestImportRemapsHandlesAndPointers(t *testing.T) {
+	target := NewDrawing()
+	existing := NewLine()
+	existing.SetHandle(Handle(0x10))
+	target.Entities = append(target.Entities, existing)
+
+	source := NewDrawing()
+	owner := NewLine()
+	owner.SetHandle(Handle(0x10))
+	owned := NewCircle()
+	owned.SetHandle(Handle(0x11))
+	owned.setOwnerPointerHandle(Handle(0x10))
+	source.Entities = append(source.Entities, owner, owned)
+
+	_, err := target.Import(source, NewImportOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, len(target.Entities))
+	newOwner := target.Entities[1]
+	newOwned := target.Entities[2]
+	assertEqUInt64(t, 0x11, uint64(newOwner.Handle()))
+	assertEqUInt64(t, 0x12, uint64(newOwned.Handle()))
+	assertEqUInt64(t, 0x11, uint64(newOwned.getOwnerPointer().handle))
+	assert(t, newOwned.Owner() != nil, "expected owner to be bound")
+	assertEqUInt64(t, 0x11, uint64((*newOwned.Owner()).Handle()))
+
+	// the source drawing is untouched
+	assertEqUInt64(t, 0x10, uint64(source.Entities[0].Handle()))
+}
+
+func TestImportRemapsHatchSourceBoundaries(t *testing.T) {
+	target := NewDrawing()
+	existing := NewLine()
+	existing.SetHandle(Handle(0x20))
+	target.Entities = append(target.Entities, existing)
+
+	source := NewDrawing()
+	boundary := NewCircle()
+	boundary.SetHandle(Handle(0x20))
+	block := *NewBlock()
+	block.Name = "BOUNDARY"
+	block.Entities = append(block.Entities, boundary)
+	source.Blocks = append(source.Blocks, block)
+	hatch := NewHatch()
+	hatch.SetHandle(Handle(0x21))
+	path := *NewHatchEdgeBoundaryPath([]HatchEdge{})
+	path.SourceBoundaryHandles = []Handle{Handle(0x20)}
+	hatch.BoundaryPaths = append(hatch.BoundaryPaths, path)
+	source.Entities = append(source.Entities, hatch)
+
+	_, err := target.Import(source, NewImportOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	newBoundary := target.Blocks[0].Entities[0]
+	newHatch := target.Entities[1].(*Hatch)
+	assertEqUInt64(t, uint64(newBoundary.Handle()), uint64(newHatch.BoundaryPaths[0].SourceBoundaryHandles[0]))
+	assert(t, newBoundary.Handle() != existing.Handle(), "expected a new handle for the boundary")
+}
+
+func TestImportKeepsUnresolvedPointers(t *testing.T) {
+	target := NewDrawing()
+	existing := NewLine()
+	existing.SetHandle(Handle(0x30))
+	target.Entities = append(target.Entities, existing)
+
+	source := NewDrawing()
+	first := NewLine()
+	first.pointerPlotStyle.handle = Handle(0x30)
+	second := NewLine()
+	second.pointerPlotStyle.handle = Handle(0x30)
+	source.Entities = append(source.Entities, first, second)
+
+	_, err := target.Import(source, NewImportOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the missing item gets a single new handle that doesn't refer to anything in the drawing
+	firstHandle := target.Entities[1].(*Line).pointerPlotStyle.handle
+	secondHandle := target.Entities[2].(*Line).pointerPlotStyle.handle
+	assert(t, firstHandle != 0, "expected the pointer to be kept")
+	assertEqUInt64(t, uint64(firstHandle), uint64(secondHandle))
+	_, err = target.GetItemByHandle(firstHandle)
+	assert(t, err != nil, "expected the pointer not to refer to an item of the drawing")
+}
+
+func TestImportObjects(t *testing.T) {
+	target := NewDrawing()
+	existing := NewMLeaderStyle()
+	existing.Name = "Callout"
+	existing.SetHandle(Handle(0x40))
+	target.Objects = append(target.Objects, existing)
+
+	source := NewDrawing()
+	root := NewDictionary()
+	root.SetHandle(Handle(0x3F))
+	callout := NewMLeaderStyle()
+	callout.Name = "Callout"
+	callout.SetHandle(Handle(0x40))
+	note := NewMLeaderStyle()
+	note.Name = "Note"
+	note.SetHandle(Handle(0x41))
+	note.DoglegLength = 3.0
+	source.Objects = append(source.Objects, root, callout, note)
+	leader := NewMLeader()
+	leader.pointerStyle.handle = Handle(0x41)
+	source.Entities = append(source.Entities, leader)
+
+	_, err := target.Import(source, NewImportOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the existing style wins and dictionaries aren't copied
+	assertEqInt(t, 2, len(target.Objects))
+	assert(t, target.Objects[0] == existing, "expected the existing style to be kept")
+	imported := target.Objects[1].(*MLeaderStyle)
+	assertEqString(t, "Note", imported.Name)
+	assertEqFloat64(t, 3.0, imported.DoglegLength)
+	assert(t, imported.Handle() != existing.Handle(), "expected a new handle")
+	newLeader := target.Entities[0].(*MLeader)
+	assert(t, newLeader.Style() != nil, "expected the style pointer to be bound")
+	assert(t, *newLeader.Style() == DrawingItem(imported), "expected the imported style")
+
+	// the source drawing is untouched
+	assertEqUInt64(t, 0x41, uint64(note.Handle()))
+}
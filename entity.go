@@ -3,6 +3,7 @@ package dxf
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -55,9 +56,18 @@ func readEntity(np CodePair, reader codePairReader) (entity Entity, nextPair Cod
 	}
 
 	created = true
+	_, isDimension := entity.(Dimension)
+	var dimensionExtendedData []CodePair
 	nextPair, error = reader.readCodePair()
 	for error == nil && nextPair.Code != 0 {
-		entity.tryApplyCodePair(nextPair)
+		if collector, ok := entity.(extendedDataCollector); ok && nextPair.Code >= 1000 {
+			collector.addExtendedDataPair(nextPair)
+		} else {
+			if isDimension && nextPair.Code >= 1000 {
+				dimensionExtendedData = append(dimensionExtendedData, nextPair)
+			}
+			entity.tryApplyCodePair(nextPair)
+		}
 		nextPair, error = reader.readCodePair()
 	}
 
@@ -66,6 +76,9 @@ func readEntity(np CodePair, reader codePairReader) (entity Entity, nextPair Cod
 	case *dimensionHelper:
 		entity, error = createAndPopulateDimension(dim)
 	}
+	if dim, ok := entity.(Dimension); ok && error == nil {
+		readDimStyleOverrides(dim, dimensionExtendedData)
+	}
 	return
 }
 
@@ -76,6 +89,41 @@ func allCodePairs(e Entity, version AcadVersion) (pairs []CodePair) {
 	return
 }
 
+// cloneEntity creates a deep copy of an entity by round-tripping it through its code pairs.
+func cloneEntity(e Entity) (clone Entity, err error) {
+	pairs := allCodePairs(e, e.maxVersion())
+	pairs = append(pairs, NewStringCodePair(0, "ENDSEC"))
+	reader := newDirectCodePairReader(pairs...)
+	nextPair, err := reader.readCodePair()
+	if err != nil {
+		return
+	}
+
+	entities, _, err := readEntities(nextPair, reader)
+	if err != nil {
+		return
+	}
+	if len(entities) != 1 {
+		err = fmt.Errorf("unable to clone entity of type %s", e.typeString())
+		return
+	}
+
+	clone = entities[0]
+	// elevation is only written for R12 and below
+	clone.SetElevation(e.Elevation())
+	switch source := e.(type) {
+	case *Viewport:
+		// the frozen layers are only written to the R12 extended data
+		clone.(*Viewport).FrozenLayerNames = append([]string{}, source.FrozenLayerNames...)
+	case ModelerGeometry:
+		// the binary payload is written to the ACDSDATA section instead of the entity
+		_, _, _, sourceData := source.modelerData()
+		_, _, _, cloneData := clone.(ModelerGeometry).modelerData()
+		*cloneData = append([]byte{}, *sourceData...)
+	}
+	return
+}
+
 func writeEntitiesSection(entities []Entity, writer codePairWriter, version AcadVersion) error {
 	pairs := make([]CodePair, 0)
 	for _, entity := range entities {
@@ -118,6 +166,14 @@ func trailingCodePairs(entity Entity, version AcadVersion) (pairs []CodePair) {
 			pairs = append(pairs, v.codePairs(version)...)
 		}
 		pairs = append(pairs, ent.seqend.codePairs(version)...)
+	case *Viewport:
+		if version <= R12 {
+			pairs = append(pairs, ent.extendedDataCodePairs()...)
+		}
+	case Dimension:
+		if version >= R12 {
+			pairs = append(pairs, dimStyleOverrideCodePairs(ent)...)
+		}
 	}
 
 	return
@@ -160,6 +216,8 @@ func afterRead(entity *Entity) {
 		for i := 0; i < minLength; i++ {
 			ent.SetClippingVertices(append(ent.ClippingVertices(), Point{ent.clippingVerticesX()[i], ent.clippingVerticesY()[i], 0.0}))
 		}
+	case *Hatch:
+		ent.afterRead()
 	case *Leader:
 		for i := 0; i < ent.vertexCount; i++ {
 			ent.Vertices = append(ent.Vertices, Point{ent.verticesX[i], ent.verticesY[i], ent.verticesZ[i]})
@@ -181,6 +239,14 @@ func afterRead(entity *Entity) {
 	case *ProxyEntity:
 		ent.GraphicsData = stringsToBytes(ent.graphicsDataString)
 		ent.EntityData = stringsToBytes(ent.entityDataString)
+	case *Mesh:
+		ent.afterRead()
+	case *SweptSurface:
+		ent.afterRead()
+	case *Table:
+		ent.afterRead()
+	case *Viewport:
+		ent.afterRead()
 	case *Spline:
 		if len(ent.weights) == len(ent.ControlPoints) {
 			for i := range ent.ControlPoints {
@@ -359,6 +425,10 @@ func stringsToBytes(vals []string) []byte {
 
 func (d *dimensionHelper) tryApplyCodePair(codePair CodePair) {
 	d.collectedPairs = append(d.collectedPairs, codePair)
+	if codePair.Code == 70 {
+		// the type picks the dimension to create
+		d.dimensionType = DimensionType(codePair.Value.(ShortCodePairValue).Value)
+	}
 }
 
 func (d *dimensionHelper) codePairs(version AcadVersion) (pairs []CodePair) {
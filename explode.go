@@ -0,0 +1,219 @@
+package dxf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ExplodeInsert returns the contents of the block referenced by `insert` transformed into the space containing the
+// insert.  Nested inserts are exploded recursively, each cell of an insert array produces its own copy of the block
+// contents, and visible attributes are converted to `Text`.  Entities in the block that are on layer "0" or whose
+// color, line type or line weight is BYBLOCK take those properties from the insert.  Entities that `Transform` can't
+// place, such as proxy entities and OLE frames, are skipped.  The returned entities have no handle assigned and the
+// drawing is not modified.
+func (d *Drawing) ExplodeInsert(insert *Insert) ([]Entity, error) {
+	if insert == nil {
+		return nil, fmt.Errorf("insert must not be nil")
+	}
+
+	return d.explodeInsert(insert, *NewIdentityMatrix4(), nil)
+}
+
+func (d *Drawing) findBlock(name string) *Block {
+	for i := range d.Blocks {
+		if strings.EqualFold(d.Blocks[i].Name, name) {
+			return &d.Blocks[i]
+		}
+	}
+
+	return nil
+}
+
+func (d *Drawing) explodeInsert(insert *Insert, parent Matrix4, blockStack []string) (entities []Entity, err error) {
+	block := d.findBlock(insert.Name)
+	if block == nil {
+		err = fmt.Errorf("block '%s' not found", insert.Name)
+		return
+	}
+	for _, name := range blockStack {
+		if strings.EqualFold(name, block.Name) {
+			err = fmt.Errorf("block '%s' references itself", block.Name)
+			return
+		}
+	}
+	blockStack = append(blockStack, block.Name)
+
+	columns := int(insert.ColumnCount)
+	if columns < 1 {
+		columns = 1
+	}
+	rows := int(insert.RowCount)
+	if rows < 1 {
+		rows = 1
+	}
+
+	ocs := *newOcsMatrix4(insert.ExtrusionDirection)
+	rotation := insert.Rotation * math.Pi / 180.0
+	columnDirection := Vector{X: math.Cos(rotation), Y: math.Sin(rotation)}
+	rowDirection := Vector{X: -math.Sin(rotation), Y: math.Cos(rotation)}
+	base := *NewTranslationMatrix4(Vector{X: -block.BasePoint.X, Y: -block.BasePoint.Y, Z: -block.BasePoint.Z})
+	entities = make([]Entity, 0)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			offset := columnDirection.Scale(float64(column) * insert.ColumnSpacing).Add(rowDirection.Scale(float64(row) * insert.RowSpacing))
+			m := parent.Multiply(*NewTranslationMatrix4(ocs.TransformVector(offset)))
+			m = m.Multiply(insertMatrix(insert))
+			m = m.Multiply(base)
+			for _, e := range block.Entities {
+				var exploded []Entity
+				exploded, err = d.explodeBlockEntity(e, insert, m, blockStack)
+				if err != nil {
+					return
+				}
+				entities = append(entities, exploded...)
+			}
+		}
+	}
+
+	// attributes are already positioned in the space containing the insert
+	for i := range insert.Attributes {
+		attribute := &insert.Attributes[i]
+		if attribute.IsInvisible() {
+			continue
+		}
+		var text Entity
+		text, err = Transform(attributeText(attribute), parent)
+		if err != nil {
+			return
+		}
+		entities = append(entities, text)
+	}
+
+	return
+}
+
+func (d *Drawing) explodeBlockEntity(e Entity, insert *Insert, m Matrix4, blockStack []string) (entities []Entity, err error) {
+	var clone Entity
+	switch ent := e.(type) {
+	case *AttributeDefinition:
+		// only constant attributes are displayed without a corresponding attribute on the insert
+		if !ent.IsConstant() || ent.IsInvisible() {
+			return
+		}
+		clone = attributeDefinitionText(ent)
+	default:
+		clone, err = cloneEntity(e)
+		if err != nil {
+			return
+		}
+	}
+
+	inheritBlockProperties(clone, insert)
+	if nested, ok := clone.(*Insert); ok {
+		return d.explodeInsert(nested, m, blockStack)
+	}
+
+	clone, err = Transform(clone, m)
+	if err != nil {
+		// entities that can't be placed are left out rather than failing the whole explode
+		err = nil
+		return
+	}
+	clone.SetHandle(Handle(0))
+	clone.setOwnerPointerHandle(Handle(0))
+	entities = append(entities, clone)
+	return
+}
+
+// inheritBlockProperties applies the properties of `insert` to an entity from the referenced block where the entity
+// defers to its containing block.
+func inheritBlockProperties(e Entity, insert *Insert) {
+	if e.Layer() == "0" {
+		e.SetLayer(insert.Layer())
+	}
+	color := e.Color()
+	if color.ByBlock() {
+		e.SetColor(insert.Color())
+	}
+	if strings.EqualFold(e.LineTypeName(), "BYBLOCK") {
+		e.SetLineTypeName(insert.LineTypeName())
+	}
+	lineWeight := e.LineWeight()
+	if lineWeight.ByBlock() {
+		e.SetLineWeight(insert.LineWeight())
+	}
+}
+
+// attributeText creates a `Text` entity that displays the value of an attribute.
+func attributeText(a *Attribute) *Text {
+	text := NewText()
+	copyEntityProperties(text, a)
+	text.Thickness = a.Thickness
+	text.Location = a.Location
+	text.Height = a.TextHeight
+	text.Value = a.Value
+	text.Rotation = a.Rotation
+	text.RelativeXScaleFactor = a.RelativeXScaleFactor
+	text.ObliqueAngle = a.ObliqueAngle
+	text.TextStyleName = a.TextStyleName
+	text.TextGenerationFlags = a.TextGenerationFlags
+	text.HorizontalTextJustification = a.HorizontalTextJustification
+	text.VerticalTextJustification = a.VerticalTextJustification
+	text.SecondAlignmentPoint = a.SecondAlignmentPoint
+	text.Normal = a.Normal
+	text.SetHandle(Handle(0))
+	text.setOwnerPointerHandle(Handle(0))
+	return text
+}
+
+// attributeDefinitionText creates a `Text` entity that displays the default value of an attribute definition.
+func attributeDefinitionText(a *AttributeDefinition) *Text {
+	text := NewText()
+	copyEntityProperties(text, a)
+	text.Thickness = a.Thickness
+	text.Location = a.Location
+	text.Height = a.TextHeight
+	text.Value = a.Value
+	text.Rotation = a.Rotation
+	text.RelativeXScaleFactor = a.RelativeXScaleFactor
+	text.ObliqueAngle = a.ObliqueAngle
+	text.TextStyleName = a.TextStyleName
+	text.TextGenerationFlags = a.TextGenerationFlags
+	text.HorizontalTextJustification = a.HorizontalTextJustification
+	text.VerticalTextJustification = a.VerticalTextJustification
+	text.SecondAlignmentPoint = a.SecondAlignmentPoint
+	text.Normal = a.Normal
+	text.SetHandle(Handle(0))
+	text.setOwnerPointerHandle(Handle(0))
+	return text
+}
+
+// insertMatrix returns the transformation an Insert applies to the contents of its block, excluding the block base
+// point and array offsets.
+func insertMatrix(insert *Insert) Matrix4 {
+	m := *newOcsMatrix4(insert.ExtrusionDirection)
+	m = m.Multiply(*NewTranslationMatrix4(Vector{X: insert.Location.X, Y: insert.Location.Y, Z: insert.Location.Z}))
+	m = m.Multiply(*NewRotationMatrix4(*NewZAxis(), insert.Rotation))
+	m = m.Multiply(*NewScaleMatrix4(insert.XScaleFactor, insert.YScaleFactor, insert.ZScaleFactor))
+	return m
+}
+
+// copyEntityProperties copies the common entity properties from `source` to `dest`.
+func copyEntityProperties(dest, source Entity) {
+	dest.SetHandle(source.Handle())
+	dest.SetIsInPaperSpace(source.IsInPaperSpace())
+	dest.SetLayer(source.Layer())
+	dest.SetLineTypeName(source.LineTypeName())
+	dest.SetElevation(source.Elevation())
+	dest.SetMaterialHandle(source.MaterialHandle())
+	dest.SetColor(source.Color())
+	dest.SetLineWeight(source.LineWeight())
+	dest.SetLineTypeScale(source.LineTypeScale())
+	dest.SetIsVisible(source.IsVisible())
+	dest.SetColor24Bit(source.Color24Bit())
+	dest.SetColorName(source.ColorName())
+	dest.SetTransparency(source.Transparency())
+	dest.SetShadowMode(source.ShadowMode())
+	dest.setOwnerPointerHandle(source.getOwnerPointer().handle)
+}
@@ -0,0 +1,169 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func explodeTestDrawing() *Drawing {
+	d := NewDrawing()
+	block := *NewBlock()
+	block.Name = "SQUARE"
+	block.BasePoint = Point{1.0, 1.0, 0.0}
+	line := NewLine()
+	line.P1 = Point{1.0, 1.0, 0.0}
+	line.P2 = Point{2.0, 1.0, 0.0}
+	line.SetColor(ByBlock())
+	line.SetLineTypeName("BYBLOCK")
+	block.Entities = append(block.Entities, line)
+	d.Blocks = append(d.Blocks, block)
+	return d
+}
+
+func TestExplodeInsertTransform(t *testing.T) {
+	d := explodeTestDrawing()
+	insert := NewInsert()
+	insert.Name = "square"
+	insert.Location = Point{10.0, 0.0, 0.0}
+	insert.XScaleFactor = 2.0
+	insert.YScaleFactor = 2.0
+	insert.Rotation = 90.0
+	insert.SetColor(Color(5))
+	insert.SetLineTypeName("DASHED")
+	entities, err := d.ExplodeInsert(insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(entities))
+	line := entities[0].(*Line)
+	assertNearPoint(t, Point{10.0, 0.0, 0.0}, line.P1)
+	assertNearPoint(t, Point{10.0, 2.0, 0.0}, line.P2)
+	assertEqShort(t, 5, int16(line.Color()))
+	assertEqString(t, "DASHED", line.LineTypeName())
+
+	// the block is untouched
+	original := d.Blocks[0].Entities[0].(*Line)
+	assertEqPoint(t, Point{1.0, 1.0, 0.0}, original.P1)
+	assertEqShort(t, 0, int16(original.Color()))
+}
+
+func TestExplodeInsertArray(t *testing.T) {
+	d := explodeTestDrawing()
+	insert := NewInsert()
+	insert.Name = "SQUARE"
+	insert.ColumnCount = 2
+	insert.RowCount = 3
+	insert.ColumnSpacing = 5.0
+	insert.RowSpacing = 10.0
+	entities, err := d.ExplodeInsert(insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 6, len(entities))
+	assertNearPoint(t, Point{5.0, 20.0, 0.0}, entities[5].(*Line).P1)
+}
+
+func TestExplodeNestedInsert(t *testing.T) {
+	d := explodeTestDrawing()
+	outer := *NewBlock()
+	outer.Name = "OUTER"
+	nested := NewInsert()
+	nested.Name = "SQUARE"
+	nested.Location = Point{0.0, 5.0, 0.0}
+	nested.SetColor(Color(2))
+	outer.Entities = append(outer.Entities, nested)
+	d.Blocks = append(d.Blocks, outer)
+
+	insert := NewInsert()
+	insert.Name = "OUTER"
+	insert.Location = Point{100.0, 0.0, 0.0}
+	insert.SetColor(Color(5))
+	entities, err := d.ExplodeInsert(insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(entities))
+	line := entities[0].(*Line)
+	assertNearPoint(t, Point{100.0, 5.0, 0.0}, line.P1)
+	assertEqShort(t, 2, int16(line.Color()))
+}
+
+func TestExplodeInsertAttributes(t *testing.T) {
+	d := explodeTestDrawing()
+	constant := NewAttributeDefinition()
+	constant.SetIsConstant(true)
+	constant.Value = "fixed"
+	constant.Location = Point{1.0, 2.0, 0.0}
+	variable := NewAttributeDefinition()
+	variable.TextTag = "TAG"
+	d.Blocks[0].Entities = append(d.Blocks[0].Entities, constant, variable)
+
+	insert := NewInsert()
+	insert.Name = "SQUARE"
+	insert.Location = Point{10.0, 0.0, 0.0}
+	attribute := *NewAttribute()
+	attribute.AttributeTag = "TAG"
+	attribute.Value = "value"
+	attribute.Location = Point{10.0, 3.0, 0.0}
+	hidden := *NewAttribute()
+	hidden.SetIsInvisible(true)
+	insert.Attributes = append(insert.Attributes, attribute, hidden)
+	entities, err := d.ExplodeInsert(insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, len(entities))
+	fixed := entities[1].(*Text)
+	assertEqString(t, "fixed", fixed.Value)
+	assertNearPoint(t, Point{10.0, 1.0, 0.0}, fixed.Location)
+	value := entities[2].(*Text)
+	assertEqString(t, "value", value.Value)
+	assertNearPoint(t, Point{10.0, 3.0, 0.0}, value.Location)
+}
+
+func TestExplodeInsertErrors(t *testing.T) {
+	d := explodeTestDrawing()
+	insert := NewInsert()
+	insert.Name = "MISSING"
+	_, err := d.ExplodeInsert(insert)
+	assert(t, err != nil, "expected an error for a missing block")
+
+	recursive := NewInsert()
+	recursive.Name = "SQUARE"
+	d.Blocks[0].Entities = append(d.Blocks[0].Entities, recursive)
+	insert.Name = "SQUARE"
+	_, err = d.ExplodeInsert(insert)
+	assert(t, err != nil, "expected an error for a self-referencing block")
+}
+
+func TestExplodeInsertSkipsUntransformableEntities(t *testing.T) {
+	d := explodeTestDrawing()
+	d.Blocks[0].Entities = append(d.Blocks[0].Entities, NewProxyEntity())
+	insert := NewInsert()
+	insert.Name = "SQUARE"
+	entities, err := d.ExplodeInsert(insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(entities))
+	_, ok := entities[0].(*Line)
+	assert(t, ok, "expected only the line")
+}
+
+func TestExplodeInsertWithHatch(t *testing.T) {
+	d := explodeTestDrawing()
+	d.Blocks[0].Entities = []Entity{halfDiskHatch(true)}
+	insert := NewInsert()
+	insert.Name = "SQUARE"
+	insert.XScaleFactor = 2.0
+	insert.YScaleFactor = 2.0
+	entities, err := d.ExplodeInsert(insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(entities))
+	assertNearBounds(t, Point{-4.0, -2.0, 0.0}, Point{0.0, 0.0, 0.0}, BoundingBox(entities[0]))
+	assertNearBounds(t, Point{-4.0, -2.0, 0.0}, Point{0.0, 0.0, 0.0}, d.BoundingBox(insert))
+
+	// the block is untouched
+	assertNearBounds(t, Point{-1.0, 0.0, 0.0}, Point{1.0, 1.0, 0.0}, BoundingBox(d.Blocks[0].Entities[0]))
+}
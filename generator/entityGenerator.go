@@ -250,7 +250,12 @@ func generateEntities() {
 				if backingField == field.Name {
 					backingField = "_" + backingField
 				}
-				builder.WriteString(fmt.Sprintf("		%s: %s,\n", backingField, field.DefaultValue))
+				defaultValue := field.DefaultValue
+				if infName == "Dimension" && field.Name == "DimensionType" && len(entity.Tag) > 0 {
+					// the tag selects the dimension type
+					defaultValue = "DimensionType" + entity.Tag
+				}
+				builder.WriteString(fmt.Sprintf("		%s: %s,\n", backingField, defaultValue))
 			}
 		}
 		for _, field := range entity.Fields {
@@ -451,7 +456,7 @@ func generateEntities() {
 
 	// dimension creator
 	builder.WriteString("func createAndPopulateDimension(temp *dimensionHelper) (dimension Entity, error error) {\n")
-	builder.WriteString("	switch temp.DimensionType() {\n")
+	builder.WriteString("	switch temp.DimensionType() & dimensionTypeMask {\n")
 	for _, dim := range spec.Entities {
 		if dim.implementsInterface("Dimension") && dim.Name != "dimensionHelper" {
 			builder.WriteString(fmt.Sprintf("	case DimensionType%s:\n", dim.Tag))
@@ -113,6 +113,8 @@ func generateTables() {
 		if generateReader {
 			builder.WriteString(fmt.Sprintf("func (this *%s) tryApplyCodePair(codePair CodePair) {\n", tableItem.Name))
 			builder.WriteString("	switch codePair.Code {\n")
+			builder.WriteString(fmt.Sprintf("	case %d:\n", tableItemHandleCode(&table)))
+			builder.WriteString("		this.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)\n")
 			for _, field := range tableItem.Fields {
 				readField(&builder, field, false)
 			}
@@ -133,11 +135,7 @@ func generateTables() {
 		builder.WriteString(fmt.Sprintf("	pairs = append(pairs, NewShortCodePair(70, int16(len(items))))\n"))
 		builder.WriteString(fmt.Sprintf("	for _, item := range items {\n"))
 		builder.WriteString(fmt.Sprintf("		pairs = append(pairs, NewStringCodePair(0, \"%s\"))\n", table.TypeString))
-		handleCode := 5
-		if table.TypeString == "DIMSTYLE" {
-			handleCode = 105
-		}
-		builder.WriteString(fmt.Sprintf("		pairs = append(pairs, NewStringCodePair(%d, stringFromHandle(item.Handle())))\n", handleCode))
+		builder.WriteString(fmt.Sprintf("		pairs = append(pairs, NewStringCodePair(%d, stringFromHandle(item.Handle())))\n", tableItemHandleCode(&table)))
 		builder.WriteString("		pairs = append(pairs, NewStringCodePair(100, \"AcDbSymbolTableRecord\"))\n")
 		builder.WriteString("		pairs = append(pairs, item.codePairs(version)...)\n")
 		builder.WriteString("	}\n")
@@ -217,9 +215,30 @@ func generateTables() {
 	builder.WriteString("}\n")
 	builder.WriteString("\n")
 
+	// largest handle
+	builder.WriteString("func maxTableItemHandle(drawing *Drawing) (max Handle) {\n")
+	for _, table := range tables {
+		builder.WriteString(fmt.Sprintf("	for i := range drawing.%s {\n", table.Collection))
+		builder.WriteString(fmt.Sprintf("		if h := drawing.%s[i].Handle(); h > max {\n", table.Collection))
+		builder.WriteString("			max = h\n")
+		builder.WriteString("		}\n")
+		builder.WriteString("	}\n")
+	}
+	builder.WriteString("	return\n")
+	builder.WriteString("}\n")
+	builder.WriteString("\n")
+
 	writeFile("tables.generated.go", builder)
 }
 
+// tableItemHandleCode returns the code of a table item's handle; DIMSTYLE uses 5 for another value.
+func tableItemHandleCode(table *xmlTable) int {
+	if table.TypeString == "DIMSTYLE" {
+		return 105
+	}
+	return 5
+}
+
 func getHandleFieldName(table *xmlTable) string {
 	return fmt.Sprintf("%s%sTableHandle", strings.ToLower(table.Collection[:1]), table.Collection[1:len(table.Collection)-1])
 }
@@ -4,5 +4,6 @@ go 1.17
 
 require (
 	github.com/google/uuid v1.1.2
-	golang.org/x/text v0.3.4
+	golang.org/x/image v0.0.0-20210628002857-a66eb6448b8d
+	golang.org/x/text v0.3.6
 )
@@ -0,0 +1,647 @@
+package dxf
+
+// HatchBoundaryPath is a single loop of a Hatch boundary.  Polyline paths use `Vertices` and `IsClosed`; all other
+// paths are made of `Edges`.
+type HatchBoundaryPath struct {
+	Flags    int
+	Vertices []HatchVertex
+	IsClosed bool
+	Edges    []HatchEdge
+
+	// SourceBoundaryHandles are the handles of the entities an associative hatch was created from.
+	SourceBoundaryHandles []Handle
+}
+
+// NewHatchPolylineBoundaryPath creates a new polyline HatchBoundaryPath from the given vertices.
+func NewHatchPolylineBoundaryPath(vertices []HatchVertex, isClosed bool) *HatchBoundaryPath {
+	path := &HatchBoundaryPath{
+		Flags:                 0,
+		Vertices:              vertices,
+		IsClosed:              isClosed,
+		Edges:                 []HatchEdge{},
+		SourceBoundaryHandles: []Handle{},
+	}
+	path.SetIsExternal(true)
+	path.SetIsPolyline(true)
+	return path
+}
+
+// NewHatchEdgeBoundaryPath creates a new HatchBoundaryPath from the given edges.
+func NewHatchEdgeBoundaryPath(edges []HatchEdge) *HatchBoundaryPath {
+	path := &HatchBoundaryPath{
+		Flags:                 0,
+		Vertices:              []HatchVertex{},
+		IsClosed:              false,
+		Edges:                 edges,
+		SourceBoundaryHandles: []Handle{},
+	}
+	path.SetIsExternal(true)
+	return path
+}
+
+// IsExternal status flag.
+func (p *HatchBoundaryPath) IsExternal() bool {
+	return p.Flags&1 != 0
+}
+
+// SetIsExternal status flag.
+func (p *HatchBoundaryPath) SetIsExternal(val bool) {
+	p.setFlag(1, val)
+}
+
+// IsPolyline status flag.
+func (p *HatchBoundaryPath) IsPolyline() bool {
+	return p.Flags&2 != 0
+}
+
+// SetIsPolyline status flag.
+func (p *HatchBoundaryPath) SetIsPolyline(val bool) {
+	p.setFlag(2, val)
+}
+
+// IsDerived status flag.
+func (p *HatchBoundaryPath) IsDerived() bool {
+	return p.Flags&4 != 0
+}
+
+// SetIsDerived status flag.
+func (p *HatchBoundaryPath) SetIsDerived(val bool) {
+	p.setFlag(4, val)
+}
+
+// IsTextBox status flag.
+func (p *HatchBoundaryPath) IsTextBox() bool {
+	return p.Flags&8 != 0
+}
+
+// SetIsTextBox status flag.
+func (p *HatchBoundaryPath) SetIsTextBox(val bool) {
+	p.setFlag(8, val)
+}
+
+// IsOutermost status flag.
+func (p *HatchBoundaryPath) IsOutermost() bool {
+	return p.Flags&16 != 0
+}
+
+// SetIsOutermost status flag.
+func (p *HatchBoundaryPath) SetIsOutermost(val bool) {
+	p.setFlag(16, val)
+}
+
+func (p *HatchBoundaryPath) setFlag(flag int, val bool) {
+	if val {
+		p.Flags |= flag
+	} else {
+		p.Flags &= ^flag
+	}
+}
+
+// HatchVertex is a vertex of a polyline HatchBoundaryPath.
+type HatchVertex struct {
+	X     float64
+	Y     float64
+	Bulge float64
+}
+
+// HatchEdge is a single edge of a HatchBoundaryPath; one of HatchLineEdge, HatchArcEdge, HatchEllipseEdge or
+// HatchSplineEdge.
+type HatchEdge interface {
+	edgeType() int16
+	codePairs(version AcadVersion) []CodePair
+}
+
+// HatchLineEdge is a straight HatchEdge.
+type HatchLineEdge struct {
+	Start Point
+	End   Point
+}
+
+// HatchArcEdge is a circular HatchEdge.  Angles are in degrees; a clockwise arc has its angles measured clockwise.
+type HatchArcEdge struct {
+	Center             Point
+	Radius             float64
+	StartAngle         float64
+	EndAngle           float64
+	IsCounterClockwise bool
+}
+
+// HatchEllipseEdge is an elliptical HatchEdge.  Angles are in degrees and `MajorAxis` is relative to `Center`.
+type HatchEllipseEdge struct {
+	Center             Point
+	MajorAxis          Vector
+	MinorAxisRatio     float64
+	StartAngle         float64
+	EndAngle           float64
+	IsCounterClockwise bool
+}
+
+// HatchSplineEdge is a NURBS HatchEdge.  Fit points and tangents are only written for R2010 and later.
+type HatchSplineEdge struct {
+	Degree        int
+	IsRational    bool
+	IsPeriodic    bool
+	Knots         []float64
+	ControlPoints []ControlPoint
+	FitPoints     []Point
+	StartTangent  Vector
+	EndTangent    Vector
+}
+
+func (e *HatchLineEdge) edgeType() int16 {
+	return 1
+}
+
+func (e *HatchArcEdge) edgeType() int16 {
+	return 2
+}
+
+func (e *HatchEllipseEdge) edgeType() int16 {
+	return 3
+}
+
+func (e *HatchSplineEdge) edgeType() int16 {
+	return 4
+}
+
+// HatchPatternDefinitionLine is one family of lines in a hatch pattern.  `Angle` is in degrees and an empty
+// `DashLengths` makes a continuous line.
+type HatchPatternDefinitionLine struct {
+	Angle       float64
+	BasePoint   Point
+	Offset      Vector
+	DashLengths []float64
+}
+
+// HatchGradientColor is a color stop of a gradient fill.  `Value` is the position of the stop from 0.0 to 1.0.
+type HatchGradientColor struct {
+	Value      float64
+	Color      Color
+	Color24Bit int
+}
+
+//
+// reading
+//
+
+func (h *Hatch) tryApplyCodePair(codePair CodePair) {
+	if h.readingHatchData {
+		// boundary data repeats codes with different meanings so it's parsed in order after reading
+		h.hatchPairs = append(h.hatchPairs, codePair)
+		return
+	}
+
+	switch codePair.Code {
+	case 100:
+		if codePair.Value.(StringCodePairValue).Value == "AcDbHatch" {
+			h.readingHatchData = true
+		}
+	default:
+		if !tryApplyCodePairForEntity(h, codePair) {
+			// no subclass marker; the hatch data has started
+			h.readingHatchData = true
+			h.hatchPairs = append(h.hatchPairs, codePair)
+		}
+	}
+}
+
+// orderedPairReader steps through code pairs that were collected to be parsed in order.
+type orderedPairReader struct {
+	pairs []CodePair
+	index int
+}
+
+func (r *orderedPairReader) peekCode() int {
+	return r.peekCodeAt(0)
+}
+
+func (r *orderedPairReader) peekCodeAt(offset int) int {
+	if r.index+offset < len(r.pairs) {
+		return r.pairs[r.index+offset].Code
+	}
+	return -1
+}
+
+func (r *orderedPairReader) next() CodePair {
+	pair := r.pairs[r.index]
+	r.index++
+	return pair
+}
+
+// The typed readers only consume the next pair when it has the expected code, otherwise a default is returned.
+
+func (r *orderedPairReader) double(code int) float64 {
+	if r.peekCode() == code {
+		if val, ok := r.next().Value.(DoubleCodePairValue); ok {
+			return val.Value
+		}
+	}
+	return 0.0
+}
+
+func (r *orderedPairReader) short(code int) int16 {
+	if r.peekCode() == code {
+		if val, ok := r.next().Value.(ShortCodePairValue); ok {
+			return val.Value
+		}
+	}
+	return 0
+}
+
+func (r *orderedPairReader) int(code int) int {
+	if r.peekCode() == code {
+		if val, ok := r.next().Value.(IntCodePairValue); ok {
+			return val.Value
+		}
+	}
+	return 0
+}
+
+func (r *orderedPairReader) string(code int) string {
+	if r.peekCode() == code {
+		if val, ok := r.next().Value.(StringCodePairValue); ok {
+			return val.Value
+		}
+	}
+	return ""
+}
+
+func (r *orderedPairReader) point(xCode int) Point {
+	x := r.double(xCode)
+	y := r.double(xCode + 10)
+	return Point{X: x, Y: y, Z: 0.0}
+}
+
+func (h *Hatch) afterRead() {
+	r := &orderedPairReader{pairs: h.hatchPairs}
+	for r.peekCode() >= 0 {
+		switch r.peekCode() {
+		case 10:
+			h.ElevationPoint.X = r.double(10)
+		case 20:
+			h.ElevationPoint.Y = r.double(20)
+		case 30:
+			h.ElevationPoint.Z = r.double(30)
+		case 210:
+			h.Normal.X = r.double(210)
+		case 220:
+			h.Normal.Y = r.double(220)
+		case 230:
+			h.Normal.Z = r.double(230)
+		case 2:
+			h.PatternName = r.string(2)
+		case 70:
+			h.IsSolidFill = boolFromShort(r.short(70))
+		case 71:
+			h.IsAssociative = boolFromShort(r.short(71))
+		case 91:
+			count := r.int(91)
+			for i := 0; i < count && r.peekCode() == 92; i++ {
+				h.BoundaryPaths = append(h.BoundaryPaths, readHatchBoundaryPath(r))
+			}
+		case 75:
+			h.Style = HatchStyle(r.short(75))
+		case 76:
+			h.PatternType = HatchPatternType(r.short(76))
+		case 52:
+			h.PatternAngle = r.double(52)
+		case 41:
+			h.PatternScale = r.double(41)
+		case 77:
+			h.IsPatternDouble = boolFromShort(r.short(77))
+		case 78:
+			count := int(r.short(78))
+			for i := 0; i < count && r.peekCode() == 53; i++ {
+				h.PatternDefinitionLines = append(h.PatternDefinitionLines, readHatchPatternDefinitionLine(r))
+			}
+		case 47:
+			h.PixelSize = r.double(47)
+		case 98:
+			count := r.int(98)
+			for i := 0; i < count && r.peekCode() == 10; i++ {
+				h.SeedPoints = append(h.SeedPoints, r.point(10))
+			}
+		case 450:
+			h.IsGradient = r.int(450) != 0
+		case 452:
+			h.IsGradientSingleColor = r.int(452) != 0
+		case 453:
+			count := r.int(453)
+			for i := 0; i < count && r.peekCode() == 463; i++ {
+				color := HatchGradientColor{Value: r.double(463)}
+				color.Color = Color(r.short(63))
+				color.Color24Bit = r.int(421)
+				h.GradientColors = append(h.GradientColors, color)
+			}
+		case 460:
+			h.GradientAngle = r.double(460)
+		case 461:
+			h.GradientShift = r.double(461)
+		case 462:
+			h.GradientTint = r.double(462)
+		case 470:
+			h.GradientName = r.string(470)
+		default:
+			// unsupported or reserved
+			r.next()
+		}
+	}
+
+	h.hatchPairs = []CodePair{}
+	h.readingHatchData = false
+}
+
+func readHatchBoundaryPath(r *orderedPairReader) HatchBoundaryPath {
+	path := HatchBoundaryPath{
+		Flags:                 r.int(92),
+		Vertices:              []HatchVertex{},
+		Edges:                 []HatchEdge{},
+		SourceBoundaryHandles: []Handle{},
+	}
+	if path.IsPolyline() {
+		hasBulge := boolFromShort(r.short(72))
+		path.IsClosed = boolFromShort(r.short(73))
+		count := r.int(93)
+		for i := 0; i < count && r.peekCode() == 10; i++ {
+			v := HatchVertex{X: r.double(10), Y: r.double(20)}
+			if hasBulge {
+				v.Bulge = r.double(42)
+			}
+			path.Vertices = append(path.Vertices, v)
+		}
+	} else {
+		count := r.int(93)
+		for i := 0; i < count && r.peekCode() == 72; i++ {
+			if edge := readHatchEdge(r, i == count-1); edge != nil {
+				path.Edges = append(path.Edges, edge)
+			}
+		}
+	}
+
+	count := r.int(97)
+	for i := 0; i < count && r.peekCode() == 330; i++ {
+		path.SourceBoundaryHandles = append(path.SourceBoundaryHandles, handleFromString(r.string(330)))
+	}
+	return path
+}
+
+// readHatchEdge reads the edge at the current position, returning nil when the edge type is unknown.
+func readHatchEdge(r *orderedPairReader, isLastEdge bool) HatchEdge {
+	switch r.short(72) {
+	case 1:
+		return &HatchLineEdge{
+			Start: r.point(10),
+			End:   r.point(11),
+		}
+	case 2:
+		return &HatchArcEdge{
+			Center:             r.point(10),
+			Radius:             r.double(40),
+			StartAngle:         r.double(50),
+			EndAngle:           r.double(51),
+			IsCounterClockwise: boolFromShort(r.short(73)),
+		}
+	case 3:
+		center := r.point(10)
+		majorAxis := r.point(11)
+		return &HatchEllipseEdge{
+			Center:             center,
+			MajorAxis:          Vector{X: majorAxis.X, Y: majorAxis.Y, Z: 0.0},
+			MinorAxisRatio:     r.double(40),
+			StartAngle:         r.double(50),
+			EndAngle:           r.double(51),
+			IsCounterClockwise: boolFromShort(r.short(73)),
+		}
+	case 4:
+		edge := &HatchSplineEdge{
+			Degree:        r.int(94),
+			IsRational:    boolFromShort(r.short(73)),
+			IsPeriodic:    boolFromShort(r.short(74)),
+			Knots:         []float64{},
+			ControlPoints: []ControlPoint{},
+			FitPoints:     []Point{},
+		}
+		knotCount := r.int(95)
+		controlPointCount := r.int(96)
+		for i := 0; i < knotCount && r.peekCode() == 40; i++ {
+			edge.Knots = append(edge.Knots, r.double(40))
+		}
+		for i := 0; i < controlPointCount && r.peekCode() == 10; i++ {
+			cp := ControlPoint{Point: r.point(10), Weight: 1.0}
+			if r.peekCode() == 42 {
+				cp.Weight = r.double(42)
+			}
+			edge.ControlPoints = append(edge.ControlPoints, cp)
+		}
+
+		// the fit point count shares code 97 with the path's source boundary count that may follow the last edge
+		if r.peekCode() == 97 && (!isLastEdge || r.peekCodeAt(1) == 97 || r.peekCodeAt(1) == 11 || r.peekCodeAt(1) == 12) {
+			fitPointCount := r.int(97)
+			for i := 0; i < fitPointCount && r.peekCode() == 11; i++ {
+				edge.FitPoints = append(edge.FitPoints, r.point(11))
+			}
+			if r.peekCode() == 12 {
+				p := r.point(12)
+				edge.StartTangent = Vector{X: p.X, Y: p.Y, Z: 0.0}
+			}
+			if r.peekCode() == 13 {
+				p := r.point(13)
+				edge.EndTangent = Vector{X: p.X, Y: p.Y, Z: 0.0}
+			}
+		}
+		return edge
+	default:
+		// unknown edge type; skip to the next edge
+		for r.peekCode() >= 0 && r.peekCode() != 72 && r.peekCode() != 97 && r.peekCode() != 92 {
+			r.next()
+		}
+		return nil
+	}
+}
+
+func readHatchPatternDefinitionLine(r *orderedPairReader) HatchPatternDefinitionLine {
+	line := HatchPatternDefinitionLine{
+		Angle:       r.double(53),
+		BasePoint:   Point{X: r.double(43), Y: r.double(44), Z: 0.0},
+		Offset:      Vector{X: r.double(45), Y: r.double(46), Z: 0.0},
+		DashLengths: []float64{},
+	}
+	count := int(r.short(79))
+	for i := 0; i < count && r.peekCode() == 49; i++ {
+		line.DashLengths = append(line.DashLengths, r.double(49))
+	}
+	return line
+}
+
+//
+// writing
+//
+
+func (h *Hatch) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "HATCH"))
+	pairs = append(pairs, codePairsForEntity(h, version)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbHatch"))
+	pairs = append(pairs, NewDoubleCodePair(10, h.ElevationPoint.X))
+	pairs = append(pairs, NewDoubleCodePair(20, h.ElevationPoint.Y))
+	pairs = append(pairs, NewDoubleCodePair(30, h.ElevationPoint.Z))
+	pairs = append(pairs, NewDoubleCodePair(210, h.Normal.X))
+	pairs = append(pairs, NewDoubleCodePair(220, h.Normal.Y))
+	pairs = append(pairs, NewDoubleCodePair(230, h.Normal.Z))
+	pairs = append(pairs, NewStringCodePair(2, h.PatternName))
+	pairs = append(pairs, NewShortCodePair(70, shortFromBool(h.IsSolidFill)))
+	pairs = append(pairs, NewShortCodePair(71, shortFromBool(h.IsAssociative)))
+	pairs = append(pairs, NewIntCodePair(91, len(h.BoundaryPaths)))
+	for _, path := range h.BoundaryPaths {
+		pairs = append(pairs, path.codePairs(version)...)
+	}
+	pairs = append(pairs, NewShortCodePair(75, int16(h.Style)))
+	pairs = append(pairs, NewShortCodePair(76, int16(h.PatternType)))
+	if !h.IsSolidFill {
+		pairs = append(pairs, NewDoubleCodePair(52, h.PatternAngle))
+		pairs = append(pairs, NewDoubleCodePair(41, h.PatternScale))
+		pairs = append(pairs, NewShortCodePair(77, shortFromBool(h.IsPatternDouble)))
+		pairs = append(pairs, NewShortCodePair(78, int16(len(h.PatternDefinitionLines))))
+		for _, line := range h.PatternDefinitionLines {
+			pairs = append(pairs, line.codePairs()...)
+		}
+	}
+	if h.PixelSize != 0.0 {
+		pairs = append(pairs, NewDoubleCodePair(47, h.PixelSize))
+	}
+	pairs = append(pairs, NewIntCodePair(98, len(h.SeedPoints)))
+	for _, seed := range h.SeedPoints {
+		pairs = append(pairs, NewDoubleCodePair(10, seed.X))
+		pairs = append(pairs, NewDoubleCodePair(20, seed.Y))
+	}
+	if version >= R2004 && h.IsGradient {
+		pairs = append(pairs, NewIntCodePair(450, 1))
+		pairs = append(pairs, NewIntCodePair(451, 0))
+		pairs = append(pairs, NewDoubleCodePair(460, h.GradientAngle))
+		pairs = append(pairs, NewDoubleCodePair(461, h.GradientShift))
+		pairs = append(pairs, NewIntCodePair(452, int(shortFromBool(h.IsGradientSingleColor))))
+		pairs = append(pairs, NewDoubleCodePair(462, h.GradientTint))
+		pairs = append(pairs, NewIntCodePair(453, len(h.GradientColors)))
+		for _, color := range h.GradientColors {
+			pairs = append(pairs, NewDoubleCodePair(463, color.Value))
+			pairs = append(pairs, NewShortCodePair(63, int16(color.Color)))
+			pairs = append(pairs, NewIntCodePair(421, color.Color24Bit))
+		}
+		pairs = append(pairs, NewStringCodePair(470, h.GradientName))
+	}
+	return
+}
+
+func (p *HatchBoundaryPath) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewIntCodePair(92, p.Flags))
+	if p.IsPolyline() {
+		hasBulge := false
+		for _, v := range p.Vertices {
+			if v.Bulge != 0.0 {
+				hasBulge = true
+				break
+			}
+		}
+		pairs = append(pairs, NewShortCodePair(72, shortFromBool(hasBulge)))
+		pairs = append(pairs, NewShortCodePair(73, shortFromBool(p.IsClosed)))
+		pairs = append(pairs, NewIntCodePair(93, len(p.Vertices)))
+		for _, v := range p.Vertices {
+			pairs = append(pairs, NewDoubleCodePair(10, v.X))
+			pairs = append(pairs, NewDoubleCodePair(20, v.Y))
+			if hasBulge {
+				pairs = append(pairs, NewDoubleCodePair(42, v.Bulge))
+			}
+		}
+	} else {
+		pairs = append(pairs, NewIntCodePair(93, len(p.Edges)))
+		for _, edge := range p.Edges {
+			pairs = append(pairs, NewShortCodePair(72, edge.edgeType()))
+			pairs = append(pairs, edge.codePairs(version)...)
+		}
+	}
+	pairs = append(pairs, NewIntCodePair(97, len(p.SourceBoundaryHandles)))
+	for _, h := range p.SourceBoundaryHandles {
+		pairs = append(pairs, NewStringCodePair(330, stringFromHandle(h)))
+	}
+	return
+}
+
+func (e *HatchLineEdge) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewDoubleCodePair(10, e.Start.X))
+	pairs = append(pairs, NewDoubleCodePair(20, e.Start.Y))
+	pairs = append(pairs, NewDoubleCodePair(11, e.End.X))
+	pairs = append(pairs, NewDoubleCodePair(21, e.End.Y))
+	return
+}
+
+func (e *HatchArcEdge) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewDoubleCodePair(10, e.Center.X))
+	pairs = append(pairs, NewDoubleCodePair(20, e.Center.Y))
+	pairs = append(pairs, NewDoubleCodePair(40, e.Radius))
+	pairs = append(pairs, NewDoubleCodePair(50, e.StartAngle))
+	pairs = append(pairs, NewDoubleCodePair(51, e.EndAngle))
+	pairs = append(pairs, NewShortCodePair(73, shortFromBool(e.IsCounterClockwise)))
+	return
+}
+
+func (e *HatchEllipseEdge) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewDoubleCodePair(10, e.Center.X))
+	pairs = append(pairs, NewDoubleCodePair(20, e.Center.Y))
+	pairs = append(pairs, NewDoubleCodePair(11, e.MajorAxis.X))
+	pairs = append(pairs, NewDoubleCodePair(21, e.MajorAxis.Y))
+	pairs = append(pairs, NewDoubleCodePair(40, e.MinorAxisRatio))
+	pairs = append(pairs, NewDoubleCodePair(50, e.StartAngle))
+	pairs = append(pairs, NewDoubleCodePair(51, e.EndAngle))
+	pairs = append(pairs, NewShortCodePair(73, shortFromBool(e.IsCounterClockwise)))
+	return
+}
+
+func (e *HatchSplineEdge) codePairs(version AcadVersion) (pairs []CodePair) {
+	isRational := e.IsRational
+	for _, cp := range e.ControlPoints {
+		if cp.Weight != 1.0 {
+			isRational = true
+		}
+	}
+	pairs = append(pairs, NewIntCodePair(94, e.Degree))
+	pairs = append(pairs, NewShortCodePair(73, shortFromBool(isRational)))
+	pairs = append(pairs, NewShortCodePair(74, shortFromBool(e.IsPeriodic)))
+	pairs = append(pairs, NewIntCodePair(95, len(e.Knots)))
+	pairs = append(pairs, NewIntCodePair(96, len(e.ControlPoints)))
+	for _, k := range e.Knots {
+		pairs = append(pairs, NewDoubleCodePair(40, k))
+	}
+	for _, cp := range e.ControlPoints {
+		pairs = append(pairs, NewDoubleCodePair(10, cp.Point.X))
+		pairs = append(pairs, NewDoubleCodePair(20, cp.Point.Y))
+		if isRational {
+			pairs = append(pairs, NewDoubleCodePair(42, cp.Weight))
+		}
+	}
+	if version >= R2010 {
+		pairs = append(pairs, NewIntCodePair(97, len(e.FitPoints)))
+		if len(e.FitPoints) > 0 {
+			for _, p := range e.FitPoints {
+				pairs = append(pairs, NewDoubleCodePair(11, p.X))
+				pairs = append(pairs, NewDoubleCodePair(21, p.Y))
+			}
+			pairs = append(pairs, NewDoubleCodePair(12, e.StartTangent.X))
+			pairs = append(pairs, NewDoubleCodePair(22, e.StartTangent.Y))
+			pairs = append(pairs, NewDoubleCodePair(13, e.EndTangent.X))
+			pairs = append(pairs, NewDoubleCodePair(23, e.EndTangent.Y))
+		}
+	}
+	return
+}
+
+func (l *HatchPatternDefinitionLine) codePairs() (pairs []CodePair) {
+	pairs = append(pairs, NewDoubleCodePair(53, l.Angle))
+	pairs = append(pairs, NewDoubleCodePair(43, l.BasePoint.X))
+	pairs = append(pairs, NewDoubleCodePair(44, l.BasePoint.Y))
+	pairs = append(pairs, NewDoubleCodePair(45, l.Offset.X))
+	pairs = append(pairs, NewDoubleCodePair(46, l.Offset.Y))
+	pairs = append(pairs, NewShortCodePair(79, int16(len(l.DashLengths))))
+	for _, d := range l.DashLengths {
+		pairs = append(pairs, NewDoubleCodePair(49, d))
+	}
+	return
+}
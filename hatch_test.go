@@ -0,0 +1,418 @@
+package dxf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadHatchWithPolylinePath(t *testing.T) {
+	h := parseEntity(t, "HATCH",
+		NewStringCodePair(100, "AcDbEntity"),
+		NewStringCodePair(8, "hatch-layer"),
+		NewStringCodePair(100, "AcDbHatch"),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(30, 3.0),
+		NewDoubleCodePair(210, 0.0),
+		NewDoubleCodePair(220, 0.0),
+		NewDoubleCodePair(230, 1.0),
+		NewStringCodePair(2, "SOLID"),
+		NewShortCodePair(70, 1),
+		NewShortCodePair(71, 1),
+		NewIntCodePair(91, 1),
+		NewIntCodePair(92, 7),
+		NewShortCodePair(72, 1),
+		NewShortCodePair(73, 1),
+		NewIntCodePair(93, 2),
+		NewDoubleCodePair(10, 1.0),
+		NewDoubleCodePair(20, 2.0),
+		NewDoubleCodePair(42, 1.0),
+		NewDoubleCodePair(10, 3.0),
+		NewDoubleCodePair(20, 4.0),
+		NewDoubleCodePair(42, 0.5),
+		NewIntCodePair(97, 1),
+		NewStringCodePair(330, "AB"),
+		NewShortCodePair(75, 1),
+		NewShortCodePair(76, 1),
+		NewIntCodePair(98, 1),
+		NewDoubleCodePair(10, 5.0),
+		NewDoubleCodePair(20, 6.0),
+	).(*Hatch)
+	assertEqString(t, "hatch-layer", h.Layer())
+	assertEqFloat64(t, 3.0, h.ElevationPoint.Z)
+	assert(t, h.IsSolidFill, "expected solid fill")
+	assert(t, h.IsAssociative, "expected associative hatch")
+	assert(t, h.Style == HatchStyleOutermost, "expected outermost style")
+	assertEqInt(t, 1, len(h.BoundaryPaths))
+	path := h.BoundaryPaths[0]
+	assert(t, path.IsExternal() && path.IsPolyline() && path.IsDerived(), "expected external derived polyline path")
+	assert(t, path.IsClosed, "expected closed path")
+	assertEqInt(t, 2, len(path.Vertices))
+	assertEqFloat64(t, 3.0, path.Vertices[1].X)
+	assertEqFloat64(t, 4.0, path.Vertices[1].Y)
+	assertEqFloat64(t, 0.5, path.Vertices[1].Bulge)
+	assertEqInt(t, 1, len(path.SourceBoundaryHandles))
+	assertEqUInt64(t, 0xAB, uint64(path.SourceBoundaryHandles[0]))
+	assertEqInt(t, 1, len(h.SeedPoints))
+	assertEqPoint(t, Point{X: 5.0, Y: 6.0, Z: 0.0}, h.SeedPoints[0])
+}
+
+func TestReadHatchWithEdgePath(t *testing.T) {
+	h := parseEntity(t, "HATCH",
+		NewStringCodePair(100, "AcDbHatch"),
+		NewIntCodePair(91, 1),
+		NewIntCodePair(92, 1),
+		NewIntCodePair(93, 4),
+		// line
+		NewShortCodePair(72, 1),
+		NewDoubleCodePair(10, 1.0),
+		NewDoubleCodePair(20, 2.0),
+		NewDoubleCodePair(11, 3.0),
+		NewDoubleCodePair(21, 4.0),
+		// arc
+		NewShortCodePair(72, 2),
+		NewDoubleCodePair(10, 5.0),
+		NewDoubleCodePair(20, 6.0),
+		NewDoubleCodePair(40, 7.0),
+		NewDoubleCodePair(50, 90.0),
+		NewDoubleCodePair(51, 180.0),
+		NewShortCodePair(73, 1),
+		// ellipse
+		NewShortCodePair(72, 3),
+		NewDoubleCodePair(10, 8.0),
+		NewDoubleCodePair(20, 9.0),
+		NewDoubleCodePair(11, 10.0),
+		NewDoubleCodePair(21, 0.0),
+		NewDoubleCodePair(40, 0.5),
+		NewDoubleCodePair(50, 0.0),
+		NewDoubleCodePair(51, 360.0),
+		NewShortCodePair(73, 0),
+		// spline with fit data
+		NewShortCodePair(72, 4),
+		NewIntCodePair(94, 1),
+		NewShortCodePair(73, 1),
+		NewShortCodePair(74, 0),
+		NewIntCodePair(95, 4),
+		NewIntCodePair(96, 2),
+		NewDoubleCodePair(40, 0.0),
+		NewDoubleCodePair(40, 0.0),
+		NewDoubleCodePair(40, 1.0),
+		NewDoubleCodePair(40, 1.0),
+		NewDoubleCodePair(10, 1.0),
+		NewDoubleCodePair(20, 1.0),
+		NewDoubleCodePair(42, 2.0),
+		NewDoubleCodePair(10, 2.0),
+		NewDoubleCodePair(20, 2.0),
+		NewDoubleCodePair(42, 3.0),
+		NewIntCodePair(97, 1),
+		NewDoubleCodePair(11, 1.5),
+		NewDoubleCodePair(21, 1.5),
+		NewDoubleCodePair(12, 1.0),
+		NewDoubleCodePair(22, 0.0),
+		NewDoubleCodePair(13, 0.0),
+		NewDoubleCodePair(23, 1.0),
+		// source boundaries
+		NewIntCodePair(97, 0),
+		NewShortCodePair(75, 0),
+	).(*Hatch)
+	assertEqInt(t, 1, len(h.BoundaryPaths))
+	edges := h.BoundaryPaths[0].Edges
+	assertEqInt(t, 4, len(edges))
+
+	line := edges[0].(*HatchLineEdge)
+	assertEqPoint(t, Point{X: 1.0, Y: 2.0, Z: 0.0}, line.Start)
+	assertEqPoint(t, Point{X: 3.0, Y: 4.0, Z: 0.0}, line.End)
+
+	arc := edges[1].(*HatchArcEdge)
+	assertEqPoint(t, Point{X: 5.0, Y: 6.0, Z: 0.0}, arc.Center)
+	assertEqFloat64(t, 7.0, arc.Radius)
+	assertEqFloat64(t, 180.0, arc.EndAngle)
+	assert(t, arc.IsCounterClockwise, "expected counter-clockwise arc")
+
+	ellipse := edges[2].(*HatchEllipseEdge)
+	assertEqVector(t, Vector{X: 10.0, Y: 0.0, Z: 0.0}, ellipse.MajorAxis)
+	assertEqFloat64(t, 0.5, ellipse.MinorAxisRatio)
+	assert(t, !ellipse.IsCounterClockwise, "expected clockwise ellipse")
+
+	spline := edges[3].(*HatchSplineEdge)
+	assertEqInt(t, 1, spline.Degree)
+	assert(t, spline.IsRational, "expected rational spline")
+	assertEqInt(t, 4, len(spline.Knots))
+	assertEqInt(t, 2, len(spline.ControlPoints))
+	assertEqFloat64(t, 3.0, spline.ControlPoints[1].Weight)
+	assertEqInt(t, 1, len(spline.FitPoints))
+	assertEqPoint(t, Point{X: 1.5, Y: 1.5, Z: 0.0}, spline.FitPoints[0])
+	assertEqVector(t, Vector{X: 1.0, Y: 0.0, Z: 0.0}, spline.StartTangent)
+	assertEqVector(t, Vector{X: 0.0, Y: 1.0, Z: 0.0}, spline.EndTangent)
+}
+
+func TestReadHatchSplineEdgeWithoutFitDataFollowedBySourceBoundaries(t *testing.T) {
+	h := parseEntity(t, "HATCH",
+		NewStringCodePair(100, "AcDbHatch"),
+		NewShortCodePair(71, 1),
+		NewIntCodePair(91, 1),
+		NewIntCodePair(92, 1),
+		NewIntCodePair(93, 1),
+		NewShortCodePair(72, 4),
+		NewIntCodePair(94, 1),
+		NewShortCodePair(73, 0),
+		NewShortCodePair(74, 0),
+		NewIntCodePair(95, 4),
+		NewIntCodePair(96, 2),
+		NewDoubleCodePair(40, 0.0),
+		NewDoubleCodePair(40, 0.0),
+		NewDoubleCodePair(40, 1.0),
+		NewDoubleCodePair(40, 1.0),
+		NewDoubleCodePair(10, 1.0),
+		NewDoubleCodePair(20, 1.0),
+		NewDoubleCodePair(10, 2.0),
+		NewDoubleCodePair(20, 2.0),
+		NewIntCodePair(97, 1),
+		NewStringCodePair(330, "42"),
+		NewShortCodePair(75, 0),
+	).(*Hatch)
+	path := h.BoundaryPaths[0]
+	spline := path.Edges[0].(*HatchSplineEdge)
+	assertEqInt(t, 0, len(spline.FitPoints))
+	assertEqFloat64(t, 1.0, spline.ControlPoints[0].Weight)
+	assertEqInt(t, 1, len(path.SourceBoundaryHandles))
+	assertEqUInt64(t, 0x42, uint64(path.SourceBoundaryHandles[0]))
+}
+
+func TestReadHatchSkipsUnknownEdgeType(t *testing.T) {
+	h := parseEntity(t, "HATCH",
+		NewStringCodePair(100, "AcDbHatch"),
+		NewIntCodePair(91, 1),
+		NewIntCodePair(92, 1),
+		NewIntCodePair(93, 2),
+		NewShortCodePair(72, 9),
+		NewDoubleCodePair(10, 5.0),
+		NewDoubleCodePair(20, 5.0),
+		NewShortCodePair(72, 1),
+		NewDoubleCodePair(10, 1.0),
+		NewDoubleCodePair(20, 2.0),
+		NewDoubleCodePair(11, 3.0),
+		NewDoubleCodePair(21, 4.0),
+		NewIntCodePair(97, 0),
+		NewShortCodePair(75, 0),
+	).(*Hatch)
+	edges := h.BoundaryPaths[0].Edges
+	assertEqInt(t, 1, len(edges))
+	line := edges[0].(*HatchLineEdge)
+	assertEqPoint(t, Point{1.0, 2.0, 0.0}, line.Start)
+	assertEqPoint(t, Point{3.0, 4.0, 0.0}, line.End)
+}
+
+func TestReadHatchPatternAndGradient(t *testing.T) {
+	h := parseEntity(t, "HATCH",
+		NewStringCodePair(100, "AcDbHatch"),
+		NewStringCodePair(2, "ANSI31"),
+		NewShortCodePair(70, 0),
+		NewIntCodePair(91, 0),
+		NewShortCodePair(75, 0),
+		NewShortCodePair(76, 1),
+		NewDoubleCodePair(52, 45.0),
+		NewDoubleCodePair(41, 2.0),
+		NewShortCodePair(77, 1),
+		NewShortCodePair(78, 1),
+		NewDoubleCodePair(53, 45.0),
+		NewDoubleCodePair(43, 1.0),
+		NewDoubleCodePair(44, 2.0),
+		NewDoubleCodePair(45, -0.5),
+		NewDoubleCodePair(46, 0.5),
+		NewShortCodePair(79, 2),
+		NewDoubleCodePair(49, 0.25),
+		NewDoubleCodePair(49, -0.125),
+		NewDoubleCodePair(47, 0.1),
+		NewIntCodePair(98, 0),
+		NewIntCodePair(450, 1),
+		NewIntCodePair(451, 0),
+		NewDoubleCodePair(460, 1.5),
+		NewDoubleCodePair(461, 0.25),
+		NewIntCodePair(452, 0),
+		NewDoubleCodePair(462, 1.0),
+		NewIntCodePair(453, 2),
+		NewDoubleCodePair(463, 0.0),
+		NewShortCodePair(63, 5),
+		NewIntCodePair(421, 255),
+		NewDoubleCodePair(463, 1.0),
+		NewShortCodePair(63, 2),
+		NewIntCodePair(421, 16776960),
+		NewStringCodePair(470, "LINEAR"),
+	).(*Hatch)
+	assertEqString(t, "ANSI31", h.PatternName)
+	assert(t, !h.IsSolidFill, "expected pattern fill")
+	assertEqFloat64(t, 45.0, h.PatternAngle)
+	assertEqFloat64(t, 2.0, h.PatternScale)
+	assert(t, h.IsPatternDouble, "expected double pattern")
+	assertEqInt(t, 1, len(h.PatternDefinitionLines))
+	line := h.PatternDefinitionLines[0]
+	assertEqFloat64(t, 45.0, line.Angle)
+	assertEqPoint(t, Point{X: 1.0, Y: 2.0, Z: 0.0}, line.BasePoint)
+	assertEqVector(t, Vector{X: -0.5, Y: 0.5, Z: 0.0}, line.Offset)
+	assertEqInt(t, 2, len(line.DashLengths))
+	assertEqFloat64(t, -0.125, line.DashLengths[1])
+	assertEqFloat64(t, 0.1, h.PixelSize)
+	assert(t, h.IsGradient, "expected gradient")
+	assertEqFloat64(t, 1.5, h.GradientAngle)
+	assertEqFloat64(t, 0.25, h.GradientShift)
+	assertEqInt(t, 2, len(h.GradientColors))
+	assertEqFloat64(t, 1.0, h.GradientColors[1].Value)
+	assert(t, h.GradientColors[1].Color == Color(2), "expected second gradient color to be 2")
+	assertEqInt(t, 16776960, h.GradientColors[1].Color24Bit)
+	assertEqString(t, "LINEAR", h.GradientName)
+}
+
+func TestWriteHatchWithPolylinePath(t *testing.T) {
+	h := NewHatch()
+	path := NewHatchPolylineBoundaryPath([]HatchVertex{
+		{X: 0.0, Y: 0.0, Bulge: 0.0},
+		{X: 1.0, Y: 0.0, Bulge: 1.0},
+	}, true)
+	path.SourceBoundaryHandles = append(path.SourceBoundaryHandles, Handle(0xAB))
+	h.BoundaryPaths = append(h.BoundaryPaths, *path)
+	h.SeedPoints = append(h.SeedPoints, Point{X: 0.5, Y: 0.5, Z: 0.0})
+	actual := allCodePairs(h, R2000)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbHatch"),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(30, 0.0),
+		NewDoubleCodePair(210, 0.0),
+		NewDoubleCodePair(220, 0.0),
+		NewDoubleCodePair(230, 1.0),
+		NewStringCodePair(2, "SOLID"),
+		NewShortCodePair(70, 1),
+		NewShortCodePair(71, 0),
+		NewIntCodePair(91, 1),
+		NewIntCodePair(92, 3),
+		NewShortCodePair(72, 1),
+		NewShortCodePair(73, 1),
+		NewIntCodePair(93, 2),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(42, 0.0),
+		NewDoubleCodePair(10, 1.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(42, 1.0),
+		NewIntCodePair(97, 1),
+		NewStringCodePair(330, "AB"),
+		NewShortCodePair(75, 0),
+		NewShortCodePair(76, 1),
+		NewIntCodePair(98, 1),
+		NewDoubleCodePair(10, 0.5),
+		NewDoubleCodePair(20, 0.5),
+	}, actual)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewDoubleCodePair(52, 0.0),
+	}, actual)
+}
+
+func TestWriteHatchSplineFitDataOnlyForR2010AndLater(t *testing.T) {
+	h := NewHatch()
+	h.BoundaryPaths = append(h.BoundaryPaths, *NewHatchEdgeBoundaryPath([]HatchEdge{
+		&HatchSplineEdge{
+			Degree:        1,
+			Knots:         []float64{0.0, 0.0, 1.0, 1.0},
+			ControlPoints: []ControlPoint{{Point: Point{X: 0.0, Y: 0.0, Z: 0.0}, Weight: 1.0}, {Point: Point{X: 1.0, Y: 1.0, Z: 0.0}, Weight: 1.0}},
+			FitPoints:     []Point{{X: 0.5, Y: 0.5, Z: 0.0}},
+		},
+	}))
+	assertNotContainsCodePairs(t, []CodePair{
+		NewDoubleCodePair(11, 0.5),
+	}, allCodePairs(h, R2007))
+	assertContainsCodePairs(t, []CodePair{
+		NewIntCodePair(97, 1),
+		NewDoubleCodePair(11, 0.5),
+		NewDoubleCodePair(21, 0.5),
+	}, allCodePairs(h, R2010))
+}
+
+func TestWriteHatchVersions(t *testing.T) {
+	h := NewHatch()
+	h.IsGradient = true
+	drawing := *NewDrawing()
+	drawing.Entities = append(drawing.Entities, h)
+
+	drawing.Header.Version = R2004
+	assertContainsCodePairs(t, []CodePair{
+		NewIntCodePair(450, 1),
+	}, drawingCodePairs(t, drawing))
+
+	drawing.Header.Version = R2000
+	actual := drawingCodePairs(t, drawing)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "HATCH"),
+	}, actual)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewIntCodePair(450, 1),
+	}, actual)
+
+	drawing.Header.Version = R13
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "HATCH"),
+	}, drawingCodePairs(t, drawing))
+
+	// R12 has no hatches, so they're dropped
+	drawing.Header.Version = R12
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "HATCH"),
+	}, drawingCodePairs(t, drawing))
+	var buf bytes.Buffer
+	err := drawing.SaveToWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := ReadFromReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 0, len(saved.Entities))
+}
+
+func TestRoundTripHatch(t *testing.T) {
+	h := NewHatch()
+	h.PatternName = "CUSTOM"
+	h.IsSolidFill = false
+	h.IsAssociative = true
+	h.PatternType = HatchPatternTypeCustom
+	h.PatternAngle = 30.0
+	h.PatternScale = 2.0
+	h.PatternDefinitionLines = append(h.PatternDefinitionLines, HatchPatternDefinitionLine{
+		Angle:       30.0,
+		BasePoint:   Point{X: 0.0, Y: 0.0, Z: 0.0},
+		Offset:      Vector{X: 0.0, Y: 0.125, Z: 0.0},
+		DashLengths: []float64{0.5, -0.25},
+	})
+	edgePath := NewHatchEdgeBoundaryPath([]HatchEdge{
+		&HatchLineEdge{Start: Point{X: 0.0, Y: 0.0, Z: 0.0}, End: Point{X: 2.0, Y: 0.0, Z: 0.0}},
+		&HatchArcEdge{Center: Point{X: 1.0, Y: 0.0, Z: 0.0}, Radius: 1.0, StartAngle: 0.0, EndAngle: 180.0, IsCounterClockwise: true},
+	})
+	edgePath.SourceBoundaryHandles = append(edgePath.SourceBoundaryHandles, Handle(0x10), Handle(0x11))
+	h.BoundaryPaths = append(h.BoundaryPaths, *edgePath)
+	h.BoundaryPaths = append(h.BoundaryPaths, *NewHatchPolylineBoundaryPath([]HatchVertex{
+		{X: 0.5, Y: 0.25}, {X: 1.5, Y: 0.25}, {X: 1.0, Y: 0.75},
+	}, true))
+	h.IsGradient = true
+	h.GradientName = "SPHERICAL"
+	h.GradientColors = append(h.GradientColors, HatchGradientColor{Value: 0.0, Color: 1}, HatchGradientColor{Value: 1.0, Color: 3})
+
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2018
+	drawing.Entities = append(drawing.Entities, h)
+	drawing = roundTripDrawing(t, &drawing)
+	assertEqInt(t, 1, len(drawing.Entities))
+	actual := drawing.Entities[0].(*Hatch)
+	assertEqString(t, "CUSTOM", actual.PatternName)
+	assert(t, actual.PatternType == HatchPatternTypeCustom, "expected custom pattern type")
+	assertEqFloat64(t, 30.0, actual.PatternAngle)
+	assertEqInt(t, 1, len(actual.PatternDefinitionLines))
+	assertEqInt(t, 2, len(actual.PatternDefinitionLines[0].DashLengths))
+	assertEqInt(t, 2, len(actual.BoundaryPaths))
+	assertEqInt(t, 2, len(actual.BoundaryPaths[0].Edges))
+	assertEqInt(t, 2, len(actual.BoundaryPaths[0].SourceBoundaryHandles))
+	assertEqUInt64(t, 0x11, uint64(actual.BoundaryPaths[0].SourceBoundaryHandles[1]))
+	assertEqInt(t, 3, len(actual.BoundaryPaths[1].Vertices))
+	assertEqString(t, "SPHERICAL", actual.GradientName)
+	assertEqInt(t, 2, len(actual.GradientColors))
+}
@@ -0,0 +1,101 @@
+package dxf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InsertBlock creates an `Insert` of the named block, adds it to the drawing and returns it.  The block is placed at
+// `location` with a uniform `scale` and a `rotation` in degrees.  An `Attribute` is created for every non-constant
+// `AttributeDefinition` in the block, positioned as the definition appears in the inserted block.  Values are looked
+// up by tag in `values`, ignoring case, and tags without a value use the definition's default.  Preset attributes
+// always use their default value and invisible definitions produce invisible attributes.  If no block with the
+// specified name exists or an attribute can't be placed, an error is returned and the drawing is unchanged.
+func (d *Drawing) InsertBlock(name string, location Point, scale, rotation float64, values map[string]string) (*Insert, error) {
+	block := d.findBlock(name)
+	if block == nil {
+		return nil, fmt.Errorf("block '%s' not found", name)
+	}
+
+	insert := NewInsert()
+	insert.Name = block.Name
+	insert.Location = location
+	insert.XScaleFactor = scale
+	insert.YScaleFactor = scale
+	insert.ZScaleFactor = scale
+	insert.Rotation = rotation
+
+	m := insertMatrix(insert)
+	m = m.Multiply(*NewTranslationMatrix4(Vector{X: -block.BasePoint.X, Y: -block.BasePoint.Y, Z: -block.BasePoint.Z}))
+	for _, e := range block.Entities {
+		definition, ok := e.(*AttributeDefinition)
+		if !ok || definition.IsConstant() {
+			continue
+		}
+
+		attribute := attributeFromDefinition(definition)
+		if !definition.IsAttributePresent() {
+			if value, found := lookupAttributeValue(values, definition.TextTag); found {
+				attribute.Value = value
+			}
+		}
+
+		if _, err := Transform(attribute, m); err != nil {
+			return nil, fmt.Errorf("unable to place attribute '%s': %v", definition.TextTag, err)
+		}
+		insert.Attributes = append(insert.Attributes, *attribute)
+	}
+
+	insert.HasAttributes = len(insert.Attributes) > 0
+	d.Entities = append(d.Entities, insert)
+	return insert, nil
+}
+
+func lookupAttributeValue(values map[string]string, tag string) (value string, found bool) {
+	if value, found = values[tag]; found {
+		return
+	}
+	for key, v := range values {
+		if strings.EqualFold(key, tag) {
+			return v, true
+		}
+	}
+
+	return
+}
+
+// attributeFromDefinition creates an `Attribute` with the properties and default value of an attribute definition.
+func attributeFromDefinition(a *AttributeDefinition) *Attribute {
+	attribute := NewAttribute()
+	copyEntityProperties(attribute, a)
+	attribute.SetHandle(Handle(0))
+	attribute.setOwnerPointerHandle(Handle(0))
+	attribute.Thickness = a.Thickness
+	attribute.Location = a.Location
+	attribute.TextHeight = a.TextHeight
+	attribute.Value = a.Value
+	attribute.Version = a.Version
+	attribute.AttributeTag = a.TextTag
+	attribute.Flags = a.Flags
+	attribute.FieldLength = a.FieldLength
+	attribute.Rotation = a.Rotation
+	attribute.RelativeXScaleFactor = a.RelativeXScaleFactor
+	attribute.ObliqueAngle = a.ObliqueAngle
+	attribute.TextStyleName = a.TextStyleName
+	attribute.TextGenerationFlags = a.TextGenerationFlags
+	attribute.HorizontalTextJustification = a.HorizontalTextJustification
+	attribute.VerticalTextJustification = a.VerticalTextJustification
+	attribute.SecondAlignmentPoint = a.SecondAlignmentPoint
+	attribute.Normal = a.Normal
+	attribute.IsLockedInBlock = a.IsLockedInBlock
+	attribute.KeepDuplicateRecords = a.KeepDuplicateRecords
+	attribute.MTextFlag = a.MTextFlag
+	attribute.IsReallyLocked = a.IsReallyLocked
+	attribute.AlignmentPoint = a.AlignmentPoint
+	attribute.AnnotationScale = a.AnnotationScale
+	attribute.XRecordTag = a.XRecordTag
+	attribute.MText = a.MText
+	attribute.MText.SetHandle(Handle(0))
+	attribute.MText.setOwnerPointerHandle(Handle(0))
+	return attribute
+}
@@ -0,0 +1,77 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func insertBlockTestDrawing() *Drawing {
+	d := NewDrawing()
+	block := *NewBlock()
+	block.Name = "TITLE"
+	block.BasePoint = Point{1.0, 0.0, 0.0}
+	block.Entities = append(block.Entities, NewLine())
+
+	addDefinition := func(tag, value string, flags int) {
+		def := NewAttributeDefinition()
+		def.TextTag = tag
+		def.Value = value
+		def.Flags = flags
+		def.TextHeight = 2.0
+		def.Location = Point{2.0, 0.0, 0.0}
+		block.Entities = append(block.Entities, def)
+	}
+	addDefinition("NAME", "default name", 0)
+	addDefinition("DATE", "default date", 0)
+	addDefinition("HIDDEN", "secret", 1)
+	addDefinition("FIXED", "constant", 2)
+	addDefinition("PRESET", "preset", 8)
+	d.Blocks = append(d.Blocks, block)
+	return d
+}
+
+func TestInsertBlockAttributes(t *testing.T) {
+	d := insertBlockTestDrawing()
+	insert, err := d.InsertBlock("title", Point{10.0, 10.0, 0.0}, 2.0, 90.0, map[string]string{
+		"name":   "drawing 1",
+		"PRESET": "ignored",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(d.Entities))
+	assertEqString(t, "TITLE", insert.Name)
+	assert(t, insert.HasAttributes, "expected HasAttributes to be set")
+	assertEqInt(t, 4, len(insert.Attributes))
+
+	name := insert.Attributes[0]
+	assertEqString(t, "NAME", name.AttributeTag)
+	assertEqString(t, "drawing 1", name.Value)
+	assertNearPoint(t, Point{10.0, 12.0, 0.0}, name.Location)
+	assertNearFloat64(t, 4.0, name.TextHeight)
+	assertNearFloat64(t, 90.0, name.Rotation)
+
+	assertEqString(t, "default date", insert.Attributes[1].Value)
+	assert(t, insert.Attributes[2].IsInvisible(), "expected an invisible attribute")
+	assertEqString(t, "PRESET", insert.Attributes[3].AttributeTag)
+	assertEqString(t, "preset", insert.Attributes[3].Value)
+}
+
+func TestInsertBlockMissing(t *testing.T) {
+	d := insertBlockTestDrawing()
+	insert, err := d.InsertBlock("missing", *NewOrigin(), 1.0, 0.0, nil)
+	assert(t, err != nil, "expected an error for a missing block")
+	assert(t, insert == nil, "expected no insert")
+	assertEqInt(t, 0, len(d.Entities))
+}
+
+func TestInsertBlockRoundTrip(t *testing.T) {
+	d := insertBlockTestDrawing()
+	_, err := d.InsertBlock("TITLE", *NewOrigin(), 1.0, 0.0, map[string]string{"NAME": "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := roundTripDrawing(t, d)
+	insert := result.Entities[0].(*Insert)
+	assertEqInt(t, 4, len(insert.Attributes))
+	assertEqString(t, "value", insert.Attributes[0].Value)
+}
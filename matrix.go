@@ -0,0 +1,156 @@
+package dxf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Matrix4 represents an affine transformation in 3D space.  The matrix is stored in row-major order and points are
+// transformed as column vectors, so the translation is held in the last column.
+type Matrix4 [4][4]float64
+
+// NewIdentityMatrix4 creates a matrix that leaves every point unchanged.
+func NewIdentityMatrix4() *Matrix4 {
+	return &Matrix4{
+		{1.0, 0.0, 0.0, 0.0},
+		{0.0, 1.0, 0.0, 0.0},
+		{0.0, 0.0, 1.0, 0.0},
+		{0.0, 0.0, 0.0, 1.0},
+	}
+}
+
+// NewTranslationMatrix4 creates a matrix that moves every point by `offset`.
+func NewTranslationMatrix4(offset Vector) *Matrix4 {
+	return &Matrix4{
+		{1.0, 0.0, 0.0, offset.X},
+		{0.0, 1.0, 0.0, offset.Y},
+		{0.0, 0.0, 1.0, offset.Z},
+		{0.0, 0.0, 0.0, 1.0},
+	}
+}
+
+// NewScaleMatrix4 creates a matrix that scales about the origin by the given factor along each axis.  A negative
+// factor mirrors across the corresponding plane.
+func NewScaleMatrix4(x, y, z float64) *Matrix4 {
+	return &Matrix4{
+		{x, 0.0, 0.0, 0.0},
+		{0.0, y, 0.0, 0.0},
+		{0.0, 0.0, z, 0.0},
+		{0.0, 0.0, 0.0, 1.0},
+	}
+}
+
+// NewRotationMatrix4 creates a matrix that rotates counter-clockwise about `axis` through the origin by the specified
+// angle in degrees.
+func NewRotationMatrix4(axis Vector, angle float64) *Matrix4 {
+	a := axis.Normalize()
+	radians := angle * math.Pi / 180.0
+	c := math.Cos(radians)
+	s := math.Sin(radians)
+	t := 1.0 - c
+	return &Matrix4{
+		{t*a.X*a.X + c, t*a.X*a.Y - s*a.Z, t*a.X*a.Z + s*a.Y, 0.0},
+		{t*a.X*a.Y + s*a.Z, t*a.Y*a.Y + c, t*a.Y*a.Z - s*a.X, 0.0},
+		{t*a.X*a.Z - s*a.Y, t*a.Y*a.Z + s*a.X, t*a.Z*a.Z + c, 0.0},
+		{0.0, 0.0, 0.0, 1.0},
+	}
+}
+
+// NewMirrorMatrix4 creates a matrix that reflects across the plane through `origin` with the specified normal.
+func NewMirrorMatrix4(origin Point, normal Vector) *Matrix4 {
+	n := normal.Normalize()
+	m := Matrix4{
+		{1.0 - 2.0*n.X*n.X, -2.0 * n.X * n.Y, -2.0 * n.X * n.Z, 0.0},
+		{-2.0 * n.Y * n.X, 1.0 - 2.0*n.Y*n.Y, -2.0 * n.Y * n.Z, 0.0},
+		{-2.0 * n.Z * n.X, -2.0 * n.Z * n.Y, 1.0 - 2.0*n.Z*n.Z, 0.0},
+		{0.0, 0.0, 0.0, 1.0},
+	}
+	o := Vector{X: origin.X, Y: origin.Y, Z: origin.Z}
+	result := NewTranslationMatrix4(o).Multiply(m.Multiply(*NewTranslationMatrix4(o.Scale(-1.0))))
+	return &result
+}
+
+// newAxesMatrix4 creates a matrix whose columns are the specified axes and origin, mapping coordinates expressed in
+// that system to world coordinates.
+func newAxesMatrix4(xAxis, yAxis, zAxis Vector, origin Point) *Matrix4 {
+	return &Matrix4{
+		{xAxis.X, yAxis.X, zAxis.X, origin.X},
+		{xAxis.Y, yAxis.Y, zAxis.Y, origin.Y},
+		{xAxis.Z, yAxis.Z, zAxis.Z, origin.Z},
+		{0.0, 0.0, 0.0, 1.0},
+	}
+}
+
+// Multiply returns the matrix product `m * other`; the result applies `other` first, then `m`.
+func (m Matrix4) Multiply(other Matrix4) (result Matrix4) {
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			for i := 0; i < 4; i++ {
+				result[row][col] += m[row][i] * other[i][col]
+			}
+		}
+	}
+	return
+}
+
+// TransformPoint applies the full transformation, including translation, to a point.
+func (m Matrix4) TransformPoint(p Point) Point {
+	return Point{
+		X: m[0][0]*p.X + m[0][1]*p.Y + m[0][2]*p.Z + m[0][3],
+		Y: m[1][0]*p.X + m[1][1]*p.Y + m[1][2]*p.Z + m[1][3],
+		Z: m[2][0]*p.X + m[2][1]*p.Y + m[2][2]*p.Z + m[2][3],
+	}
+}
+
+// TransformVector applies the transformation to a direction; translation is ignored.
+func (m Matrix4) TransformVector(v Vector) Vector {
+	return Vector{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// Determinant returns the determinant of the linear part of the transformation.  A negative value indicates the
+// transformation mirrors geometry.
+func (m Matrix4) Determinant() float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+// Inverse returns the inverse transformation, or an error if the matrix is singular.
+func (m Matrix4) Inverse() (inverse Matrix4, err error) {
+	det := m.Determinant()
+	if math.Abs(det) < 1.0e-12 {
+		err = errors.New("matrix is not invertible")
+		return
+	}
+
+	// invert the linear part via the adjugate, then the translation
+	inverse[0][0] = (m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det
+	inverse[0][1] = (m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det
+	inverse[0][2] = (m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det
+	inverse[1][0] = (m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det
+	inverse[1][1] = (m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det
+	inverse[1][2] = (m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det
+	inverse[2][0] = (m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det
+	inverse[2][1] = (m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det
+	inverse[2][2] = (m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det
+	for row := 0; row < 3; row++ {
+		inverse[row][3] = -(inverse[row][0]*m[0][3] + inverse[row][1]*m[1][3] + inverse[row][2]*m[2][3])
+	}
+	inverse[3][3] = 1.0
+	return
+}
+
+func (m *Matrix4) String() string {
+	return fmt.Sprintf("[%v %v %v %v]", m[0], m[1], m[2], m[3])
+}
+
+// newOcsMatrix4 creates a matrix that maps object coordinates for the specified normal to world coordinates.
+func newOcsMatrix4(normal Vector) *Matrix4 {
+	xAxis, yAxis := normal.ArbitraryAxes()
+	return newAxesMatrix4(xAxis, yAxis, normal.ocsZAxis(), *NewOrigin())
+}
@@ -0,0 +1,37 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func TestMatrixRotation(t *testing.T) {
+	m := *NewRotationMatrix4(*NewZAxis(), 90.0)
+	assertNearPoint(t, Point{0.0, 1.0, 0.0}, m.TransformPoint(Point{1.0, 0.0, 0.0}))
+}
+
+func TestMatrixMultiplyAppliesRightOperandFirst(t *testing.T) {
+	translate := *NewTranslationMatrix4(Vector{1.0, 0.0, 0.0})
+	scale := *NewScaleMatrix4(2.0, 2.0, 2.0)
+	m := translate.Multiply(scale)
+	assertNearPoint(t, Point{3.0, 2.0, 2.0}, m.TransformPoint(Point{1.0, 1.0, 1.0}))
+}
+
+func TestMatrixInverse(t *testing.T) {
+	m := NewTranslationMatrix4(Vector{1.0, 2.0, 3.0}).Multiply(*NewRotationMatrix4(Vector{1.0, 1.0, 0.0}, 30.0))
+	m = m.Multiply(*NewScaleMatrix4(2.0, 3.0, 4.0))
+	inverse, err := m.Inverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Point{4.0, 5.0, 6.0}
+	assertNearPoint(t, p, inverse.TransformPoint(m.TransformPoint(p)))
+
+	_, err = NewScaleMatrix4(1.0, 0.0, 1.0).Inverse()
+	assert(t, err != nil, "expected a singular matrix error")
+}
+
+func TestMirrorMatrix(t *testing.T) {
+	m := *NewMirrorMatrix4(Point{1.0, 0.0, 0.0}, *NewXAxis())
+	assertNearPoint(t, Point{-1.0, 2.0, 0.0}, m.TransformPoint(Point{3.0, 2.0, 0.0}))
+	assert(t, m.Determinant() < 0.0, "expected a mirroring determinant")
+}
@@ -0,0 +1,114 @@
+package dxf
+
+// MeshEdge joins two vertices of a Mesh by their indices.
+type MeshEdge struct {
+	StartVertexIndex int
+	EndVertexIndex   int
+}
+
+func (m *Mesh) tryApplyCodePair(codePair CodePair) {
+	if m.readingMeshData {
+		// the lists all use code 90 so they're parsed in order after reading
+		m.meshPairs = append(m.meshPairs, codePair)
+		return
+	}
+
+	switch codePair.Code {
+	case 100:
+		if codePair.Value.(StringCodePairValue).Value == "AcDbSubDMesh" {
+			m.readingMeshData = true
+		}
+	default:
+		tryApplyCodePairForEntity(m, codePair)
+	}
+}
+
+func (m *Mesh) afterRead() {
+	r := &orderedPairReader{pairs: m.meshPairs}
+	for r.peekCode() >= 0 {
+		switch r.peekCode() {
+		case 71:
+			m.Version = r.short(71)
+		case 72:
+			m.IsBlendCreased = boolFromShort(r.short(72))
+		case 91:
+			m.SubdivisionLevel = r.int(91)
+		case 92:
+			count := r.int(92)
+			for i := 0; i < count && r.peekCode() == 10; i++ {
+				x := r.double(10)
+				y := r.double(20)
+				z := r.double(30)
+				m.Vertices = append(m.Vertices, Point{X: x, Y: y, Z: z})
+			}
+		case 93:
+			// each face is its vertex count followed by that many indices
+			size := r.int(93)
+			for read := 0; read < size && r.peekCode() == 90; {
+				count := r.int(90)
+				read++
+				face := []int{}
+				for i := 0; i < count && read < size && r.peekCode() == 90; i++ {
+					face = append(face, r.int(90))
+					read++
+				}
+				m.Faces = append(m.Faces, face)
+			}
+		case 94:
+			count := r.int(94)
+			for i := 0; i < count && r.peekCode() == 90; i++ {
+				edge := MeshEdge{StartVertexIndex: r.int(90)}
+				edge.EndVertexIndex = r.int(90)
+				m.Edges = append(m.Edges, edge)
+			}
+		case 95:
+			count := r.int(95)
+			for i := 0; i < count && r.peekCode() == 140; i++ {
+				m.Creases = append(m.Creases, r.double(140))
+			}
+		default:
+			// sub-entity property overrides aren't supported
+			r.next()
+		}
+	}
+
+	m.meshPairs = []CodePair{}
+	m.readingMeshData = false
+}
+
+func (m *Mesh) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "MESH"))
+	pairs = append(pairs, codePairsForEntity(m, version)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbSubDMesh"))
+	pairs = append(pairs, NewShortCodePair(71, m.Version))
+	pairs = append(pairs, NewShortCodePair(72, shortFromBool(m.IsBlendCreased)))
+	pairs = append(pairs, NewIntCodePair(91, m.SubdivisionLevel))
+	pairs = append(pairs, NewIntCodePair(92, len(m.Vertices)))
+	for _, v := range m.Vertices {
+		pairs = append(pairs, NewDoubleCodePair(10, v.X))
+		pairs = append(pairs, NewDoubleCodePair(20, v.Y))
+		pairs = append(pairs, NewDoubleCodePair(30, v.Z))
+	}
+	faceListSize := 0
+	for _, face := range m.Faces {
+		faceListSize += len(face) + 1
+	}
+	pairs = append(pairs, NewIntCodePair(93, faceListSize))
+	for _, face := range m.Faces {
+		pairs = append(pairs, NewIntCodePair(90, len(face)))
+		for _, index := range face {
+			pairs = append(pairs, NewIntCodePair(90, index))
+		}
+	}
+	pairs = append(pairs, NewIntCodePair(94, len(m.Edges)))
+	for _, edge := range m.Edges {
+		pairs = append(pairs, NewIntCodePair(90, edge.StartVertexIndex))
+		pairs = append(pairs, NewIntCodePair(90, edge.EndVertexIndex))
+	}
+	pairs = append(pairs, NewIntCodePair(95, len(m.Creases)))
+	for _, crease := range m.Creases {
+		pairs = append(pairs, NewDoubleCodePair(140, crease))
+	}
+	pairs = append(pairs, NewIntCodePair(90, 0)) // no property overrides
+	return
+}
@@ -0,0 +1,127 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func TestReadMesh(t *testing.T) {
+	m := parseEntity(t, "MESH",
+		NewStringCodePair(100, "AcDbEntity"),
+		NewStringCodePair(8, "mesh-layer"),
+		NewStringCodePair(100, "AcDbSubDMesh"),
+		NewShortCodePair(71, 2),
+		NewShortCodePair(72, 1),
+		NewIntCodePair(91, 3),
+		NewIntCodePair(92, 3),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(30, 0.0),
+		NewDoubleCodePair(10, 1.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(30, 0.0),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 1.0),
+		NewDoubleCodePair(30, 2.0),
+		NewIntCodePair(93, 4),
+		NewIntCodePair(90, 3),
+		NewIntCodePair(90, 0),
+		NewIntCodePair(90, 1),
+		NewIntCodePair(90, 2),
+		NewIntCodePair(94, 2),
+		NewIntCodePair(90, 0),
+		NewIntCodePair(90, 1),
+		NewIntCodePair(90, 1),
+		NewIntCodePair(90, 2),
+		NewIntCodePair(95, 2),
+		NewDoubleCodePair(140, 0.0),
+		NewDoubleCodePair(140, -1.0),
+		NewIntCodePair(90, 0),
+	).(*Mesh)
+	assertEqString(t, "mesh-layer", m.Layer())
+	assertEqInt(t, 2, int(m.Version))
+	assert(t, m.IsBlendCreased, "expected blend creased")
+	assertEqInt(t, 3, m.SubdivisionLevel)
+	assertEqInt(t, 3, len(m.Vertices))
+	assertEqPoint(t, Point{X: 0.0, Y: 1.0, Z: 2.0}, m.Vertices[2])
+	assertEqInt(t, 1, len(m.Faces))
+	assertEqInt(t, 3, len(m.Faces[0]))
+	assertEqInt(t, 2, m.Faces[0][2])
+	assertEqInt(t, 2, len(m.Edges))
+	assertEqInt(t, 1, m.Edges[1].StartVertexIndex)
+	assertEqInt(t, 2, m.Edges[1].EndVertexIndex)
+	assertEqInt(t, 2, len(m.Creases))
+	assertEqFloat64(t, -1.0, m.Creases[1])
+}
+
+func TestWriteMesh(t *testing.T) {
+	m := NewMesh()
+	m.Vertices = []Point{{X: 0.0, Y: 0.0, Z: 0.0}, {X: 1.0, Y: 0.0, Z: 0.0}, {X: 1.0, Y: 1.0, Z: 0.0}, {X: 0.0, Y: 1.0, Z: 0.0}}
+	m.Faces = [][]int{{0, 1, 2}, {0, 2, 3}}
+	m.Edges = []MeshEdge{{StartVertexIndex: 0, EndVertexIndex: 2}}
+	m.Creases = []float64{0.5}
+	actual := allCodePairs(m, R2010)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbSubDMesh"),
+		NewShortCodePair(71, 2),
+		NewShortCodePair(72, 0),
+		NewIntCodePair(91, 0),
+		NewIntCodePair(92, 4),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewIntCodePair(93, 8),
+		NewIntCodePair(90, 3),
+		NewIntCodePair(90, 0),
+		NewIntCodePair(90, 1),
+		NewIntCodePair(90, 2),
+		NewIntCodePair(90, 3),
+		NewIntCodePair(90, 0),
+		NewIntCodePair(90, 2),
+		NewIntCodePair(90, 3),
+		NewIntCodePair(94, 1),
+		NewIntCodePair(90, 0),
+		NewIntCodePair(90, 2),
+		NewIntCodePair(95, 1),
+		NewDoubleCodePair(140, 0.5),
+	}, actual)
+}
+
+func TestRoundTripMesh(t *testing.T) {
+	m := NewMesh()
+	m.SubdivisionLevel = 2
+	m.Vertices = []Point{{X: 0.0, Y: 0.0, Z: 0.0}, {X: 1.0, Y: 0.0, Z: 0.0}, {X: 1.0, Y: 1.0, Z: 0.0}, {X: 0.0, Y: 1.0, Z: 0.0}}
+	m.Faces = [][]int{{0, 1, 2, 3}}
+	m.Edges = []MeshEdge{{StartVertexIndex: 0, EndVertexIndex: 1}, {StartVertexIndex: 1, EndVertexIndex: 2}}
+	m.Creases = []float64{1.0, 2.0}
+
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2010
+	drawing.Entities = append(drawing.Entities, m)
+	drawing = roundTripDrawing(t, &drawing)
+	assertEqInt(t, 1, len(drawing.Entities))
+	rt := drawing.Entities[0].(*Mesh)
+	assertEqInt(t, 2, rt.SubdivisionLevel)
+	assertEqInt(t, 4, len(rt.Vertices))
+	assertEqInt(t, 1, len(rt.Faces))
+	assertEqInt(t, 4, len(rt.Faces[0]))
+	assertEqInt(t, 3, rt.Faces[0][3])
+	assertEqInt(t, 2, len(rt.Edges))
+	assertEqFloat64(t, 2.0, rt.Creases[1])
+
+	// not written before it was introduced
+	drawing.Header.Version = R2007
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "MESH"),
+	}, drawingCodePairs(t, drawing))
+}
+
+func TestTransformMesh(t *testing.T) {
+	m := NewMesh()
+	m.Vertices = []Point{{X: 1.0, Y: 2.0, Z: 3.0}}
+	matrix := *NewIdentityMatrix4()
+	matrix[0][3] = 10.0
+	transformed, err := Transform(m, matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqPoint(t, Point{X: 11.0, Y: 2.0, Z: 3.0}, transformed.(*Mesh).Vertices[0])
+}
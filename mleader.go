@@ -0,0 +1,775 @@
+package dxf
+
+// MLeaderContext is the context data of an MLeader; the geometry of its content and leaders.
+type MLeaderContext struct {
+	ContentScale               float64
+	ContentBasePoint           Point
+	TextHeight                 float64
+	ArrowheadSize              float64
+	LandingGap                 float64
+	TextLeftAttachment         MLeaderTextAttachment
+	TextRightAttachment        MLeaderTextAttachment
+	TextAlignment              int16
+	BlockContentConnectionType MLeaderBlockConnectionType
+	HasMText                   bool
+	MText                      MLeaderMTextContent
+	HasBlock                   bool
+	Block                      MLeaderBlockContent
+	PlaneOrigin                Point
+	PlaneXAxis                 Vector
+	PlaneYAxis                 Vector
+	IsPlaneNormalReversed      bool
+	Roots                      []MLeaderRoot
+	TextBottomAttachment       MLeaderTextAttachment
+	TextTopAttachment          MLeaderTextAttachment
+}
+
+// NewMLeaderContext creates a new MLeaderContext with mtext content in the XY plane.
+func NewMLeaderContext() *MLeaderContext {
+	return &MLeaderContext{
+		ContentScale:               1.0,
+		ContentBasePoint:           *NewOrigin(),
+		TextHeight:                 4.0,
+		ArrowheadSize:              4.0,
+		LandingGap:                 2.0,
+		TextLeftAttachment:         MLeaderTextAttachmentMiddleOfTopLine,
+		TextRightAttachment:        MLeaderTextAttachmentMiddleOfTopLine,
+		TextAlignment:              0,
+		BlockContentConnectionType: MLeaderBlockConnectionTypeExtents,
+		HasMText:                   true,
+		MText:                      *NewMLeaderMTextContent(),
+		HasBlock:                   false,
+		Block:                      *NewMLeaderBlockContent(),
+		PlaneOrigin:                *NewOrigin(),
+		PlaneXAxis:                 *NewXAxis(),
+		PlaneYAxis:                 *NewYAxis(),
+		IsPlaneNormalReversed:      false,
+		Roots:                      []MLeaderRoot{},
+		TextBottomAttachment:       MLeaderTextAttachmentCenterOfText,
+		TextTopAttachment:          MLeaderTextAttachmentCenterOfText,
+	}
+}
+
+// MLeaderMTextContent is the mtext shown by an MLeader with mtext content.
+type MLeaderMTextContent struct {
+	Text                     string
+	Normal                   Vector
+	TextStyleHandle          Handle
+	Location                 Point
+	Direction                Vector
+	Rotation                 float64 // Text rotation in radians.
+	BoundaryWidth            float64
+	BoundaryHeight           float64
+	LineSpacingFactor        float64
+	LineSpacingStyle         int16
+	Color                    int // Raw color value.
+	Alignment                int16
+	FlowDirection            int16
+	BackgroundColor          int // Raw color value.
+	BackgroundScaleFactor    float64
+	BackgroundTransparency   int
+	IsBackgroundColorEnabled bool
+	IsBackgroundFillEnabled  bool
+	ColumnType               int16
+	IsTextHeightAutomatic    bool
+	ColumnWidth              float64
+	ColumnGutter             float64
+	IsColumnFlowReversed     bool
+	ColumnSizes              []float64
+	UseWordBreak             bool
+}
+
+// NewMLeaderMTextContent creates a new MLeaderMTextContent.
+func NewMLeaderMTextContent() *MLeaderMTextContent {
+	return &MLeaderMTextContent{
+		Text:                     "",
+		Normal:                   *NewZAxis(),
+		TextStyleHandle:          0,
+		Location:                 *NewOrigin(),
+		Direction:                *NewXAxis(),
+		Rotation:                 0.0,
+		BoundaryWidth:            0.0,
+		BoundaryHeight:           0.0,
+		LineSpacingFactor:        1.0,
+		LineSpacingStyle:         1,
+		Color:                    -1056964608, // by block
+		Alignment:                1,
+		FlowDirection:            1,
+		BackgroundColor:          -939524096, // none
+		BackgroundScaleFactor:    1.5,
+		BackgroundTransparency:   0,
+		IsBackgroundColorEnabled: false,
+		IsBackgroundFillEnabled:  false,
+		ColumnType:               0,
+		IsTextHeightAutomatic:    false,
+		ColumnWidth:              0.0,
+		ColumnGutter:             0.0,
+		IsColumnFlowReversed:     false,
+		ColumnSizes:              []float64{},
+		UseWordBreak:             true,
+	}
+}
+
+// MLeaderBlockContent is the block shown by an MLeader with block content.
+type MLeaderBlockContent struct {
+	BlockHandle          Handle
+	Normal               Vector
+	Location             Point
+	Scale                Vector
+	Rotation             float64 // Block rotation in radians.
+	Color                int     // Raw color value.
+	TransformationMatrix Matrix4
+}
+
+// NewMLeaderBlockContent creates a new MLeaderBlockContent.
+func NewMLeaderBlockContent() *MLeaderBlockContent {
+	return &MLeaderBlockContent{
+		BlockHandle:          0,
+		Normal:               *NewZAxis(),
+		Location:             *NewOrigin(),
+		Scale:                Vector{X: 1.0, Y: 1.0, Z: 1.0},
+		Rotation:             0.0,
+		Color:                -1056964608, // by block
+		TransformationMatrix: *NewIdentityMatrix4(),
+	}
+}
+
+// MLeaderRoot is where one or more leader lines join the content of an MLeader.
+type MLeaderRoot struct {
+	HasConnectionPoint  bool
+	HasDoglegVector     bool
+	ConnectionPoint     Point
+	DoglegVector        Vector
+	Breaks              []MLeaderBreak
+	LeaderIndex         int
+	DoglegLength        float64
+	Lines               []MLeaderLine
+	AttachmentDirection MLeaderTextAttachmentDirection
+}
+
+// MLeaderLine is a single leader line running from its arrowhead at `Vertices[0]` towards the root.
+type MLeaderLine struct {
+	Vertices  []Point
+	Breaks    []MLeaderBreak
+	LineIndex int
+}
+
+// MLeaderBreak is a gap in a leader where it's broken by other geometry.
+type MLeaderBreak struct {
+	Index int
+	Start Point
+	End   Point
+}
+
+// MLeaderArrowhead overrides the arrowhead block of a single leader line.
+type MLeaderArrowhead struct {
+	Index       int
+	BlockHandle Handle
+}
+
+// MLeaderBlockAttribute is the value of an attribute of the block content.
+type MLeaderBlockAttribute struct {
+	AttributeDefinitionHandle Handle
+	Index                     int16
+	Width                     float64
+	Text                      string
+}
+
+type mleaderReadState int
+
+const (
+	mleaderReadStateEntity mleaderReadState = iota
+	mleaderReadStateCommon
+	mleaderReadStateContext
+	mleaderReadStateRoot
+	mleaderReadStateLine
+)
+
+//
+// reading
+//
+
+func (m *MLeader) tryApplyCodePair(codePair CodePair) {
+	switch m.readState {
+	case mleaderReadStateEntity:
+		if codePair.Code == 100 {
+			if codePair.Value.(StringCodePairValue).Value == "AcDbMLeader" {
+				m.readState = mleaderReadStateCommon
+			}
+			return
+		}
+		if !tryApplyCodePairForEntity(m, codePair) {
+			// no subclass marker; the mleader data has started
+			m.readState = mleaderReadStateCommon
+			m.applyCommonCodePair(codePair)
+		}
+	case mleaderReadStateCommon:
+		m.applyCommonCodePair(codePair)
+	case mleaderReadStateContext:
+		m.applyContextCodePair(codePair)
+	case mleaderReadStateRoot:
+		m.applyRootCodePair(codePair)
+	case mleaderReadStateLine:
+		m.applyLineCodePair(codePair)
+	}
+}
+
+func (m *MLeader) applyCommonCodePair(codePair CodePair) {
+	switch codePair.Code {
+	case 300:
+		m.readState = mleaderReadStateContext
+	case 270:
+		m.Version = codePair.Value.(ShortCodePairValue).Value
+	case 340:
+		m.pointerStyle.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 90:
+		m.PropertyOverrideFlags = codePair.Value.(IntCodePairValue).Value
+	case 170:
+		m.LeaderLineType = MLeaderLineType(codePair.Value.(ShortCodePairValue).Value)
+	case 91:
+		m.LeaderLineColor = codePair.Value.(IntCodePairValue).Value
+	case 341:
+		m.pointerLeaderLineTypeRecord.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 171:
+		m.LeaderLineWeight = codePair.Value.(ShortCodePairValue).Value
+	case 290:
+		m.IsLandingEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 291:
+		m.IsDoglegEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 41:
+		m.DoglegLength = codePair.Value.(DoubleCodePairValue).Value
+	case 342:
+		m.pointerArrowheadBlock.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 42:
+		m.ArrowheadSize = codePair.Value.(DoubleCodePairValue).Value
+	case 172:
+		m.ContentType = MLeaderContentType(codePair.Value.(ShortCodePairValue).Value)
+	case 343:
+		m.pointerTextStyle.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 173:
+		m.TextLeftAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 95:
+		m.TextRightAttachment = MLeaderTextAttachment(codePair.Value.(IntCodePairValue).Value)
+	case 174:
+		m.TextAngleType = codePair.Value.(ShortCodePairValue).Value
+	case 175:
+		m.TextAlignment = codePair.Value.(ShortCodePairValue).Value
+	case 92:
+		m.TextColor = codePair.Value.(IntCodePairValue).Value
+	case 292:
+		m.IsTextFrameEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 344:
+		m.pointerBlockContent.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 93:
+		m.BlockContentColor = codePair.Value.(IntCodePairValue).Value
+	case 10:
+		m.BlockContentScale.X = codePair.Value.(DoubleCodePairValue).Value
+	case 20:
+		m.BlockContentScale.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 30:
+		m.BlockContentScale.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 43:
+		m.BlockContentRotation = codePair.Value.(DoubleCodePairValue).Value
+	case 176:
+		m.BlockContentConnectionType = MLeaderBlockConnectionType(codePair.Value.(ShortCodePairValue).Value)
+	case 293:
+		m.IsAnnotative = codePair.Value.(BoolCodePairValue).Value
+	case 94:
+		m.Arrowheads = append(m.Arrowheads, MLeaderArrowhead{Index: codePair.Value.(IntCodePairValue).Value})
+	case 345:
+		if len(m.Arrowheads) > 0 {
+			m.Arrowheads[len(m.Arrowheads)-1].BlockHandle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+		}
+	case 330:
+		m.BlockAttributes = append(m.BlockAttributes, MLeaderBlockAttribute{AttributeDefinitionHandle: handleFromString(codePair.Value.(StringCodePairValue).Value)})
+	case 177:
+		if len(m.BlockAttributes) > 0 {
+			m.BlockAttributes[len(m.BlockAttributes)-1].Index = codePair.Value.(ShortCodePairValue).Value
+		}
+	case 44:
+		if len(m.BlockAttributes) > 0 {
+			m.BlockAttributes[len(m.BlockAttributes)-1].Width = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 302:
+		if len(m.BlockAttributes) > 0 {
+			m.BlockAttributes[len(m.BlockAttributes)-1].Text = codePair.Value.(StringCodePairValue).Value
+		}
+	case 294:
+		m.IsTextDirectionNegative = codePair.Value.(BoolCodePairValue).Value
+	case 178:
+		m.TextAlignInIPE = codePair.Value.(ShortCodePairValue).Value
+	case 179:
+		m.TextAttachmentPoint = codePair.Value.(ShortCodePairValue).Value
+	case 271:
+		m.TextAttachmentDirection = MLeaderTextAttachmentDirection(codePair.Value.(ShortCodePairValue).Value)
+	case 272:
+		m.TextBottomAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 273:
+		m.TextTopAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	}
+}
+
+func (m *MLeader) applyContextCodePair(codePair CodePair) {
+	c := &m.Context
+	switch codePair.Code {
+	case 301:
+		m.readState = mleaderReadStateCommon
+	case 302:
+		c.Roots = append(c.Roots, MLeaderRoot{Breaks: []MLeaderBreak{}, Lines: []MLeaderLine{}})
+		m.readState = mleaderReadStateRoot
+	case 40:
+		c.ContentScale = codePair.Value.(DoubleCodePairValue).Value
+	case 10:
+		c.ContentBasePoint.X = codePair.Value.(DoubleCodePairValue).Value
+	case 20:
+		c.ContentBasePoint.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 30:
+		c.ContentBasePoint.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 41:
+		c.TextHeight = codePair.Value.(DoubleCodePairValue).Value
+	case 140:
+		c.ArrowheadSize = codePair.Value.(DoubleCodePairValue).Value
+	case 145:
+		c.LandingGap = codePair.Value.(DoubleCodePairValue).Value
+	case 174:
+		c.TextLeftAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 175:
+		c.TextRightAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 176:
+		c.TextAlignment = codePair.Value.(ShortCodePairValue).Value
+	case 177:
+		c.BlockContentConnectionType = MLeaderBlockConnectionType(codePair.Value.(ShortCodePairValue).Value)
+	case 290:
+		c.HasMText = codePair.Value.(BoolCodePairValue).Value
+	case 304:
+		c.MText.Text = codePair.Value.(StringCodePairValue).Value
+	case 11:
+		c.MText.Normal.X = codePair.Value.(DoubleCodePairValue).Value
+	case 21:
+		c.MText.Normal.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 31:
+		c.MText.Normal.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 340:
+		c.MText.TextStyleHandle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 12:
+		c.MText.Location.X = codePair.Value.(DoubleCodePairValue).Value
+	case 22:
+		c.MText.Location.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 32:
+		c.MText.Location.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 13:
+		c.MText.Direction.X = codePair.Value.(DoubleCodePairValue).Value
+	case 23:
+		c.MText.Direction.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 33:
+		c.MText.Direction.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 42:
+		c.MText.Rotation = codePair.Value.(DoubleCodePairValue).Value
+	case 43:
+		c.MText.BoundaryWidth = codePair.Value.(DoubleCodePairValue).Value
+	case 44:
+		c.MText.BoundaryHeight = codePair.Value.(DoubleCodePairValue).Value
+	case 45:
+		c.MText.LineSpacingFactor = codePair.Value.(DoubleCodePairValue).Value
+	case 170:
+		c.MText.LineSpacingStyle = codePair.Value.(ShortCodePairValue).Value
+	case 90:
+		c.MText.Color = codePair.Value.(IntCodePairValue).Value
+	case 171:
+		c.MText.Alignment = codePair.Value.(ShortCodePairValue).Value
+	case 172:
+		c.MText.FlowDirection = codePair.Value.(ShortCodePairValue).Value
+	case 91:
+		c.MText.BackgroundColor = codePair.Value.(IntCodePairValue).Value
+	case 141:
+		c.MText.BackgroundScaleFactor = codePair.Value.(DoubleCodePairValue).Value
+	case 92:
+		c.MText.BackgroundTransparency = codePair.Value.(IntCodePairValue).Value
+	case 291:
+		c.MText.IsBackgroundColorEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 292:
+		c.MText.IsBackgroundFillEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 173:
+		c.MText.ColumnType = codePair.Value.(ShortCodePairValue).Value
+	case 293:
+		c.MText.IsTextHeightAutomatic = codePair.Value.(BoolCodePairValue).Value
+	case 142:
+		c.MText.ColumnWidth = codePair.Value.(DoubleCodePairValue).Value
+	case 143:
+		c.MText.ColumnGutter = codePair.Value.(DoubleCodePairValue).Value
+	case 294:
+		c.MText.IsColumnFlowReversed = codePair.Value.(BoolCodePairValue).Value
+	case 144:
+		c.MText.ColumnSizes = append(c.MText.ColumnSizes, codePair.Value.(DoubleCodePairValue).Value)
+	case 295:
+		c.MText.UseWordBreak = codePair.Value.(BoolCodePairValue).Value
+	case 296:
+		c.HasBlock = codePair.Value.(BoolCodePairValue).Value
+	case 341:
+		c.Block.BlockHandle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 14:
+		c.Block.Normal.X = codePair.Value.(DoubleCodePairValue).Value
+	case 24:
+		c.Block.Normal.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 34:
+		c.Block.Normal.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 15:
+		c.Block.Location.X = codePair.Value.(DoubleCodePairValue).Value
+	case 25:
+		c.Block.Location.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 35:
+		c.Block.Location.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 16:
+		c.Block.Scale.X = codePair.Value.(DoubleCodePairValue).Value
+	case 26:
+		c.Block.Scale.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 36:
+		c.Block.Scale.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 46:
+		c.Block.Rotation = codePair.Value.(DoubleCodePairValue).Value
+	case 93:
+		c.Block.Color = codePair.Value.(IntCodePairValue).Value
+	case 47:
+		// the 16 matrix values are written row by row
+		if m.matrixValueCount < 16 {
+			c.Block.TransformationMatrix[m.matrixValueCount/4][m.matrixValueCount%4] = codePair.Value.(DoubleCodePairValue).Value
+			m.matrixValueCount++
+		}
+	case 110:
+		c.PlaneOrigin.X = codePair.Value.(DoubleCodePairValue).Value
+	case 120:
+		c.PlaneOrigin.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 130:
+		c.PlaneOrigin.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 111:
+		c.PlaneXAxis.X = codePair.Value.(DoubleCodePairValue).Value
+	case 121:
+		c.PlaneXAxis.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 131:
+		c.PlaneXAxis.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 112:
+		c.PlaneYAxis.X = codePair.Value.(DoubleCodePairValue).Value
+	case 122:
+		c.PlaneYAxis.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 132:
+		c.PlaneYAxis.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 297:
+		c.IsPlaneNormalReversed = codePair.Value.(BoolCodePairValue).Value
+	case 272:
+		c.TextBottomAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 273:
+		c.TextTopAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	}
+}
+
+func (m *MLeader) applyRootCodePair(codePair CodePair) {
+	r := &m.Context.Roots[len(m.Context.Roots)-1]
+	switch codePair.Code {
+	case 303:
+		m.readState = mleaderReadStateContext
+	case 304:
+		r.Lines = append(r.Lines, MLeaderLine{Vertices: []Point{}, Breaks: []MLeaderBreak{}})
+		m.readState = mleaderReadStateLine
+	case 290:
+		r.HasConnectionPoint = codePair.Value.(BoolCodePairValue).Value
+	case 291:
+		r.HasDoglegVector = codePair.Value.(BoolCodePairValue).Value
+	case 10:
+		r.ConnectionPoint.X = codePair.Value.(DoubleCodePairValue).Value
+	case 20:
+		r.ConnectionPoint.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 30:
+		r.ConnectionPoint.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 11:
+		r.DoglegVector.X = codePair.Value.(DoubleCodePairValue).Value
+	case 21:
+		r.DoglegVector.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 31:
+		r.DoglegVector.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 12:
+		r.Breaks = append(r.Breaks, MLeaderBreak{Index: len(r.Breaks), Start: Point{X: codePair.Value.(DoubleCodePairValue).Value}})
+	case 22:
+		if len(r.Breaks) > 0 {
+			r.Breaks[len(r.Breaks)-1].Start.Y = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 32:
+		if len(r.Breaks) > 0 {
+			r.Breaks[len(r.Breaks)-1].Start.Z = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 13:
+		if len(r.Breaks) > 0 {
+			r.Breaks[len(r.Breaks)-1].End.X = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 23:
+		if len(r.Breaks) > 0 {
+			r.Breaks[len(r.Breaks)-1].End.Y = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 33:
+		if len(r.Breaks) > 0 {
+			r.Breaks[len(r.Breaks)-1].End.Z = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 90:
+		r.LeaderIndex = codePair.Value.(IntCodePairValue).Value
+	case 40:
+		r.DoglegLength = codePair.Value.(DoubleCodePairValue).Value
+	case 271:
+		r.AttachmentDirection = MLeaderTextAttachmentDirection(codePair.Value.(ShortCodePairValue).Value)
+	}
+}
+
+func (m *MLeader) applyLineCodePair(codePair CodePair) {
+	roots := m.Context.Roots
+	lines := roots[len(roots)-1].Lines
+	l := &lines[len(lines)-1]
+	switch codePair.Code {
+	case 305:
+		m.readState = mleaderReadStateRoot
+	case 10:
+		l.Vertices = append(l.Vertices, Point{X: codePair.Value.(DoubleCodePairValue).Value})
+	case 20:
+		if len(l.Vertices) > 0 {
+			l.Vertices[len(l.Vertices)-1].Y = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 30:
+		if len(l.Vertices) > 0 {
+			l.Vertices[len(l.Vertices)-1].Z = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 90:
+		l.Breaks = append(l.Breaks, MLeaderBreak{Index: codePair.Value.(IntCodePairValue).Value})
+	case 11:
+		if len(l.Breaks) > 0 {
+			l.Breaks[len(l.Breaks)-1].Start.X = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 21:
+		if len(l.Breaks) > 0 {
+			l.Breaks[len(l.Breaks)-1].Start.Y = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 31:
+		if len(l.Breaks) > 0 {
+			l.Breaks[len(l.Breaks)-1].Start.Z = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 12:
+		if len(l.Breaks) > 0 {
+			l.Breaks[len(l.Breaks)-1].End.X = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 22:
+		if len(l.Breaks) > 0 {
+			l.Breaks[len(l.Breaks)-1].End.Y = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 32:
+		if len(l.Breaks) > 0 {
+			l.Breaks[len(l.Breaks)-1].End.Z = codePair.Value.(DoubleCodePairValue).Value
+		}
+	case 91:
+		l.LineIndex = codePair.Value.(IntCodePairValue).Value
+	}
+}
+
+//
+// writing
+//
+
+func (m *MLeader) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "MULTILEADER"))
+	pairs = append(pairs, codePairsForEntity(m, version)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbMLeader"))
+	pairs = append(pairs, NewShortCodePair(270, m.Version))
+	pairs = append(pairs, m.Context.codePairs(version)...)
+	pairs = append(pairs, handleCodePair(340, m.pointerStyle.handle))
+	pairs = append(pairs, NewIntCodePair(90, m.PropertyOverrideFlags))
+	pairs = append(pairs, NewShortCodePair(170, int16(m.LeaderLineType)))
+	pairs = append(pairs, NewIntCodePair(91, m.LeaderLineColor))
+	pairs = append(pairs, handleCodePair(341, m.pointerLeaderLineTypeRecord.handle))
+	pairs = append(pairs, NewShortCodePair(171, m.LeaderLineWeight))
+	pairs = append(pairs, NewBoolCodePair(290, m.IsLandingEnabled))
+	pairs = append(pairs, NewBoolCodePair(291, m.IsDoglegEnabled))
+	pairs = append(pairs, NewDoubleCodePair(41, m.DoglegLength))
+	if m.pointerArrowheadBlock.handle != 0 {
+		pairs = append(pairs, handleCodePair(342, m.pointerArrowheadBlock.handle))
+	}
+	pairs = append(pairs, NewDoubleCodePair(42, m.ArrowheadSize))
+	pairs = append(pairs, NewShortCodePair(172, int16(m.ContentType)))
+	pairs = append(pairs, handleCodePair(343, m.pointerTextStyle.handle))
+	pairs = append(pairs, NewShortCodePair(173, int16(m.TextLeftAttachment)))
+	pairs = append(pairs, NewIntCodePair(95, int(m.TextRightAttachment)))
+	pairs = append(pairs, NewShortCodePair(174, m.TextAngleType))
+	pairs = append(pairs, NewShortCodePair(175, m.TextAlignment))
+	pairs = append(pairs, NewIntCodePair(92, m.TextColor))
+	pairs = append(pairs, NewBoolCodePair(292, m.IsTextFrameEnabled))
+	if m.pointerBlockContent.handle != 0 {
+		pairs = append(pairs, handleCodePair(344, m.pointerBlockContent.handle))
+	}
+	pairs = append(pairs, NewIntCodePair(93, m.BlockContentColor))
+	pairs = append(pairs, NewDoubleCodePair(10, m.BlockContentScale.X))
+	pairs = append(pairs, NewDoubleCodePair(20, m.BlockContentScale.Y))
+	pairs = append(pairs, NewDoubleCodePair(30, m.BlockContentScale.Z))
+	pairs = append(pairs, NewDoubleCodePair(43, m.BlockContentRotation))
+	pairs = append(pairs, NewShortCodePair(176, int16(m.BlockContentConnectionType)))
+	pairs = append(pairs, NewBoolCodePair(293, m.IsAnnotative))
+	for _, a := range m.Arrowheads {
+		pairs = append(pairs, NewIntCodePair(94, a.Index))
+		pairs = append(pairs, handleCodePair(345, a.BlockHandle))
+	}
+	for _, a := range m.BlockAttributes {
+		pairs = append(pairs, handleCodePair(330, a.AttributeDefinitionHandle))
+		pairs = append(pairs, NewShortCodePair(177, a.Index))
+		pairs = append(pairs, NewDoubleCodePair(44, a.Width))
+		pairs = append(pairs, NewStringCodePair(302, a.Text))
+	}
+	pairs = append(pairs, NewBoolCodePair(294, m.IsTextDirectionNegative))
+	pairs = append(pairs, NewShortCodePair(178, m.TextAlignInIPE))
+	pairs = append(pairs, NewShortCodePair(179, m.TextAttachmentPoint))
+	if version >= R2010 {
+		pairs = append(pairs, NewShortCodePair(271, int16(m.TextAttachmentDirection)))
+		pairs = append(pairs, NewShortCodePair(272, int16(m.TextBottomAttachment)))
+		pairs = append(pairs, NewShortCodePair(273, int16(m.TextTopAttachment)))
+	}
+	return
+}
+
+func (c *MLeaderContext) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(300, "CONTEXT_DATA{"))
+	pairs = append(pairs, NewDoubleCodePair(40, c.ContentScale))
+	pairs = append(pairs, NewDoubleCodePair(10, c.ContentBasePoint.X))
+	pairs = append(pairs, NewDoubleCodePair(20, c.ContentBasePoint.Y))
+	pairs = append(pairs, NewDoubleCodePair(30, c.ContentBasePoint.Z))
+	pairs = append(pairs, NewDoubleCodePair(41, c.TextHeight))
+	pairs = append(pairs, NewDoubleCodePair(140, c.ArrowheadSize))
+	pairs = append(pairs, NewDoubleCodePair(145, c.LandingGap))
+	pairs = append(pairs, NewShortCodePair(174, int16(c.TextLeftAttachment)))
+	pairs = append(pairs, NewShortCodePair(175, int16(c.TextRightAttachment)))
+	pairs = append(pairs, NewShortCodePair(176, c.TextAlignment))
+	pairs = append(pairs, NewShortCodePair(177, int16(c.BlockContentConnectionType)))
+	pairs = append(pairs, NewBoolCodePair(290, c.HasMText))
+	if c.HasMText {
+		t := &c.MText
+		pairs = append(pairs, NewStringCodePair(304, t.Text))
+		pairs = append(pairs, NewDoubleCodePair(11, t.Normal.X))
+		pairs = append(pairs, NewDoubleCodePair(21, t.Normal.Y))
+		pairs = append(pairs, NewDoubleCodePair(31, t.Normal.Z))
+		pairs = append(pairs, handleCodePair(340, t.TextStyleHandle))
+		pairs = append(pairs, NewDoubleCodePair(12, t.Location.X))
+		pairs = append(pairs, NewDoubleCodePair(22, t.Location.Y))
+		pairs = append(pairs, NewDoubleCodePair(32, t.Location.Z))
+		pairs = append(pairs, NewDoubleCodePair(13, t.Direction.X))
+		pairs = append(pairs, NewDoubleCodePair(23, t.Direction.Y))
+		pairs = append(pairs, NewDoubleCodePair(33, t.Direction.Z))
+		pairs = append(pairs, NewDoubleCodePair(42, t.Rotation))
+		pairs = append(pairs, NewDoubleCodePair(43, t.BoundaryWidth))
+		pairs = append(pairs, NewDoubleCodePair(44, t.BoundaryHeight))
+		pairs = append(pairs, NewDoubleCodePair(45, t.LineSpacingFactor))
+		pairs = append(pairs, NewShortCodePair(170, t.LineSpacingStyle))
+		pairs = append(pairs, NewIntCodePair(90, t.Color))
+		pairs = append(pairs, NewShortCodePair(171, t.Alignment))
+		pairs = append(pairs, NewShortCodePair(172, t.FlowDirection))
+		pairs = append(pairs, NewIntCodePair(91, t.BackgroundColor))
+		pairs = append(pairs, NewDoubleCodePair(141, t.BackgroundScaleFactor))
+		pairs = append(pairs, NewIntCodePair(92, t.BackgroundTransparency))
+		pairs = append(pairs, NewBoolCodePair(291, t.IsBackgroundColorEnabled))
+		pairs = append(pairs, NewBoolCodePair(292, t.IsBackgroundFillEnabled))
+		pairs = append(pairs, NewShortCodePair(173, t.ColumnType))
+		pairs = append(pairs, NewBoolCodePair(293, t.IsTextHeightAutomatic))
+		pairs = append(pairs, NewDoubleCodePair(142, t.ColumnWidth))
+		pairs = append(pairs, NewDoubleCodePair(143, t.ColumnGutter))
+		pairs = append(pairs, NewBoolCodePair(294, t.IsColumnFlowReversed))
+		for _, size := range t.ColumnSizes {
+			pairs = append(pairs, NewDoubleCodePair(144, size))
+		}
+		pairs = append(pairs, NewBoolCodePair(295, t.UseWordBreak))
+	}
+	pairs = append(pairs, NewBoolCodePair(296, c.HasBlock))
+	if c.HasBlock {
+		b := &c.Block
+		pairs = append(pairs, handleCodePair(341, b.BlockHandle))
+		pairs = append(pairs, NewDoubleCodePair(14, b.Normal.X))
+		pairs = append(pairs, NewDoubleCodePair(24, b.Normal.Y))
+		pairs = append(pairs, NewDoubleCodePair(34, b.Normal.Z))
+		pairs = append(pairs, NewDoubleCodePair(15, b.Location.X))
+		pairs = append(pairs, NewDoubleCodePair(25, b.Location.Y))
+		pairs = append(pairs, NewDoubleCodePair(35, b.Location.Z))
+		pairs = append(pairs, NewDoubleCodePair(16, b.Scale.X))
+		pairs = append(pairs, NewDoubleCodePair(26, b.Scale.Y))
+		pairs = append(pairs, NewDoubleCodePair(36, b.Scale.Z))
+		pairs = append(pairs, NewDoubleCodePair(46, b.Rotation))
+		pairs = append(pairs, NewIntCodePair(93, b.Color))
+		for _, row := range b.TransformationMatrix {
+			for _, val := range row {
+				pairs = append(pairs, NewDoubleCodePair(47, val))
+			}
+		}
+	}
+	pairs = append(pairs, NewDoubleCodePair(110, c.PlaneOrigin.X))
+	pairs = append(pairs, NewDoubleCodePair(120, c.PlaneOrigin.Y))
+	pairs = append(pairs, NewDoubleCodePair(130, c.PlaneOrigin.Z))
+	pairs = append(pairs, NewDoubleCodePair(111, c.PlaneXAxis.X))
+	pairs = append(pairs, NewDoubleCodePair(121, c.PlaneXAxis.Y))
+	pairs = append(pairs, NewDoubleCodePair(131, c.PlaneXAxis.Z))
+	pairs = append(pairs, NewDoubleCodePair(112, c.PlaneYAxis.X))
+	pairs = append(pairs, NewDoubleCodePair(122, c.PlaneYAxis.Y))
+	pairs = append(pairs, NewDoubleCodePair(132, c.PlaneYAxis.Z))
+	pairs = append(pairs, NewBoolCodePair(297, c.IsPlaneNormalReversed))
+	for _, r := range c.Roots {
+		pairs = append(pairs, r.codePairs(version)...)
+	}
+	if version >= R2010 {
+		pairs = append(pairs, NewShortCodePair(272, int16(c.TextBottomAttachment)))
+		pairs = append(pairs, NewShortCodePair(273, int16(c.TextTopAttachment)))
+	}
+	pairs = append(pairs, NewStringCodePair(301, "}"))
+	return
+}
+
+func (r *MLeaderRoot) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(302, "LEADER{"))
+	pairs = append(pairs, NewBoolCodePair(290, r.HasConnectionPoint))
+	pairs = append(pairs, NewBoolCodePair(291, r.HasDoglegVector))
+	if r.HasConnectionPoint {
+		pairs = append(pairs, NewDoubleCodePair(10, r.ConnectionPoint.X))
+		pairs = append(pairs, NewDoubleCodePair(20, r.ConnectionPoint.Y))
+		pairs = append(pairs, NewDoubleCodePair(30, r.ConnectionPoint.Z))
+	}
+	if r.HasDoglegVector {
+		pairs = append(pairs, NewDoubleCodePair(11, r.DoglegVector.X))
+		pairs = append(pairs, NewDoubleCodePair(21, r.DoglegVector.Y))
+		pairs = append(pairs, NewDoubleCodePair(31, r.DoglegVector.Z))
+	}
+	for _, b := range r.Breaks {
+		pairs = append(pairs, NewDoubleCodePair(12, b.Start.X))
+		pairs = append(pairs, NewDoubleCodePair(22, b.Start.Y))
+		pairs = append(pairs, NewDoubleCodePair(32, b.Start.Z))
+		pairs = append(pairs, NewDoubleCodePair(13, b.End.X))
+		pairs = append(pairs, NewDoubleCodePair(23, b.End.Y))
+		pairs = append(pairs, NewDoubleCodePair(33, b.End.Z))
+	}
+	pairs = append(pairs, NewIntCodePair(90, r.LeaderIndex))
+	pairs = append(pairs, NewDoubleCodePair(40, r.DoglegLength))
+	for _, l := range r.Lines {
+		pairs = append(pairs, l.codePairs()...)
+	}
+	if version >= R2010 {
+		pairs = append(pairs, NewShortCodePair(271, int16(r.AttachmentDirection)))
+	}
+	pairs = append(pairs, NewStringCodePair(303, "}"))
+	return
+}
+
+func (l *MLeaderLine) codePairs() (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(304, "LEADER_LINE{"))
+	for _, v := range l.Vertices {
+		pairs = append(pairs, NewDoubleCodePair(10, v.X))
+		pairs = append(pairs, NewDoubleCodePair(20, v.Y))
+		pairs = append(pairs, NewDoubleCodePair(30, v.Z))
+	}
+	for _, b := range l.Breaks {
+		pairs = append(pairs, NewIntCodePair(90, b.Index))
+		pairs = append(pairs, NewDoubleCodePair(11, b.Start.X))
+		pairs = append(pairs, NewDoubleCodePair(21, b.Start.Y))
+		pairs = append(pairs, NewDoubleCodePair(31, b.Start.Z))
+		pairs = append(pairs, NewDoubleCodePair(12, b.End.X))
+		pairs = append(pairs, NewDoubleCodePair(22, b.End.Y))
+		pairs = append(pairs, NewDoubleCodePair(32, b.End.Z))
+	}
+	pairs = append(pairs, NewIntCodePair(91, l.LineIndex))
+	pairs = append(pairs, NewStringCodePair(305, "}"))
+	return
+}
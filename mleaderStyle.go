@@ -0,0 +1,310 @@
+package dxf
+
+// MLeaderStyle is the MLEADERSTYLE object that supplies the default properties of an MLeader.
+type MLeaderStyle struct {
+	handle       Handle
+	pointerOwner pointer
+
+	// Name is the name of the style in the ACAD_MLEADERSTYLE dictionary; it isn't part of the object itself.
+	Name                          string
+	Description                   string
+	ContentType                   MLeaderContentType
+	DrawMLeaderOrderType          int16
+	DrawLeaderOrderType           int16
+	MaxLeaderSegmentPoints        int
+	FirstSegmentAngleConstraint   float64
+	SecondSegmentAngleConstraint  float64
+	LeaderLineType                MLeaderLineType
+	LeaderLineColor               int // Raw color value.
+	LeaderLineTypeHandle          Handle
+	LeaderLineWeight              int
+	IsLandingEnabled              bool
+	LandingGap                    float64
+	IsDoglegEnabled               bool
+	DoglegLength                  float64
+	ArrowheadHandle               Handle
+	ArrowheadSize                 float64
+	DefaultMTextContents          string
+	TextStyleHandle               Handle
+	TextLeftAttachment            MLeaderTextAttachment
+	TextAngleType                 int16
+	TextAlignment                 int16
+	TextRightAttachment           MLeaderTextAttachment
+	TextColor                     int // Raw color value.
+	TextHeight                    float64
+	IsTextFrameEnabled            bool
+	IsTextAlwaysLeftAligned       bool
+	AlignSpace                    float64
+	BlockContentHandle            Handle
+	BlockContentColor             int // Raw color value.
+	BlockContentScale             Vector
+	IsBlockContentScaleEnabled    bool
+	BlockContentRotation          float64 // Block rotation in radians.
+	IsBlockContentRotationEnabled bool
+	BlockContentConnectionType    MLeaderBlockConnectionType
+	Scale                         float64
+	IsPropertyValueOverwritten    bool
+	IsAnnotative                  bool
+	BreakGapSize                  float64
+	TextAttachmentDirection       MLeaderTextAttachmentDirection
+	TextBottomAttachment          MLeaderTextAttachment
+	TextTopAttachment             MLeaderTextAttachment
+	readingStyleData              bool
+}
+
+// NewMLeaderStyle creates a new MLeaderStyle with the values AutoCAD uses for its STANDARD style.
+func NewMLeaderStyle() *MLeaderStyle {
+	return &MLeaderStyle{
+		handle:                        0,
+		Name:                          "",
+		Description:                   "",
+		ContentType:                   MLeaderContentTypeMText,
+		DrawMLeaderOrderType:          1,
+		DrawLeaderOrderType:           0,
+		MaxLeaderSegmentPoints:        2,
+		FirstSegmentAngleConstraint:   0.0,
+		SecondSegmentAngleConstraint:  0.0,
+		LeaderLineType:                MLeaderLineTypeStraight,
+		LeaderLineColor:               -1056964608, // by block
+		LeaderLineTypeHandle:          0,
+		LeaderLineWeight:              -2,
+		IsLandingEnabled:              true,
+		LandingGap:                    2.0,
+		IsDoglegEnabled:               true,
+		DoglegLength:                  8.0,
+		ArrowheadHandle:               0,
+		ArrowheadSize:                 4.0,
+		DefaultMTextContents:          "",
+		TextStyleHandle:               0,
+		TextLeftAttachment:            MLeaderTextAttachmentMiddleOfTopLine,
+		TextAngleType:                 1,
+		TextAlignment:                 0,
+		TextRightAttachment:           MLeaderTextAttachmentMiddleOfTopLine,
+		TextColor:                     -1056964608, // by block
+		TextHeight:                    4.0,
+		IsTextFrameEnabled:            false,
+		IsTextAlwaysLeftAligned:       false,
+		AlignSpace:                    4.0,
+		BlockContentHandle:            0,
+		BlockContentColor:             -1056964608, // by block
+		BlockContentScale:             Vector{X: 1.0, Y: 1.0, Z: 1.0},
+		IsBlockContentScaleEnabled:    true,
+		BlockContentRotation:          0.0,
+		IsBlockContentRotationEnabled: true,
+		BlockContentConnectionType:    MLeaderBlockConnectionTypeExtents,
+		Scale:                         1.0,
+		IsPropertyValueOverwritten:    false,
+		IsAnnotative:                  false,
+		BreakGapSize:                  3.75,
+		TextAttachmentDirection:       MLeaderTextAttachmentDirectionHorizontal,
+		TextBottomAttachment:          MLeaderTextAttachmentCenterOfText,
+		TextTopAttachment:             MLeaderTextAttachmentCenterOfText,
+	}
+}
+
+// Handle gets the handle of the object.
+func (s *MLeaderStyle) Handle() Handle {
+	return s.handle
+}
+
+// SetHandle sets the handle of the object.
+func (s *MLeaderStyle) SetHandle(val Handle) {
+	s.handle = val
+}
+
+// Owner gets the owner of the object.
+func (s *MLeaderStyle) Owner() *DrawingItem {
+	return s.pointerOwner.value
+}
+
+// SetOwner sets the owner of the object.
+func (s *MLeaderStyle) SetOwner(val *DrawingItem) {
+	s.pointerOwner.value = val
+}
+
+func (s *MLeaderStyle) getOwnerPointer() pointer {
+	return s.pointerOwner
+}
+
+func (s *MLeaderStyle) setOwnerPointerHandle(h Handle) {
+	s.pointerOwner.handle = h
+}
+
+func (s *MLeaderStyle) pointers() (pointers []*pointer) {
+	pointers = append(pointers, &s.pointerOwner)
+	return
+}
+
+func (s *MLeaderStyle) typeString() string {
+	return "MLEADERSTYLE"
+}
+
+func (s *MLeaderStyle) minVersion() AcadVersion {
+	return R2007
+}
+
+func (s *MLeaderStyle) maxVersion() AcadVersion {
+	return R2018
+}
+
+func (s *MLeaderStyle) tryApplyCodePair(codePair CodePair) {
+	if !s.readingStyleData {
+		// the owner and reactors come before the subclass marker
+		if codePair.Code == 100 {
+			s.readingStyleData = codePair.Value.(StringCodePairValue).Value == "AcDbMLeaderStyle"
+			return
+		}
+		if tryApplyCodePairForObject(s, codePair) {
+			return
+		}
+	}
+
+	switch codePair.Code {
+	case 3:
+		s.Description = codePair.Value.(StringCodePairValue).Value
+	case 40:
+		s.FirstSegmentAngleConstraint = codePair.Value.(DoubleCodePairValue).Value
+	case 41:
+		s.SecondSegmentAngleConstraint = codePair.Value.(DoubleCodePairValue).Value
+	case 42:
+		s.LandingGap = codePair.Value.(DoubleCodePairValue).Value
+	case 43:
+		s.DoglegLength = codePair.Value.(DoubleCodePairValue).Value
+	case 44:
+		s.ArrowheadSize = codePair.Value.(DoubleCodePairValue).Value
+	case 45:
+		s.TextHeight = codePair.Value.(DoubleCodePairValue).Value
+	case 46:
+		s.AlignSpace = codePair.Value.(DoubleCodePairValue).Value
+	case 47:
+		s.BlockContentScale.X = codePair.Value.(DoubleCodePairValue).Value
+	case 49:
+		s.BlockContentScale.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 140:
+		s.BlockContentScale.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 141:
+		s.BlockContentRotation = codePair.Value.(DoubleCodePairValue).Value
+	case 142:
+		s.Scale = codePair.Value.(DoubleCodePairValue).Value
+	case 143:
+		s.BreakGapSize = codePair.Value.(DoubleCodePairValue).Value
+	case 90:
+		s.MaxLeaderSegmentPoints = codePair.Value.(IntCodePairValue).Value
+	case 91:
+		s.LeaderLineColor = codePair.Value.(IntCodePairValue).Value
+	case 92:
+		s.LeaderLineWeight = codePair.Value.(IntCodePairValue).Value
+	case 93:
+		s.TextColor = codePair.Value.(IntCodePairValue).Value
+	case 94:
+		s.BlockContentColor = codePair.Value.(IntCodePairValue).Value
+	case 170:
+		s.ContentType = MLeaderContentType(codePair.Value.(ShortCodePairValue).Value)
+	case 171:
+		s.DrawMLeaderOrderType = codePair.Value.(ShortCodePairValue).Value
+	case 172:
+		s.DrawLeaderOrderType = codePair.Value.(ShortCodePairValue).Value
+	case 173:
+		s.LeaderLineType = MLeaderLineType(codePair.Value.(ShortCodePairValue).Value)
+	case 174:
+		s.TextLeftAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 175:
+		s.TextAlignment = codePair.Value.(ShortCodePairValue).Value
+	case 176:
+		s.TextRightAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 177:
+		s.BlockContentConnectionType = MLeaderBlockConnectionType(codePair.Value.(ShortCodePairValue).Value)
+	case 178:
+		s.TextAngleType = codePair.Value.(ShortCodePairValue).Value
+	case 271:
+		s.TextAttachmentDirection = MLeaderTextAttachmentDirection(codePair.Value.(ShortCodePairValue).Value)
+	case 272:
+		s.TextBottomAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 273:
+		s.TextTopAttachment = MLeaderTextAttachment(codePair.Value.(ShortCodePairValue).Value)
+	case 290:
+		s.IsLandingEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 291:
+		s.IsDoglegEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 292:
+		s.IsTextFrameEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 293:
+		s.IsBlockContentScaleEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 294:
+		s.IsBlockContentRotationEnabled = codePair.Value.(BoolCodePairValue).Value
+	case 295:
+		s.IsPropertyValueOverwritten = codePair.Value.(BoolCodePairValue).Value
+	case 296:
+		s.IsAnnotative = codePair.Value.(BoolCodePairValue).Value
+	case 297:
+		s.IsTextAlwaysLeftAligned = codePair.Value.(BoolCodePairValue).Value
+	case 300:
+		s.DefaultMTextContents = codePair.Value.(StringCodePairValue).Value
+	case 340:
+		s.LeaderLineTypeHandle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 341:
+		s.ArrowheadHandle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 342:
+		s.TextStyleHandle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 343:
+		s.BlockContentHandle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	}
+}
+
+func (s *MLeaderStyle) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, codePairsForObject(s, version)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbMLeaderStyle"))
+	pairs = append(pairs, NewShortCodePair(179, 2))
+	pairs = append(pairs, NewShortCodePair(170, int16(s.ContentType)))
+	pairs = append(pairs, NewShortCodePair(171, s.DrawMLeaderOrderType))
+	pairs = append(pairs, NewShortCodePair(172, s.DrawLeaderOrderType))
+	pairs = append(pairs, NewIntCodePair(90, s.MaxLeaderSegmentPoints))
+	pairs = append(pairs, NewDoubleCodePair(40, s.FirstSegmentAngleConstraint))
+	pairs = append(pairs, NewDoubleCodePair(41, s.SecondSegmentAngleConstraint))
+	pairs = append(pairs, NewShortCodePair(173, int16(s.LeaderLineType)))
+	pairs = append(pairs, NewIntCodePair(91, s.LeaderLineColor))
+	pairs = append(pairs, handleCodePair(340, s.LeaderLineTypeHandle))
+	pairs = append(pairs, NewIntCodePair(92, s.LeaderLineWeight))
+	pairs = append(pairs, NewBoolCodePair(290, s.IsLandingEnabled))
+	pairs = append(pairs, NewDoubleCodePair(42, s.LandingGap))
+	pairs = append(pairs, NewBoolCodePair(291, s.IsDoglegEnabled))
+	pairs = append(pairs, NewDoubleCodePair(43, s.DoglegLength))
+	pairs = append(pairs, NewStringCodePair(3, s.Description))
+	pairs = append(pairs, handleCodePair(341, s.ArrowheadHandle))
+	pairs = append(pairs, NewDoubleCodePair(44, s.ArrowheadSize))
+	pairs = append(pairs, NewStringCodePair(300, s.DefaultMTextContents))
+	pairs = append(pairs, handleCodePair(342, s.TextStyleHandle))
+	pairs = append(pairs, NewShortCodePair(174, int16(s.TextLeftAttachment)))
+	pairs = append(pairs, NewShortCodePair(178, s.TextAngleType))
+	pairs = append(pairs, NewShortCodePair(175, s.TextAlignment))
+	pairs = append(pairs, NewShortCodePair(176, int16(s.TextRightAttachment)))
+	pairs = append(pairs, NewIntCodePair(93, s.TextColor))
+	pairs = append(pairs, NewDoubleCodePair(45, s.TextHeight))
+	pairs = append(pairs, NewBoolCodePair(292, s.IsTextFrameEnabled))
+	pairs = append(pairs, NewBoolCodePair(297, s.IsTextAlwaysLeftAligned))
+	pairs = append(pairs, NewDoubleCodePair(46, s.AlignSpace))
+	pairs = append(pairs, handleCodePair(343, s.BlockContentHandle))
+	pairs = append(pairs, NewIntCodePair(94, s.BlockContentColor))
+	pairs = append(pairs, NewDoubleCodePair(47, s.BlockContentScale.X))
+	pairs = append(pairs, NewDoubleCodePair(49, s.BlockContentScale.Y))
+	pairs = append(pairs, NewDoubleCodePair(140, s.BlockContentScale.Z))
+	pairs = append(pairs, NewBoolCodePair(293, s.IsBlockContentScaleEnabled))
+	pairs = append(pairs, NewDoubleCodePair(141, s.BlockContentRotation))
+	pairs = append(pairs, NewBoolCodePair(294, s.IsBlockContentRotationEnabled))
+	pairs = append(pairs, NewShortCodePair(177, int16(s.BlockContentConnectionType)))
+	pairs = append(pairs, NewDoubleCodePair(142, s.Scale))
+	pairs = append(pairs, NewBoolCodePair(295, s.IsPropertyValueOverwritten))
+	pairs = append(pairs, NewBoolCodePair(296, s.IsAnnotative))
+	pairs = append(pairs, NewDoubleCodePair(143, s.BreakGapSize))
+	if version >= R2010 {
+		pairs = append(pairs, NewShortCodePair(271, int16(s.TextAttachmentDirection)))
+		pairs = append(pairs, NewShortCodePair(272, int16(s.TextBottomAttachment)))
+		pairs = append(pairs, NewShortCodePair(273, int16(s.TextTopAttachment)))
+	}
+	return
+}
+
+// handleCodePair writes a handle reference, using the null handle "0" when none is set.
+func handleCodePair(code int, h Handle) CodePair {
+	return NewStringCodePair(code, stringFromHandle(h))
+}
@@ -0,0 +1,311 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func TestReadMLeader(t *testing.T) {
+	m := parseEntity(t, "MULTILEADER",
+		NewStringCodePair(5, "A0"),
+		NewStringCodePair(100, "AcDbEntity"),
+		NewStringCodePair(8, "notes"),
+		NewStringCodePair(100, "AcDbMLeader"),
+		NewShortCodePair(270, 2),
+		NewStringCodePair(300, "CONTEXT_DATA{"),
+		NewDoubleCodePair(40, 1.0),
+		NewDoubleCodePair(10, 10.0),
+		NewDoubleCodePair(20, 5.0),
+		NewDoubleCodePair(30, 0.0),
+		NewDoubleCodePair(41, 2.5),
+		NewBoolCodePair(290, true),
+		NewStringCodePair(304, "Note"),
+		NewDoubleCodePair(12, 12.0),
+		NewDoubleCodePair(22, 6.0),
+		NewDoubleCodePair(32, 0.0),
+		NewBoolCodePair(296, false),
+		NewStringCodePair(302, "LEADER{"),
+		NewBoolCodePair(290, true),
+		NewBoolCodePair(291, true),
+		NewDoubleCodePair(10, 10.0),
+		NewDoubleCodePair(20, 5.0),
+		NewDoubleCodePair(30, 0.0),
+		NewDoubleCodePair(11, 1.0),
+		NewDoubleCodePair(21, 0.0),
+		NewDoubleCodePair(31, 0.0),
+		NewIntCodePair(90, 0),
+		NewDoubleCodePair(40, 2.0),
+		NewStringCodePair(304, "LEADER_LINE{"),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(30, 0.0),
+		NewDoubleCodePair(10, 5.0),
+		NewDoubleCodePair(20, 5.0),
+		NewDoubleCodePair(30, 0.0),
+		NewIntCodePair(91, 0),
+		NewStringCodePair(305, "}"),
+		NewStringCodePair(303, "}"),
+		NewStringCodePair(301, "}"),
+		NewStringCodePair(340, "B0"),
+		NewShortCodePair(170, 2),
+		NewBoolCodePair(290, false),
+		NewDoubleCodePair(41, 3.0),
+		NewShortCodePair(172, 2),
+		NewIntCodePair(94, 0),
+		NewStringCodePair(345, "C0"),
+		NewStringCodePair(330, "D0"),
+		NewShortCodePair(177, 1),
+		NewDoubleCodePair(44, 0.0),
+		NewStringCodePair(302, "attribute value"),
+	).(*MLeader)
+	assertEqString(t, "notes", m.Layer())
+	assertEqPoint(t, Point{X: 10.0, Y: 5.0, Z: 0.0}, m.Context.ContentBasePoint)
+	assertEqFloat64(t, 2.5, m.Context.TextHeight)
+	assert(t, m.Context.HasMText, "expected mtext content")
+	assertEqString(t, "Note", m.Context.MText.Text)
+	assertEqPoint(t, Point{X: 12.0, Y: 6.0, Z: 0.0}, m.Context.MText.Location)
+	assertEqInt(t, 1, len(m.Context.Roots))
+	root := m.Context.Roots[0]
+	assert(t, root.HasConnectionPoint, "expected connection point")
+	assertEqPoint(t, Point{X: 10.0, Y: 5.0, Z: 0.0}, root.ConnectionPoint)
+	assertEqVector(t, Vector{X: 1.0, Y: 0.0, Z: 0.0}, root.DoglegVector)
+	assertEqFloat64(t, 2.0, root.DoglegLength)
+	assertEqInt(t, 1, len(root.Lines))
+	assertEqInt(t, 2, len(root.Lines[0].Vertices))
+	assertEqPoint(t, Point{X: 5.0, Y: 5.0, Z: 0.0}, root.Lines[0].Vertices[1])
+	assert(t, m.LeaderLineType == MLeaderLineTypeSpline, "expected spline leader")
+	assert(t, !m.IsLandingEnabled, "expected landing to be disabled")
+	assertEqFloat64(t, 3.0, m.DoglegLength)
+	assert(t, m.ContentType == MLeaderContentTypeMText, "expected mtext content type")
+	assertEqInt(t, 1, len(m.Arrowheads))
+	assertEqUInt64(t, 0xC0, uint64(m.Arrowheads[0].BlockHandle))
+	assertEqInt(t, 1, len(m.BlockAttributes))
+	assertEqUInt64(t, 0xD0, uint64(m.BlockAttributes[0].AttributeDefinitionHandle))
+	assertEqString(t, "attribute value", m.BlockAttributes[0].Text)
+}
+
+func TestWriteMLeader(t *testing.T) {
+	m := NewMLeader()
+	m.Context.MText.Text = "Note"
+	m.Context.Roots = append(m.Context.Roots, MLeaderRoot{
+		HasConnectionPoint: true,
+		ConnectionPoint:    Point{X: 10.0, Y: 5.0, Z: 0.0},
+		Lines: []MLeaderLine{
+			{Vertices: []Point{{X: 0.0, Y: 0.0, Z: 0.0}, {X: 5.0, Y: 5.0, Z: 0.0}}},
+		},
+	})
+	actual := allCodePairs(m, R2007)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "MULTILEADER"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbMLeader"),
+		NewShortCodePair(270, 2),
+		NewStringCodePair(300, "CONTEXT_DATA{"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewBoolCodePair(290, true),
+		NewStringCodePair(304, "Note"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(302, "LEADER{"),
+		NewBoolCodePair(290, true),
+		NewBoolCodePair(291, false),
+		NewDoubleCodePair(10, 10.0),
+		NewDoubleCodePair(20, 5.0),
+		NewDoubleCodePair(30, 0.0),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(304, "LEADER_LINE{"),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(30, 0.0),
+		NewDoubleCodePair(10, 5.0),
+		NewDoubleCodePair(20, 5.0),
+		NewDoubleCodePair(30, 0.0),
+		NewIntCodePair(91, 0),
+		NewStringCodePair(305, "}"),
+		NewStringCodePair(303, "}"),
+	}, actual)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewShortCodePair(271, 0),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewShortCodePair(271, 0),
+	}, allCodePairs(m, R2010))
+}
+
+func TestWriteMLeaderVersions(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Entities = append(drawing.Entities, NewMLeader())
+
+	drawing.Header.Version = R2007
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "MULTILEADER"),
+	}, drawingCodePairs(t, drawing))
+
+	drawing.Header.Version = R2004
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "MULTILEADER"),
+	}, drawingCodePairs(t, drawing))
+}
+
+func TestRoundTripMLeaderWithBlockContent(t *testing.T) {
+	m := NewMLeader()
+	m.ContentType = MLeaderContentTypeBlock
+	m.pointerBlockContent.handle = Handle(0x42)
+	m.Context.HasMText = false
+	m.Context.HasBlock = true
+	m.Context.Block.BlockHandle = Handle(0x42)
+	m.Context.Block.Location = Point{X: 3.0, Y: 4.0, Z: 0.0}
+	m.Context.Block.TransformationMatrix = *NewTranslationMatrix4(Vector{X: 3.0, Y: 4.0, Z: 0.0})
+	m.Context.Roots = append(m.Context.Roots,
+		MLeaderRoot{Lines: []MLeaderLine{{Vertices: []Point{{X: 0.0, Y: 0.0, Z: 0.0}}}}},
+		MLeaderRoot{LeaderIndex: 1, Lines: []MLeaderLine{{Vertices: []Point{{X: 6.0, Y: 0.0, Z: 0.0}}}, {Vertices: []Point{{X: 6.0, Y: 8.0, Z: 0.0}}, LineIndex: 1}}},
+	)
+	m.BlockAttributes = append(m.BlockAttributes, MLeaderBlockAttribute{AttributeDefinitionHandle: Handle(0x43), Index: 1, Text: "A-1"})
+
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2018
+	drawing.Entities = append(drawing.Entities, m)
+	drawing = roundTripDrawing(t, &drawing)
+	actual := drawing.Entities[0].(*MLeader)
+	assert(t, actual.ContentType == MLeaderContentTypeBlock, "expected block content type")
+	assert(t, !actual.Context.HasMText && actual.Context.HasBlock, "expected only block content")
+	assertEqUInt64(t, 0x42, uint64(actual.pointerBlockContent.handle))
+	assertEqPoint(t, Point{X: 3.0, Y: 4.0, Z: 0.0}, actual.Context.Block.Location)
+	assertEqFloat64(t, 3.0, actual.Context.Block.TransformationMatrix[0][3])
+	assertEqFloat64(t, 4.0, actual.Context.Block.TransformationMatrix[1][3])
+	assertEqInt(t, 2, len(actual.Context.Roots))
+	assertEqInt(t, 2, len(actual.Context.Roots[1].Lines))
+	assertEqInt(t, 1, actual.Context.Roots[1].Lines[1].LineIndex)
+	assertEqPoint(t, Point{X: 6.0, Y: 8.0, Z: 0.0}, actual.Context.Roots[1].Lines[1].Vertices[0])
+	assertEqInt(t, 1, len(actual.BlockAttributes))
+	assertEqString(t, "A-1", actual.BlockAttributes[0].Text)
+}
+
+func TestReadMLeaderStyle(t *testing.T) {
+	drawing := parseFromCodePairs(t,
+		NewStringCodePair(0, "SECTION"),
+		NewStringCodePair(2, "OBJECTS"),
+		NewStringCodePair(0, "UNSUPPORTED_OBJECT"),
+		NewStringCodePair(5, "10"),
+		NewStringCodePair(0, "MLEADERSTYLE"),
+		NewStringCodePair(5, "B0"),
+		NewStringCodePair(102, "{ACAD_REACTORS"),
+		NewStringCodePair(330, "C"),
+		NewStringCodePair(102, "}"),
+		NewStringCodePair(330, "D"),
+		NewStringCodePair(100, "AcDbMLeaderStyle"),
+		NewShortCodePair(179, 2),
+		NewShortCodePair(170, 1),
+		NewShortCodePair(173, 2),
+		NewDoubleCodePair(43, 5.0),
+		NewStringCodePair(3, "described"),
+		NewDoubleCodePair(45, 2.5),
+		NewDoubleCodePair(47, 2.0),
+		NewDoubleCodePair(49, 3.0),
+		NewDoubleCodePair(140, 4.0),
+		NewShortCodePair(273, 10),
+		NewStringCodePair(0, "ENDSEC"),
+		NewStringCodePair(0, "EOF"),
+	)
+	// the unsupported object is kept as it was read
+	assertEqInt(t, 2, len(drawing.Objects))
+	assertEqString(t, "UNSUPPORTED_OBJECT", drawing.Objects[0].typeString())
+	assertEqUInt64(t, 0x10, uint64(drawing.Objects[0].Handle()))
+	s := drawing.Objects[1].(*MLeaderStyle)
+	assertEqUInt64(t, 0xB0, uint64(s.Handle()))
+	assertEqUInt64(t, 0xD, uint64(s.getOwnerPointer().handle))
+	assert(t, s.ContentType == MLeaderContentTypeBlock, "expected block content type")
+	assert(t, s.LeaderLineType == MLeaderLineTypeSpline, "expected spline leader")
+	assertEqFloat64(t, 5.0, s.DoglegLength)
+	assertEqString(t, "described", s.Description)
+	assertEqFloat64(t, 2.5, s.TextHeight)
+	assertEqVector(t, Vector{X: 2.0, Y: 3.0, Z: 4.0}, s.BlockContentScale)
+	assert(t, s.TextTopAttachment == MLeaderTextAttachmentCenterOfTextOverline, "expected overline attachment")
+}
+
+func TestWriteMLeaderStyle(t *testing.T) {
+	s := NewMLeaderStyle()
+	s.Description = "described"
+	drawing := *NewDrawing()
+	drawing.Objects = append(drawing.Objects, s)
+
+	drawing.Header.Version = R2010
+	actual := drawingCodePairs(t, drawing)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "SECTION"),
+		NewStringCodePair(2, "OBJECTS"),
+		NewStringCodePair(0, "DICTIONARY"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbDictionary"),
+		NewShortCodePair(281, 1),
+		NewStringCodePair(3, "ACAD_MLEADERSTYLE"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbMLeaderStyle"),
+		NewShortCodePair(179, 2),
+		NewShortCodePair(170, 2),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(3, "described"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewShortCodePair(271, 0),
+		NewShortCodePair(272, 9),
+		NewShortCodePair(273, 9),
+	}, actual)
+
+	drawing.Header.Version = R2004
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "MLEADERSTYLE"),
+	}, drawingCodePairs(t, drawing))
+}
+
+func TestRoundTripMLeaderStylePointer(t *testing.T) {
+	s := NewMLeaderStyle()
+	s.DoglegLength = 12.0
+	var item DrawingItem = s
+	m := NewMLeader()
+	m.SetStyle(&item)
+
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2018
+	drawing.Entities = append(drawing.Entities, m)
+	drawing.Objects = append(drawing.Objects, s)
+	drawing = roundTripDrawing(t, &drawing)
+	assertEqInt(t, 3, len(drawing.Objects))
+	actual := drawing.Entities[0].(*MLeader)
+	assert(t, actual.Style() != nil, "expected the style to be bound")
+	style := (*actual.Style()).(*MLeaderStyle)
+	assertEqFloat64(t, 12.0, style.DoglegLength)
+}
+
+func TestRoundTripMLeaderStyleDictionary(t *testing.T) {
+	standard := NewMLeaderStyle()
+	custom := NewMLeaderStyle()
+	custom.Name = "Callout"
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2018
+	drawing.Objects = append(drawing.Objects, standard, custom)
+	drawing = roundTripDrawing(t, &drawing)
+
+	root := drawing.Objects[0].(*Dictionary)
+	assert(t, root.Owner() == nil, "expected the root dictionary to have no owner")
+	item := root.Get("ACAD_MLEADERSTYLE")
+	assert(t, item != nil, "expected the mleader style dictionary")
+	styles := (*item).(*Dictionary)
+	assertEqUInt64(t, uint64(root.Handle()), uint64(styles.getOwnerPointer().handle))
+	assertEqInt(t, 2, len(styles.Entries))
+
+	actual := (*styles.Get("Callout")).(*MLeaderStyle)
+	assertEqString(t, "Callout", actual.Name)
+	assertEqUInt64(t, uint64(styles.Handle()), uint64(actual.getOwnerPointer().handle))
+	assertEqString(t, "Standard", (*styles.Get("Standard")).(*MLeaderStyle).Name)
+
+	// saving again doesn't add more dictionaries or entries
+	drawing = roundTripDrawing(t, &drawing)
+	assertEqInt(t, 4, len(drawing.Objects))
+	assertEqInt(t, 2, len((*drawing.Objects[0].(*Dictionary).Get("ACAD_MLEADERSTYLE")).(*Dictionary).Entries))
+}
@@ -0,0 +1,176 @@
+package dxf
+
+// ModelerGeometry is implemented by the entities whose shape is stored as an ACIS payload.
+type ModelerGeometry interface {
+	Entity
+	// AcisLines returns the payload one line per item, with any continuations rejoined.
+	AcisLines() []string
+	// SetAcisLines replaces the payload, splitting lines that are too long for a single code pair.
+	SetAcisLines(lines []string)
+	modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte)
+}
+
+// maxAcisChunkLength is the longest line written with code 1; the remainder follows with code 3.
+const maxAcisChunkLength = 255
+
+func (b *Body) modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte) {
+	return &b.FormatVersionNumber, &b.CustomData, &b.CustomData2, &b.AcisBinaryData
+}
+
+func (b *Body) AcisLines() []string {
+	return acisLines(b)
+}
+
+func (b *Body) SetAcisLines(lines []string) {
+	setAcisLines(b, lines)
+}
+
+func (r *Region) modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte) {
+	return &r.FormatVersionNumber, &r.CustomData, &r.CustomData2, &r.AcisBinaryData
+}
+
+func (r *Region) AcisLines() []string {
+	return acisLines(r)
+}
+
+func (r *Region) SetAcisLines(lines []string) {
+	setAcisLines(r, lines)
+}
+
+func (s *Solid3D) modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte) {
+	return &s.FormatVersionNumber, &s.CustomData, &s.CustomData2, &s.AcisBinaryData
+}
+
+func (s *Solid3D) AcisLines() []string {
+	return acisLines(s)
+}
+
+func (s *Solid3D) SetAcisLines(lines []string) {
+	setAcisLines(s, lines)
+}
+
+func (s *ExtrudedSurface) modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte) {
+	return &s.FormatVersionNumber, &s.CustomData, &s.CustomData2, &s.AcisBinaryData
+}
+
+func (s *ExtrudedSurface) AcisLines() []string {
+	return acisLines(s)
+}
+
+func (s *ExtrudedSurface) SetAcisLines(lines []string) {
+	setAcisLines(s, lines)
+}
+
+func (s *LoftedSurface) modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte) {
+	return &s.FormatVersionNumber, &s.CustomData, &s.CustomData2, &s.AcisBinaryData
+}
+
+func (s *LoftedSurface) AcisLines() []string {
+	return acisLines(s)
+}
+
+func (s *LoftedSurface) SetAcisLines(lines []string) {
+	setAcisLines(s, lines)
+}
+
+func (s *PlaneSurface) modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte) {
+	return &s.FormatVersionNumber, &s.CustomData, &s.CustomData2, &s.AcisBinaryData
+}
+
+func (s *PlaneSurface) AcisLines() []string {
+	return acisLines(s)
+}
+
+func (s *PlaneSurface) SetAcisLines(lines []string) {
+	setAcisLines(s, lines)
+}
+
+func (s *RevolvedSurface) modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte) {
+	return &s.FormatVersionNumber, &s.CustomData, &s.CustomData2, &s.AcisBinaryData
+}
+
+func (s *RevolvedSurface) AcisLines() []string {
+	return acisLines(s)
+}
+
+func (s *RevolvedSurface) SetAcisLines(lines []string) {
+	setAcisLines(s, lines)
+}
+
+func (s *SweptSurface) modelerData() (formatVersionNumber *int16, data, continuations *[]string, binaryData *[]byte) {
+	return &s.FormatVersionNumber, &s.CustomData, &s.CustomData2, &s.AcisBinaryData
+}
+
+func (s *SweptSurface) AcisLines() []string {
+	return acisLines(s)
+}
+
+func (s *SweptSurface) SetAcisLines(lines []string) {
+	setAcisLines(s, lines)
+}
+
+// acisLines rejoins the code 3 continuations onto the full-length code 1 lines they follow.
+func acisLines(g ModelerGeometry) (lines []string) {
+	_, data, continuations, _ := g.modelerData()
+	next := 0
+	for _, line := range *data {
+		chunk := line
+		for len(chunk) == maxAcisChunkLength && next < len(*continuations) {
+			chunk = (*continuations)[next]
+			next++
+			line += chunk
+		}
+		lines = append(lines, line)
+	}
+	return
+}
+
+func setAcisLines(g ModelerGeometry, lines []string) {
+	_, data, continuations, _ := g.modelerData()
+	*data = []string{}
+	*continuations = []string{}
+	for _, line := range lines {
+		if len(line) < maxAcisChunkLength {
+			*data = append(*data, line)
+			continue
+		}
+
+		// a full-length chunk is always followed by another, even an empty one, so the line can be rejoined
+		*data = append(*data, line[:maxAcisChunkLength])
+		line = line[maxAcisChunkLength:]
+		for len(line) >= maxAcisChunkLength {
+			*continuations = append(*continuations, line[:maxAcisChunkLength])
+			line = line[maxAcisChunkLength:]
+		}
+		*continuations = append(*continuations, line)
+	}
+}
+
+// tryApplyCodePairForModelerGeometry applies the pairs of the AcDbModelerGeometry subclass.
+func tryApplyCodePairForModelerGeometry(g ModelerGeometry, codePair CodePair) bool {
+	formatVersionNumber, data, continuations, _ := g.modelerData()
+	switch codePair.Code {
+	case 70:
+		*formatVersionNumber = codePair.Value.(ShortCodePairValue).Value
+	case 1:
+		*data = append(*data, codePair.Value.(StringCodePairValue).Value)
+	case 3:
+		*continuations = append(*continuations, codePair.Value.(StringCodePairValue).Value)
+	default:
+		return false
+	}
+	return true
+}
+
+func codePairsForModelerGeometry(g ModelerGeometry) (pairs []CodePair) {
+	formatVersionNumber, data, continuations, _ := g.modelerData()
+	pairs = append(pairs, NewStringCodePair(100, "AcDbModelerGeometry"))
+	pairs = append(pairs, NewShortCodePair(70, *formatVersionNumber))
+	for _, line := range *data {
+		pairs = append(pairs, NewStringCodePair(1, line))
+	}
+	for _, line := range *continuations {
+		pairs = append(pairs, NewStringCodePair(3, line))
+	}
+	return
+}
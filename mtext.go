@@ -0,0 +1,450 @@
+package dxf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// mtextChunkLength is the longest string written to a single MTEXT code pair; longer text continues in the
+// ExtendedText chunks.
+const mtextChunkLength = 250
+
+// MTextRun is a piece of MText content sharing one format.  Paragraph breaks appear as newlines in Text.
+type MTextRun struct {
+	Text            string
+	FontName        string // the font name, or empty for the text style's font
+	IsBold          bool
+	IsItalic        bool
+	Height          float64 // the text height, or 0 for the entity's height
+	HeightScale     float64 // a factor applied to the height
+	WidthFactor     float64
+	ObliqueAngle    float64 // in degrees
+	Color           Color   // ByEntity() for the entity's color
+	TrueColor       int     // the raw 24-bit color value, or -1 if there is none
+	IsUnderlined    bool
+	IsOverlined     bool
+	IsStrikethrough bool
+	Stack           *MTextStack // the stacked fraction shown by this run, if any
+}
+
+// MTextStack is a stacked fraction.
+type MTextStack struct {
+	Numerator   string
+	Denominator string
+	Separator   rune // '/' draws a horizontal bar, '#' a diagonal bar, and '^' no bar, as for tolerances
+}
+
+// NewMTextRun creates a new MTextRun with the formatting of the entity.
+func NewMTextRun(text string) *MTextRun {
+	return &MTextRun{
+		Text:        text,
+		HeightScale: 1.0,
+		WidthFactor: 1.0,
+		Color:       ByEntity(),
+		TrueColor:   -1,
+	}
+}
+
+// format returns the run without its content, for comparing formats.
+func (r MTextRun) format() MTextRun {
+	r.Text = ""
+	r.Stack = nil
+	return r
+}
+
+// FullText returns the complete, still formatted, content of the MText.
+func (t *MText) FullText() string {
+	return strings.Join(t.ExtendedText, "") + t.Text
+}
+
+// SetFullText sets the formatted content of the MText, spreading it over ExtendedText chunks when it's longer than
+// 250 characters.
+func (t *MText) SetFullText(text string) {
+	t.ExtendedText = []string{}
+	runes := []rune(text)
+	for len(runes) > mtextChunkLength {
+		t.ExtendedText = append(t.ExtendedText, string(runes[:mtextChunkLength]))
+		runes = runes[mtextChunkLength:]
+	}
+	t.Text = string(runes)
+}
+
+// Runs returns the formatted runs of the MText content.
+func (t *MText) Runs() []MTextRun {
+	return ParseMText(t.FullText())
+}
+
+// SetRuns replaces the MText content with the escaped form of `runs`.
+func (t *MText) SetRuns(runs []MTextRun) {
+	t.SetFullText(FormatMText(runs))
+}
+
+// xAxis returns the world direction of the text.  The rotation angle is used when the x-axis direction is zero or
+// still the default, since files often only write the rotation.
+func (t *MText) xAxis() Vector {
+	normal := t.ExtrusionDirection.Normalize()
+	if normal.IsZero(0.0) {
+		normal = *NewZAxis()
+	}
+	direction := t.XAxisDirection
+	if direction.IsZero(0.0) || direction == *NewXAxis() {
+		direction = Vector{X: math.Cos(t.RotationAngle), Y: math.Sin(t.RotationAngle), Z: 0.0}.OcsToWcs(normal)
+	}
+	return direction.Normalize()
+}
+
+// PlainText returns the MText content without its formatting codes.
+func (t *MText) PlainText() string {
+	return MTextPlainText(t.FullText())
+}
+
+// MTextPlainText returns formatted MText content without its formatting codes.
+func MTextPlainText(text string) string {
+	var builder strings.Builder
+	for _, run := range ParseMText(text) {
+		builder.WriteString(run.Text)
+	}
+	return builder.String()
+}
+
+// specialCharacter returns the character represented by the `%%<code>` sequence shared by all text entities.
+func specialCharacter(code byte) (rune, bool) {
+	switch code {
+	case 'c', 'C':
+		return 'Ø', true
+	case 'd', 'D':
+		return '°', true
+	case 'p', 'P':
+		return '±', true
+	case '%':
+		return '%', true
+	}
+	return 0, false
+}
+
+// mtextParser splits formatted MText content into runs.
+type mtextParser struct {
+	text    string
+	pos     int
+	current MTextRun
+	groups  []MTextRun
+	content strings.Builder
+	runs    []MTextRun
+}
+
+// ParseMText splits formatted MText content into runs of the same format.
+func ParseMText(text string) []MTextRun {
+	p := &mtextParser{text: text, current: *NewMTextRun("")}
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		switch {
+		case c == '{':
+			p.pos++
+			p.groups = append(p.groups, p.current)
+		case c == '}':
+			p.pos++
+			if len(p.groups) > 0 {
+				p.setFormat(p.groups[len(p.groups)-1])
+				p.groups = p.groups[:len(p.groups)-1]
+			}
+		case c == '\\' && p.pos+1 < len(p.text):
+			p.pos += 2
+			p.readCode(p.text[p.pos-1])
+		case c == '%' && strings.HasPrefix(p.text[p.pos:], "%%") && p.pos+2 < len(p.text):
+			p.readSpecialCharacter()
+		default:
+			r, size := utf8.DecodeRuneInString(p.text[p.pos:])
+			p.pos += size
+			p.content.WriteRune(r)
+		}
+	}
+	p.flush()
+	return p.runs
+}
+
+// flush ends the run collected so far.
+func (p *mtextParser) flush() {
+	if p.content.Len() == 0 {
+		return
+	}
+	run := p.current
+	run.Text = p.content.String()
+	p.content.Reset()
+	if count := len(p.runs); count > 0 && p.runs[count-1].Stack == nil && p.runs[count-1].format() == run.format() {
+		p.runs[count-1].Text += run.Text
+		return
+	}
+	p.runs = append(p.runs, run)
+}
+
+func (p *mtextParser) setFormat(format MTextRun) {
+	if format.format() != p.current.format() {
+		p.flush()
+	}
+	p.current = format
+}
+
+// readArgument returns the value of a code up to its terminating semicolon.
+func (p *mtextParser) readArgument() string {
+	end := strings.IndexByte(p.text[p.pos:], ';')
+	if end < 0 {
+		value := p.text[p.pos:]
+		p.pos = len(p.text)
+		return value
+	}
+	value := p.text[p.pos : p.pos+end]
+	p.pos += end + 1
+	return value
+}
+
+// readScale parses a height or width value, where a trailing `x` makes it relative to the current value.
+func readScale(value string) (scale float64, isRelative bool, ok bool) {
+	isRelative = strings.HasSuffix(strings.ToLower(value), "x")
+	if isRelative {
+		value = value[:len(value)-1]
+	}
+	scale, err := strconv.ParseFloat(value, 64)
+	return scale, isRelative, err == nil
+}
+
+func (p *mtextParser) readCode(code byte) {
+	format := p.current
+	switch code {
+	case 'P', 'N', 'X':
+		// paragraph, column and dimension text line breaks
+		p.content.WriteRune('\n')
+	case '~':
+		p.content.WriteRune('\u00a0')
+	case 'L':
+		format.IsUnderlined = true
+	case 'l':
+		format.IsUnderlined = false
+	case 'O':
+		format.IsOverlined = true
+	case 'o':
+		format.IsOverlined = false
+	case 'K':
+		format.IsStrikethrough = true
+	case 'k':
+		format.IsStrikethrough = false
+	case 'f', 'F':
+		parts := strings.Split(p.readArgument(), "|")
+		format.FontName = parts[0]
+		format.IsBold = false
+		format.IsItalic = false
+		for _, part := range parts[1:] {
+			switch {
+			case strings.HasPrefix(part, "b"):
+				format.IsBold = part == "b1"
+			case strings.HasPrefix(part, "i"):
+				format.IsItalic = part == "i1"
+			}
+		}
+	case 'H':
+		if height, isRelative, ok := readScale(p.readArgument()); ok {
+			switch {
+			case !isRelative:
+				format.Height = height
+				format.HeightScale = 1.0
+			case format.Height > 0.0:
+				format.Height *= height
+			default:
+				format.HeightScale *= height
+			}
+		}
+	case 'W':
+		if width, isRelative, ok := readScale(p.readArgument()); ok {
+			if isRelative {
+				width *= format.WidthFactor
+			}
+			format.WidthFactor = width
+		}
+	case 'Q':
+		if angle, err := strconv.ParseFloat(p.readArgument(), 64); err == nil {
+			format.ObliqueAngle = angle
+		}
+	case 'C':
+		if color, err := strconv.Atoi(p.readArgument()); err == nil {
+			format.Color = Color(color)
+			format.TrueColor = -1
+		}
+	case 'c':
+		if color, err := strconv.Atoi(p.readArgument()); err == nil {
+			format.TrueColor = color
+		}
+	case 'A', 'T', 'p':
+		// alignment, tracking and paragraph properties don't change the runs
+		p.readArgument()
+	case 'S':
+		p.readStack()
+	case 'M':
+		// a multibyte character in a code page this library doesn't decode
+		if strings.HasPrefix(p.text[p.pos:], "+") && p.pos+6 <= len(p.text) {
+			p.pos += 6
+			p.content.WriteRune(utf8.RuneError)
+			return
+		}
+		p.content.WriteByte(code)
+	case 'U':
+		if strings.HasPrefix(p.text[p.pos:], "+") && p.pos+5 <= len(p.text) {
+			if value, err := strconv.ParseUint(p.text[p.pos+1:p.pos+5], 16, 32); err == nil {
+				p.pos += 5
+				p.content.WriteRune(rune(value))
+				return
+			}
+		}
+		p.content.WriteByte(code)
+	default:
+		// an escaped character such as `\\`, `\{` or `\}`
+		p.pos--
+		r, size := utf8.DecodeRuneInString(p.text[p.pos:])
+		p.pos += size
+		p.content.WriteRune(r)
+	}
+	p.setFormat(format)
+}
+
+func (p *mtextParser) readSpecialCharacter() {
+	code := p.text[p.pos+2]
+	if r, ok := specialCharacter(code); ok {
+		p.pos += 3
+		p.content.WriteRune(r)
+		return
+	}
+	digits := 0
+	for digits < 3 && p.pos+2+digits < len(p.text) && p.text[p.pos+2+digits] >= '0' && p.text[p.pos+2+digits] <= '9' {
+		digits++
+	}
+	if digits == 3 {
+		value, _ := strconv.Atoi(p.text[p.pos+2 : p.pos+5])
+		p.pos += 5
+		p.content.WriteRune(rune(value))
+		return
+	}
+	p.pos += 2
+	p.content.WriteString("%%")
+}
+
+func (p *mtextParser) readStack() {
+	stack := &MTextStack{}
+	var builder strings.Builder
+	for p.pos < len(p.text) && p.text[p.pos] != ';' {
+		c := p.text[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.text):
+			// an escaped separator
+			builder.WriteByte(p.text[p.pos+1])
+			p.pos += 2
+			continue
+		case stack.Separator == 0 && (c == '/' || c == '#' || c == '^'):
+			stack.Numerator = builder.String()
+			stack.Separator = rune(c)
+			builder.Reset()
+		default:
+			builder.WriteByte(c)
+		}
+		p.pos++
+	}
+	p.pos++
+	if stack.Separator == 0 {
+		p.content.WriteString(builder.String())
+		return
+	}
+	stack.Denominator = builder.String()
+
+	p.flush()
+	run := p.current
+	run.Stack = stack
+	separator := "/"
+	if stack.Separator == '^' {
+		separator = " "
+	}
+	run.Text = stack.Numerator + separator + stack.Denominator
+	p.runs = append(p.runs, run)
+}
+
+// FormatMText returns the escaped MText content showing `runs`; runs with a format other than the entity's are
+// wrapped in their own group.
+func FormatMText(runs []MTextRun) string {
+	var builder strings.Builder
+	defaultFormat := NewMTextRun("").format()
+	for _, run := range runs {
+		codes := mtextFormatCodes(run)
+		isGrouped := run.format() != defaultFormat
+		if isGrouped {
+			builder.WriteByte('{')
+		}
+		builder.WriteString(codes)
+		if run.Stack != nil {
+			escape := strings.NewReplacer("\\", "\\\\", "/", "\\/", "#", "\\#", "^", "\\^", ";", "\\;")
+			separator := run.Stack.Separator
+			if separator == 0 {
+				separator = '/'
+			}
+			builder.WriteString(fmt.Sprintf("\\S%s%c%s;", escape.Replace(run.Stack.Numerator), separator, escape.Replace(run.Stack.Denominator)))
+		} else {
+			builder.WriteString(escapeMText(run.Text))
+		}
+		if isGrouped {
+			builder.WriteByte('}')
+		}
+	}
+	return builder.String()
+}
+
+// escapeMText escapes the characters of `text` that would otherwise read as MText codes.  A percent sign followed by
+// another one, or ending the text where the next run may start with one, becomes `%%%` so no `%%` code appears.
+func escapeMText(text string) string {
+	text = strings.NewReplacer("\\", "\\\\", "{", "\\{", "}", "\\}", "\r\n", "\\P", "\n", "\\P", "\u00a0", "\\~").Replace(text)
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '%' && (i+1 == len(text) || text[i+1] == '%') {
+			builder.WriteString("%%%")
+		} else {
+			builder.WriteByte(text[i])
+		}
+	}
+	return builder.String()
+}
+
+// mtextFormatCodes returns the codes switching from the entity's format to the format of `run`.
+func mtextFormatCodes(run MTextRun) string {
+	var builder strings.Builder
+	number := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	if len(run.FontName) > 0 || run.IsBold || run.IsItalic {
+		builder.WriteString(fmt.Sprintf("\\f%s|b%d|i%d;", run.FontName, shortFromBool(run.IsBold), shortFromBool(run.IsItalic)))
+	}
+	if run.Height > 0.0 {
+		builder.WriteString(fmt.Sprintf("\\H%s;", number(run.Height)))
+	}
+	if run.HeightScale != 1.0 && run.HeightScale != 0.0 {
+		builder.WriteString(fmt.Sprintf("\\H%sx;", number(run.HeightScale)))
+	}
+	if run.WidthFactor != 1.0 && run.WidthFactor != 0.0 {
+		builder.WriteString(fmt.Sprintf("\\W%s;", number(run.WidthFactor)))
+	}
+	if run.ObliqueAngle != 0.0 {
+		builder.WriteString(fmt.Sprintf("\\Q%s;", number(run.ObliqueAngle)))
+	}
+	if run.Color != ByEntity() {
+		builder.WriteString(fmt.Sprintf("\\C%d;", run.Color))
+	}
+	if run.TrueColor >= 0 {
+		builder.WriteString(fmt.Sprintf("\\c%d;", run.TrueColor))
+	}
+	if run.IsUnderlined {
+		builder.WriteString("\\L")
+	}
+	if run.IsOverlined {
+		builder.WriteString("\\O")
+	}
+	if run.IsStrikethrough {
+		builder.WriteString("\\K")
+	}
+	return builder.String()
+}
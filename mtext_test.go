@@ -0,0 +1,101 @@
+package dxf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMTextRuns(t *testing.T) {
+	runs := ParseMText(`plain{\fArial|b1|i0|c0|p34;\H2.5;\C1;bold}\Pline \L2{\H0.5x;\S1/2;}\l%%c10`)
+	assertEqInt(t, 6, len(runs))
+	assertEqString(t, "plain", runs[0].Text)
+	assert(t, runs[0].Color == ByEntity(), "expected the entity color")
+
+	bold := runs[1]
+	assertEqString(t, "bold", bold.Text)
+	assertEqString(t, "Arial", bold.FontName)
+	assert(t, bold.IsBold && !bold.IsItalic, "expected bold, not italic")
+	assertEqFloat64(t, 2.5, bold.Height)
+	assert(t, bold.Color == Color(1), "expected red")
+
+	assertEqString(t, "\nline ", runs[2].Text)
+	assert(t, !runs[2].IsUnderlined, "expected no underline")
+	assertEqString(t, "2", runs[3].Text)
+	assert(t, runs[3].IsUnderlined, "expected an underline")
+
+	fraction := runs[4]
+	assertEqString(t, "1/2", fraction.Text)
+	assertEqFloat64(t, 0.5, fraction.HeightScale)
+	assert(t, fraction.IsUnderlined, "expected the group to inherit the underline")
+	assertEqString(t, "1", fraction.Stack.Numerator)
+	assertEqString(t, "2", fraction.Stack.Denominator)
+	assert(t, fraction.Stack.Separator == '/', "expected a horizontal bar")
+	assertEqString(t, "Ø10", runs[5].Text)
+	assert(t, !runs[5].IsUnderlined, "expected the underline to end")
+
+	assertEqString(t, "plainbold\nline 21/2Ø10", MTextPlainText(`plain{\fArial|b1|i0|c0|p34;\H2.5;\C1;bold}\Pline \L2{\H0.5x;\S1/2;}\l%%c10`))
+}
+
+func TestMTextPlainTextEscapes(t *testing.T) {
+	mtext := NewMText()
+	mtext.ExtendedText = []string{`\A1;{\T1.1;a\\b\{c\}}`}
+	mtext.Text = `\U+00B0 %%d%%p%%%\~x %%065 \Stol\^1^-2;`
+	assertEqString(t, "a\\b{c}° °±% x A tol^1 -2", mtext.PlainText())
+	runs := mtext.Runs()
+	stack := runs[len(runs)-1].Stack
+	assertEqString(t, "tol^1", stack.Numerator)
+	assert(t, stack.Separator == '^', "expected a tolerance stack")
+}
+
+func TestFormatMTextRoundTrip(t *testing.T) {
+	bold := NewMTextRun("bold {text}")
+	bold.FontName = "Arial"
+	bold.IsBold = true
+	bold.Height = 2.0
+	bold.TrueColor = 0xFF0000
+	fraction := NewMTextRun("")
+	fraction.Stack = &MTextStack{Numerator: "1", Denominator: "4", Separator: '#'}
+	runs := []MTextRun{*NewMTextRun("a\\b\nc"), *bold, *fraction}
+
+	text := FormatMText(runs)
+	assertEqString(t, `a\\b\Pc{\fArial|b1|i0;\H2;\c16711680;bold \{text\}}\S1#4;`, text)
+	parsed := ParseMText(text)
+	assertEqInt(t, 3, len(parsed))
+	assertEqString(t, "a\\b\nc", parsed[0].Text)
+	assert(t, parsed[1].format() == bold.format(), "expected the bold format")
+	assertEqString(t, "bold {text}", parsed[1].Text)
+	assertEqString(t, "4", parsed[2].Stack.Denominator)
+}
+
+func TestFormatMTextRoundTripPercentSigns(t *testing.T) {
+	for _, value := range []string{"%%c", "100%%d", "%%%", "50% off", "%"} {
+		runs := ParseMText(FormatMText([]MTextRun{*NewMTextRun(value)}))
+		assertEqInt(t, 1, len(runs))
+		assertEqString(t, value, runs[0].Text)
+	}
+	assertEqString(t, "50% off", FormatMText([]MTextRun{*NewMTextRun("50% off")}))
+
+	// a trailing percent sign can't combine with a percent sign starting the next run
+	underlined := NewMTextRun("%c")
+	underlined.IsUnderlined = true
+	runs := ParseMText(FormatMText([]MTextRun{*NewMTextRun("%"), *underlined}))
+	assertEqInt(t, 2, len(runs))
+	assertEqString(t, "%", runs[0].Text)
+	assertEqString(t, "%c", runs[1].Text)
+}
+
+func TestMTextSetRunsSplitsLongText(t *testing.T) {
+	mtext := NewMText()
+	mtext.SetRuns([]MTextRun{*NewMTextRun(strings.Repeat("a", 300) + "\n" + strings.Repeat("b", 200))})
+	assertEqInt(t, 2, len(mtext.ExtendedText))
+	assertEqInt(t, 250, len(mtext.ExtendedText[0]))
+	assertEqInt(t, 2, len(mtext.Text))
+	assertEqString(t, strings.Repeat("a", 300)+"\n"+strings.Repeat("b", 200), mtext.PlainText())
+
+	actual := allCodePairs(mtext, R2000)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(3, strings.Repeat("a", 250)),
+		NewStringCodePair(3, strings.Repeat("a", 50)+`\P`+strings.Repeat("b", 198)),
+		NewStringCodePair(1, "bb"),
+	}, actual)
+}
@@ -0,0 +1,548 @@
+package dxf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// nurbsSpan returns the index of the knot span containing `t`, such that knots[span] <= t < knots[span+1].  The last
+// non-empty span is returned for the end of the domain.
+func nurbsSpan(degree int, knots []float64, controlPointCount int, t float64) int {
+	n := controlPointCount - 1
+	if t >= knots[n+1] {
+		span := n
+		for span > degree && knots[span] == knots[span+1] {
+			span--
+		}
+		return span
+	}
+	if t <= knots[degree] {
+		span := degree
+		for span < n && knots[span] == knots[span+1] {
+			span++
+		}
+		return span
+	}
+
+	low := degree
+	high := n + 1
+	span := (low + high) / 2
+	for t < knots[span] || t >= knots[span+1] {
+		if t < knots[span] {
+			high = span
+		} else {
+			low = span
+		}
+		span = (low + high) / 2
+	}
+	return span
+}
+
+// homogeneousPoint is a weighted control point with its coordinates premultiplied by the weight.
+type homogeneousPoint struct{ x, y, z, w float64 }
+
+func toHomogeneous(cp ControlPoint) homogeneousPoint {
+	w := cp.Weight
+	if w <= 0.0 {
+		// weights must be positive; treat a missing weight as non-rational
+		w = 1.0
+	}
+	return homogeneousPoint{cp.Point.X * w, cp.Point.Y * w, cp.Point.Z * w, w}
+}
+
+func (h homogeneousPoint) lerp(other homogeneousPoint, alpha float64) homogeneousPoint {
+	return homogeneousPoint{
+		(1.0-alpha)*h.x + alpha*other.x,
+		(1.0-alpha)*h.y + alpha*other.y,
+		(1.0-alpha)*h.z + alpha*other.z,
+		(1.0-alpha)*h.w + alpha*other.w,
+	}
+}
+
+func (h homogeneousPoint) controlPoint() ControlPoint {
+	return ControlPoint{Point: Point{X: h.x / h.w, Y: h.y / h.w, Z: h.z / h.w}, Weight: h.w}
+}
+
+// deBoor evaluates the B-spline with the given homogeneous control points at `t`.
+func deBoor(degree int, knots []float64, points []homogeneousPoint, t float64) homogeneousPoint {
+	span := nurbsSpan(degree, knots, len(points), t)
+	d := make([]homogeneousPoint, degree+1)
+	copy(d, points[span-degree:span+1])
+	for r := 1; r <= degree; r++ {
+		for j := degree; j >= r; j-- {
+			i := span - degree + j
+			denominator := knots[i+degree-r+1] - knots[i]
+			alpha := 0.0
+			if denominator != 0.0 {
+				alpha = (t - knots[i]) / denominator
+			}
+			d[j] = d[j-1].lerp(d[j], alpha)
+		}
+	}
+	return d[degree]
+}
+
+// nurbsPoint evaluates a rational B-spline at `t` using de Boor's algorithm on homogeneous coordinates.
+func nurbsPoint(degree int, knots []float64, controlPoints []ControlPoint, t float64) Point {
+	points := make([]homogeneousPoint, len(controlPoints))
+	for i, cp := range controlPoints {
+		points[i] = toHomogeneous(cp)
+	}
+	result := deBoor(degree, knots, points, t)
+	return Point{X: result.x / result.w, Y: result.y / result.w, Z: result.z / result.w}
+}
+
+// nurbsDerivative evaluates the first derivative of a rational B-spline at `t`.  The derivative of the homogeneous
+// curve is a B-spline of one lower degree whose control points are the scaled differences of the originals.
+func nurbsDerivative(degree int, knots []float64, controlPoints []ControlPoint, t float64) Vector {
+	points := make([]homogeneousPoint, len(controlPoints))
+	for i, cp := range controlPoints {
+		points[i] = toHomogeneous(cp)
+	}
+	value := deBoor(degree, knots, points, t)
+
+	differences := make([]homogeneousPoint, len(points)-1)
+	for i := range differences {
+		scale := 0.0
+		if span := knots[i+degree+1] - knots[i+1]; span != 0.0 {
+			scale = float64(degree) / span
+		}
+		differences[i] = homogeneousPoint{
+			(points[i+1].x - points[i].x) * scale,
+			(points[i+1].y - points[i].y) * scale,
+			(points[i+1].z - points[i].z) * scale,
+			(points[i+1].w - points[i].w) * scale,
+		}
+	}
+	var derivative homogeneousPoint
+	if degree == 1 {
+		derivative = differences[nurbsSpan(degree, knots, len(points), t)-1]
+	} else {
+		derivative = deBoor(degree-1, knots[1:len(knots)-1], differences, t)
+	}
+
+	// quotient rule: C' = (A' - w' C) / w
+	return Vector{
+		X: (derivative.x - derivative.w*value.x/value.w) / value.w,
+		Y: (derivative.y - derivative.w*value.y/value.w) / value.w,
+		Z: (derivative.z - derivative.w*value.z/value.w) / value.w,
+	}
+}
+
+// hasValidKnots determines whether the spline's control points and knots describe an evaluable curve.
+func (s *Spline) hasValidKnots() bool {
+	degree := s.DegreeOfCurve
+	count := len(s.ControlPoints)
+	if degree < 1 || count <= degree || len(s.KnotValues) != count+degree+1 {
+		return false
+	}
+	for i := 1; i < len(s.KnotValues); i++ {
+		if s.KnotValues[i] < s.KnotValues[i-1] {
+			return false
+		}
+	}
+	return s.KnotValues[degree] < s.KnotValues[count]
+}
+
+// parameterRange returns the parameter domain of a spline with valid knots.
+func (s *Spline) parameterRange() (start, end float64) {
+	return s.KnotValues[s.DegreeOfCurve], s.KnotValues[len(s.ControlPoints)]
+}
+
+// samplePoints evaluates the spline at `perSpan` evenly spaced parameters within each non-empty knot span.  Splines
+// without valid knots are sampled at their control points, or their fit points if there are no control points.
+func (s *Spline) samplePoints(perSpan int) (points []Point) {
+	if !s.hasValidKnots() {
+		for _, cp := range s.ControlPoints {
+			points = append(points, cp.Point)
+		}
+		if len(points) == 0 {
+			points = append(points, s.FitPoints...)
+		}
+		return
+	}
+
+	start, end := s.parameterRange()
+	for i := s.DegreeOfCurve; i < len(s.ControlPoints); i++ {
+		t0 := s.KnotValues[i]
+		t1 := s.KnotValues[i+1]
+		if t1 <= t0 || t1 <= start || t0 >= end {
+			continue
+		}
+		for j := 0; j < perSpan; j++ {
+			t := t0 + (t1-t0)*float64(j)/float64(perSpan)
+			points = append(points, nurbsPoint(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, t))
+		}
+	}
+	points = append(points, nurbsPoint(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, end))
+	return
+}
+
+// nurbsBasis returns the values of the `degree + 1` non-zero basis functions at `t` within knot span `span`.
+func nurbsBasis(degree int, knots []float64, span int, t float64) []float64 {
+	basis := make([]float64, degree+1)
+	left := make([]float64, degree+1)
+	right := make([]float64, degree+1)
+	basis[0] = 1.0
+	for j := 1; j <= degree; j++ {
+		left[j] = t - knots[span+1-j]
+		right[j] = knots[span+j] - t
+		saved := 0.0
+		for r := 0; r < j; r++ {
+			temp := basis[r] / (right[r+1] + left[j-r])
+			basis[r] = saved + right[r+1]*temp
+			saved = left[j-r] * temp
+		}
+		basis[j] = saved
+	}
+	return basis
+}
+
+// chordLengthParameters assigns each point a parameter in [0, 1] proportional to the distance along the polygon
+// through the points.
+func chordLengthParameters(points []Point) (parameters []float64, total float64) {
+	parameters = make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		total += points[i].DistanceTo(points[i-1])
+		parameters[i] = total
+	}
+	for i := range parameters {
+		if total > 0.0 {
+			parameters[i] /= total
+		} else {
+			parameters[i] = float64(i) / float64(len(points)-1)
+		}
+	}
+	return
+}
+
+// estimateEndDerivative approximates the derivative with respect to the parameter at the first of three points from
+// the parabola through them.
+func estimateEndDerivative(q0, q1, q2 Point, u0, u1, u2 float64) Vector {
+	v0 := Vector{X: q0.X, Y: q0.Y, Z: q0.Z}
+	v1 := Vector{X: q1.X, Y: q1.Y, Z: q1.Z}
+	v2 := Vector{X: q2.X, Y: q2.Y, Z: q2.Z}
+	return v0.Scale((2.0*u0 - u1 - u2) / ((u0 - u1) * (u0 - u2))).
+		Add(v1.Scale((u0 - u2) / ((u1 - u0) * (u1 - u2)))).
+		Add(v2.Scale((u0 - u1) / ((u2 - u0) * (u2 - u1))))
+}
+
+// interpolateCubic computes the control points and knots of the cubic B-spline that passes through every point, using
+// chord length parameterization.  The tangent directions at the ends are honoured when they are non-zero, otherwise
+// they are estimated from the points.
+func interpolateCubic(points []Point, startTangent, endTangent Vector) (knots []float64, controlPoints []ControlPoint) {
+	// coincident points would produce a repeated parameter
+	distinct := make([]Point, 0, len(points))
+	for i, p := range points {
+		if i == 0 || p.DistanceTo(distinct[len(distinct)-1]) > 0.0 {
+			distinct = append(distinct, p)
+		}
+	}
+	points = distinct
+	n := len(points) - 1
+	if n < 1 {
+		return
+	}
+
+	parameters, total := chordLengthParameters(points)
+	var startDerivative, endDerivative Vector
+	switch {
+	case !startTangent.IsZero(0.0):
+		startDerivative = startTangent.Normalize().Scale(total)
+	case n >= 2:
+		startDerivative = estimateEndDerivative(points[0], points[1], points[2], parameters[0], parameters[1], parameters[2])
+	default:
+		startDerivative = points[1].Sub(points[0])
+	}
+	switch {
+	case !endTangent.IsZero(0.0):
+		endDerivative = endTangent.Normalize().Scale(total)
+	case n >= 2:
+		endDerivative = estimateEndDerivative(points[n], points[n-1], points[n-2], parameters[n], parameters[n-1], parameters[n-2])
+	default:
+		endDerivative = points[1].Sub(points[0])
+	}
+
+	// the interior knots are the parameters of the interior points
+	knots = []float64{0.0, 0.0, 0.0, 0.0}
+	knots = append(knots, parameters[1:n]...)
+	knots = append(knots, 1.0, 1.0, 1.0, 1.0)
+
+	// each point contributes an equation, plus the derivative at each end
+	count := n + 3
+	matrix := make([][]float64, count)
+	rhs := make([][3]float64, count)
+	for i := range matrix {
+		matrix[i] = make([]float64, count)
+	}
+	row := 0
+	addPoint := func(k int) {
+		span := nurbsSpan(3, knots, count, parameters[k])
+		for j, value := range nurbsBasis(3, knots, span, parameters[k]) {
+			matrix[row][span-3+j] = value
+		}
+		rhs[row] = [3]float64{points[k].X, points[k].Y, points[k].Z}
+		row++
+	}
+
+	// C'(0) = 3 / u1 * (P1 - P0) and C'(1) = 3 / (1 - u(n-1)) * (P(n+2) - P(n+1))
+	addPoint(0)
+	startFactor := 3.0 / knots[4]
+	matrix[row][0] = -startFactor
+	matrix[row][1] = startFactor
+	rhs[row] = [3]float64{startDerivative.X, startDerivative.Y, startDerivative.Z}
+	row++
+	for k := 1; k < n; k++ {
+		addPoint(k)
+	}
+	endFactor := 3.0 / (1.0 - knots[count-1])
+	matrix[row][count-2] = -endFactor
+	matrix[row][count-1] = endFactor
+	rhs[row] = [3]float64{endDerivative.X, endDerivative.Y, endDerivative.Z}
+	row++
+	addPoint(n)
+
+	solution := solveLinearSystem(matrix, rhs)
+	controlPoints = make([]ControlPoint, count)
+	for i, s := range solution {
+		controlPoints[i] = ControlPoint{Point: Point{X: s[0], Y: s[1], Z: s[2]}, Weight: 1.0}
+	}
+	return
+}
+
+// solveLinearSystem solves `matrix * x = rhs` for three right hand sides at once using Gaussian elimination with
+// partial pivoting.  The inputs are modified.
+func solveLinearSystem(matrix [][]float64, rhs [][3]float64) [][3]float64 {
+	size := len(matrix)
+	for col := 0; col < size; col++ {
+		pivot := col
+		for r := col + 1; r < size; r++ {
+			if math.Abs(matrix[r][col]) > math.Abs(matrix[pivot][col]) {
+				pivot = r
+			}
+		}
+		matrix[col], matrix[pivot] = matrix[pivot], matrix[col]
+		rhs[col], rhs[pivot] = rhs[pivot], rhs[col]
+		if matrix[col][col] == 0.0 {
+			continue
+		}
+		for r := col + 1; r < size; r++ {
+			factor := matrix[r][col] / matrix[col][col]
+			if factor == 0.0 {
+				continue
+			}
+			for c := col; c < size; c++ {
+				matrix[r][c] -= factor * matrix[col][c]
+			}
+			for k := 0; k < 3; k++ {
+				rhs[r][k] -= factor * rhs[col][k]
+			}
+		}
+	}
+
+	solution := make([][3]float64, size)
+	for r := size - 1; r >= 0; r-- {
+		for k := 0; k < 3; k++ {
+			sum := rhs[r][k]
+			for c := r + 1; c < size; c++ {
+				sum -= matrix[r][c] * solution[c][k]
+			}
+			if matrix[r][r] != 0.0 {
+				solution[r][k] = sum / matrix[r][r]
+			}
+		}
+	}
+	return solution
+}
+
+// validateKnots returns an error if the spline can't be evaluated.
+func (s *Spline) validateKnots() error {
+	if !s.hasValidKnots() {
+		return errors.New("spline does not have a valid set of control points and knots")
+	}
+	return nil
+}
+
+// ParameterRange returns the range of parameter values over which the spline is defined.
+func (s *Spline) ParameterRange() (start, end float64, err error) {
+	if err = s.validateKnots(); err != nil {
+		return
+	}
+	start, end = s.parameterRange()
+	return
+}
+
+// PointAt returns the point on the spline at parameter `t`.
+func (s *Spline) PointAt(t float64) (Point, error) {
+	if err := s.validateKnots(); err != nil {
+		return Point{}, err
+	}
+	return nurbsPoint(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, t), nil
+}
+
+// DerivativeAt returns the first derivative of the spline with respect to its parameter at `t`.
+func (s *Spline) DerivativeAt(t float64) (Vector, error) {
+	if err := s.validateKnots(); err != nil {
+		return Vector{}, err
+	}
+	return nurbsDerivative(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, t), nil
+}
+
+// TangentAt returns the unit tangent of the spline at parameter `t`.
+func (s *Spline) TangentAt(t float64) (Vector, error) {
+	derivative, err := s.DerivativeAt(t)
+	if err != nil {
+		return Vector{}, err
+	}
+	return derivative.Normalize(), nil
+}
+
+// knotMultiplicity returns the number of times `t` appears in the knot vector.
+func (s *Spline) knotMultiplicity(t float64) (multiplicity int) {
+	for _, knot := range s.KnotValues {
+		if knot == t {
+			multiplicity++
+		}
+	}
+	return
+}
+
+// InsertKnot adds the knot `t` to the spline, along with one more control point, without changing the shape of the
+// curve.  The knot must lie within the interior of the parameter range and may not already appear as many times as the
+// degree of the curve.
+func (s *Spline) InsertKnot(t float64) error {
+	if err := s.validateKnots(); err != nil {
+		return err
+	}
+	start, end := s.parameterRange()
+	if t <= start || t >= end {
+		return fmt.Errorf("knot %v is outside of the parameter range (%v, %v)", t, start, end)
+	}
+	degree := s.DegreeOfCurve
+	if s.knotMultiplicity(t) >= degree {
+		return fmt.Errorf("knot %v already has a multiplicity of %d", t, degree)
+	}
+
+	// Boehm's algorithm; only the control points of the span containing the knot are affected
+	span := nurbsSpan(degree, s.KnotValues, len(s.ControlPoints), t)
+	controlPoints := make([]ControlPoint, len(s.ControlPoints)+1)
+	copy(controlPoints, s.ControlPoints[:span-degree+1])
+	for i := span - degree + 1; i <= span; i++ {
+		alpha := (t - s.KnotValues[i]) / (s.KnotValues[i+degree] - s.KnotValues[i])
+		controlPoints[i] = toHomogeneous(s.ControlPoints[i-1]).lerp(toHomogeneous(s.ControlPoints[i]), alpha).controlPoint()
+	}
+	copy(controlPoints[span+1:], s.ControlPoints[span:])
+
+	knots := make([]float64, 0, len(s.KnotValues)+1)
+	knots = append(knots, s.KnotValues[:span+1]...)
+	knots = append(knots, t)
+	knots = append(knots, s.KnotValues[span+1:]...)
+
+	s.KnotValues = knots
+	s.ControlPoints = controlPoints
+	return nil
+}
+
+// Split divides the spline at parameter `t` into two new splines that together trace the same curve.  The original
+// spline is not modified and the new splines have no handle or fit points.
+func (s *Spline) Split(t float64) (first, second *Spline, err error) {
+	work := *s
+	work.KnotValues = append([]float64{}, s.KnotValues...)
+	work.ControlPoints = append([]ControlPoint{}, s.ControlPoints...)
+	if err = work.validateKnots(); err != nil {
+		return
+	}
+	start, end := work.parameterRange()
+	if t <= start || t >= end {
+		err = fmt.Errorf("unable to split spline at %v outside of the parameter range (%v, %v)", t, start, end)
+		return
+	}
+
+	// once the knot is repeated `degree` times, a control point lies on the curve and divides it
+	degree := work.DegreeOfCurve
+	for work.knotMultiplicity(t) < degree {
+		if err = work.InsertKnot(t); err != nil {
+			return
+		}
+	}
+	index := 0
+	for work.KnotValues[index] != t {
+		index++
+	}
+
+	startTangent := nurbsDerivative(degree, work.KnotValues, work.ControlPoints, start).Normalize()
+	splitTangent := nurbsDerivative(degree, work.KnotValues, work.ControlPoints, t).Normalize()
+	endTangent := nurbsDerivative(degree, work.KnotValues, work.ControlPoints, end).Normalize()
+	newPart := func(knots []float64, controlPoints []ControlPoint, startTangent, endTangent Vector) *Spline {
+		part := work
+		part.SetHandle(0)
+		part.SetIsClosed(false)
+		part.SetIsPeriodic(false)
+		part.KnotValues = knots
+		part.ControlPoints = controlPoints
+		part.FitPoints = []Point{}
+		part.weights = []float64{}
+		part.StartTangent = startTangent
+		part.EndTangent = endTangent
+		return &part
+	}
+
+	firstKnots := append(append([]float64{}, work.KnotValues[:index+degree]...), t)
+	firstControlPoints := append([]ControlPoint{}, work.ControlPoints[:index]...)
+	secondKnots := append([]float64{t}, work.KnotValues[index:]...)
+	secondControlPoints := append([]ControlPoint{}, work.ControlPoints[index-1:]...)
+	first = newPart(firstKnots, firstControlPoints, startTangent, splitTangent)
+	second = newPart(secondKnots, secondControlPoints, splitTangent, endTangent)
+	return
+}
+
+// ComputeControlPoints replaces the control points and knots with those of the cubic spline passing through each of
+// the fit points.  The curve leaves the first fit point in the direction of `StartTangent` and arrives at the last
+// in the direction of `EndTangent`; when either is a zero vector that direction is estimated from the fit points.
+func (s *Spline) ComputeControlPoints() error {
+	knots, controlPoints := interpolateCubic(s.FitPoints, s.StartTangent, s.EndTangent)
+	if len(controlPoints) == 0 {
+		return errors.New("at least two distinct fit points are required")
+	}
+
+	s.DegreeOfCurve = 3
+	s.KnotValues = knots
+	s.ControlPoints = controlPoints
+	s.weights = []float64{}
+	s.SetIsRational(false)
+	return nil
+}
+
+// ComputeFitPoints replaces the fit points and end tangents with values taken from the curve described by the control
+// points and knots.  The fit points are the curve's points at each distinct knot; spans of curves that aren't
+// non-rational cubics are also sampled between the knots.  Interpolating the fit points with `ComputeControlPoints`
+// yields a curve through the same points which may deviate slightly from the original between them.
+func (s *Spline) ComputeFitPoints() error {
+	if err := s.validateKnots(); err != nil {
+		return err
+	}
+
+	piecesPerSpan := 1
+	if s.DegreeOfCurve != 3 || s.shouldWriteWeights() {
+		piecesPerSpan = s.DegreeOfCurve + 1
+	}
+	start, end := s.parameterRange()
+	fitPoints := []Point{nurbsPoint(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, start)}
+	for i := s.DegreeOfCurve; i < len(s.ControlPoints); i++ {
+		t0 := s.KnotValues[i]
+		t1 := s.KnotValues[i+1]
+		if t1 <= t0 {
+			continue
+		}
+		for j := 1; j <= piecesPerSpan; j++ {
+			t := t0 + (t1-t0)*float64(j)/float64(piecesPerSpan)
+			fitPoints = append(fitPoints, nurbsPoint(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, t))
+		}
+	}
+
+	s.FitPoints = fitPoints
+	s.StartTangent = nurbsDerivative(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, start).Normalize()
+	s.EndTangent = nurbsDerivative(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, end).Normalize()
+	return nil
+}
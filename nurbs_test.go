@@ -0,0 +1,152 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+// quarterCircleSpline creates a rational quadratic spline tracing the unit circle from the X axis to the Y axis.
+func quarterCircleSpline() *Spline {
+	spline := NewSpline()
+	spline.DegreeOfCurve = 2
+	spline.KnotValues = []float64{0.0, 0.0, 0.0, 1.0, 1.0, 1.0}
+	spline.ControlPoints = []ControlPoint{
+		{Point{1.0, 0.0, 0.0}, 1.0},
+		{Point{1.0, 1.0, 0.0}, math.Sqrt(0.5)},
+		{Point{0.0, 1.0, 0.0}, 1.0},
+	}
+	return spline
+}
+
+func TestSplinePointAndTangent(t *testing.T) {
+	spline := quarterCircleSpline()
+	p, err := spline.PointAt(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearPoint(t, Point{math.Sqrt(0.5), math.Sqrt(0.5), 0.0}, p)
+
+	tangent, err := spline.TangentAt(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearVector(t, Vector{-math.Sqrt(0.5), math.Sqrt(0.5), 0.0}, tangent)
+	tangent, _ = spline.TangentAt(0.0)
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, tangent)
+}
+
+func TestSplineDerivativeMatchesFiniteDifference(t *testing.T) {
+	spline := NewSpline()
+	spline.DegreeOfCurve = 3
+	spline.KnotValues = []float64{0.0, 0.0, 0.0, 0.0, 0.4, 1.0, 1.0, 1.0, 1.0}
+	spline.ControlPoints = []ControlPoint{
+		{Point{0.0, 0.0, 0.0}, 1.0},
+		{Point{1.0, 2.0, 0.0}, 2.0},
+		{Point{3.0, 2.0, 1.0}, 0.5},
+		{Point{4.0, 0.0, 0.0}, 1.0},
+		{Point{5.0, 1.0, 0.0}, 1.0},
+	}
+	for _, parameter := range []float64{0.1, 0.4, 0.7} {
+		derivative, err := spline.DerivativeAt(parameter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := 1.0e-6
+		before, _ := spline.PointAt(parameter - h)
+		after, _ := spline.PointAt(parameter + h)
+		expected := after.Sub(before).Scale(1.0 / (2.0 * h))
+		assert(t, derivative.Sub(expected).Length() < 1.0e-4, "derivative differs from the finite difference")
+	}
+}
+
+func TestSplineWithoutKnotsCannotBeEvaluated(t *testing.T) {
+	spline := NewSpline()
+	_, err := spline.PointAt(0.0)
+	assert(t, err != nil, "expected an error")
+}
+
+func TestSplineInsertKnotPreservesShape(t *testing.T) {
+	spline := quarterCircleSpline()
+	original := quarterCircleSpline()
+	if err := spline.InsertKnot(0.3); err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 4, len(spline.ControlPoints))
+	assertEqInt(t, 7, len(spline.KnotValues))
+	for _, parameter := range []float64{0.0, 0.2, 0.3, 0.6, 1.0} {
+		expected, _ := original.PointAt(parameter)
+		actual, _ := spline.PointAt(parameter)
+		assertNearPoint(t, expected, actual)
+	}
+
+	spline.InsertKnot(0.3)
+	assert(t, spline.InsertKnot(0.3) != nil, "expected an error when exceeding the degree")
+	assert(t, spline.InsertKnot(1.0) != nil, "expected an error outside of the parameter range")
+}
+
+func TestSplineSplit(t *testing.T) {
+	spline := quarterCircleSpline()
+	first, second, err := spline.Split(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, len(spline.ControlPoints))
+
+	middle := Point{math.Sqrt(0.5), math.Sqrt(0.5), 0.0}
+	start, end, _ := first.ParameterRange()
+	p, _ := first.PointAt(start)
+	assertNearPoint(t, Point{1.0, 0.0, 0.0}, p)
+	p, _ = first.PointAt(end)
+	assertNearPoint(t, middle, p)
+	p, _ = first.PointAt((start + end) / 2.0)
+	assertNearFloat64(t, 1.0, p.DistanceTo(*NewOrigin()))
+
+	start, end, _ = second.ParameterRange()
+	p, _ = second.PointAt(start)
+	assertNearPoint(t, middle, p)
+	p, _ = second.PointAt(end)
+	assertNearPoint(t, Point{0.0, 1.0, 0.0}, p)
+}
+
+func TestSplineComputeControlPointsPassesThroughFitPoints(t *testing.T) {
+	spline := NewSpline()
+	spline.FitPoints = []Point{{0.0, 0.0, 0.0}, {1.0, 2.0, 0.0}, {3.0, 3.0, 0.0}, {6.0, 1.0, 0.0}, {7.0, 0.0, 0.0}}
+	spline.StartTangent = Vector{0.0, 1.0, 0.0}
+	spline.EndTangent = Vector{1.0, 0.0, 0.0}
+	if err := spline.ComputeControlPoints(); err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, spline.DegreeOfCurve)
+	assertEqInt(t, 7, len(spline.ControlPoints))
+
+	tangent, _ := spline.TangentAt(0.0)
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, tangent)
+	tangent, _ = spline.TangentAt(1.0)
+	assertNearVector(t, Vector{1.0, 0.0, 0.0}, tangent)
+
+	// the interior knots are the parameters of the interior fit points
+	for i, fitPoint := range spline.FitPoints[1:4] {
+		p, _ := spline.PointAt(spline.KnotValues[4+i])
+		assertNearPoint(t, fitPoint, p)
+	}
+}
+
+func TestSplineComputeFitPointsRoundTrip(t *testing.T) {
+	spline := NewSpline()
+	spline.FitPoints = []Point{{0.0, 0.0, 0.0}, {1.0, 2.0, 0.0}, {3.0, 3.0, 0.0}, {6.0, 1.0, 0.0}}
+	spline.StartTangent = Vector{0.0, 1.0, 0.0}
+	spline.EndTangent = Vector{1.0, -1.0, 0.0}
+	spline.ComputeControlPoints()
+	original := spline.FitPoints
+
+	spline.FitPoints = nil
+	if err := spline.ComputeFitPoints(); err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, len(original), len(spline.FitPoints))
+	for i := range original {
+		assertNearPoint(t, original[i], spline.FitPoints[i])
+	}
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, spline.StartTangent)
+	assertNearVector(t, Vector{1.0, -1.0, 0.0}.Normalize(), spline.EndTangent)
+}
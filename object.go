@@ -0,0 +1,202 @@
+package dxf
+
+import (
+	"errors"
+	"strings"
+)
+
+// Object represents an item in the OBJECTS section of a drawing.
+type Object interface {
+	DrawingItem
+	typeString() string
+	minVersion() AcadVersion
+	maxVersion() AcadVersion
+	pointers() []*pointer
+	getOwnerPointer() pointer
+	setOwnerPointerHandle(h Handle)
+	tryApplyCodePair(codePair CodePair)
+	codePairs(version AcadVersion) []CodePair
+}
+
+func createObject(objectType string) (object Object, ok bool) {
+	ok = true
+	switch objectType {
+	case "DICTIONARY":
+		object = NewDictionary()
+	case "MLEADERSTYLE":
+		object = NewMLeaderStyle()
+	default:
+		ok = false
+	}
+	return
+}
+
+func readObjects(np CodePair, reader codePairReader) (objects []Object, nextPair CodePair, err error) {
+	nextPair = np
+	for err == nil && !nextPair.isEndSection() {
+		if nextPair.Code != 0 {
+			err = errors.New("expected 0/<object-type>")
+			return
+		}
+
+		objectType := nextPair.Value.(StringCodePairValue).Value
+		object, ok := createObject(objectType)
+		if !ok {
+			// an unsupported object is kept as it was read
+			object = &unknownObject{objectType: objectType}
+		}
+		nextPair, err = reader.readCodePair()
+		for err == nil && nextPair.Code != 0 {
+			object.tryApplyCodePair(nextPair)
+			nextPair, err = reader.readCodePair()
+		}
+
+		objects = append(objects, object)
+	}
+
+	return
+}
+
+// tryApplyCodePairForObject applies the code pairs common to all objects.
+func tryApplyCodePairForObject(object Object, codePair CodePair) bool {
+	switch codePair.Code {
+	case 5:
+		object.SetHandle(handleFromString(codePair.Value.(StringCodePairValue).Value))
+	case 330:
+		object.setOwnerPointerHandle(handleFromString(codePair.Value.(StringCodePairValue).Value))
+	default:
+		return false
+	}
+	return true
+}
+
+func codePairsForObject(object Object, version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, object.typeString()))
+	if object.Handle() != 0 {
+		pairs = append(pairs, NewStringCodePair(5, stringFromHandle(object.Handle())))
+	}
+	if object.getOwnerPointer().handle != 0 {
+		pairs = append(pairs, NewStringCodePair(330, stringFromHandle(object.getOwnerPointer().handle)))
+	}
+	return
+}
+
+func writeObjectsSection(objects []Object, writer codePairWriter, version AcadVersion) error {
+	pairs := make([]CodePair, 0)
+	for _, object := range objects {
+		if version >= object.minVersion() && version <= object.maxVersion() {
+			pairs = append(pairs, object.codePairs(version)...)
+		}
+	}
+
+	err := writeSectionStart(writer, "OBJECTS")
+	if err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		err = writer.writeCodePair(pair)
+		if err != nil {
+			return err
+		}
+	}
+	return writeSectionEnd(writer)
+}
+
+// unknownObject is an object of an unsupported type.  Its code pairs are written back unchanged apart from its handle
+// and owner, so anything it refers to keeps its handle only as long as that item does.
+type unknownObject struct {
+	handle       Handle
+	pointerOwner pointer
+	objectType   string
+	pairs        []CodePair
+	inGroup      bool
+	readingData  bool
+}
+
+// Handle gets the handle of the object.
+func (o *unknownObject) Handle() Handle {
+	return o.handle
+}
+
+// SetHandle sets the handle of the object.
+func (o *unknownObject) SetHandle(val Handle) {
+	o.handle = val
+}
+
+// Owner gets the owner of the object.
+func (o *unknownObject) Owner() *DrawingItem {
+	return o.pointerOwner.value
+}
+
+// SetOwner sets the owner of the object.
+func (o *unknownObject) SetOwner(val *DrawingItem) {
+	o.pointerOwner.value = val
+}
+
+func (o *unknownObject) getOwnerPointer() pointer {
+	return o.pointerOwner
+}
+
+func (o *unknownObject) setOwnerPointerHandle(h Handle) {
+	o.pointerOwner.handle = h
+}
+
+func (o *unknownObject) pointers() []*pointer {
+	return []*pointer{&o.pointerOwner}
+}
+
+func (o *unknownObject) typeString() string {
+	return o.objectType
+}
+
+func (o *unknownObject) minVersion() AcadVersion {
+	return R13
+}
+
+func (o *unknownObject) maxVersion() AcadVersion {
+	return R2018
+}
+
+func (o *unknownObject) tryApplyCodePair(codePair CodePair) {
+	o.pairs = append(o.pairs, codePair)
+	if o.readingData {
+		return
+	}
+	switch codePair.Code {
+	case 5:
+		o.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+	case 100:
+		o.readingData = true
+	case 102:
+		// the reactors and extension dictionary are in groups before the owner
+		o.inGroup = strings.HasPrefix(codePair.Value.(StringCodePairValue).Value, "{")
+	case 330:
+		if !o.inGroup {
+			o.pointerOwner.handle = handleFromString(codePair.Value.(StringCodePairValue).Value)
+		}
+	}
+}
+
+func (o *unknownObject) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, o.objectType))
+	inGroup := false
+	readingData := false
+	for _, pair := range o.pairs {
+		if !readingData {
+			switch pair.Code {
+			case 5:
+				pair = NewStringCodePair(5, stringFromHandle(o.handle))
+			case 100:
+				readingData = true
+			case 102:
+				inGroup = strings.HasPrefix(pair.Value.(StringCodePairValue).Value, "{")
+			case 330:
+				if !inGroup && o.pointerOwner.handle != 0 {
+					pair = NewStringCodePair(330, stringFromHandle(o.pointerOwner.handle))
+				}
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+	return
+}
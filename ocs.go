@@ -0,0 +1,135 @@
+package dxf
+
+import (
+	"math"
+)
+
+// arbitraryAxisLimit is the bound on the X and Y components of a normal below which the arbitrary axis algorithm
+// derives the OCS X axis from the world Y axis instead of the world Z axis.
+const arbitraryAxisLimit = 1.0 / 64.0
+
+// ocsZAxis returns the normalized Z axis of the OCS defined by the vector, treating a zero vector as the world Z axis.
+func (v Vector) ocsZAxis() Vector {
+	n := v.Normalize()
+	if n.IsZero(0.0) {
+		return *NewZAxis()
+	}
+	return n
+}
+
+// ArbitraryAxes returns the X and Y axes of the object coordinate system (OCS) whose Z axis is the vector, as
+// specified by the DXF arbitrary axis algorithm.
+func (v Vector) ArbitraryAxes() (xAxis, yAxis Vector) {
+	n := v.ocsZAxis()
+	if math.Abs(n.X) < arbitraryAxisLimit && math.Abs(n.Y) < arbitraryAxisLimit {
+		xAxis = NewYAxis().Cross(n).Normalize()
+	} else {
+		xAxis = NewZAxis().Cross(n).Normalize()
+	}
+	yAxis = n.Cross(xAxis).Normalize()
+	return
+}
+
+// OcsToWcs converts a point in the object coordinate system defined by `normal` to world coordinates.
+func (p Point) OcsToWcs(normal Vector) Point {
+	v := Vector{X: p.X, Y: p.Y, Z: p.Z}.OcsToWcs(normal)
+	return Point{X: v.X, Y: v.Y, Z: v.Z}
+}
+
+// WcsToOcs converts a point in world coordinates to the object coordinate system defined by `normal`.
+func (p Point) WcsToOcs(normal Vector) Point {
+	v := Vector{X: p.X, Y: p.Y, Z: p.Z}.WcsToOcs(normal)
+	return Point{X: v.X, Y: v.Y, Z: v.Z}
+}
+
+// OcsToWcs converts a direction in the object coordinate system defined by `normal` to world coordinates.
+func (v Vector) OcsToWcs(normal Vector) Vector {
+	xAxis, yAxis := normal.ArbitraryAxes()
+	return xAxis.Scale(v.X).Add(yAxis.Scale(v.Y)).Add(normal.ocsZAxis().Scale(v.Z))
+}
+
+// WcsToOcs converts a direction in world coordinates to the object coordinate system defined by `normal`.
+func (v Vector) WcsToOcs(normal Vector) Vector {
+	xAxis, yAxis := normal.ArbitraryAxes()
+	return Vector{X: v.Dot(xAxis), Y: v.Dot(yAxis), Z: v.Dot(normal.ocsZAxis())}
+}
+
+func ocsPointsToWcs(normal Vector, points ...Point) []Point {
+	result := make([]Point, len(points))
+	for i, p := range points {
+		result[i] = p.OcsToWcs(normal)
+	}
+	return result
+}
+
+// WorldPoints returns the center of the circle in world coordinates.
+func (c *Circle) WorldPoints() []Point {
+	return ocsPointsToWcs(c.Normal, c.Center)
+}
+
+// WorldPoints returns the center, start point and end point of the arc in world coordinates.
+func (a *Arc) WorldPoints() []Point {
+	start := a.StartAngle * math.Pi / 180.0
+	end := a.EndAngle * math.Pi / 180.0
+	return ocsPointsToWcs(a.Normal,
+		a.Center,
+		Point{X: a.Center.X + a.Radius*math.Cos(start), Y: a.Center.Y + a.Radius*math.Sin(start), Z: a.Center.Z},
+		Point{X: a.Center.X + a.Radius*math.Cos(end), Y: a.Center.Y + a.Radius*math.Sin(end), Z: a.Center.Z})
+}
+
+// WorldPoints returns the vertices of the polyline in world coordinates.
+func (p *LWPolyline) WorldPoints() []Point {
+	points := make([]Point, len(p.Vertices))
+	for i, v := range p.Vertices {
+		points[i] = Point{X: v.X, Y: v.Y, Z: p.Elevation()}.OcsToWcs(p.ExtrusionDirection)
+	}
+	return points
+}
+
+// WorldPoints returns the vertices of the polyline in world coordinates.
+func (p *Polyline) WorldPoints() []Point {
+	points := make([]Point, len(p.Vertices))
+	for i, v := range p.Vertices {
+		if p.Is3DPolyline() || p.Is3DPolygonMesh() || p.IsPolyfaceMesh() {
+			points[i] = v.Location
+		} else {
+			points[i] = Point{X: v.Location.X, Y: v.Location.Y, Z: p.Location.Z}.OcsToWcs(p.Normal)
+		}
+	}
+	return points
+}
+
+// WorldPoints returns the location and second alignment point of the text in world coordinates.
+func (t *Text) WorldPoints() []Point {
+	return ocsPointsToWcs(t.Normal, t.Location, t.SecondAlignmentPoint)
+}
+
+// WorldPoints returns the location and second alignment point of the attribute in world coordinates.
+func (a *Attribute) WorldPoints() []Point {
+	return ocsPointsToWcs(a.Normal, a.Location, a.SecondAlignmentPoint)
+}
+
+// WorldPoints returns the location and second alignment point of the attribute definition in world coordinates.
+func (a *AttributeDefinition) WorldPoints() []Point {
+	return ocsPointsToWcs(a.Normal, a.Location, a.SecondAlignmentPoint)
+}
+
+// WorldPoints returns the insertion point in world coordinates.
+func (i *Insert) WorldPoints() []Point {
+	return ocsPointsToWcs(i.ExtrusionDirection, i.Location)
+}
+
+// WorldPoints returns the location of the shape in world coordinates.
+func (s *Shape) WorldPoints() []Point {
+	return ocsPointsToWcs(s.ExtrusionDirection, s.Location)
+}
+
+// WorldPoints returns the four corners of the solid in world coordinates.
+func (s *Solid) WorldPoints() []Point {
+	return ocsPointsToWcs(s.ExtrusionDirection, s.FirstCorner, s.SecondCorner, s.ThirdCorner, s.FourthCorner)
+}
+
+// WorldPoints returns the four corners of the trace in world coordinates.
+func (t *Trace) WorldPoints() []Point {
+	return ocsPointsToWcs(t.ExtrusionDirection, t.FirstCorner, t.SecondCorner, t.ThirdCorner, t.FourthCorner)
+}
@@ -0,0 +1,57 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func TestArbitraryAxes(t *testing.T) {
+	xAxis, yAxis := NewZAxis().ArbitraryAxes()
+	assertNearVector(t, *NewXAxis(), xAxis)
+	assertNearVector(t, *NewYAxis(), yAxis)
+
+	xAxis, yAxis = Vector{0.0, 0.0, -1.0}.ArbitraryAxes()
+	assertNearVector(t, Vector{-1.0, 0.0, 0.0}, xAxis)
+	assertNearVector(t, *NewYAxis(), yAxis)
+
+	normal := Vector{1.0, 1.0, 1.0}
+	p := Point{1.0, 2.0, 3.0}
+	assertNearPoint(t, p, p.OcsToWcs(normal).WcsToOcs(normal))
+}
+
+func TestArbitraryAxesNearZAxis(t *testing.T) {
+	// a normal within 1/64 of the Z axis derives its X axis from the world Y axis
+	xAxis, _ := Vector{0.01, 0.0, 1.0}.ArbitraryAxes()
+	assert(t, xAxis.Dot(*NewXAxis()) > 0.99, "expected the OCS X axis to be close to the world X axis")
+	xAxis, _ = Vector{0.1, 0.0, 1.0}.ArbitraryAxes()
+	assertNearFloat64(t, 0.0, xAxis.X)
+}
+
+func TestMirroredArcWorldPoints(t *testing.T) {
+	arc := NewArc()
+	arc.Center = Point{1.0, 2.0, 3.0}
+	arc.Radius = 1.0
+	arc.Normal = Vector{0.0, 0.0, -1.0}
+	arc.StartAngle = 0.0
+	arc.EndAngle = 90.0
+	points := arc.WorldPoints()
+	assertEqInt(t, 3, len(points))
+	assertNearPoint(t, Point{-1.0, 2.0, -3.0}, points[0])
+	assertNearPoint(t, Point{-2.0, 2.0, -3.0}, points[1])
+	assertNearPoint(t, Point{-1.0, 3.0, -3.0}, points[2])
+}
+
+func TestLWPolylineWorldPoints(t *testing.T) {
+	poly := NewLWPolyline()
+	poly.SetElevation(2.0)
+	poly.ExtrusionDirection = Vector{1.0, 0.0, 0.0}
+	poly.Vertices = []LwVertex{{X: 1.0, Y: 0.0}, {X: 0.0, Y: 1.0}}
+	points := poly.WorldPoints()
+	assertNearPoint(t, Point{2.0, 1.0, 0.0}, points[0])
+	assertNearPoint(t, Point{2.0, 0.0, 1.0}, points[1])
+}
+
+func TestVectorOcsRoundTrip(t *testing.T) {
+	normal := Vector{0.3, -0.4, 0.5}
+	v := Vector{1.0, 2.0, 3.0}
+	assertNearVector(t, v, v.WcsToOcs(normal).OcsToWcs(normal))
+}
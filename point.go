@@ -23,3 +23,23 @@ func NewOrigin() *Point {
 func (p *Point) String() string {
 	return fmt.Sprintf("(%s, %s, %s)", formatFloat64Text(p.X), formatFloat64Text(p.Y), formatFloat64Text(p.Z))
 }
+
+// Add returns the point offset by a vector.
+func (p Point) Add(v Vector) Point {
+	return Point{X: p.X + v.X, Y: p.Y + v.Y, Z: p.Z + v.Z}
+}
+
+// Sub returns the vector from `other` to this point.
+func (p Point) Sub(other Point) Vector {
+	return Vector{X: p.X - other.X, Y: p.Y - other.Y, Z: p.Z - other.Z}
+}
+
+// DistanceTo returns the distance between two points.
+func (p Point) DistanceTo(other Point) float64 {
+	return p.Sub(other).Length()
+}
+
+// Lerp returns the point that is `t` of the way from this point to `other`.
+func (p Point) Lerp(other Point, t float64) Point {
+	return p.Add(other.Sub(p).Scale(t))
+}
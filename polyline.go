@@ -0,0 +1,388 @@
+package dxf
+
+import (
+	"math"
+)
+
+// PolylineSegment describes the portion of an LWPolyline or Polyline between two consecutive vertices, in the object
+// coordinate system of the polyline.
+type PolylineSegment struct {
+	Start         Point
+	End           Point
+	Bulge         float64
+	StartingWidth float64
+	EndingWidth   float64
+
+	// Center, Radius, StartAngle and EndAngle describe the arc of a segment with a non-zero bulge.  As with an Arc
+	// entity the angles are in degrees and the arc runs counter-clockwise from StartAngle to EndAngle; a segment with a
+	// negative bulge travels the arc from EndAngle back to StartAngle.
+	Center     Point
+	Radius     float64
+	StartAngle float64
+	EndAngle   float64
+}
+
+// newPolylineSegment creates the segment between two vertices, computing the arc if there is a bulge.
+func newPolylineSegment(start, end Point, bulge, startingWidth, endingWidth float64) PolylineSegment {
+	segment := PolylineSegment{
+		Start:         start,
+		End:           end,
+		Bulge:         bulge,
+		StartingWidth: startingWidth,
+		EndingWidth:   endingWidth,
+	}
+	if segment.IsArc() {
+		center, radius, startAngle, sweep := bulgeArc(start, end, bulge)
+		endAngle := startAngle + sweep
+		if sweep < 0.0 {
+			startAngle, endAngle = endAngle, startAngle
+		}
+		segment.Center = center
+		segment.Radius = radius
+		segment.StartAngle = segmentAngle(startAngle)
+		segment.EndAngle = segmentAngle(endAngle)
+	}
+	return segment
+}
+
+// segmentAngle converts radians to degrees in [0, 360), treating values that round to 360 as zero.
+func segmentAngle(radians float64) float64 {
+	degrees := normalizeAngle(radians * 180.0 / math.Pi)
+	if 360.0-degrees < transformTolerance {
+		degrees = 0.0
+	}
+	return degrees
+}
+
+// IsArc returns true if the segment is a circular arc rather than a straight line.
+func (s PolylineSegment) IsArc() bool {
+	return s.Bulge != 0.0 && (s.Start.X != s.End.X || s.Start.Y != s.End.Y)
+}
+
+// IsClockwise returns true if the segment is an arc that turns clockwise.
+func (s PolylineSegment) IsClockwise() bool {
+	return s.IsArc() && s.Bulge < 0.0
+}
+
+// Sweep returns the angle in degrees swept by an arc segment; negative values indicate a clockwise arc.
+func (s PolylineSegment) Sweep() float64 {
+	if !s.IsArc() {
+		return 0.0
+	}
+	return 4.0 * math.Atan(s.Bulge) * 180.0 / math.Pi
+}
+
+// Length returns the distance along the segment.
+func (s PolylineSegment) Length() float64 {
+	if !s.IsArc() {
+		return s.Start.DistanceTo(s.End)
+	}
+	return s.Radius * math.Abs(s.Sweep()) * math.Pi / 180.0
+}
+
+// area returns the signed area enclosed between the segment and the origin.
+func (s PolylineSegment) area() float64 {
+	// the triangle formed with the origin, plus the region between the chord and the arc
+	area := (s.Start.X*s.End.Y - s.End.X*s.Start.Y) / 2.0
+	if s.IsArc() {
+		sweep := s.Sweep() * math.Pi / 180.0
+		area += s.Radius * s.Radius * (sweep - math.Sin(sweep)) / 2.0
+	}
+	return area
+}
+
+// BulgeFromAngles returns the bulge of a polyline segment that follows an arc from `startAngle` to `endAngle`, in
+// degrees, in the specified direction.
+func BulgeFromAngles(startAngle, endAngle float64, clockwise bool) float64 {
+	sweep := normalizeAngle(endAngle - startAngle)
+	if clockwise {
+		sweep = -normalizeAngle(startAngle - endAngle)
+	}
+	return math.Tan(sweep * math.Pi / 180.0 / 4.0)
+}
+
+// arcVertices returns the points and bulges that trace an arc, splitting a full circle in two since a single bulge
+// can't describe it.  The final point has no bulge.
+func arcVertices(center Point, radius, startAngle, endAngle float64, clockwise bool) (points []Point, bulges []float64) {
+	pointAt := func(angle float64) Point {
+		radians := angle * math.Pi / 180.0
+		return Point{X: center.X + radius*math.Cos(radians), Y: center.Y + radius*math.Sin(radians), Z: center.Z}
+	}
+	if normalizeAngle(endAngle-startAngle) == 0.0 {
+		middle := startAngle + 180.0
+		bulge := BulgeFromAngles(startAngle, middle, clockwise)
+		return []Point{pointAt(startAngle), pointAt(middle), pointAt(startAngle)}, []float64{bulge, bulge, 0.0}
+	}
+	return []Point{pointAt(startAngle), pointAt(endAngle)}, []float64{BulgeFromAngles(startAngle, endAngle, clockwise), 0.0}
+}
+
+// LwVerticesFromArc returns the vertices of an LWPolyline that traces an arc from `startAngle` to `endAngle`, in
+// degrees, in the specified direction.  Equal angles produce a full circle.
+func LwVerticesFromArc(center Point, radius, startAngle, endAngle float64, clockwise bool) []LwVertex {
+	points, bulges := arcVertices(center, radius, startAngle, endAngle, clockwise)
+	vertices := make([]LwVertex, len(points))
+	for i, p := range points {
+		vertices[i] = LwVertex{X: p.X, Y: p.Y, Bulge: bulges[i]}
+	}
+	return vertices
+}
+
+// VerticesFromArc returns the vertices of a 2D Polyline that traces an arc from `startAngle` to `endAngle`, in
+// degrees, in the specified direction.  Equal angles produce a full circle.
+func VerticesFromArc(center Point, radius, startAngle, endAngle float64, clockwise bool) []Vertex {
+	points, bulges := arcVertices(center, radius, startAngle, endAngle, clockwise)
+	vertices := make([]Vertex, len(points))
+	for i, p := range points {
+		vertex := NewVertex()
+		vertex.Location = p
+		vertex.Bulge = bulges[i]
+		vertices[i] = *vertex
+	}
+	return vertices
+}
+
+// polylineVertex is the geometry shared by LwVertex and Vertex.
+type polylineVertex struct {
+	point         Point
+	bulge         float64
+	startingWidth float64
+	endingWidth   float64
+}
+
+func polylineSegments(vertices []polylineVertex, closed bool) []PolylineSegment {
+	count := len(vertices) - 1
+	if closed && len(vertices) > 1 {
+		count = len(vertices)
+	}
+	if count <= 0 {
+		return nil
+	}
+
+	segments := make([]PolylineSegment, count)
+	for i := range segments {
+		start := vertices[i]
+		end := vertices[(i+1)%len(vertices)]
+		segments[i] = newPolylineSegment(start.point, end.point, start.bulge, start.startingWidth, start.endingWidth)
+	}
+	return segments
+}
+
+func segmentsLength(segments []PolylineSegment) (length float64) {
+	for _, segment := range segments {
+		length += segment.Length()
+	}
+	return
+}
+
+// segmentsArea returns the signed area of the region bounded by the segments and the straight line back to the start.
+func segmentsArea(segments []PolylineSegment) (area float64) {
+	if len(segments) == 0 {
+		return
+	}
+	for _, segment := range segments {
+		area += segment.area()
+	}
+	first := segments[0].Start
+	last := segments[len(segments)-1].End
+	area += (last.X*first.Y - first.X*last.Y) / 2.0
+	return
+}
+
+// reversedPolylineVertices reverses the direction of travel.  Each segment's bulge and widths move to the vertex that
+// now starts it.
+func reversedPolylineVertices(vertices []polylineVertex, closed bool) []polylineVertex {
+	n := len(vertices)
+	reversed := make([]polylineVertex, n)
+	for j := range reversed {
+		reversed[j] = polylineVertex{point: vertices[n-1-j].point}
+		source := n - j - 2
+		if source < 0 {
+			if !closed {
+				continue
+			}
+			source += n
+		}
+		reversed[j].bulge = -vertices[source].bulge
+		reversed[j].startingWidth = vertices[source].endingWidth
+		reversed[j].endingWidth = vertices[source].startingWidth
+	}
+	return reversed
+}
+
+func (p *LWPolyline) polylineVertices() []polylineVertex {
+	vertices := make([]polylineVertex, len(p.Vertices))
+	for i, v := range p.Vertices {
+		vertices[i] = polylineVertex{point: Point{X: v.X, Y: v.Y, Z: p.Elevation()}, bulge: v.Bulge, startingWidth: v.StartingWidth, endingWidth: v.EndingWidth}
+		if v.StartingWidth == 0.0 && v.EndingWidth == 0.0 {
+			vertices[i].startingWidth = p.ConstantWidth
+			vertices[i].endingWidth = p.ConstantWidth
+		}
+	}
+	return vertices
+}
+
+// Segments returns the line and arc segments of the polyline in object coordinates.  Vertices without a width report
+// the polyline's constant width.
+func (p *LWPolyline) Segments() []PolylineSegment {
+	return polylineSegments(p.polylineVertices(), p.IsClosed())
+}
+
+// Length returns the distance along the polyline, including the closing segment of a closed polyline.
+func (p *LWPolyline) Length() float64 {
+	return segmentsLength(p.Segments())
+}
+
+// Area returns the signed area enclosed by the polyline in its object coordinate system.  Open polylines are treated as
+// if they were closed.  The area is positive when the vertices run counter-clockwise.
+func (p *LWPolyline) Area() float64 {
+	return segmentsArea(polylineSegments(p.polylineVertices(), true))
+}
+
+// IsCounterClockwise returns true if the vertices run counter-clockwise around the enclosed area when viewed from the
+// extrusion direction.
+func (p *LWPolyline) IsCounterClockwise() bool {
+	return p.Area() > 0.0
+}
+
+// Reverse reverses the direction of the polyline without changing its shape.
+func (p *LWPolyline) Reverse() {
+	reversed := reversedPolylineVertices(p.polylineVertices(), p.IsClosed())
+	vertices := make([]LwVertex, len(reversed))
+	for i, v := range reversed {
+		vertices[i] = p.Vertices[len(p.Vertices)-1-i]
+		vertices[i].Bulge = v.bulge
+		vertices[i].StartingWidth = v.startingWidth
+		vertices[i].EndingWidth = v.endingWidth
+		if v.startingWidth == p.ConstantWidth && v.endingWidth == p.ConstantWidth {
+			vertices[i].StartingWidth = 0.0
+			vertices[i].EndingWidth = 0.0
+		}
+	}
+	p.Vertices = vertices
+}
+
+// Close marks the polyline as closed.  A final vertex that duplicates the first is removed since the closing segment
+// takes its place.
+func (p *LWPolyline) Close() {
+	if p.IsClosed() {
+		return
+	}
+	if n := len(p.Vertices); n > 2 && p.Vertices[0].X == p.Vertices[n-1].X && p.Vertices[0].Y == p.Vertices[n-1].Y {
+		p.Vertices = p.Vertices[:n-1]
+	}
+	p.SetIsClosed(true)
+}
+
+// Open marks the polyline as open.  The closing segment is preserved by appending a copy of the first vertex.
+func (p *LWPolyline) Open() {
+	if !p.IsClosed() {
+		return
+	}
+	if len(p.Vertices) > 1 {
+		last := p.Vertices[0]
+		last.Bulge = 0.0
+		last.StartingWidth = 0.0
+		last.EndingWidth = 0.0
+		p.Vertices = append(p.Vertices, last)
+	}
+	p.SetIsClosed(false)
+}
+
+func (p *Polyline) polylineVertices() []polylineVertex {
+	vertices := make([]polylineVertex, len(p.Vertices))
+	for i, v := range p.Vertices {
+		vertices[i] = polylineVertex{point: v.Location, bulge: v.Bulge, startingWidth: v.StartingWidth, endingWidth: v.EndingWidth}
+		if p.Is3DPolyline() {
+			// 3D polylines have neither arcs nor widths
+			vertices[i] = polylineVertex{point: v.Location}
+			continue
+		}
+		vertices[i].point.Z = p.Location.Z
+		if v.StartingWidth == 0.0 && v.EndingWidth == 0.0 {
+			vertices[i].startingWidth = p.DefaultStartingWidth
+			vertices[i].endingWidth = p.DefaultEndingWidth
+		}
+	}
+	return vertices
+}
+
+// Segments returns the line and arc segments of the polyline.  The segments of a 2D polyline are in its object
+// coordinate system and vertices without a width report the polyline's default widths; the segments of a 3D polyline
+// are straight lines in world coordinates.  Meshes have no segments.
+func (p *Polyline) Segments() []PolylineSegment {
+	if p.IsPolyfaceMesh() || p.Is3DPolygonMesh() {
+		return nil
+	}
+	return polylineSegments(p.polylineVertices(), p.IsClosed())
+}
+
+// Length returns the distance along the polyline, including the closing segment of a closed polyline.
+func (p *Polyline) Length() float64 {
+	return segmentsLength(p.Segments())
+}
+
+// Area returns the signed area enclosed by a 2D polyline in its object coordinate system.  Open polylines are treated as
+// if they were closed.  The area is positive when the vertices run counter-clockwise.
+func (p *Polyline) Area() float64 {
+	if p.IsPolyfaceMesh() || p.Is3DPolygonMesh() {
+		return 0.0
+	}
+	return segmentsArea(polylineSegments(p.polylineVertices(), true))
+}
+
+// IsCounterClockwise returns true if the vertices of a 2D polyline run counter-clockwise around the enclosed area when
+// viewed from the normal.
+func (p *Polyline) IsCounterClockwise() bool {
+	return p.Area() > 0.0
+}
+
+// Reverse reverses the direction of the polyline without changing its shape.
+func (p *Polyline) Reverse() {
+	if p.IsPolyfaceMesh() || p.Is3DPolygonMesh() {
+		return
+	}
+	reversed := reversedPolylineVertices(p.polylineVertices(), p.IsClosed())
+	vertices := make([]Vertex, len(reversed))
+	for i, v := range reversed {
+		vertices[i] = p.Vertices[len(p.Vertices)-1-i]
+		if p.Is3DPolyline() {
+			continue
+		}
+		vertices[i].Bulge = v.bulge
+		vertices[i].StartingWidth = v.startingWidth
+		vertices[i].EndingWidth = v.endingWidth
+		if v.startingWidth == p.DefaultStartingWidth && v.endingWidth == p.DefaultEndingWidth {
+			vertices[i].StartingWidth = 0.0
+			vertices[i].EndingWidth = 0.0
+		}
+	}
+	p.Vertices = vertices
+}
+
+// Close marks the polyline as closed.  A final vertex that duplicates the first is removed since the closing segment
+// takes its place.
+func (p *Polyline) Close() {
+	if p.IsClosed() {
+		return
+	}
+	if n := len(p.Vertices); n > 2 && p.Vertices[0].Location == p.Vertices[n-1].Location {
+		p.Vertices = p.Vertices[:n-1]
+	}
+	p.SetIsClosed(true)
+}
+
+// Open marks the polyline as open.  The closing segment is preserved by appending a copy of the first vertex.
+func (p *Polyline) Open() {
+	if !p.IsClosed() {
+		return
+	}
+	if len(p.Vertices) > 1 {
+		last := p.Vertices[0]
+		last.SetHandle(0)
+		last.Bulge = 0.0
+		last.StartingWidth = 0.0
+		last.EndingWidth = 0.0
+		p.Vertices = append(p.Vertices, last)
+	}
+	p.SetIsClosed(false)
+}
@@ -0,0 +1,339 @@
+package dxf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ToPolyline converts the lightweight polyline to an equivalent 2D Polyline with Vertex entities.  The constant width
+// becomes the default width of the new polyline and of each vertex without its own width.  The new polyline and its
+// vertices have no handles.
+func (p *LWPolyline) ToPolyline() *Polyline {
+	poly := NewPolyline()
+	copyEntityProperties(poly, p)
+	poly.SetHandle(0)
+	poly.SetElevation(0.0)
+	poly.Location = Point{X: 0.0, Y: 0.0, Z: p.Elevation()}
+	poly.Normal = p.ExtrusionDirection
+	poly.Thickness = p.Thickness
+	poly.SetIsClosed(p.IsClosed())
+	poly.SetIsLineTypePatternGeneratedContinuously(p.IsPLineGen())
+	poly.DefaultStartingWidth = p.ConstantWidth
+	poly.DefaultEndingWidth = p.ConstantWidth
+	for _, v := range p.Vertices {
+		vertex := NewVertex()
+		copyEntityProperties(vertex, poly)
+		vertex.setOwnerPointerHandle(Handle(0))
+		vertex.Location = Point{X: v.X, Y: v.Y, Z: 0.0}
+		vertex.StartingWidth = v.StartingWidth
+		vertex.EndingWidth = v.EndingWidth
+		if v.StartingWidth == 0.0 && v.EndingWidth == 0.0 {
+			vertex.StartingWidth = p.ConstantWidth
+			vertex.EndingWidth = p.ConstantWidth
+		}
+		vertex.Bulge = v.Bulge
+		vertex.Identifier = v.ID
+		poly.Vertices = append(poly.Vertices, *vertex)
+	}
+	copyEntityProperties(&poly.seqend, poly)
+	poly.seqend.setOwnerPointerHandle(Handle(0))
+	return poly
+}
+
+// ToLWPolyline converts a 2D Polyline, or a 3D Polyline whose vertices all lie in one plane, to an equivalent
+// lightweight polyline.  The frame control points of a spline fit polyline are omitted, leaving the fitted vertices.
+// An error is returned for meshes and non-planar 3D polylines.
+func (p *Polyline) ToLWPolyline() (*LWPolyline, error) {
+	if p.IsPolyfaceMesh() || p.Is3DPolygonMesh() {
+		return nil, errors.New("unable to convert a polygon or polyface mesh to a lightweight polyline")
+	}
+
+	var vertices []Vertex
+	for _, v := range p.Vertices {
+		if !v.IsSplineFrameControlPoint() {
+			vertices = append(vertices, v)
+		}
+	}
+
+	poly := NewLWPolyline()
+	copyEntityProperties(poly, p)
+	poly.SetHandle(0)
+	poly.Thickness = p.Thickness
+	poly.SetIsClosed(p.IsClosed())
+	poly.SetIsPLineGen(p.IsLineTypePatternGeneratedContinuously())
+	if p.Is3DPolyline() {
+		points := make([]Point, len(vertices))
+		for i, v := range vertices {
+			points[i] = v.Location
+		}
+		normal, ok := planeNormal(points)
+		if !ok {
+			return nil, errors.New("unable to convert a non-planar 3D polyline to a lightweight polyline")
+		}
+		poly.ExtrusionDirection = normal
+		for i, point := range points {
+			ocs := point.WcsToOcs(normal)
+			if i == 0 {
+				poly.SetElevation(ocs.Z)
+			}
+			poly.Vertices = append(poly.Vertices, LwVertex{X: ocs.X, Y: ocs.Y, ID: vertices[i].Identifier})
+		}
+		return poly, nil
+	}
+
+	poly.SetElevation(p.Location.Z)
+	poly.ExtrusionDirection = p.Normal
+	for _, v := range vertices {
+		vertex := LwVertex{X: v.Location.X, Y: v.Location.Y, ID: v.Identifier, StartingWidth: v.StartingWidth, EndingWidth: v.EndingWidth, Bulge: v.Bulge}
+		if v.StartingWidth == 0.0 && v.EndingWidth == 0.0 {
+			vertex.StartingWidth = p.DefaultStartingWidth
+			vertex.EndingWidth = p.DefaultEndingWidth
+		}
+		poly.Vertices = append(poly.Vertices, vertex)
+	}
+
+	// a width shared by every vertex is stored once
+	if len(poly.Vertices) > 0 {
+		width := poly.Vertices[0].StartingWidth
+		for _, v := range poly.Vertices {
+			if v.StartingWidth != width || v.EndingWidth != width {
+				return poly, nil
+			}
+		}
+		poly.ConstantWidth = width
+		for i := range poly.Vertices {
+			poly.Vertices[i].StartingWidth = 0.0
+			poly.Vertices[i].EndingWidth = 0.0
+		}
+	}
+	return poly, nil
+}
+
+// planeNormal returns the normal of the plane containing every point, preferring one that points towards positive Z.
+// Collinear points lie in the XY plane when they can.
+func planeNormal(points []Point) (normal Vector, ok bool) {
+	if len(points) == 0 {
+		return *NewZAxis(), true
+	}
+
+	origin := points[0]
+	extent := 1.0
+	for _, p := range points {
+		extent = math.Max(extent, p.DistanceTo(origin))
+	}
+	normal = newellNormal(points)
+	tolerance := transformTolerance * extent
+	if normal.Length() <= tolerance*tolerance {
+		// the points are collinear; find the line through them
+		var direction Vector
+		for _, p := range points {
+			if offset := p.Sub(origin); offset.Length() > direction.Length() {
+				direction = offset
+			}
+		}
+		normal = *NewZAxis()
+		if direction.Length() > tolerance && math.Abs(direction.Normalize().Z) > transformTolerance {
+			_, normal = direction.Normalize().ArbitraryAxes()
+		}
+	}
+	normal = normal.Normalize()
+	if normal.Z < 0.0 {
+		normal = normal.Scale(-1.0)
+	}
+
+	for _, p := range points {
+		if math.Abs(p.Sub(origin).Dot(normal)) > tolerance {
+			return Vector{}, false
+		}
+	}
+	return normal, true
+}
+
+// newellNormal returns the unnormalized normal of the polygon through the points, whose length is twice the area.
+// Newell's method is robust for concave polygons.
+func newellNormal(points []Point) (normal Vector) {
+	for i, p := range points {
+		next := points[(i+1)%len(points)]
+		normal.X += (p.Y - next.Y) * (p.Z + next.Z)
+		normal.Y += (p.Z - next.Z) * (p.X + next.X)
+		normal.Z += (p.X - next.X) * (p.Y + next.Y)
+	}
+	return
+}
+
+// explodeSegments creates a Line or Arc for each segment of a polyline in the specified object coordinate system.
+func explodeSegments(source Entity, segments []PolylineSegment, normal Vector, thickness float64) []Entity {
+	entities := make([]Entity, 0, len(segments))
+	for _, segment := range segments {
+		var e Entity
+		switch {
+		case segment.IsArc():
+			arc := NewArc()
+			arc.Center = segment.Center
+			arc.Radius = segment.Radius
+			arc.StartAngle = segment.StartAngle
+			arc.EndAngle = segment.EndAngle
+			arc.Normal = normal
+			arc.Thickness = thickness
+			e = arc
+		case segment.Start != segment.End:
+			line := NewLine()
+			line.P1 = segment.Start.OcsToWcs(normal)
+			line.P2 = segment.End.OcsToWcs(normal)
+			line.ExtrusionDirection = normal
+			line.Thickness = thickness
+			e = line
+		default:
+			continue
+		}
+		copyEntityProperties(e, source)
+		e.SetHandle(0)
+		e.SetElevation(0.0)
+		entities = append(entities, e)
+	}
+	return entities
+}
+
+// Explode returns a Line or Arc for each segment of the polyline.  Segment widths are not preserved.
+func (p *LWPolyline) Explode() []Entity {
+	return explodeSegments(p, p.Segments(), p.ExtrusionDirection, p.Thickness)
+}
+
+// Explode returns a Line or Arc for each segment of a 2D or 3D polyline.  Segment widths are not preserved and an error
+// is returned for meshes.
+func (p *Polyline) Explode() ([]Entity, error) {
+	if p.IsPolyfaceMesh() || p.Is3DPolygonMesh() {
+		return nil, errors.New("unable to explode a polygon or polyface mesh into lines and arcs")
+	}
+	if p.Is3DPolyline() {
+		return explodeSegments(p, p.Segments(), *NewZAxis(), 0.0), nil
+	}
+	return explodeSegments(p, p.Segments(), p.Normal, p.Thickness), nil
+}
+
+// joinPiece is a Line or Arc in world coordinates; the bulge is relative to the normal of an Arc.
+type joinPiece struct {
+	start  Point
+	end    Point
+	bulge  float64
+	normal Vector
+	isArc  bool
+}
+
+// JoinLWPolyline creates a lightweight polyline from a sequence of Line and Arc entities, each of which must start or
+// end within `tolerance` of the end of the previous one.  Entities are reversed as needed to follow the chain and the
+// polyline is closed if the last entity returns to the start of the first.  Every entity must lie in one plane and the
+// new polyline takes its properties from the first entity.
+func JoinLWPolyline(entities []Entity, tolerance float64) (*LWPolyline, error) {
+	if len(entities) == 0 {
+		return nil, errors.New("no entities to join")
+	}
+
+	pieces := make([]joinPiece, len(entities))
+	var points []Point
+	for i, e := range entities {
+		switch ent := e.(type) {
+		case *Line:
+			pieces[i] = joinPiece{start: ent.P1, end: ent.P2}
+		case *Arc:
+			sweep := normalizeAngle(ent.EndAngle - ent.StartAngle)
+			if sweep == 0.0 {
+				sweep = 360.0
+			}
+			if sweep == 360.0 {
+				return nil, fmt.Errorf("unable to join entity %d; full circle arcs can't be joined", i)
+			}
+			worldPoints := ent.WorldPoints()
+			pieces[i] = joinPiece{start: worldPoints[1], end: worldPoints[2], bulge: math.Tan(sweep * math.Pi / 180.0 / 4.0), normal: ent.Normal.Normalize(), isArc: true}
+		default:
+			return nil, fmt.Errorf("unable to join entity of type %s", e.typeString())
+		}
+		points = append(points, pieces[i].start, pieces[i].end)
+	}
+
+	// the first arc defines the plane, otherwise it's found from the points
+	var normal Vector
+	for _, piece := range pieces {
+		if piece.isArc {
+			normal = piece.normal
+			break
+		}
+	}
+	if normal.IsZero(0.0) {
+		var ok bool
+		if normal, ok = planeNormal(points); !ok {
+			return nil, errors.New("unable to join entities that don't lie in one plane")
+		}
+	}
+
+	// orient each piece to follow on from the previous one
+	if len(pieces) > 1 {
+		next := pieces[1]
+		first := pieces[0]
+		endConnects := first.end.DistanceTo(next.start) <= tolerance || first.end.DistanceTo(next.end) <= tolerance
+		if !endConnects {
+			pieces[0] = first.reversed()
+		}
+	}
+	for i := 1; i < len(pieces); i++ {
+		previousEnd := pieces[i-1].end
+		switch {
+		case pieces[i].start.DistanceTo(previousEnd) <= tolerance:
+		case pieces[i].end.DistanceTo(previousEnd) <= tolerance:
+			pieces[i] = pieces[i].reversed()
+		default:
+			return nil, fmt.Errorf("entity %d doesn't connect to the previous entity", i)
+		}
+	}
+
+	poly := NewLWPolyline()
+	copyEntityProperties(poly, entities[0])
+	poly.SetHandle(0)
+	poly.ExtrusionDirection = normal
+	switch ent := entities[0].(type) {
+	case *Line:
+		poly.Thickness = ent.Thickness
+	case *Arc:
+		poly.Thickness = ent.Thickness
+	}
+
+	elevation := pieces[0].start.WcsToOcs(normal).Z
+	poly.SetElevation(elevation)
+	addVertex := func(p Point, bulge float64) error {
+		ocs := p.WcsToOcs(normal)
+		if math.Abs(ocs.Z-elevation) > tolerance {
+			return errors.New("unable to join entities that don't lie in one plane")
+		}
+		poly.Vertices = append(poly.Vertices, LwVertex{X: ocs.X, Y: ocs.Y, Bulge: bulge})
+		return nil
+	}
+	for _, piece := range pieces {
+		bulge := piece.bulge
+		if piece.isArc {
+			switch {
+			case piece.normal.Sub(normal).IsZero(transformTolerance):
+			case piece.normal.Add(normal).IsZero(transformTolerance):
+				// an arc viewed from behind turns the other way
+				bulge = -bulge
+			default:
+				return nil, errors.New("unable to join entities that don't lie in one plane")
+			}
+		}
+		if err := addVertex(piece.start, bulge); err != nil {
+			return nil, err
+		}
+	}
+
+	last := pieces[len(pieces)-1].end
+	if len(pieces) > 1 && last.DistanceTo(pieces[0].start) <= tolerance {
+		poly.SetIsClosed(true)
+	} else if err := addVertex(last, 0.0); err != nil {
+		return nil, err
+	}
+	return poly, nil
+}
+
+func (p joinPiece) reversed() joinPiece {
+	return joinPiece{start: p.end, end: p.start, bulge: -p.bulge, normal: p.normal, isArc: p.isArc}
+}
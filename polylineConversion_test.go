@@ -0,0 +1,180 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLWPolylineToPolylineRoundTrip(t *testing.T) {
+	lw := roundedSquare()
+	lw.SetLayer("outline")
+	lw.SetElevation(2.0)
+	lw.ConstantWidth = 0.5
+	lw.Vertices[2].StartingWidth = 1.0
+	lw.Vertices[2].EndingWidth = 0.75
+
+	poly := lw.ToPolyline()
+	assert(t, poly.IsClosed(), "expected a closed polyline")
+	assert(t, !poly.Is3DPolyline(), "expected a 2D polyline")
+	assertEqString(t, "outline", poly.Layer())
+	assertNearFloat64(t, 2.0, poly.Location.Z)
+	assertEqInt(t, 4, len(poly.Vertices))
+	assertNearFloat64(t, 1.0, poly.Vertices[1].Bulge)
+	assertNearFloat64(t, 0.5, poly.Vertices[0].StartingWidth)
+	assertNearFloat64(t, 0.75, poly.Vertices[2].EndingWidth)
+	assertEqString(t, "outline", poly.Vertices[3].Layer())
+	assertNearFloat64(t, lw.Area(), poly.Area())
+
+	back, err := poly.ToLWPolyline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, back.IsClosed(), "expected a closed polyline")
+	assertNearFloat64(t, 2.0, back.Elevation())
+	assertEqInt(t, 4, len(back.Vertices))
+	assertNearFloat64(t, 0.5, back.Vertices[0].StartingWidth)
+	assertNearFloat64(t, 1.0, back.Vertices[2].StartingWidth)
+	assertNearFloat64(t, lw.Length(), back.Length())
+}
+
+func TestLWPolylineToPolylineWritesVerticesAndSeqend(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Entities = append(drawing.Entities, roundedSquare().ToPolyline())
+	actual := drawing.String()
+	assertContains(t, join(
+		"  0", "VERTEX",
+	), actual)
+	assertContains(t, join(
+		"  0", "SEQEND",
+	), actual)
+
+	parsed := roundTripDrawing(t, &drawing)
+	poly := parsed.Entities[0].(*Polyline)
+	assertEqInt(t, 4, len(poly.Vertices))
+}
+
+func TestPolylineSharedWidthBecomesConstantWidth(t *testing.T) {
+	poly := NewPolyline()
+	poly.DefaultStartingWidth = 0.25
+	poly.DefaultEndingWidth = 0.25
+	poly.Vertices = VerticesFromArc(*NewOrigin(), 1.0, 0.0, 90.0, false)
+	lw, err := poly.ToLWPolyline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearFloat64(t, 0.25, lw.ConstantWidth)
+	assertNearFloat64(t, 0.0, lw.Vertices[0].StartingWidth)
+}
+
+func TestPlanar3DPolylineToLWPolyline(t *testing.T) {
+	poly := NewPolyline()
+	poly.SetIs3DPolyline(true)
+	poly.SetIsClosed(true)
+	for _, p := range []Point{{0.0, 0.0, 1.0}, {2.0, 0.0, 1.0}, {2.0, 0.0, 3.0}, {0.0, 0.0, 3.0}} {
+		vertex := NewVertex()
+		vertex.Location = p
+		vertex.SetIs3DPolylineVertex(true)
+		poly.Vertices = append(poly.Vertices, *vertex)
+	}
+
+	lw, err := poly.ToLWPolyline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearFloat64(t, 0.0, math.Abs(lw.ExtrusionDirection.Dot(*NewXAxis())))
+	assertNearFloat64(t, 1.0, math.Abs(lw.ExtrusionDirection.Dot(*NewYAxis())))
+	assertNearFloat64(t, 4.0, math.Abs(lw.Area()))
+	for i, p := range lw.WorldPoints() {
+		assertNearPoint(t, poly.Vertices[i].Location, p)
+	}
+
+	poly.Vertices[3].Location.Y = 1.0
+	_, err = poly.ToLWPolyline()
+	assert(t, err != nil, "expected an error for a non-planar polyline")
+}
+
+func TestExplodeLWPolyline(t *testing.T) {
+	lw := roundedSquare()
+	lw.SetLayer("outline")
+	lw.ExtrusionDirection = Vector{0.0, 0.0, -1.0}
+	entities := lw.Explode()
+	assertEqInt(t, 4, len(entities))
+
+	line := entities[0].(*Line)
+	assertEqString(t, "outline", line.Layer())
+	assertNearPoint(t, Point{0.0, 0.0, 0.0}, line.P1)
+	assertNearPoint(t, Point{-2.0, 0.0, 0.0}, line.P2)
+
+	arc := entities[1].(*Arc)
+	assertNearPoint(t, Point{2.0, 1.0, 0.0}, arc.Center)
+	assertNearFloat64(t, 270.0, arc.StartAngle)
+	assertNearFloat64(t, 90.0, arc.EndAngle)
+	assertNearVector(t, Vector{0.0, 0.0, -1.0}, arc.Normal)
+}
+
+func TestJoinLinesAndArcs(t *testing.T) {
+	bottom := NewLine()
+	bottom.P1 = Point{0.0, 0.0, 0.0}
+	bottom.P2 = Point{2.0, 0.0, 0.0}
+	right := NewArc()
+	right.Center = Point{2.0, 1.0, 0.0}
+	right.Radius = 1.0
+	right.StartAngle = 270.0
+	right.EndAngle = 90.0
+	top := NewLine()
+	top.P1 = Point{0.0, 2.0, 0.0}
+	top.P2 = Point{2.0, 2.0, 0.0}
+	left := NewLine()
+	left.P1 = Point{0.0, 2.0, 0.0}
+	left.P2 = Point{0.0, 0.0, 0.0}
+
+	lw, err := JoinLWPolyline([]Entity{bottom, right, top, left}, 1.0e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, lw.IsClosed(), "expected a closed polyline")
+	assertEqInt(t, 4, len(lw.Vertices))
+	assertNearFloat64(t, 4.0+math.Pi/2.0, lw.Area())
+
+	// joining in the opposite direction reverses the arc
+	lw, err = JoinLWPolyline([]Entity{left, top, right, bottom}, 1.0e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearFloat64(t, -(4.0 + math.Pi/2.0), lw.Area())
+	assertNearFloat64(t, -1.0, lw.Vertices[2].Bulge)
+}
+
+func TestJoinMirroredArc(t *testing.T) {
+	arc := NewArc()
+	arc.Normal = Vector{0.0, 0.0, -1.0}
+	arc.Radius = 1.0
+	arc.StartAngle = 0.0
+	arc.EndAngle = 90.0
+	line := NewLine()
+	line.P1 = Point{0.0, 1.0, 0.0}
+	line.P2 = Point{-1.0, 0.0, 0.0}
+
+	lw, err := JoinLWPolyline([]Entity{line, arc}, 1.0e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, lw.IsClosed(), "expected a closed polyline")
+	for i, p := range lw.WorldPoints() {
+		assertNearFloat64(t, 0.0, p.Z)
+		if i == 0 {
+			assertNearPoint(t, Point{0.0, 1.0, 0.0}, p)
+		}
+	}
+	assertNearFloat64(t, math.Pi/4.0-0.5, math.Abs(lw.Area()))
+}
+
+func TestJoinDisconnectedEntities(t *testing.T) {
+	first := NewLine()
+	first.P2 = Point{1.0, 0.0, 0.0}
+	second := NewLine()
+	second.P1 = Point{2.0, 0.0, 0.0}
+	second.P2 = Point{3.0, 0.0, 0.0}
+	_, err := JoinLWPolyline([]Entity{first, second}, 1.0e-6)
+	assert(t, err != nil, "expected an error")
+}
@@ -0,0 +1,145 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+// roundedSquare is a counter-clockwise 2x2 square whose right side bulges out as a semicircle.
+func roundedSquare() *LWPolyline {
+	poly := NewLWPolyline()
+	poly.Vertices = []LwVertex{
+		{X: 0.0, Y: 0.0},
+		{X: 2.0, Y: 0.0, Bulge: 1.0},
+		{X: 2.0, Y: 2.0},
+		{X: 0.0, Y: 2.0},
+	}
+	poly.SetIsClosed(true)
+	return poly
+}
+
+func TestLWPolylineSegments(t *testing.T) {
+	segments := roundedSquare().Segments()
+	assertEqInt(t, 4, len(segments))
+	assert(t, !segments[0].IsArc(), "expected a line")
+	assert(t, segments[1].IsArc(), "expected an arc")
+	assertNearPoint(t, Point{2.0, 1.0, 0.0}, segments[1].Center)
+	assertNearFloat64(t, 1.0, segments[1].Radius)
+	assertNearFloat64(t, 270.0, segments[1].StartAngle)
+	assertNearFloat64(t, 90.0, segments[1].EndAngle)
+	assertNearFloat64(t, 180.0, segments[1].Sweep())
+	assertNearPoint(t, Point{0.0, 0.0, 0.0}, segments[3].End)
+}
+
+func TestLWPolylineClockwiseArcAngles(t *testing.T) {
+	poly := NewLWPolyline()
+	poly.Vertices = []LwVertex{{X: 1.0, Y: 0.0, Bulge: -math.Tan(math.Pi / 8.0)}, {X: 0.0, Y: -1.0}}
+	segment := poly.Segments()[0]
+	assert(t, segment.IsClockwise(), "expected a clockwise arc")
+	assertNearPoint(t, Point{0.0, 0.0, 0.0}, segment.Center)
+	assertNearFloat64(t, 270.0, segment.StartAngle)
+	assertNearFloat64(t, 0.0, segment.EndAngle)
+}
+
+func TestLWPolylineLengthAndArea(t *testing.T) {
+	poly := roundedSquare()
+	assertNearFloat64(t, 6.0+math.Pi, poly.Length())
+	assertNearFloat64(t, 4.0+math.Pi/2.0, poly.Area())
+	assert(t, poly.IsCounterClockwise(), "expected counter-clockwise")
+
+	poly.Reverse()
+	assertNearFloat64(t, -(4.0 + math.Pi/2.0), poly.Area())
+	assert(t, !poly.IsCounterClockwise(), "expected clockwise")
+	assertNearFloat64(t, 6.0+math.Pi, poly.Length())
+}
+
+func TestLWPolylineReverse(t *testing.T) {
+	poly := NewLWPolyline()
+	poly.Vertices = []LwVertex{
+		{X: 0.0, Y: 0.0, StartingWidth: 1.0, EndingWidth: 2.0},
+		{X: 1.0, Y: 0.0, Bulge: 0.5},
+		{X: 2.0, Y: 1.0},
+	}
+	poly.Reverse()
+	assertEqInt(t, 3, len(poly.Vertices))
+	assertNearFloat64(t, 2.0, poly.Vertices[0].X)
+	assertNearFloat64(t, -0.5, poly.Vertices[0].Bulge)
+	assertNearFloat64(t, 0.0, poly.Vertices[1].Bulge)
+	assertNearFloat64(t, 2.0, poly.Vertices[1].StartingWidth)
+	assertNearFloat64(t, 1.0, poly.Vertices[1].EndingWidth)
+	assertNearFloat64(t, 0.0, poly.Vertices[2].X)
+	assertNearFloat64(t, 0.0, poly.Vertices[2].StartingWidth)
+}
+
+func TestLWPolylineReverseClosed(t *testing.T) {
+	poly := roundedSquare()
+	poly.Reverse()
+	segments := poly.Segments()
+	assertNearPoint(t, Point{0.0, 2.0, 0.0}, segments[0].Start)
+	arc := segments[1]
+	assertNearPoint(t, Point{2.0, 2.0, 0.0}, arc.Start)
+	assertNearPoint(t, Point{2.0, 0.0, 0.0}, arc.End)
+	assertNearFloat64(t, -1.0, arc.Bulge)
+	assertNearPoint(t, Point{2.0, 1.0, 0.0}, arc.Center)
+}
+
+func TestLWPolylineOpenAndClose(t *testing.T) {
+	poly := roundedSquare()
+	poly.Vertices[3].Bulge = 0.25
+	poly.Open()
+	assert(t, !poly.IsClosed(), "expected an open polyline")
+	assertEqInt(t, 5, len(poly.Vertices))
+	assertNearFloat64(t, 0.25, poly.Vertices[3].Bulge)
+	assertNearFloat64(t, 0.0, poly.Vertices[4].X)
+	assertEqInt(t, 4, len(poly.Segments()))
+
+	poly.Close()
+	assert(t, poly.IsClosed(), "expected a closed polyline")
+	assertEqInt(t, 4, len(poly.Vertices))
+}
+
+func TestLWPolylineConstantWidth(t *testing.T) {
+	poly := roundedSquare()
+	poly.ConstantWidth = 0.5
+	poly.Vertices[2].StartingWidth = 1.0
+	segments := poly.Segments()
+	assertNearFloat64(t, 0.5, segments[0].StartingWidth)
+	assertNearFloat64(t, 1.0, segments[2].StartingWidth)
+	assertNearFloat64(t, 0.0, segments[2].EndingWidth)
+}
+
+func TestLwVerticesFromArc(t *testing.T) {
+	poly := NewLWPolyline()
+	poly.Vertices = LwVerticesFromArc(Point{1.0, 1.0, 0.0}, 2.0, 0.0, 90.0, false)
+	assertEqInt(t, 2, len(poly.Vertices))
+	segment := poly.Segments()[0]
+	assertNearPoint(t, Point{1.0, 1.0, 0.0}, segment.Center)
+	assertNearFloat64(t, 2.0, segment.Radius)
+	assertNearFloat64(t, math.Pi, segment.Length())
+
+	poly.Vertices = LwVerticesFromArc(Point{1.0, 1.0, 0.0}, 2.0, 0.0, 90.0, true)
+	assertNearFloat64(t, 3.0*math.Pi, poly.Length())
+
+	poly.Vertices = LwVerticesFromArc(*NewOrigin(), 1.0, 45.0, 45.0, false)
+	assertEqInt(t, 3, len(poly.Vertices))
+	assertNearFloat64(t, math.Pi, poly.Area())
+}
+
+func TestPolylineGeometry(t *testing.T) {
+	poly := NewPolyline()
+	poly.Location = Point{0.0, 0.0, 3.0}
+	poly.DefaultStartingWidth = 0.25
+	poly.Vertices = VerticesFromArc(*NewOrigin(), 1.0, 0.0, 180.0, false)
+	poly.Close()
+	assertEqInt(t, 2, len(poly.Segments()))
+	assertNearFloat64(t, 3.0, poly.Segments()[0].Start.Z)
+	assertNearFloat64(t, 0.25, poly.Segments()[1].StartingWidth)
+	assertNearFloat64(t, math.Pi/2.0, poly.Area())
+	assertNearFloat64(t, math.Pi+2.0, poly.Length())
+
+	poly.Reverse()
+	assertNearFloat64(t, -math.Pi/2.0, poly.Area())
+	poly.Open()
+	assertEqInt(t, 3, len(poly.Vertices))
+	assertNearFloat64(t, math.Pi+2.0, poly.Length())
+}
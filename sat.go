@@ -0,0 +1,472 @@
+package dxf
+
+import (
+	"fmt"
+	"math"
+)
+
+// AcisModel is the topology of an ACIS payload reduced to its bodies and faces.
+type AcisModel struct {
+	Version int
+	Bodies  []AcisBody
+}
+
+// AcisBody is a single ACIS body.
+type AcisBody struct {
+	Faces  []AcisFace
+	Bounds Bounds
+}
+
+// AcisFace is a face of an ACIS body and the surface it lies on.
+type AcisFace struct {
+	Surface    AcisSurface // nil for unsupported surface types
+	IsReversed bool
+	Bounds     Bounds
+}
+
+// AcisSurface is the geometry of an AcisFace: one of AcisPlaneSurface, AcisConeSurface, AcisSphereSurface,
+// AcisTorusSurface or AcisSplineSurface.
+type AcisSurface interface {
+	isAcisSurface()
+}
+
+// AcisPlaneSurface is an infinite plane.
+type AcisPlaneSurface struct {
+	Root   Point
+	Normal Vector
+}
+
+// AcisConeSurface is an elliptical cone, or a cylinder when the half angle is zero.
+type AcisConeSurface struct {
+	Center      Point
+	Axis        Vector
+	MajorAxis   Vector
+	RadiusRatio float64
+	SineAngle   float64
+	CosineAngle float64
+}
+
+// AcisSphereSurface is a sphere.
+type AcisSphereSurface struct {
+	Center Point
+	Radius float64
+}
+
+// AcisTorusSurface is a torus.
+type AcisTorusSurface struct {
+	Center      Point
+	Normal      Vector
+	MajorRadius float64
+	MinorRadius float64
+}
+
+// AcisSplineSurface is a spline surface; its definition isn't decoded.
+type AcisSplineSurface struct {
+}
+
+func (AcisPlaneSurface) isAcisSurface()  {}
+func (AcisConeSurface) isAcisSurface()   {}
+func (AcisSphereSurface) isAcisSurface() {}
+func (AcisTorusSurface) isAcisSurface()  {}
+func (AcisSplineSurface) isAcisSurface() {}
+
+// Bounds returns the box containing every body of the model.
+func (m *AcisModel) Bounds() Bounds {
+	b := *NewBounds()
+	for _, body := range m.Bodies {
+		b.Union(body.Bounds)
+	}
+	return b
+}
+
+// ParseSat parses plain SAT text.
+func ParseSat(text string) (*AcisModel, error) {
+	file, err := readSatText(text)
+	if err != nil {
+		return nil, err
+	}
+	return file.model()
+}
+
+// ParseSab parses binary SAB data.
+func ParseSab(data []byte) (*AcisModel, error) {
+	file, err := readSab(data)
+	if err != nil {
+		return nil, err
+	}
+	// SAB booleans only get their meaning when written as SAT
+	return ParseSat(file.satText())
+}
+
+// satRecordReader gives positional access to the values of a record, ignoring its common header and sub-types.
+type satRecordReader struct {
+	pointers []int
+	numbers  []float64
+	words    []string
+}
+
+func (f *acisFile) recordReader(index int) *satRecordReader {
+	r := &satRecordReader{}
+	if index < 0 || index >= len(f.records) {
+		return r
+	}
+
+	// every record starts with its attribute pointer; from version 7.0 an id and another pointer follow
+	skipPointers := 1
+	skipNumbers := 0
+	if f.version >= 700 {
+		skipPointers = 2
+		skipNumbers = 1
+	}
+	depth := 0
+	for _, token := range f.records[index].tokens {
+		switch token.kind {
+		case acisSubtypeStart:
+			depth++
+		case acisSubtypeEnd:
+			depth--
+		}
+		if depth > 0 {
+			continue
+		}
+		switch token.kind {
+		case acisPointer:
+			if skipPointers > 0 {
+				skipPointers--
+				continue
+			}
+			r.pointers = append(r.pointers, int(token.number))
+		case acisNumber:
+			if skipNumbers > 0 {
+				skipNumbers--
+				continue
+			}
+			r.numbers = append(r.numbers, token.number)
+		case acisWord:
+			r.words = append(r.words, token.text)
+		}
+	}
+	return r
+}
+
+func (f *acisFile) recordType(index int) string {
+	if index < 0 || index >= len(f.records) {
+		return ""
+	}
+	return f.records[index].entityType
+}
+
+func (r *satRecordReader) pointer(index int) int {
+	if index < len(r.pointers) {
+		return r.pointers[index]
+	}
+	return -1
+}
+
+func (r *satRecordReader) number(index int) float64 {
+	if index < len(r.numbers) {
+		return r.numbers[index]
+	}
+	return 0.0
+}
+
+func (r *satRecordReader) point(index int) Point {
+	return Point{X: r.number(index), Y: r.number(index + 1), Z: r.number(index + 2)}
+}
+
+func (r *satRecordReader) vector(index int) Vector {
+	return Vector{X: r.number(index), Y: r.number(index + 1), Z: r.number(index + 2)}
+}
+
+func (r *satRecordReader) isReversed() bool {
+	return len(r.words) > 0 && r.words[0] == "reversed"
+}
+
+// walkChain visits the records linked through the `next` pointer at position `nextIndex`, stopping at a cycle.
+func (f *acisFile) walkChain(start, nextIndex int, visit func(index int, r *satRecordReader)) {
+	visited := map[int]bool{}
+	for index := start; index >= 0 && index < len(f.records) && !visited[index]; {
+		visited[index] = true
+		r := f.recordReader(index)
+		visit(index, r)
+		index = r.pointer(nextIndex)
+	}
+}
+
+func (f *acisFile) model() (*AcisModel, error) {
+	model := &AcisModel{Version: f.version}
+	for index, record := range f.records {
+		if record.entityType == "body" {
+			model.Bodies = append(model.Bodies, f.body(index))
+		}
+	}
+	if len(model.Bodies) == 0 && len(f.records) > 0 {
+		return nil, fmt.Errorf("no bodies in %d ACIS records", len(f.records))
+	}
+	return model, nil
+}
+
+func (f *acisFile) body(index int) AcisBody {
+	body := AcisBody{Bounds: *NewBounds()}
+	bodyReader := f.recordReader(index)
+	transform := f.transform(bodyReader.pointer(2))
+	f.walkChain(bodyReader.pointer(0), 0, func(_ int, lump *satRecordReader) {
+		f.walkChain(lump.pointer(1), 0, func(_ int, shell *satRecordReader) {
+			f.walkChain(shell.pointer(2), 0, func(faceIndex int, face *satRecordReader) {
+				acisFace := f.face(face)
+				if transform != nil {
+					acisFace.Bounds = transform(acisFace.Bounds)
+				}
+				body.Faces = append(body.Faces, acisFace)
+				body.Bounds.Union(acisFace.Bounds)
+			})
+		})
+	})
+	return body
+}
+
+func (f *acisFile) face(face *satRecordReader) AcisFace {
+	result := AcisFace{IsReversed: face.isReversed(), Bounds: *NewBounds()}
+	surfaceIndex := face.pointer(4)
+	surface := f.recordReader(surfaceIndex)
+	switch f.recordType(surfaceIndex) {
+	case "plane-surface":
+		result.Surface = AcisPlaneSurface{Root: surface.point(0), Normal: surface.vector(3)}
+	case "cone-surface":
+		cone := AcisConeSurface{Center: surface.point(0), Axis: surface.vector(3), MajorAxis: surface.vector(6), RadiusRatio: surface.number(9)}
+		// an optional interval precedes the angle; finite bounds add a number each
+		angleIndex := 10
+		for _, word := range surface.words {
+			if word == "F" {
+				angleIndex++
+			}
+			if word != "F" && word != "I" {
+				break
+			}
+		}
+		cone.SineAngle = surface.number(angleIndex)
+		cone.CosineAngle = surface.number(angleIndex + 1)
+		result.Surface = cone
+	case "sphere-surface":
+		result.Surface = AcisSphereSurface{Center: surface.point(0), Radius: surface.number(3)}
+	case "torus-surface":
+		result.Surface = AcisTorusSurface{Center: surface.point(0), Normal: surface.vector(3), MajorRadius: surface.number(6), MinorRadius: surface.number(7)}
+	case "spline-surface":
+		result.Surface = AcisSplineSurface{}
+	}
+
+	f.walkChain(face.pointer(1), 0, func(_ int, loop *satRecordReader) {
+		f.walkChain(loop.pointer(1), 0, func(_ int, coedge *satRecordReader) {
+			f.addEdgeBounds(&result.Bounds, coedge.pointer(3))
+		})
+	})
+
+	if result.Bounds.IsEmpty() {
+		// closed surfaces such as a full sphere have no edges
+		switch s := result.Surface.(type) {
+		case AcisSphereSurface:
+			extent := Vector{X: s.Radius, Y: s.Radius, Z: s.Radius}
+			result.Bounds.AddPoint(s.Center.Add(extent))
+			result.Bounds.AddPoint(s.Center.Add(extent.Scale(-1.0)))
+		case AcisTorusSurface:
+			normal := s.Normal.Normalize()
+			axisExtent := func(n float64) float64 {
+				return s.MajorRadius*math.Sqrt(math.Max(0.0, 1.0-n*n)) + s.MinorRadius
+			}
+			extent := Vector{X: axisExtent(normal.X), Y: axisExtent(normal.Y), Z: axisExtent(normal.Z)}
+			result.Bounds.AddPoint(s.Center.Add(extent))
+			result.Bounds.AddPoint(s.Center.Add(extent.Scale(-1.0)))
+		}
+	}
+	return result
+}
+
+func (f *acisFile) addEdgeBounds(b *Bounds, edgeIndex int) {
+	edge := f.recordReader(edgeIndex)
+	for _, vertexIndex := range []int{edge.pointer(0), edge.pointer(1)} {
+		vertex := f.recordReader(vertexIndex)
+		pointIndex := vertex.pointer(1)
+		if f.recordType(pointIndex) == "point" {
+			b.AddPoint(f.recordReader(pointIndex).point(0))
+		}
+	}
+
+	curveIndex := edge.pointer(3)
+	if f.recordType(curveIndex) == "ellipse-curve" {
+		curve := f.recordReader(curveIndex)
+		center := curve.point(0)
+		normal := curve.vector(3).Normalize()
+		majorAxis := curve.vector(6)
+		minorAxis := normal.Cross(majorAxis).Scale(curve.number(9))
+		start, end := 0.0, 2.0*math.Pi
+		if len(edge.numbers) >= 2 {
+			start, end = edge.number(0), edge.number(1)
+			if edge.isReversed() {
+				start, end = -end, -start
+			}
+		}
+		b.addEllipticalArc(center, majorAxis, minorAxis, start, end)
+	}
+}
+
+// transform returns a function mapping a box through the body transform record at `index`, or nil if there is none.
+func (f *acisFile) transform(index int) func(Bounds) Bounds {
+	if f.recordType(index) != "transform" {
+		return nil
+	}
+
+	// the record ends with a 3x3 matrix applied to row vectors, the translation and the scale
+	numbers := f.recordReader(index).numbers
+	if len(numbers) < 13 {
+		return nil
+	}
+	m := numbers[len(numbers)-13:]
+	apply := func(p Point) Point {
+		return Point{
+			X: (p.X*m[0]+p.Y*m[3]+p.Z*m[6])*m[12] + m[9],
+			Y: (p.X*m[1]+p.Y*m[4]+p.Z*m[7])*m[12] + m[10],
+			Z: (p.X*m[2]+p.Y*m[5]+p.Z*m[8])*m[12] + m[11],
+		}
+	}
+	return func(b Bounds) Bounds {
+		result := *NewBounds()
+		if b.IsEmpty() {
+			return result
+		}
+		for _, x := range []float64{b.Min.X, b.Max.X} {
+			for _, y := range []float64{b.Min.Y, b.Max.Y} {
+				for _, z := range []float64{b.Min.Z, b.Max.Z} {
+					result.AddPoint(apply(Point{X: x, Y: y, Z: z}))
+				}
+			}
+		}
+		return result
+	}
+}
+
+// pointerToken returns the position within the tokens of record `index` of the pointer that `recordReader` reports at
+// `position`, or -1.
+func (f *acisFile) pointerToken(index, position int) int {
+	skipPointers := 1
+	if f.version >= 700 {
+		skipPointers = 2
+	}
+	depth := 0
+	for i, token := range f.records[index].tokens {
+		switch token.kind {
+		case acisSubtypeStart:
+			depth++
+		case acisSubtypeEnd:
+			depth--
+		}
+		if depth > 0 || token.kind != acisPointer {
+			continue
+		}
+		if skipPointers > 0 {
+			skipPointers--
+			continue
+		}
+		if position == 0 {
+			return i
+		}
+		position--
+	}
+	return -1
+}
+
+// transformBodies applies `m` to every body by composing it with the body's transform record, adding a record to the
+// bodies that have none.
+func (f *acisFile) transformBodies(m Matrix4) {
+	transformed := map[int]bool{}
+	for index := range f.records {
+		if f.records[index].entityType != "body" {
+			continue
+		}
+		tokenIndex := f.pointerToken(index, 2)
+		if tokenIndex < 0 {
+			continue
+		}
+		transformIndex := int(f.records[index].tokens[tokenIndex].number)
+		if f.recordType(transformIndex) != "transform" {
+			transformIndex = len(f.records)
+			header := []acisToken{{kind: acisPointer, number: -1.0}}
+			if f.version >= 700 {
+				header = append(header, acisToken{kind: acisNumber, number: -1.0})
+			}
+			f.records = append(f.records, acisRecord{entityType: "transform", tokens: header})
+			f.records[index].tokens[tokenIndex].number = float64(transformIndex)
+			if f.recordCount > 0 {
+				f.recordCount++
+			}
+		}
+		if !transformed[transformIndex] {
+			f.composeTransform(transformIndex, m)
+			transformed[transformIndex] = true
+		}
+	}
+}
+
+// composeTransform replaces the transform record at `index` with `m` applied after it.
+func (f *acisFile) composeTransform(index int, m Matrix4) {
+	record := &f.records[index]
+	existing := *NewIdentityMatrix4()
+	numbers := f.recordReader(index).numbers
+	keep := len(record.tokens)
+	if len(numbers) >= 13 {
+		// the record stores a 3x3 matrix applied to row vectors, the translation and the scale
+		values := numbers[len(numbers)-13:]
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				existing[row][col] = values[col*3+row] * values[12]
+			}
+			existing[row][3] = values[9+row]
+		}
+
+		// the values and the flags following them are rewritten
+		for count := 0; count < 13; keep-- {
+			if record.tokens[keep-1].kind == acisNumber {
+				count++
+			}
+		}
+	}
+
+	combined := m.Multiply(existing)
+	scale := math.Cbrt(math.Abs(combined.Determinant()))
+	if scale < transformTolerance {
+		scale = 1.0
+	}
+	var rotation Matrix4
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			rotation[row][col] = combined[row][col] / scale
+		}
+	}
+	c1 := Vector{X: rotation[0][0], Y: rotation[1][0], Z: rotation[2][0]}
+	c2 := Vector{X: rotation[0][1], Y: rotation[1][1], Z: rotation[2][1]}
+	c3 := Vector{X: rotation[0][2], Y: rotation[1][2], Z: rotation[2][2]}
+	isRotated := !c1.Sub(*NewXAxis()).IsZero(transformTolerance) || !c2.Sub(*NewYAxis()).IsZero(transformTolerance) ||
+		!c3.Sub(*NewZAxis()).IsZero(transformTolerance)
+	isReflected := combined.Determinant() < 0.0
+	isSheared := math.Abs(c1.Dot(c2)) > transformTolerance || math.Abs(c1.Dot(c3)) > transformTolerance ||
+		math.Abs(c2.Dot(c3)) > transformTolerance || math.Abs(c1.Length()-c2.Length()) > transformTolerance ||
+		math.Abs(c1.Length()-c3.Length()) > transformTolerance
+
+	tokens := record.tokens[:keep]
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			tokens = append(tokens, acisToken{kind: acisNumber, number: rotation[col][row]})
+		}
+	}
+	for row := 0; row < 3; row++ {
+		tokens = append(tokens, acisToken{kind: acisNumber, number: combined[row][3]})
+	}
+	tokens = append(tokens, acisToken{kind: acisNumber, number: scale})
+	flag := func(set bool, name string) acisToken {
+		if set {
+			return acisToken{kind: acisWord, text: name}
+		}
+		return acisToken{kind: acisWord, text: "no_" + name}
+	}
+	tokens = append(tokens, flag(isRotated, "rotate"), flag(isReflected, "reflect"), flag(isSheared, "shear"))
+	record.tokens = tokens
+}
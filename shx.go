@@ -0,0 +1,653 @@
+package dxf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+const (
+	// maxSubshapeDepth bounds the nesting of subshapes so a malformed font can't recurse forever.
+	maxSubshapeDepth = 8
+
+	// shapeArcTolerance is the chord height, in shape vector units, used to approximate arcs.
+	shapeArcTolerance = 0.01
+)
+
+// ShapeFont is a compiled SHX shape file or font: a `shapes 1.0` or `1.1` file, a Unicode font or a big font.
+type ShapeFont struct {
+	Name       string  // the description of the font
+	Above      float64 // how far capital letters reach above the baseline, in shape vector units
+	Below      float64 // how far descenders reach below the baseline, in shape vector units
+	IsVertical bool    // whether the font can be written vertically
+	IsUnifont  bool
+	IsBigFont  bool
+	shapes     map[uint16]shapeDefinition
+	glyphs     map[uint16]*ShapeGlyph
+}
+
+type shapeDefinition struct {
+	name string
+	spec []byte
+}
+
+// ShapeGlyph is the drawing of a shape or a character in shape vector units, starting at the origin.
+type ShapeGlyph struct {
+	Name    string
+	Strokes [][]Point // the polylines drawn with the pen down
+	Advance Vector    // where the pen ends, i.e., the start of the next character
+}
+
+// ParseShapeFont parses the contents of a `.shx` file.
+func ParseShapeFont(data []byte) (*ShapeFont, error) {
+	end := bytes.IndexByte(data, 0x1a)
+	if end < 0 {
+		return nil, fmt.Errorf("unrecognized shape file format")
+	}
+	header := strings.TrimSpace(string(data[:end]))
+	f := &ShapeFont{shapes: map[uint16]shapeDefinition{}, glyphs: map[uint16]*ShapeGlyph{}}
+	r := &shapeFileReader{data: data, offset: end + 1}
+	var err error
+	switch {
+	case strings.HasPrefix(header, "AutoCAD-86 shapes 1."):
+		err = f.readShapes(r)
+	case header == "AutoCAD-86 unifont 1.0":
+		f.IsUnifont = true
+		err = f.readUnifont(r)
+	case header == "AutoCAD-86 bigfont 1.0":
+		f.IsBigFont = true
+		err = f.readBigFont(r)
+	default:
+		return nil, fmt.Errorf("unsupported shape file '%s'", header)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// shape 0 describes the font
+	if info, ok := f.shapes[0]; ok {
+		f.Name = info.name
+		if len(info.spec) >= 3 {
+			f.Above = float64(info.spec[0])
+			f.Below = float64(info.spec[1])
+			f.IsVertical = info.spec[2] == 2
+		}
+		delete(f.shapes, 0)
+	}
+	return f, nil
+}
+
+// shapeFileReader reads the little-endian values of a shape file.
+type shapeFileReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (r *shapeFileReader) bytes(count int) []byte {
+	if r.err != nil || count < 0 || r.offset+count > len(r.data) {
+		r.err = fmt.Errorf("shape file is truncated")
+		return make([]byte, count)
+	}
+	b := r.data[r.offset : r.offset+count]
+	r.offset += count
+	return b
+}
+
+func (r *shapeFileReader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.bytes(2))
+}
+
+func (r *shapeFileReader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.bytes(4))
+}
+
+// splitShapeDefinition separates the name a definition starts with from its specification bytes.
+func splitShapeDefinition(definition []byte) shapeDefinition {
+	end := bytes.IndexByte(definition, 0)
+	if end < 0 {
+		return shapeDefinition{spec: definition}
+	}
+	return shapeDefinition{name: string(definition[:end]), spec: definition[end+1:]}
+}
+
+// readShapes reads the lowest and highest shape numbers and the count, an index of shape numbers and definition
+// lengths, then the definitions.
+func (f *ShapeFont) readShapes(r *shapeFileReader) error {
+	r.uint16()
+	r.uint16()
+	count := int(r.uint16())
+	numbers := make([]uint16, count)
+	lengths := make([]int, count)
+	for i := 0; i < count; i++ {
+		numbers[i] = r.uint16()
+		lengths[i] = int(r.uint16())
+	}
+	for i := 0; i < count && r.err == nil; i++ {
+		f.shapes[numbers[i]] = splitShapeDefinition(r.bytes(lengths[i]))
+	}
+	return r.err
+}
+
+// readUnifont reads the shape count, the font description, then each character code with its definition.
+func (f *ShapeFont) readUnifont(r *shapeFileReader) error {
+	count := int(r.uint32())
+	f.shapes[0] = splitShapeDefinition(r.bytes(int(r.uint16())))
+	for i := 1; i < count && r.err == nil; i++ {
+		code := r.uint16()
+		f.shapes[code] = splitShapeDefinition(r.bytes(int(r.uint16())))
+	}
+	return r.err
+}
+
+// readBigFont reads the index entry count, the character count, the ranges of lead bytes of double-byte codes,
+// then an index of codes, definition lengths and file offsets.
+func (f *ShapeFont) readBigFont(r *shapeFileReader) error {
+	count := int(r.uint16())
+	r.uint16()
+	r.bytes(int(r.uint16()) * 4)
+	for i := 0; i < count && r.err == nil; i++ {
+		code := r.uint16()
+		length := int(r.uint16())
+		offset := int(r.uint32())
+		if code == 0 && length == 0 && offset == 0 {
+			// an unused entry
+			continue
+		}
+		if offset+length > len(r.data) {
+			return fmt.Errorf("shape %d is out of range", code)
+		}
+		f.shapes[code] = splitShapeDefinition(r.data[offset : offset+length])
+	}
+	return r.err
+}
+
+// HasShape returns whether the font defines the shape or character `code`.
+func (f *ShapeFont) HasShape(code uint16) bool {
+	_, ok := f.shapes[code]
+	return ok
+}
+
+// ShapeCode returns the number of the shape named `name`.
+func (f *ShapeFont) ShapeCode(name string) (uint16, bool) {
+	for code, shape := range f.shapes {
+		if strings.EqualFold(shape.name, name) {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// unitScale returns the factor drawing a shape `size` tall; shape files without a font description are drawn with
+// one vector unit per size unit.
+func (f *ShapeFont) unitScale(size float64) float64 {
+	if f.Above > 0.0 {
+		return size / f.Above
+	}
+	return size
+}
+
+// Glyph returns the strokes of the shape or character `code` written horizontally.
+func (f *ShapeFont) Glyph(code uint16) (*ShapeGlyph, error) {
+	if glyph, ok := f.glyphs[code]; ok {
+		return glyph, nil
+	}
+	shape, ok := f.shapes[code]
+	if !ok {
+		return nil, fmt.Errorf("shape %d not found", code)
+	}
+	s := &shapeInterpreter{font: f, scale: 1.0, stretch: Vector{X: 1.0, Y: 1.0}, isPenDown: true}
+	if err := s.run(code, 0); err != nil {
+		return nil, err
+	}
+	s.penUp()
+	glyph := &ShapeGlyph{Name: shape.name, Strokes: s.strokes, Advance: Vector{X: s.position.X, Y: s.position.Y}}
+	f.glyphs[code] = glyph
+	return glyph, nil
+}
+
+// shapeDirections are the unit steps of the 16 directions of a length and direction byte.
+var shapeDirections = [16]Vector{
+	{X: 1.0, Y: 0.0}, {X: 1.0, Y: 0.5}, {X: 1.0, Y: 1.0}, {X: 0.5, Y: 1.0},
+	{X: 0.0, Y: 1.0}, {X: -0.5, Y: 1.0}, {X: -1.0, Y: 1.0}, {X: -1.0, Y: 0.5},
+	{X: -1.0, Y: 0.0}, {X: -1.0, Y: -0.5}, {X: -1.0, Y: -1.0}, {X: -0.5, Y: -1.0},
+	{X: 0.0, Y: -1.0}, {X: 0.5, Y: -1.0}, {X: 1.0, Y: -1.0}, {X: 1.0, Y: -0.5},
+}
+
+// shapeInterpreter draws the specification bytes of shapes.
+type shapeInterpreter struct {
+	font      *ShapeFont
+	scale     float64
+	stretch   Vector
+	isPenDown bool
+	position  Point
+	stack     []Point
+	current   []Point
+	strokes   [][]Point
+}
+
+func (s *shapeInterpreter) penUp() {
+	if len(s.current) > 1 {
+		s.strokes = append(s.strokes, s.current)
+	}
+	s.current = nil
+}
+
+// moveBy moves the pen by a displacement in vector units, drawing when it's down.
+func (s *shapeInterpreter) moveBy(dx, dy float64) {
+	s.moveTo(s.position.Add(Vector{X: dx * s.scale * s.stretch.X, Y: dy * s.scale * s.stretch.Y}))
+}
+
+func (s *shapeInterpreter) moveTo(p Point) {
+	if s.isPenDown {
+		if len(s.current) == 0 {
+			s.current = []Point{s.position}
+		}
+		s.current = append(s.current, p)
+	}
+	s.position = p
+}
+
+// arc draws an arc from the pen position around the circle with the given center and radius in drawing units.
+func (s *shapeInterpreter) arc(center Point, radius, startAngle, sweep float64) {
+	u := Vector{X: radius * s.stretch.X}
+	v := Vector{Y: radius * s.stretch.Y}
+	points := tessellateEllipticalArc(center, u, v, startAngle, startAngle+sweep, shapeArcTolerance*s.scale)
+	for _, p := range points[1:] {
+		s.moveTo(p)
+	}
+}
+
+// bulgeTo draws a straight or circular segment to a displacement in vector units.
+func (s *shapeInterpreter) bulgeTo(dx, dy, bulge float64) {
+	if bulge == 0.0 {
+		s.moveBy(dx, dy)
+		return
+	}
+	// find the circle before stretching it
+	center, radius, startAngle, sweep := bulgeArc(Point{}, Point{X: dx * s.scale, Y: dy * s.scale}, bulge)
+	start := s.position
+	s.arc(start.Add(Vector{X: center.X * s.stretch.X, Y: center.Y * s.stretch.Y}), radius, startAngle, sweep)
+	// land exactly on the end, even when stretched
+	s.position = start.Add(Vector{X: dx * s.scale * s.stretch.X, Y: dy * s.scale * s.stretch.Y})
+	if s.isPenDown {
+		s.current[len(s.current)-1] = s.position
+	}
+}
+
+// run draws the shape `code` of the font.
+func (s *shapeInterpreter) run(code uint16, depth int) error {
+	if depth > maxSubshapeDepth {
+		return fmt.Errorf("shape %d nests too deeply", code)
+	}
+	shape, ok := s.font.shapes[code]
+	if !ok {
+		return fmt.Errorf("shape %d not found", code)
+	}
+	spec := shape.spec
+	for i := 0; i < len(spec); {
+		next, err := s.command(spec, i, depth)
+		if err != nil {
+			return fmt.Errorf("shape %d: %s", code, err)
+		}
+		if next < 0 {
+			break
+		}
+		i = next
+	}
+	return nil
+}
+
+// command draws the command at `spec[i]`, returning the index of the next command or -1 at the end of the shape.
+func (s *shapeInterpreter) command(spec []byte, i, depth int) (int, error) {
+	arguments := func(count int) ([]byte, error) {
+		if i+1+count > len(spec) {
+			return nil, fmt.Errorf("command %d is truncated", spec[i])
+		}
+		return spec[i+1 : i+1+count], nil
+	}
+	b := spec[i]
+	if b > 0x0f {
+		// the high nibble is the length and the low nibble the direction
+		direction := shapeDirections[b&0x0f]
+		length := float64(b >> 4)
+		s.moveBy(direction.X*length, direction.Y*length)
+		return i + 1, nil
+	}
+
+	switch b {
+	case 0:
+		return -1, nil
+	case 1:
+		s.isPenDown = true
+	case 2:
+		s.penUp()
+		s.isPenDown = false
+	case 3, 4:
+		args, err := arguments(1)
+		if err != nil {
+			return 0, err
+		}
+		if args[0] == 0 {
+			return 0, fmt.Errorf("scale factor is zero")
+		}
+		if b == 3 {
+			s.scale /= float64(args[0])
+		} else {
+			s.scale *= float64(args[0])
+		}
+		return i + 2, nil
+	case 5:
+		s.stack = append(s.stack, s.position)
+	case 6:
+		if len(s.stack) == 0 {
+			return 0, fmt.Errorf("position stack is empty")
+		}
+		s.penUp()
+		s.position = s.stack[len(s.stack)-1]
+		s.stack = s.stack[:len(s.stack)-1]
+	case 7:
+		return s.subshape(spec, i, depth)
+	case 8:
+		args, err := arguments(2)
+		if err != nil {
+			return 0, err
+		}
+		s.moveBy(float64(int8(args[0])), float64(int8(args[1])))
+		return i + 3, nil
+	case 9:
+		for i++; ; i += 2 {
+			if i+2 > len(spec) {
+				return 0, fmt.Errorf("command 9 is truncated")
+			}
+			dx, dy := int8(spec[i]), int8(spec[i+1])
+			if dx == 0 && dy == 0 {
+				return i + 2, nil
+			}
+			s.moveBy(float64(dx), float64(dy))
+		}
+	case 10:
+		// an arc spanning whole octants
+		args, err := arguments(2)
+		if err != nil {
+			return 0, err
+		}
+		radius := float64(args[0]) * s.scale
+		s.octantArc(radius, int8(args[1]), 0.0, 0.0)
+		return i + 3, nil
+	case 11:
+		// an arc starting and ending between octant boundaries
+		args, err := arguments(5)
+		if err != nil {
+			return 0, err
+		}
+		radius := float64(int(args[2])<<8|int(args[3])) * s.scale
+		s.octantArc(radius, int8(args[4]), float64(args[0]), float64(args[1]))
+		return i + 6, nil
+	case 12:
+		args, err := arguments(3)
+		if err != nil {
+			return 0, err
+		}
+		s.bulgeTo(float64(int8(args[0])), float64(int8(args[1])), float64(int8(args[2]))/127.0)
+		return i + 4, nil
+	case 13:
+		for i++; ; i += 3 {
+			if i+2 > len(spec) {
+				return 0, fmt.Errorf("command 13 is truncated")
+			}
+			dx, dy := int8(spec[i]), int8(spec[i+1])
+			if dx == 0 && dy == 0 {
+				return i + 2, nil
+			}
+			if i+3 > len(spec) {
+				return 0, fmt.Errorf("command 13 is truncated")
+			}
+			s.bulgeTo(float64(dx), float64(dy), float64(int8(spec[i+2]))/127.0)
+		}
+	case 14:
+		// the next command only applies to vertical text, which isn't drawn; skip it on a copy
+		if i+1 >= len(spec) {
+			return -1, nil
+		}
+		skipped := *s
+		skipped.stack = append([]Point{}, s.stack...)
+		return skipped.command(spec, i+1, depth)
+	}
+	return i + 1, nil
+}
+
+// octantArc draws the arc of command 10 or 11 from the pen position.  The low nibble of `octants` is the octant
+// count, 0 meaning a full circle, the next three bits the starting octant and its sign the direction.  The offsets
+// are in 256ths of an octant from the start of the first octant and from the start of the last one.
+func (s *shapeInterpreter) octantArc(radius float64, octants int8, startOffset, endOffset float64) {
+	direction := 1.0
+	if octants < 0 {
+		direction = -1.0
+	}
+	value := int(math.Abs(float64(octants)))
+	startOctant := float64(value >> 4 & 0x07)
+	span := float64(value & 0x0f)
+	if span == 0.0 {
+		span = 8.0
+	}
+
+	octant := math.Pi / 4.0
+	startAngle := (startOctant + direction*startOffset/256.0) * octant
+	sweep := direction * span * octant
+	if startOffset != 0.0 || endOffset != 0.0 {
+		endAngle := (startOctant + direction*span) * octant
+		if endOffset != 0.0 {
+			endAngle = (startOctant + direction*(span-1.0) + direction*endOffset/256.0) * octant
+		}
+		sweep = endAngle - startAngle
+	}
+	center := s.position.Sub(Point{X: radius * math.Cos(startAngle) * s.stretch.X, Y: radius * math.Sin(startAngle) * s.stretch.Y})
+	s.arc(Point{X: center.X, Y: center.Y, Z: s.position.Z}, radius, startAngle, sweep)
+}
+
+// subshape draws the shape referenced by command 7: a byte in shape files, two bytes in Unicode fonts, and in big
+// fonts either a byte, or a zero followed by two bytes and the origin, width and height of the box it's fitted to.
+func (s *shapeInterpreter) subshape(spec []byte, i, depth int) (int, error) {
+	truncated := fmt.Errorf("command 7 is truncated")
+	switch {
+	case s.font.IsUnifont:
+		if i+3 > len(spec) {
+			return 0, truncated
+		}
+		return i + 3, s.run(uint16(spec[i+1])<<8|uint16(spec[i+2]), depth+1)
+	case s.font.IsBigFont && i+1 < len(spec) && spec[i+1] == 0:
+		if i+8 > len(spec) {
+			return 0, truncated
+		}
+		code := uint16(spec[i+2])<<8 | uint16(spec[i+3])
+		start, stretch := s.position, s.stretch
+		above := s.font.Above
+		if above == 0.0 {
+			above = 1.0
+		}
+		s.position = start.Add(Vector{X: float64(spec[i+4]) * s.scale, Y: float64(spec[i+5]) * s.scale})
+		s.stretch = Vector{X: float64(spec[i+6]) / above, Y: float64(spec[i+7]) / above}
+		err := s.run(code, depth+1)
+		s.penUp()
+		s.position, s.stretch = start, stretch
+		return i + 8, err
+	}
+	if i+2 > len(spec) {
+		return 0, truncated
+	}
+	return i + 2, s.run(uint16(spec[i+1]), depth+1)
+}
+
+// shapeCharacterCodes are the shapes standard SHX fonts draw for the characters of the `%%d`, `%%p` and `%%c` codes.
+var shapeCharacterCodes = map[rune]uint16{
+	'°': 127,
+	'±': 128,
+	'Ø': 129,
+}
+
+// characterCode returns the shape drawing the character `r`.
+func (f *ShapeFont) characterCode(r rune) (uint16, bool) {
+	if r > 0 && r <= 0xffff && f.HasShape(uint16(r)) {
+		return uint16(r), true
+	}
+	if code, ok := shapeCharacterCodes[r]; ok && f.HasShape(code) {
+		return code, true
+	}
+	return 0, false
+}
+
+// bigFontEncodings are the double-byte code pages big fonts are indexed by.
+var bigFontEncodings = map[string]encoding.Encoding{
+	"ANSI_932": japanese.ShiftJIS,
+	"ANSI_936": simplifiedchinese.GBK,
+	"ANSI_949": korean.EUCKR,
+	"ANSI_950": traditionalchinese.Big5,
+}
+
+// bigFontCode returns the code of the character `r` in the drawing's code page, e.g., 0x82a0 for 'あ' in ANSI_932.
+func bigFontCode(r rune, codePage string) uint16 {
+	if e, ok := bigFontEncodings[strings.ToUpper(codePage)]; ok {
+		if encoded, err := e.NewEncoder().String(string(r)); err == nil {
+			code := uint16(0)
+			for i := 0; i < len(encoded) && i < 2; i++ {
+				code = code<<8 | uint16(encoded[i])
+			}
+			return code
+		}
+	}
+	return uint16(r)
+}
+
+// ExplodeShape returns the strokes of a `Shape` as open `LWPolyline`s on the layer of the shape.  The shape is
+// looked up by name in the shape files of the drawing's styles, loaded from `fonts`.  The returned entities have no
+// handle assigned and the drawing is not modified.
+func (d *Drawing) ExplodeShape(shape *Shape, fonts *FontDirectory) ([]Entity, error) {
+	if shape == nil || fonts == nil {
+		return nil, fmt.Errorf("shape and fonts must not be nil")
+	}
+	font, code, err := d.findShape(shape.Name, fonts)
+	if err != nil {
+		return nil, err
+	}
+	glyph, err := font.Glyph(code)
+	if err != nil {
+		return nil, err
+	}
+
+	widthFactor := shape.RelativeXScaleFactor
+	if widthFactor == 0.0 {
+		widthFactor = 1.0
+	}
+	scale := font.unitScale(shape.Size)
+	slant := math.Tan(shape.ObliqueAngle * math.Pi / 180.0)
+	rotation := shape.RotationAngle * math.Pi / 180.0
+	cos, sin := math.Cos(rotation), math.Sin(rotation)
+	entities := []Entity{}
+	for _, stroke := range glyph.Strokes {
+		polyline := NewLWPolyline()
+		polyline.SetLayer(shape.Layer())
+		polyline.ExtrusionDirection = shape.ExtrusionDirection
+		polyline.SetElevation(shape.Location.Z)
+		polyline.Thickness = shape.Thickness
+		for _, p := range stroke {
+			x := (p.X*widthFactor + p.Y*slant) * scale
+			y := p.Y * scale
+			vertex := *NewLwVertex()
+			vertex.X = shape.Location.X + x*cos - y*sin
+			vertex.Y = shape.Location.Y + x*sin + y*cos
+			polyline.Vertices = append(polyline.Vertices, vertex)
+		}
+		entities = append(entities, polyline)
+	}
+	return entities, nil
+}
+
+// findShape returns the shape file of the drawing's styles defining the shape `name`.
+func (d *Drawing) findShape(name string, fonts *FontDirectory) (*ShapeFont, uint16, error) {
+	var loadError error
+	for _, style := range d.Styles {
+		if style.Flags&1 == 0 {
+			// not a shape file
+			continue
+		}
+		font, err := fonts.ShapeFont(style.PrimaryFontFileName)
+		if err != nil {
+			loadError = err
+			continue
+		}
+		if code, ok := font.ShapeCode(name); ok {
+			return font, code, nil
+		}
+	}
+	if loadError != nil {
+		return nil, 0, loadError
+	}
+	return nil, 0, fmt.Errorf("shape '%s' not found", name)
+}
+
+// LineTypeShape returns the strokes of the shape drawn by the element `element` of a index line type, scaled,
+// rotated and offset by the element.  X runs along the line from the start of the element and Y to its left; the
+// caller turns the strokes with the line unless the element's flags make the rotation absolute.
+func (d *Drawing) LineTypeShape(lineType *LineType, element int, fonts *FontDirectory) ([][]Point, error) {
+	if lineType == nil || fonts == nil {
+		return nil, fmt.Errorf("line type and fonts must not be nil")
+	}
+	types := lineType.ComplexLineTypeElementTypes
+	if element < 0 || element >= len(types) || types[element]&4 == 0 {
+		return nil, fmt.Errorf("element %d of line type '%s' is not a shape", element, lineType.Name)
+	}
+
+	// the shape values are only stored for index elements
+	index := 0
+	for _, t := range types[:element] {
+		if t != 0 {
+			index++
+		}
+	}
+	if index >= len(lineType.ShapeNumbers) || index >= len(lineType.StyleHandles) || index >= len(lineType.ScaleValues) ||
+		index >= len(lineType.RotationAngles) || index >= len(lineType.XOffsets) || index >= len(lineType.YOffsets) {
+		return nil, fmt.Errorf("element %d of line type '%s' is incomplete", element, lineType.Name)
+	}
+
+	handle := handleFromString(lineType.StyleHandles[index])
+	var style *Style
+	for i := range d.Styles {
+		if d.Styles[i].Handle() == handle {
+			style = &d.Styles[i]
+			break
+		}
+	}
+	if style == nil {
+		return nil, fmt.Errorf("style '%s' not found", lineType.StyleHandles[index])
+	}
+	font, err := fonts.ShapeFont(style.PrimaryFontFileName)
+	if err != nil {
+		return nil, err
+	}
+	glyph, err := font.Glyph(uint16(lineType.ShapeNumbers[index]))
+	if err != nil {
+		return nil, err
+	}
+
+	scale := font.unitScale(lineType.ScaleValues[index])
+	cos, sin := math.Cos(lineType.RotationAngles[index]), math.Sin(lineType.RotationAngles[index])
+	offset := Vector{X: lineType.XOffsets[index], Y: lineType.YOffsets[index]}
+	strokes := make([][]Point, len(glyph.Strokes))
+	for i, stroke := range glyph.Strokes {
+		strokes[i] = make([]Point, len(stroke))
+		for j, p := range stroke {
+			x, y := p.X*scale, p.Y*scale
+			strokes[i][j] = Point{X: x*cos - y*sin, Y: x*sin + y*cos}.Add(offset)
+		}
+	}
+	return strokes, nil
+}
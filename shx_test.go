@@ -0,0 +1,267 @@
+package dxf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func littleEndianBytes(values ...interface{}) []byte {
+	var buf bytes.Buffer
+	for _, v := range values {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	return buf.Bytes()
+}
+
+// testShapeFile builds a `shapes 1.0` font 10 units tall.
+func testShapeFile() []byte {
+	shapes := []struct {
+		number uint16
+		name   string
+		spec   []byte
+	}{
+		{0, "TEST", []byte{10, 2, 0, 0}},
+		{'L', "", []byte{2, 8, 0, 10, 1, 0xac, 0x60, 2, 0x20, 0}},
+		{'O', "", []byte{2, 8, 0, 5, 1, 10, 5, 0x40, 2, 8, 12, 0xfb, 0}},
+		{127, "", []byte{0x10, 0}},
+		{200, "BOX", []byte{9, 4, 0, 0, 4, 0xfc, 0, 0, 0xfc, 0, 0, 0}},
+		{201, "TWOBOX", []byte{7, 200, 2, 8, 6, 0, 1, 7, 200, 0}},
+		{202, "ARC", []byte{12, 10, 0, 127, 0}},
+		{203, "VERTICAL", []byte{14, 0x14, 0x10, 0}},
+		{204, "SCALED", []byte{4, 2, 0x10, 0}},
+	}
+	data := append([]byte("AutoCAD-86 shapes 1.0\r\n\x1a"), littleEndianBytes(uint16(0), uint16(204), uint16(len(shapes)))...)
+	var definitions []byte
+	for _, shape := range shapes {
+		definition := append(append([]byte(shape.name), 0), shape.spec...)
+		data = append(data, littleEndianBytes(shape.number, uint16(len(definition)))...)
+		definitions = append(definitions, definition...)
+	}
+	return append(data, definitions...)
+}
+
+// testUnifont builds a Unicode font whose 'Ω' draws 'A' twice as a subshape.
+func testUnifont() []byte {
+	info := append([]byte("UNI\x00"), 10, 2, 0, 0, 0, 0)
+	data := append([]byte("AutoCAD-86 unifont 1.0\r\n\x1a"), littleEndianBytes(uint32(3), uint16(len(info)))...)
+	data = append(data, info...)
+	a := []byte{0, 0x30, 0}
+	omega := []byte{0, 7, 0x00, 0x41, 7, 0x00, 0x41, 0}
+	data = append(data, littleEndianBytes(uint16('A'), uint16(len(a)))...)
+	data = append(data, a...)
+	data = append(data, littleEndianBytes(uint16(0x03a9), uint16(len(omega)))...)
+	return append(data, omega...)
+}
+
+// testBigFont builds a big font 8 units tall defining the Shift-JIS character 0x82a0.
+func testBigFont() []byte {
+	info := append([]byte("BIG\x00"), 8, 0, 0, 0)
+	glyph := []byte{0, 0x80, 0}
+	header := append([]byte("AutoCAD-86 bigfont 1.0\r\n\x1a"), littleEndianBytes(uint16(3), uint16(1), uint16(1), uint16(0x81), uint16(0x9f))...)
+	offset := uint32(len(header) + 3*8)
+	index := littleEndianBytes(uint16(0), uint16(len(info)), offset, uint16(0x82a0), uint16(len(glyph)), offset+uint32(len(info)), [4]uint16{})
+	data := append(header, index...)
+	data = append(data, info...)
+	return append(data, glyph...)
+}
+
+func testShapeFontDirectory(t *testing.T) *FontDirectory {
+	directory := t.TempDir()
+	files := map[string][]byte{"txt.shx": testShapeFile(), "UNI.SHX": testUnifont(), "big.shx": testBigFont()}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(directory, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewFontDirectory(directory)
+}
+
+func assertNearPoints(t *testing.T, expected, actual []Point) {
+	assertEqInt(t, len(expected), len(actual))
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		assertNearPoint(t, expected[i], actual[i])
+	}
+}
+
+func TestParseShapeFile(t *testing.T) {
+	font, err := ParseShapeFont(testShapeFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqString(t, "TEST", font.Name)
+	assertEqFloat64(t, 10.0, font.Above)
+	assertEqFloat64(t, 2.0, font.Below)
+	assert(t, !font.HasShape(0), "expected the font description not to be a shape")
+	code, ok := font.ShapeCode("box")
+	assert(t, ok, "expected to find the shape by name")
+	assertEqInt(t, 200, int(code))
+
+	glyph, _ := font.Glyph('L')
+	assertEqInt(t, 1, len(glyph.Strokes))
+	assertNearPoints(t, []Point{{0.0, 10.0, 0.0}, {0.0, 0.0, 0.0}, {6.0, 0.0, 0.0}}, glyph.Strokes[0])
+	assertNearVector(t, Vector{8.0, 0.0, 0.0}, glyph.Advance)
+
+	box, _ := font.Glyph(200)
+	assertNearPoints(t, []Point{{0.0, 0.0, 0.0}, {4.0, 0.0, 0.0}, {4.0, 4.0, 0.0}, {0.0, 4.0, 0.0}, {0.0, 0.0, 0.0}}, box.Strokes[0])
+
+	twoBoxes, _ := font.Glyph(201)
+	assertEqInt(t, 2, len(twoBoxes.Strokes))
+	assertNearPoint(t, Point{6.0, 0.0, 0.0}, twoBoxes.Strokes[1][0])
+
+	_, err = font.Glyph(999)
+	assert(t, err != nil, "expected a missing shape to fail")
+}
+
+func TestShapeArcs(t *testing.T) {
+	font, _ := ParseShapeFont(testShapeFile())
+
+	// a full circle of radius 5 starting at its left
+	circle, _ := font.Glyph('O')
+	assertEqInt(t, 1, len(circle.Strokes))
+	for _, p := range circle.Strokes[0] {
+		assertNearFloat64(t, 5.0, p.DistanceTo(Point{5.0, 5.0, 0.0}))
+	}
+	assertNearVector(t, Vector{12.0, 0.0, 0.0}, circle.Advance)
+
+	// a counterclockwise half circle below its chord
+	arc, _ := font.Glyph(202)
+	lowest := 0.0
+	for _, p := range arc.Strokes[0] {
+		lowest = math.Min(lowest, p.Y)
+	}
+	assert(t, lowest < -4.9 && lowest > -5.0-1.0e-9, "expected the arc to reach its radius below the chord")
+	assertNearVector(t, Vector{10.0, 0.0, 0.0}, arc.Advance)
+
+	// vertical-only commands are skipped and the scale applies to vectors
+	vertical, _ := font.Glyph(203)
+	assertNearPoints(t, []Point{{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}}, vertical.Strokes[0])
+	scaled, _ := font.Glyph(204)
+	assertNearVector(t, Vector{2.0, 0.0, 0.0}, scaled.Advance)
+}
+
+func TestParseUnifontAndBigFont(t *testing.T) {
+	unifont, err := ParseShapeFont(testUnifont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, unifont.IsUnifont, "expected a Unicode font")
+	assertEqString(t, "UNI", unifont.Name)
+	omega, err := unifont.Glyph(0x03a9)
+	assert(t, err == nil, "expected no error")
+	assertNearVector(t, Vector{6.0, 0.0, 0.0}, omega.Advance)
+
+	bigFont, err := ParseShapeFont(testBigFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, bigFont.IsBigFont, "expected a big font")
+	assertEqFloat64(t, 8.0, bigFont.Above)
+	assert(t, bigFont.HasShape(0x82a0), "expected the double-byte character")
+	assertEqInt(t, 0x82a0, int(bigFontCode('あ', "ANSI_932")))
+
+	_, err = ParseShapeFont([]byte("AutoCAD-86 mystery 1.0\r\n\x1a"))
+	assert(t, err != nil, "expected an unknown format to fail")
+}
+
+func TestExplodeShapeFontText(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Header.DrawingCodePage = "ANSI_932"
+	style := *NewStyle()
+	style.Name = "SIMPLE"
+	style.PrimaryFontFileName = "txt"
+	style.BigFontFileName = "big.shx"
+	drawing.Styles = append(drawing.Styles, style)
+
+	text := NewText()
+	text.SetLayer("TEXT")
+	text.TextStyleName = "SIMPLE"
+	text.Height = 10.0
+	text.Value = "L%%dあ"
+	entities, err := drawing.ExplodeText(text, testShapeFontDirectory(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, len(entities))
+	l := entities[0].(*LWPolyline)
+	assertEqString(t, "TEXT", l.Layer())
+	assert(t, !l.IsClosed(), "expected an open stroke")
+	assertEqInt(t, 3, len(l.Vertices))
+
+	// the degree sign is drawn by shape 127, and the big font is scaled to its own height
+	degree := entities[1].(*LWPolyline)
+	assertNearFloat64(t, 8.0, degree.Vertices[0].X)
+	big := entities[2].(*LWPolyline)
+	assertNearFloat64(t, 9.0, big.Vertices[0].X)
+	assertNearFloat64(t, 19.0, big.Vertices[1].X)
+
+	// the font's own metrics lay out the text
+	text.HorizontalTextJustification = HorizontalTextJustificationRight
+	text.SecondAlignmentPoint = Point{0.0, 0.0, 0.0}
+	text.Value = "LL"
+	geometry, _ := drawing.TextGeometry(text, testShapeFontDirectory(t))
+	assertNearFloat64(t, 16.0, geometry.Width)
+	assertNearFloat64(t, 12.0, geometry.Height)
+}
+
+func TestExplodeShape(t *testing.T) {
+	drawing := *NewDrawing()
+	style := *NewStyle()
+	style.Flags = 1
+	style.PrimaryFontFileName = "txt.shx"
+	drawing.Styles = append(drawing.Styles, style)
+
+	shape := NewShape()
+	shape.SetLayer("SHAPES")
+	shape.Name = "box"
+	shape.Size = 5.0
+	shape.Location = Point{1.0, 1.0, 0.0}
+	shape.RotationAngle = 90.0
+	entities, err := drawing.ExplodeShape(shape, testShapeFontDirectory(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(entities))
+	polyline := entities[0].(*LWPolyline)
+	assertEqString(t, "SHAPES", polyline.Layer())
+	assertNearFloat64(t, 1.0, polyline.Vertices[1].X)
+	assertNearFloat64(t, 3.0, polyline.Vertices[1].Y)
+	assertNearFloat64(t, -1.0, polyline.Vertices[2].X)
+
+	shape.Name = "missing"
+	_, err = drawing.ExplodeShape(shape, testShapeFontDirectory(t))
+	assert(t, err != nil, "expected a missing shape to fail")
+}
+
+func TestLineTypeShape(t *testing.T) {
+	drawing := *NewDrawing()
+	style := *NewStyle()
+	style.Flags = 1
+	style.PrimaryFontFileName = "txt"
+	style.SetHandle(0x42)
+	drawing.Styles = append(drawing.Styles, style)
+
+	lineType := NewLineType()
+	lineType.Name = "BOXES"
+	lineType.DashDotSpaceLengths = []float64{0.5, -2.0}
+	lineType.ComplexLineTypeElementTypes = []int16{0, 4}
+	lineType.ShapeNumbers = []int16{200}
+	lineType.StyleHandles = []string{"42"}
+	lineType.ScaleValues = []float64{10.0}
+	lineType.RotationAngles = []float64{math.Pi / 2.0}
+	lineType.XOffsets = []float64{1.0}
+	lineType.YOffsets = []float64{2.0}
+	strokes, err := drawing.LineTypeShape(lineType, 1, testShapeFontDirectory(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(strokes))
+	assertNearPoint(t, Point{1.0, 2.0, 0.0}, strokes[0][0])
+	assertNearPoint(t, Point{1.0, 6.0, 0.0}, strokes[0][1])
+
+	_, err = drawing.LineTypeShape(lineType, 0, testShapeFontDirectory(t))
+	assert(t, err != nil, "expected a simple element to fail")
+}
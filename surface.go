@@ -0,0 +1,419 @@
+package dxf
+
+// SweepOptions holds the settings used when a profile is extruded or swept along a path.
+type SweepOptions struct {
+	DraftAngle                     float64 // radians
+	DraftStartDistance             float64
+	DraftEndDistance               float64
+	TwistAngle                     float64 // radians
+	ScaleFactor                    float64
+	AlignAngle                     float64 // radians
+	SweepEntityTransform           Matrix4
+	PathEntityTransform            Matrix4
+	IsSolid                        bool
+	Alignment                      SweepAlignment
+	IsAlignedToStart               bool
+	IsBanked                       bool
+	IsBasePointSet                 bool
+	IsSweepEntityTransformComputed bool
+	IsPathEntityTransformComputed  bool
+	ReferenceVector                Vector // controls the twist
+}
+
+// NewSweepOptions creates the options for a sweep without draft, twist or scaling.
+func NewSweepOptions() *SweepOptions {
+	return &SweepOptions{
+		ScaleFactor:          1.0,
+		SweepEntityTransform: *NewIdentityMatrix4(),
+		PathEntityTransform:  *NewIdentityMatrix4(),
+		Alignment:            SweepAlignmentNone,
+	}
+}
+
+// surface is implemented by the entities with the AcDbSurface subclass.
+type surface interface {
+	ModelerGeometry
+	surfaceData() (readingSubclass *string, uIsolineCount, vIsolineCount *int16)
+}
+
+func (s *ExtrudedSurface) surfaceData() (readingSubclass *string, uIsolineCount, vIsolineCount *int16) {
+	return &s.readingSubclass, &s.UIsolineCount, &s.VIsolineCount
+}
+
+func (s *LoftedSurface) surfaceData() (readingSubclass *string, uIsolineCount, vIsolineCount *int16) {
+	return &s.readingSubclass, &s.UIsolineCount, &s.VIsolineCount
+}
+
+func (s *PlaneSurface) surfaceData() (readingSubclass *string, uIsolineCount, vIsolineCount *int16) {
+	return &s.readingSubclass, &s.UIsolineCount, &s.VIsolineCount
+}
+
+func (s *RevolvedSurface) surfaceData() (readingSubclass *string, uIsolineCount, vIsolineCount *int16) {
+	return &s.readingSubclass, &s.UIsolineCount, &s.VIsolineCount
+}
+
+func (s *SweptSurface) surfaceData() (readingSubclass *string, uIsolineCount, vIsolineCount *int16) {
+	return &s.readingSubclass, &s.UIsolineCount, &s.VIsolineCount
+}
+
+//
+// reading
+//
+
+// tryApplyCodePairForSurface applies the pairs of the subclasses shared by all surfaces.  Pairs that belong to the
+// surface's own subclass are left for the caller.
+func tryApplyCodePairForSurface(s surface, codePair CodePair) bool {
+	readingSubclass, uIsolineCount, vIsolineCount := s.surfaceData()
+	if codePair.Code == 100 {
+		*readingSubclass = codePair.Value.(StringCodePairValue).Value
+		return true
+	}
+
+	switch *readingSubclass {
+	case "", "AcDbEntity":
+		tryApplyCodePairForEntity(s, codePair)
+	case "AcDbModelerGeometry":
+		tryApplyCodePairForModelerGeometry(s, codePair)
+	case "AcDbSurface":
+		switch codePair.Code {
+		case 71:
+			*uIsolineCount = codePair.Value.(ShortCodePairValue).Value
+		case 72:
+			*vIsolineCount = codePair.Value.(ShortCodePairValue).Value
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// applyMatrixValue fills the matrix row by row as its 16 values are read.
+func applyMatrixValue(m *Matrix4, counts map[int]int, codePair CodePair) {
+	index := counts[codePair.Code]
+	if index < 16 {
+		m[index/4][index%4] = codePair.Value.(DoubleCodePairValue).Value
+		counts[codePair.Code] = index + 1
+	}
+}
+
+func (o *SweepOptions) tryApplyCodePair(counts map[int]int, codePair CodePair) bool {
+	switch codePair.Code {
+	case 42:
+		o.DraftAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 43:
+		o.DraftStartDistance = codePair.Value.(DoubleCodePairValue).Value
+	case 44:
+		o.DraftEndDistance = codePair.Value.(DoubleCodePairValue).Value
+	case 45:
+		o.TwistAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 48:
+		o.ScaleFactor = codePair.Value.(DoubleCodePairValue).Value
+	case 49:
+		o.AlignAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 46:
+		applyMatrixValue(&o.SweepEntityTransform, counts, codePair)
+	case 47:
+		applyMatrixValue(&o.PathEntityTransform, counts, codePair)
+	case 290:
+		o.IsSolid = codePair.Value.(BoolCodePairValue).Value
+	case 70:
+		o.Alignment = SweepAlignment(codePair.Value.(ShortCodePairValue).Value)
+	case 292:
+		o.IsAlignedToStart = codePair.Value.(BoolCodePairValue).Value
+	case 293:
+		o.IsBanked = codePair.Value.(BoolCodePairValue).Value
+	case 294:
+		o.IsBasePointSet = codePair.Value.(BoolCodePairValue).Value
+	case 295:
+		o.IsSweepEntityTransformComputed = codePair.Value.(BoolCodePairValue).Value
+	case 296:
+		o.IsPathEntityTransformComputed = codePair.Value.(BoolCodePairValue).Value
+	case 11:
+		o.ReferenceVector.X = codePair.Value.(DoubleCodePairValue).Value
+	case 21:
+		o.ReferenceVector.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 31:
+		o.ReferenceVector.Z = codePair.Value.(DoubleCodePairValue).Value
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *ExtrudedSurface) tryApplyCodePair(codePair CodePair) {
+	if tryApplyCodePairForSurface(s, codePair) {
+		return
+	}
+
+	switch codePair.Code {
+	case 90:
+		s.ClassID = codePair.Value.(IntCodePairValue).Value
+	case 10:
+		s.SweepVector.X = codePair.Value.(DoubleCodePairValue).Value
+	case 20:
+		s.SweepVector.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 30:
+		s.SweepVector.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 40:
+		applyMatrixValue(&s.ExtrudeEntityTransform, s.matrixValueCounts, codePair)
+	default:
+		s.SweepOptions.tryApplyCodePair(s.matrixValueCounts, codePair)
+	}
+}
+
+func (s *LoftedSurface) tryApplyCodePair(codePair CodePair) {
+	if tryApplyCodePairForSurface(s, codePair) {
+		return
+	}
+
+	switch codePair.Code {
+	case 40:
+		applyMatrixValue(&s.Transform, s.matrixValueCounts, codePair)
+	case 70:
+		s.NormalLoftingType = LoftedObjectNormalMode(codePair.Value.(ShortCodePairValue).Value)
+	case 41:
+		s.StartDraftAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 42:
+		s.EndDraftAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 43:
+		s.StartDraftMagnitude = codePair.Value.(DoubleCodePairValue).Value
+	case 44:
+		s.EndDraftMagnitude = codePair.Value.(DoubleCodePairValue).Value
+	case 290:
+		s.IsArcLengthParameterization = codePair.Value.(BoolCodePairValue).Value
+	case 291:
+		s.HasNoTwist = codePair.Value.(BoolCodePairValue).Value
+	case 292:
+		s.IsDirectionAligned = codePair.Value.(BoolCodePairValue).Value
+	case 293:
+		s.CreatesSimpleSurfaces = codePair.Value.(BoolCodePairValue).Value
+	case 294:
+		s.CreatesClosedSurfaces = codePair.Value.(BoolCodePairValue).Value
+	case 295:
+		s.IsSolid = codePair.Value.(BoolCodePairValue).Value
+	case 296:
+		s.CreatesRuledSurface = codePair.Value.(BoolCodePairValue).Value
+	case 297:
+		s.IsVirtualGuide = codePair.Value.(BoolCodePairValue).Value
+	}
+}
+
+func (s *PlaneSurface) tryApplyCodePair(codePair CodePair) {
+	// the AcDbPlaneSurface subclass has no data of its own
+	tryApplyCodePairForSurface(s, codePair)
+}
+
+func (s *RevolvedSurface) tryApplyCodePair(codePair CodePair) {
+	if tryApplyCodePairForSurface(s, codePair) {
+		return
+	}
+
+	switch codePair.Code {
+	case 90:
+		s.ClassID = codePair.Value.(IntCodePairValue).Value
+	case 10:
+		s.AxisPoint.X = codePair.Value.(DoubleCodePairValue).Value
+	case 20:
+		s.AxisPoint.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 30:
+		s.AxisPoint.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 11:
+		s.AxisDirection.X = codePair.Value.(DoubleCodePairValue).Value
+	case 21:
+		s.AxisDirection.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 31:
+		s.AxisDirection.Z = codePair.Value.(DoubleCodePairValue).Value
+	case 40:
+		s.RevolveAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 41:
+		s.StartAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 42:
+		applyMatrixValue(&s.RevolvedEntityTransform, s.matrixValueCounts, codePair)
+	case 43:
+		s.DraftAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 44:
+		s.StartDraftDistance = codePair.Value.(DoubleCodePairValue).Value
+	case 45:
+		s.EndDraftDistance = codePair.Value.(DoubleCodePairValue).Value
+	case 46:
+		s.TwistAngle = codePair.Value.(DoubleCodePairValue).Value
+	case 290:
+		s.IsSolid = codePair.Value.(BoolCodePairValue).Value
+	case 291:
+		s.IsCloseToAxis = codePair.Value.(BoolCodePairValue).Value
+	}
+}
+
+func (s *SweptSurface) tryApplyCodePair(codePair CodePair) {
+	if tryApplyCodePairForSurface(s, codePair) {
+		return
+	}
+
+	switch codePair.Code {
+	case 90:
+		// swept entity id, swept data size, path entity id, path data size
+		switch s.intValueCount {
+		case 0:
+			s.SweptEntityID = codePair.Value.(IntCodePairValue).Value
+		case 2:
+			s.PathEntityID = codePair.Value.(IntCodePairValue).Value
+		}
+		s.intValueCount++
+	case 310:
+		if s.intValueCount <= 2 {
+			s.sweptEntityDataStrings = append(s.sweptEntityDataStrings, codePair.Value.(StringCodePairValue).Value)
+		} else {
+			s.pathEntityDataStrings = append(s.pathEntityDataStrings, codePair.Value.(StringCodePairValue).Value)
+		}
+	case 40:
+		applyMatrixValue(&s.SweptEntityTransform, s.matrixValueCounts, codePair)
+	case 41:
+		applyMatrixValue(&s.PathTransform, s.matrixValueCounts, codePair)
+	default:
+		s.SweepOptions.tryApplyCodePair(s.matrixValueCounts, codePair)
+	}
+}
+
+func (s *SweptSurface) afterRead() {
+	s.SweptEntityData = stringsToBytes(s.sweptEntityDataStrings)
+	s.PathEntityData = stringsToBytes(s.pathEntityDataStrings)
+	s.sweptEntityDataStrings = []string{}
+	s.pathEntityDataStrings = []string{}
+}
+
+//
+// writing
+//
+
+func codePairsForSurface(s surface) (pairs []CodePair) {
+	_, uIsolineCount, vIsolineCount := s.surfaceData()
+	pairs = append(pairs, codePairsForModelerGeometry(s)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbSurface"))
+	pairs = append(pairs, NewShortCodePair(71, *uIsolineCount))
+	pairs = append(pairs, NewShortCodePair(72, *vIsolineCount))
+	return
+}
+
+func matrixCodePairs(code int, m Matrix4) (pairs []CodePair) {
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			pairs = append(pairs, NewDoubleCodePair(code, m[row][col]))
+		}
+	}
+	return
+}
+
+func (o *SweepOptions) codePairs() (pairs []CodePair) {
+	pairs = append(pairs, NewDoubleCodePair(42, o.DraftAngle))
+	pairs = append(pairs, NewDoubleCodePair(43, o.DraftStartDistance))
+	pairs = append(pairs, NewDoubleCodePair(44, o.DraftEndDistance))
+	pairs = append(pairs, NewDoubleCodePair(45, o.TwistAngle))
+	pairs = append(pairs, NewDoubleCodePair(48, o.ScaleFactor))
+	pairs = append(pairs, NewDoubleCodePair(49, o.AlignAngle))
+	pairs = append(pairs, matrixCodePairs(46, o.SweepEntityTransform)...)
+	pairs = append(pairs, matrixCodePairs(47, o.PathEntityTransform)...)
+	pairs = append(pairs, NewBoolCodePair(290, o.IsSolid))
+	pairs = append(pairs, NewShortCodePair(70, int16(o.Alignment)))
+	pairs = append(pairs, NewBoolCodePair(292, o.IsAlignedToStart))
+	pairs = append(pairs, NewBoolCodePair(293, o.IsBanked))
+	pairs = append(pairs, NewBoolCodePair(294, o.IsBasePointSet))
+	pairs = append(pairs, NewBoolCodePair(295, o.IsSweepEntityTransformComputed))
+	pairs = append(pairs, NewBoolCodePair(296, o.IsPathEntityTransformComputed))
+	pairs = append(pairs, NewDoubleCodePair(11, o.ReferenceVector.X))
+	pairs = append(pairs, NewDoubleCodePair(21, o.ReferenceVector.Y))
+	pairs = append(pairs, NewDoubleCodePair(31, o.ReferenceVector.Z))
+	return
+}
+
+func (s *ExtrudedSurface) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "EXTRUDEDSURFACE"))
+	pairs = append(pairs, codePairsForEntity(s, version)...)
+	pairs = append(pairs, codePairsForSurface(s)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbExtrudedSurface"))
+	pairs = append(pairs, NewIntCodePair(90, s.ClassID))
+	pairs = append(pairs, NewDoubleCodePair(10, s.SweepVector.X))
+	pairs = append(pairs, NewDoubleCodePair(20, s.SweepVector.Y))
+	pairs = append(pairs, NewDoubleCodePair(30, s.SweepVector.Z))
+	pairs = append(pairs, matrixCodePairs(40, s.ExtrudeEntityTransform)...)
+	pairs = append(pairs, s.SweepOptions.codePairs()...)
+	return
+}
+
+func (s *LoftedSurface) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "LOFTEDSURFACE"))
+	pairs = append(pairs, codePairsForEntity(s, version)...)
+	pairs = append(pairs, codePairsForSurface(s)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbLoftedSurface"))
+	pairs = append(pairs, matrixCodePairs(40, s.Transform)...)
+	pairs = append(pairs, NewShortCodePair(70, int16(s.NormalLoftingType)))
+	pairs = append(pairs, NewDoubleCodePair(41, s.StartDraftAngle))
+	pairs = append(pairs, NewDoubleCodePair(42, s.EndDraftAngle))
+	pairs = append(pairs, NewDoubleCodePair(43, s.StartDraftMagnitude))
+	pairs = append(pairs, NewDoubleCodePair(44, s.EndDraftMagnitude))
+	pairs = append(pairs, NewBoolCodePair(290, s.IsArcLengthParameterization))
+	pairs = append(pairs, NewBoolCodePair(291, s.HasNoTwist))
+	pairs = append(pairs, NewBoolCodePair(292, s.IsDirectionAligned))
+	pairs = append(pairs, NewBoolCodePair(293, s.CreatesSimpleSurfaces))
+	pairs = append(pairs, NewBoolCodePair(294, s.CreatesClosedSurfaces))
+	pairs = append(pairs, NewBoolCodePair(295, s.IsSolid))
+	pairs = append(pairs, NewBoolCodePair(296, s.CreatesRuledSurface))
+	pairs = append(pairs, NewBoolCodePair(297, s.IsVirtualGuide))
+	return
+}
+
+func (s *PlaneSurface) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "PLANESURFACE"))
+	pairs = append(pairs, codePairsForEntity(s, version)...)
+	pairs = append(pairs, codePairsForSurface(s)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbPlaneSurface"))
+	return
+}
+
+func (s *RevolvedSurface) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "REVOLVEDSURFACE"))
+	pairs = append(pairs, codePairsForEntity(s, version)...)
+	pairs = append(pairs, codePairsForSurface(s)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbRevolvedSurface"))
+	pairs = append(pairs, NewIntCodePair(90, s.ClassID))
+	pairs = append(pairs, NewDoubleCodePair(10, s.AxisPoint.X))
+	pairs = append(pairs, NewDoubleCodePair(20, s.AxisPoint.Y))
+	pairs = append(pairs, NewDoubleCodePair(30, s.AxisPoint.Z))
+	pairs = append(pairs, NewDoubleCodePair(11, s.AxisDirection.X))
+	pairs = append(pairs, NewDoubleCodePair(21, s.AxisDirection.Y))
+	pairs = append(pairs, NewDoubleCodePair(31, s.AxisDirection.Z))
+	pairs = append(pairs, NewDoubleCodePair(40, s.RevolveAngle))
+	pairs = append(pairs, NewDoubleCodePair(41, s.StartAngle))
+	pairs = append(pairs, matrixCodePairs(42, s.RevolvedEntityTransform)...)
+	pairs = append(pairs, NewDoubleCodePair(43, s.DraftAngle))
+	pairs = append(pairs, NewDoubleCodePair(44, s.StartDraftDistance))
+	pairs = append(pairs, NewDoubleCodePair(45, s.EndDraftDistance))
+	pairs = append(pairs, NewDoubleCodePair(46, s.TwistAngle))
+	pairs = append(pairs, NewBoolCodePair(290, s.IsSolid))
+	pairs = append(pairs, NewBoolCodePair(291, s.IsCloseToAxis))
+	return
+}
+
+func (s *SweptSurface) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "SWEPTSURFACE"))
+	pairs = append(pairs, codePairsForEntity(s, version)...)
+	pairs = append(pairs, codePairsForSurface(s)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbSweptSurface"))
+	pairs = append(pairs, NewIntCodePair(90, s.SweptEntityID))
+	pairs = append(pairs, NewIntCodePair(90, len(s.SweptEntityData)))
+	if len(s.SweptEntityData) > 0 {
+		for _, str := range bytesToStrings(s.SweptEntityData) {
+			pairs = append(pairs, NewStringCodePair(310, str))
+		}
+	}
+	pairs = append(pairs, NewIntCodePair(90, s.PathEntityID))
+	pairs = append(pairs, NewIntCodePair(90, len(s.PathEntityData)))
+	if len(s.PathEntityData) > 0 {
+		for _, str := range bytesToStrings(s.PathEntityData) {
+			pairs = append(pairs, NewStringCodePair(310, str))
+		}
+	}
+	pairs = append(pairs, matrixCodePairs(40, s.SweptEntityTransform)...)
+	pairs = append(pairs, matrixCodePairs(41, s.PathTransform)...)
+	pairs = append(pairs, s.SweepOptions.codePairs()...)
+	return
+}
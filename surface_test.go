@@ -0,0 +1,171 @@
+package dxf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAcisLinesRejoinContinuations(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	exact := strings.Repeat("b", 255)
+	body := NewBody()
+	body.SetAcisLines([]string{"short", long, exact, "last"})
+	assertEqInt(t, 4, len(body.CustomData))
+	assertEqInt(t, 2, len(body.CustomData2))
+	assertEqString(t, long[255:], body.CustomData2[0])
+	assertEqString(t, "", body.CustomData2[1])
+
+	lines := body.AcisLines()
+	assertEqInt(t, 4, len(lines))
+	assertEqString(t, "short", lines[0])
+	assertEqString(t, long, lines[1])
+	assertEqString(t, exact, lines[2])
+	assertEqString(t, "last", lines[3])
+}
+
+func TestReadModelerGeometryAcisLines(t *testing.T) {
+	solid := parseEntity(t, "3DSOLID",
+		NewStringCodePair(100, "AcDbModelerGeometry"),
+		NewShortCodePair(70, 1),
+		NewStringCodePair(1, strings.Repeat("a", 255)),
+		NewStringCodePair(1, "second"),
+		NewStringCodePair(3, "tail"),
+	).(*Solid3D)
+	var geometry ModelerGeometry = solid
+	lines := geometry.AcisLines()
+	assertEqInt(t, 2, len(lines))
+	assertEqString(t, strings.Repeat("a", 255)+"tail", lines[0])
+	assertEqString(t, "second", lines[1])
+}
+
+func TestReadExtrudedSurface(t *testing.T) {
+	s := parseEntity(t, "EXTRUDEDSURFACE",
+		NewStringCodePair(100, "AcDbEntity"),
+		NewStringCodePair(8, "surface-layer"),
+		NewStringCodePair(100, "AcDbModelerGeometry"),
+		NewShortCodePair(70, 1),
+		NewStringCodePair(1, "acis line"),
+		NewStringCodePair(100, "AcDbSurface"),
+		NewShortCodePair(71, 4),
+		NewShortCodePair(72, 5),
+		NewStringCodePair(100, "AcDbExtrudedSurface"),
+		NewIntCodePair(90, 7),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(30, 3.0),
+		NewDoubleCodePair(40, 2.0), // only the first matrix value differs from the identity
+		NewDoubleCodePair(40, 0.0),
+		NewDoubleCodePair(40, 0.0),
+		NewDoubleCodePair(40, 0.0),
+		NewDoubleCodePair(40, 0.0),
+		NewDoubleCodePair(40, 1.0),
+		NewDoubleCodePair(42, 0.25),
+		NewDoubleCodePair(48, 2.5),
+		NewBoolCodePair(290, true),
+		NewShortCodePair(70, 2),
+		NewDoubleCodePair(11, 1.0),
+	).(*ExtrudedSurface)
+	assertEqString(t, "surface-layer", s.Layer())
+	assertEqInt(t, 1, int(s.FormatVersionNumber))
+	assertEqInt(t, 1, len(s.AcisLines()))
+	assertEqInt(t, 4, int(s.UIsolineCount))
+	assertEqInt(t, 5, int(s.VIsolineCount))
+	assertEqInt(t, 7, s.ClassID)
+	assertEqVector(t, Vector{X: 0.0, Y: 0.0, Z: 3.0}, s.SweepVector)
+	assertEqFloat64(t, 2.0, s.ExtrudeEntityTransform[0][0])
+	assertEqFloat64(t, 1.0, s.ExtrudeEntityTransform[1][1])
+	assertEqFloat64(t, 0.25, s.SweepOptions.DraftAngle)
+	assertEqFloat64(t, 2.5, s.SweepOptions.ScaleFactor)
+	assert(t, s.SweepOptions.IsSolid, "expected solid")
+	assert(t, s.SweepOptions.Alignment == SweepAlignmentTranslateToPath, "expected translate to path")
+	assertEqFloat64(t, 1.0, s.SweepOptions.ReferenceVector.X)
+}
+
+func TestReadSweptSurfaceBinaryData(t *testing.T) {
+	s := parseEntity(t, "SWEPTSURFACE",
+		NewStringCodePair(100, "AcDbModelerGeometry"),
+		NewShortCodePair(70, 1),
+		NewStringCodePair(100, "AcDbSurface"),
+		NewStringCodePair(100, "AcDbSweptSurface"),
+		NewIntCodePair(90, 11),
+		NewIntCodePair(90, 2),
+		NewStringCodePair(310, "ABCD"),
+		NewIntCodePair(90, 12),
+		NewIntCodePair(90, 1),
+		NewStringCodePair(310, "EF"),
+		NewDoubleCodePair(41, 3.0),
+	).(*SweptSurface)
+	assertEqInt(t, 11, s.SweptEntityID)
+	assertEqByteArray(t, []byte{0xAB, 0xCD}, s.SweptEntityData)
+	assertEqInt(t, 12, s.PathEntityID)
+	assertEqByteArray(t, []byte{0xEF}, s.PathEntityData)
+	assertEqFloat64(t, 3.0, s.PathTransform[0][0])
+}
+
+func TestWriteRevolvedSurface(t *testing.T) {
+	s := NewRevolvedSurface()
+	s.SetAcisLines([]string{"acis line"})
+	s.AxisDirection = Vector{X: 1.0, Y: 0.0, Z: 0.0}
+	s.RevolveAngle = 3.0
+	s.IsSolid = true
+	actual := allCodePairs(s, R2007)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbModelerGeometry"),
+		NewShortCodePair(70, 1),
+		NewStringCodePair(1, "acis line"),
+		NewStringCodePair(100, "AcDbSurface"),
+		NewShortCodePair(71, 6),
+		NewShortCodePair(72, 6),
+		NewStringCodePair(100, "AcDbRevolvedSurface"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewDoubleCodePair(11, 1.0),
+		NewDoubleCodePair(21, 0.0),
+		NewDoubleCodePair(31, 0.0),
+		NewDoubleCodePair(40, 3.0),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewBoolCodePair(290, true),
+	}, actual)
+}
+
+func TestSurfacesAreNotWrittenBeforeR2007(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Entities = append(drawing.Entities, NewPlaneSurface())
+	drawing.Header.Version = R2004
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "PLANESURFACE"),
+	}, drawingCodePairs(t, drawing))
+}
+
+func TestRoundTripSurfaces(t *testing.T) {
+	lofted := NewLoftedSurface()
+	lofted.SetAcisLines([]string{strings.Repeat("x", 400)})
+	lofted.Transform[0][3] = 5.0
+	lofted.NormalLoftingType = LoftedObjectNormalModeAllCrossSections
+	lofted.CreatesRuledSurface = true
+	swept := NewSweptSurface()
+	swept.SweptEntityData = []byte{0x01, 0x02}
+	swept.SweepOptions.SweepEntityTransform[2][2] = 4.0
+	swept.SweepOptions.IsBanked = true
+
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2010
+	drawing.Entities = append(drawing.Entities, lofted, NewPlaneSurface(), swept)
+	drawing = roundTripDrawing(t, &drawing)
+	assertEqInt(t, 3, len(drawing.Entities))
+
+	rtLofted := drawing.Entities[0].(*LoftedSurface)
+	assertEqString(t, strings.Repeat("x", 400), rtLofted.AcisLines()[0])
+	assertEqFloat64(t, 5.0, rtLofted.Transform[0][3])
+	assert(t, rtLofted.NormalLoftingType == LoftedObjectNormalModeAllCrossSections, "expected all cross sections")
+	assert(t, rtLofted.CreatesRuledSurface, "expected ruled surface")
+
+	_ = drawing.Entities[1].(*PlaneSurface)
+
+	rtSwept := drawing.Entities[2].(*SweptSurface)
+	assertEqByteArray(t, []byte{0x01, 0x02}, rtSwept.SweptEntityData)
+	assertEqInt(t, 0, len(rtSwept.PathEntityData))
+	assertEqFloat64(t, 4.0, rtSwept.SweepOptions.SweepEntityTransform[2][2])
+	assert(t, rtSwept.SweepOptions.IsBanked, "expected banked sweep")
+}
@@ -0,0 +1,555 @@
+package dxf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxTableTextChunkLength is the longest cell text written in a single code pair.
+const maxTableTextChunkLength = 250
+
+// The values AutoCAD's STANDARD table style uses for cells without overrides.
+const (
+	tableDefaultTextHeight = 0.18
+	tableDefaultCellMargin = 0.06
+)
+
+// TableCell is a single cell of a Table.  A text cell can instead show the value of a field object.
+type TableCell struct {
+	Type              TableCellType
+	Flags             int16
+	IsMerged          bool // The cell is covered by a merged range anchored at another cell.
+	IsAutoFit         bool
+	MergedColumnCount int // The number of columns spanned by a merged range anchored at this cell.
+	MergedRowCount    int // The number of rows spanned by a merged range anchored at this cell.
+	OverrideFlags     int
+	VirtualEdgeFlags  int16
+	Rotation          float64 // Text rotation in radians.
+	Alignment         AttachmentPoint
+	TextHeight        float64 // Zero uses the height of the table style.
+	Text              string
+	TextStyleName     string
+	FieldHandle       Handle
+	BlockRecordHandle Handle
+	BlockScale        float64
+	BlockAttributes   []TableCellBlockAttribute
+}
+
+// TableCellBlockAttribute is the value of an attribute of the block shown in a cell.
+type TableCellBlockAttribute struct {
+	DefinitionHandle Handle
+	Value            string
+}
+
+// TableCellRange is an inclusive range of cells.
+type TableCellRange struct {
+	TopRow      int
+	LeftColumn  int
+	BottomRow   int
+	RightColumn int
+}
+
+// NewTableCell creates an empty text cell.
+func NewTableCell() *TableCell {
+	return &TableCell{
+		Type:              TableCellTypeText,
+		MergedColumnCount: 1,
+		MergedRowCount:    1,
+		Alignment:         AttachmentPointTopLeft,
+		BlockScale:        1.0,
+		BlockAttributes:   []TableCellBlockAttribute{},
+	}
+}
+
+// RowCount returns the number of rows in the table.
+func (t *Table) RowCount() int {
+	return len(t.RowHeights)
+}
+
+// ColumnCount returns the number of columns in the table.
+func (t *Table) ColumnCount() int {
+	return len(t.ColumnWidths)
+}
+
+// Cell returns the cell at the given row and column, or nil if it's outside the table.
+func (t *Table) Cell(row, column int) *TableCell {
+	if row < 0 || row >= t.RowCount() || column < 0 || column >= t.ColumnCount() {
+		return nil
+	}
+	index := row*t.ColumnCount() + column
+	if index >= len(t.Cells) {
+		return nil
+	}
+	return &t.Cells[index]
+}
+
+// SetCellText makes the cell at the given row and column a text cell showing `text`, removing any field.
+func (t *Table) SetCellText(row, column int, text string) error {
+	cell := t.Cell(row, column)
+	if cell == nil {
+		return fmt.Errorf("cell (%d, %d) is outside the table", row, column)
+	}
+	cell.Type = TableCellTypeText
+	cell.Text = text
+	cell.FieldHandle = 0
+	return nil
+}
+
+// AddRow appends a row of empty cells.
+func (t *Table) AddRow(height float64) {
+	t.RowHeights = append(t.RowHeights, height)
+	for i := 0; i < t.ColumnCount(); i++ {
+		t.Cells = append(t.Cells, *NewTableCell())
+	}
+}
+
+// AddColumn appends a column of empty cells.
+func (t *Table) AddColumn(width float64) {
+	columnCount := t.ColumnCount()
+	cells := make([]TableCell, 0, len(t.Cells)+t.RowCount())
+	for row := 0; row < t.RowCount(); row++ {
+		cells = append(cells, t.Cells[row*columnCount:(row+1)*columnCount]...)
+		cells = append(cells, *NewTableCell())
+	}
+	t.ColumnWidths = append(t.ColumnWidths, width)
+	t.Cells = cells
+}
+
+// MergeCells merges a range of cells into its top left cell.
+func (t *Table) MergeCells(r TableCellRange) error {
+	if r.BottomRow < r.TopRow || r.RightColumn < r.LeftColumn || t.Cell(r.TopRow, r.LeftColumn) == nil || t.Cell(r.BottomRow, r.RightColumn) == nil {
+		return fmt.Errorf("invalid cell range (%d, %d)-(%d, %d)", r.TopRow, r.LeftColumn, r.BottomRow, r.RightColumn)
+	}
+	for _, existing := range t.MergedRanges() {
+		if existing.TopRow <= r.BottomRow && r.TopRow <= existing.BottomRow && existing.LeftColumn <= r.RightColumn && r.LeftColumn <= existing.RightColumn {
+			return fmt.Errorf("cell range overlaps the merged range at (%d, %d)", existing.TopRow, existing.LeftColumn)
+		}
+	}
+
+	for row := r.TopRow; row <= r.BottomRow; row++ {
+		for column := r.LeftColumn; column <= r.RightColumn; column++ {
+			t.Cell(row, column).IsMerged = row != r.TopRow || column != r.LeftColumn
+		}
+	}
+	anchor := t.Cell(r.TopRow, r.LeftColumn)
+	anchor.MergedRowCount = r.BottomRow - r.TopRow + 1
+	anchor.MergedColumnCount = r.RightColumn - r.LeftColumn + 1
+	return nil
+}
+
+// MergedRanges returns the ranges of merged cells.
+func (t *Table) MergedRanges() (ranges []TableCellRange) {
+	for row := 0; row < t.RowCount(); row++ {
+		for column := 0; column < t.ColumnCount(); column++ {
+			cell := t.Cell(row, column)
+			if cell != nil && !cell.IsMerged && (cell.MergedRowCount > 1 || cell.MergedColumnCount > 1) {
+				ranges = append(ranges, TableCellRange{
+					TopRow:      row,
+					LeftColumn:  column,
+					BottomRow:   row + cell.MergedRowCount - 1,
+					RightColumn: column + cell.MergedColumnCount - 1,
+				})
+			}
+		}
+	}
+	return
+}
+
+//
+// reading
+//
+
+func (t *Table) tryApplyCodePair(codePair CodePair) {
+	if t.readingTableData {
+		// every cell reuses the same codes so they're parsed in order after reading
+		t.tablePairs = append(t.tablePairs, codePair)
+		return
+	}
+
+	switch codePair.Code {
+	case 100:
+		if codePair.Value.(StringCodePairValue).Value == "AcDbTable" {
+			t.readingTableData = true
+		}
+	case 2:
+		t.BlockName = codePair.Value.(StringCodePairValue).Value
+	case 10:
+		t.InsertionPoint.X = codePair.Value.(DoubleCodePairValue).Value
+	case 20:
+		t.InsertionPoint.Y = codePair.Value.(DoubleCodePairValue).Value
+	case 30:
+		t.InsertionPoint.Z = codePair.Value.(DoubleCodePairValue).Value
+	default:
+		tryApplyCodePairForEntity(t, codePair)
+	}
+}
+
+func (t *Table) afterRead() {
+	r := &orderedPairReader{pairs: t.tablePairs}
+	rowCount, columnCount := 0, 0
+	for r.peekCode() >= 0 && r.peekCode() != 171 {
+		switch r.peekCode() {
+		case 280:
+			t.Version = r.short(280)
+		case 342:
+			t.pointerStyle.handle = handleFromString(r.string(342))
+		case 343:
+			t.BlockRecordHandle = handleFromString(r.string(343))
+		case 11:
+			t.HorizontalDirection.X = r.double(11)
+		case 21:
+			t.HorizontalDirection.Y = r.double(21)
+		case 31:
+			t.HorizontalDirection.Z = r.double(31)
+		case 90:
+			t.Flags = r.int(90)
+		case 91:
+			rowCount = r.int(91)
+		case 92:
+			columnCount = r.int(92)
+		case 93:
+			t.TableOverrideFlags = r.int(93)
+		case 94:
+			t.BorderColorOverrideFlags = r.int(94)
+		case 95:
+			t.BorderLineweightOverrideFlags = r.int(95)
+		case 96:
+			t.BorderVisibilityOverrideFlags = r.int(96)
+		case 141:
+			t.RowHeights = append(t.RowHeights, r.double(141))
+		case 142:
+			t.ColumnWidths = append(t.ColumnWidths, r.double(142))
+		default:
+			// table style overrides aren't supported
+			r.next()
+		}
+	}
+
+	for r.peekCode() == 171 {
+		t.Cells = append(t.Cells, readTableCell(r))
+	}
+
+	// keep the sizes consistent with the declared counts
+	for len(t.RowHeights) < rowCount {
+		t.RowHeights = append(t.RowHeights, 0.0)
+	}
+	for len(t.ColumnWidths) < columnCount {
+		t.ColumnWidths = append(t.ColumnWidths, 0.0)
+	}
+	for len(t.Cells) < t.RowCount()*t.ColumnCount() {
+		t.Cells = append(t.Cells, *NewTableCell())
+	}
+
+	t.tablePairs = []CodePair{}
+	t.readingTableData = false
+}
+
+func readTableCell(r *orderedPairReader) TableCell {
+	cell := *NewTableCell()
+	cell.Type = TableCellType(r.short(171))
+	textChunks := []string{}
+	valueText := ""
+	for r.peekCode() >= 0 && r.peekCode() != 171 {
+		switch r.peekCode() {
+		case 172:
+			cell.Flags = r.short(172)
+		case 173:
+			cell.IsMerged = boolFromShort(r.short(173))
+		case 174:
+			cell.IsAutoFit = boolFromShort(r.short(174))
+		case 175:
+			cell.MergedColumnCount = int(r.short(175))
+		case 176:
+			cell.MergedRowCount = int(r.short(176))
+		case 91:
+			cell.OverrideFlags = r.int(91)
+		case 178:
+			cell.VirtualEdgeFlags = r.short(178)
+		case 145:
+			cell.Rotation = r.double(145)
+		case 170:
+			cell.Alignment = AttachmentPoint(r.short(170))
+		case 140:
+			cell.TextHeight = r.double(140)
+		case 344:
+			cell.FieldHandle = handleFromString(r.string(344))
+		case 2:
+			textChunks = append(textChunks, r.string(2))
+		case 1:
+			cell.Text = strings.Join(textChunks, "") + r.string(1)
+		case 7:
+			cell.TextStyleName = r.string(7)
+		case 340:
+			cell.BlockRecordHandle = handleFromString(r.string(340))
+		case 144:
+			cell.BlockScale = r.double(144)
+		case 331:
+			cell.BlockAttributes = append(cell.BlockAttributes, TableCellBlockAttribute{DefinitionHandle: handleFromString(r.string(331))})
+		case 300:
+			value := r.string(300)
+			if count := len(cell.BlockAttributes); count > 0 {
+				cell.BlockAttributes[count-1].Value = value
+			}
+		case 301:
+			// the typed cell value of newer versions repeats the text
+			r.next()
+			for r.peekCode() >= 0 && r.peekCode() != 304 && r.peekCode() != 171 {
+				if r.peekCode() == 302 {
+					valueText = r.string(302)
+				} else {
+					r.next()
+				}
+			}
+			r.string(304)
+		default:
+			r.next()
+		}
+	}
+	if len(cell.Text) == 0 && cell.Type == TableCellTypeText {
+		cell.Text = valueText
+	}
+	return cell
+}
+
+//
+// writing
+//
+
+func (t *Table) codePairs(version AcadVersion) (pairs []CodePair) {
+	pairs = append(pairs, NewStringCodePair(0, "ACAD_TABLE"))
+	pairs = append(pairs, codePairsForEntity(t, version)...)
+	pairs = append(pairs, NewStringCodePair(100, "AcDbBlockReference"))
+	pairs = append(pairs, NewStringCodePair(2, t.BlockName))
+	pairs = append(pairs, NewDoubleCodePair(10, t.InsertionPoint.X))
+	pairs = append(pairs, NewDoubleCodePair(20, t.InsertionPoint.Y))
+	pairs = append(pairs, NewDoubleCodePair(30, t.InsertionPoint.Z))
+	pairs = append(pairs, NewStringCodePair(100, "AcDbTable"))
+	pairs = append(pairs, NewShortCodePair(280, t.Version))
+	pairs = append(pairs, handleCodePair(342, t.pointerStyle.handle))
+	pairs = append(pairs, handleCodePair(343, t.BlockRecordHandle))
+	pairs = append(pairs, NewDoubleCodePair(11, t.HorizontalDirection.X))
+	pairs = append(pairs, NewDoubleCodePair(21, t.HorizontalDirection.Y))
+	pairs = append(pairs, NewDoubleCodePair(31, t.HorizontalDirection.Z))
+	pairs = append(pairs, NewIntCodePair(90, t.Flags))
+	pairs = append(pairs, NewIntCodePair(91, t.RowCount()))
+	pairs = append(pairs, NewIntCodePair(92, t.ColumnCount()))
+	pairs = append(pairs, NewIntCodePair(93, t.TableOverrideFlags))
+	pairs = append(pairs, NewIntCodePair(94, t.BorderColorOverrideFlags))
+	pairs = append(pairs, NewIntCodePair(95, t.BorderLineweightOverrideFlags))
+	pairs = append(pairs, NewIntCodePair(96, t.BorderVisibilityOverrideFlags))
+	for _, height := range t.RowHeights {
+		pairs = append(pairs, NewDoubleCodePair(141, height))
+	}
+	for _, width := range t.ColumnWidths {
+		pairs = append(pairs, NewDoubleCodePair(142, width))
+	}
+	for _, cell := range t.Cells {
+		pairs = append(pairs, cell.codePairs()...)
+	}
+	return
+}
+
+func (c *TableCell) codePairs() (pairs []CodePair) {
+	pairs = append(pairs, NewShortCodePair(171, int16(c.Type)))
+	pairs = append(pairs, NewShortCodePair(172, c.Flags))
+	pairs = append(pairs, NewShortCodePair(173, shortFromBool(c.IsMerged)))
+	pairs = append(pairs, NewShortCodePair(174, shortFromBool(c.IsAutoFit)))
+	pairs = append(pairs, NewShortCodePair(175, int16(c.MergedColumnCount)))
+	pairs = append(pairs, NewShortCodePair(176, int16(c.MergedRowCount)))
+	pairs = append(pairs, NewIntCodePair(91, c.OverrideFlags))
+	pairs = append(pairs, NewShortCodePair(178, c.VirtualEdgeFlags))
+	pairs = append(pairs, NewDoubleCodePair(145, c.Rotation))
+	if c.Alignment != AttachmentPointTopLeft {
+		pairs = append(pairs, NewShortCodePair(170, int16(c.Alignment)))
+	}
+	if c.TextHeight != 0.0 {
+		pairs = append(pairs, NewDoubleCodePair(140, c.TextHeight))
+	}
+	switch c.Type {
+	case TableCellTypeBlock:
+		pairs = append(pairs, handleCodePair(340, c.BlockRecordHandle))
+		pairs = append(pairs, NewDoubleCodePair(144, c.BlockScale))
+		pairs = append(pairs, NewShortCodePair(179, int16(len(c.BlockAttributes))))
+		for _, attribute := range c.BlockAttributes {
+			pairs = append(pairs, handleCodePair(331, attribute.DefinitionHandle))
+			pairs = append(pairs, NewStringCodePair(300, attribute.Value))
+		}
+	default:
+		if c.FieldHandle != 0 {
+			pairs = append(pairs, handleCodePair(344, c.FieldHandle))
+		}
+		text := c.Text
+		for len(text) > maxTableTextChunkLength {
+			pairs = append(pairs, NewStringCodePair(2, text[:maxTableTextChunkLength]))
+			text = text[maxTableTextChunkLength:]
+		}
+		pairs = append(pairs, NewStringCodePair(1, text))
+		if len(c.TextStyleName) > 0 {
+			pairs = append(pairs, NewStringCodePair(7, c.TextStyleName))
+		}
+	}
+	return
+}
+
+//
+// display block
+//
+
+// tableAxes returns the directions of increasing columns and rows.
+func (t *Table) tableAxes() (right, down Vector) {
+	right = t.HorizontalDirection.Normalize()
+	if right.IsZero(1.0e-12) {
+		right = *NewXAxis()
+	}
+	down = right.Cross(*NewZAxis()).Normalize()
+	return
+}
+
+// cellBox returns the offsets of the left and top edges of a cell and its size, including any merged cells.
+func (t *Table) cellBox(row, column int) (left, top, width, height float64) {
+	for i := 0; i < column; i++ {
+		left += t.ColumnWidths[i]
+	}
+	for i := 0; i < row; i++ {
+		top += t.RowHeights[i]
+	}
+	cell := t.Cell(row, column)
+	for i := column; i < column+cell.MergedColumnCount && i < t.ColumnCount(); i++ {
+		width += t.ColumnWidths[i]
+	}
+	for i := row; i < row+cell.MergedRowCount && i < t.RowCount(); i++ {
+		height += t.RowHeights[i]
+	}
+	return
+}
+
+// TableBlock builds the anonymous block that displays a table: the cell borders, the text of each text cell and an
+// insert of each block cell.
+func (d *Drawing) TableBlock(t *Table) *Block {
+	block := NewBlock()
+	block.Name = t.BlockName
+	right, down := t.tableAxes()
+	origin := *NewOrigin()
+	at := func(x, y float64) Point {
+		return origin.Add(right.Scale(x)).Add(down.Scale(y))
+	}
+
+	// shared borders are only drawn once
+	borders := map[string]bool{}
+	addBorder := func(x1, y1, x2, y2 float64) {
+		key := fmt.Sprintf("%g,%g,%g,%g", x1, y1, x2, y2)
+		if !borders[key] {
+			borders[key] = true
+			line := NewLine()
+			line.P1 = at(x1, y1)
+			line.P2 = at(x2, y2)
+			block.Entities = append(block.Entities, line)
+		}
+	}
+
+	for row := 0; row < t.RowCount(); row++ {
+		for column := 0; column < t.ColumnCount(); column++ {
+			cell := t.Cell(row, column)
+			if cell == nil || cell.IsMerged {
+				continue
+			}
+			left, top, width, height := t.cellBox(row, column)
+			addBorder(left, top, left+width, top)
+			addBorder(left, top+height, left+width, top+height)
+			addBorder(left, top, left, top+height)
+			addBorder(left+width, top, left+width, top+height)
+
+			// the alignment picks a column and a row of a 3x3 grid within the margins
+			alignment := int(cell.Alignment) - 1
+			if alignment < 0 || alignment > 8 {
+				alignment = 0
+			}
+			horizontal := float64(alignment % 3)
+			vertical := float64(alignment / 3)
+			x := left + tableDefaultCellMargin + horizontal*0.5*(width-2.0*tableDefaultCellMargin)
+			y := top + tableDefaultCellMargin + vertical*0.5*(height-2.0*tableDefaultCellMargin)
+
+			switch cell.Type {
+			case TableCellTypeBlock:
+				name := d.blockRecordName(cell.BlockRecordHandle)
+				if len(name) == 0 {
+					continue
+				}
+				insert := NewInsert()
+				insert.Name = name
+				insert.Location = at(left+width*0.5, top+height*0.5)
+				insert.XScaleFactor = cell.BlockScale
+				insert.YScaleFactor = cell.BlockScale
+				insert.ZScaleFactor = cell.BlockScale
+				insert.Rotation = math.Atan2(right.Y, right.X) * 180.0 / math.Pi
+				block.Entities = append(block.Entities, insert)
+			default:
+				if len(cell.Text) == 0 {
+					continue
+				}
+				text := NewMText()
+				text.InsertionPoint = at(x, y)
+				text.InitialTextHeight = tableDefaultTextHeight
+				if cell.TextHeight > 0.0 {
+					text.InitialTextHeight = cell.TextHeight
+				}
+				text.ReferenceRectangleWidth = math.Max(0.0, width-2.0*tableDefaultCellMargin)
+				text.AttachmentPoint = AttachmentPoint(alignment + 1)
+				text.XAxisDirection = right
+				text.RotationAngle = cell.Rotation
+				text.Text = cell.Text
+				if len(cell.TextStyleName) > 0 {
+					text.TextStyleName = cell.TextStyleName
+				}
+				block.Entities = append(block.Entities, text)
+			}
+		}
+	}
+	return block
+}
+
+func (d *Drawing) blockRecordName(h Handle) string {
+	for _, record := range d.BlockRecords {
+		if h != 0 && record.handle == h {
+			return record.Name
+		}
+	}
+	return ""
+}
+
+// nextAnonymousBlockName returns an unused name of the form `<prefix><n>`, e.g., `*T1`.
+func (d *Drawing) nextAnonymousBlockName(prefix string) string {
+	next := 1
+	for _, block := range d.Blocks {
+		if strings.HasPrefix(strings.ToUpper(block.Name), prefix) {
+			if n, err := strconv.Atoi(block.Name[len(prefix):]); err == nil && n >= next {
+				next = n + 1
+			}
+		}
+	}
+	return fmt.Sprintf("%s%d", prefix, next)
+}
+
+// updateTableBlocks regenerates the block of each table so it matches the cells when the drawing is saved.
+func (d *Drawing) updateTableBlocks() {
+	if d.Header.Version < R2004 {
+		return
+	}
+	for _, e := range d.Entities {
+		t, ok := e.(*Table)
+		if !ok {
+			continue
+		}
+		if len(t.BlockName) == 0 {
+			t.BlockName = d.nextAnonymousBlockName("*T")
+		}
+		block := *d.TableBlock(t)
+		if existing := d.findBlock(t.BlockName); existing != nil {
+			*existing = block
+		} else {
+			d.Blocks = append(d.Blocks, block)
+		}
+	}
+}
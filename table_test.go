@@ -0,0 +1,241 @@
+package dxf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadTable(t *testing.T) {
+	long := strings.Repeat("a", 250)
+	table := parseEntity(t, "ACAD_TABLE",
+		NewStringCodePair(100, "AcDbEntity"),
+		NewStringCodePair(8, "table-layer"),
+		NewStringCodePair(100, "AcDbBlockReference"),
+		NewStringCodePair(2, "*T3"),
+		NewDoubleCodePair(10, 1.0),
+		NewDoubleCodePair(20, 2.0),
+		NewDoubleCodePair(30, 0.0),
+		NewStringCodePair(100, "AcDbTable"),
+		NewShortCodePair(280, 0),
+		NewStringCodePair(342, "A1"),
+		NewStringCodePair(343, "B2"),
+		NewDoubleCodePair(11, 1.0),
+		NewDoubleCodePair(21, 0.0),
+		NewDoubleCodePair(31, 0.0),
+		NewIntCodePair(90, 22),
+		NewIntCodePair(91, 2),
+		NewIntCodePair(92, 2),
+		NewIntCodePair(93, 0),
+		NewIntCodePair(94, 0),
+		NewIntCodePair(95, 0),
+		NewIntCodePair(96, 0),
+		NewDoubleCodePair(141, 0.5),
+		NewDoubleCodePair(141, 0.25),
+		NewDoubleCodePair(142, 2.0),
+		NewDoubleCodePair(142, 3.0),
+		// a title merged across both columns
+		NewShortCodePair(171, 1),
+		NewShortCodePair(172, 0),
+		NewShortCodePair(173, 0),
+		NewShortCodePair(174, 0),
+		NewShortCodePair(175, 2),
+		NewShortCodePair(176, 1),
+		NewIntCodePair(91, 0),
+		NewShortCodePair(178, 0),
+		NewDoubleCodePair(145, 0.0),
+		NewStringCodePair(2, long),
+		NewStringCodePair(1, "title"),
+		NewStringCodePair(7, "STANDARD"),
+		NewShortCodePair(171, 1),
+		NewShortCodePair(173, 1),
+		NewStringCodePair(1, ""),
+		// a field with its value only in the typed cell value
+		NewShortCodePair(171, 1),
+		NewStringCodePair(344, "C3"),
+		NewStringCodePair(301, "CELL_VALUE"),
+		NewIntCodePair(93, 0),
+		NewIntCodePair(90, 4),
+		NewStringCodePair(1, "field value"),
+		NewStringCodePair(300, ""),
+		NewStringCodePair(302, "field value"),
+		NewStringCodePair(304, "ACVALUE_END"),
+		// a block with an attribute
+		NewShortCodePair(171, 2),
+		NewStringCodePair(340, "D4"),
+		NewDoubleCodePair(144, 0.5),
+		NewShortCodePair(179, 1),
+		NewStringCodePair(331, "E5"),
+		NewStringCodePair(300, "attribute value"),
+	).(*Table)
+	assertEqString(t, "table-layer", table.Layer())
+	assertEqString(t, "*T3", table.BlockName)
+	assertEqPoint(t, Point{X: 1.0, Y: 2.0, Z: 0.0}, table.InsertionPoint)
+	assertEqUInt64(t, 0xB2, uint64(table.BlockRecordHandle))
+	assertEqUInt64(t, 0xA1, uint64(table.pointerStyle.handle))
+	assertEqInt(t, 22, table.Flags)
+	assertEqInt(t, 2, table.RowCount())
+	assertEqInt(t, 2, table.ColumnCount())
+	assertEqFloat64(t, 0.25, table.RowHeights[1])
+	assertEqFloat64(t, 3.0, table.ColumnWidths[1])
+	assertEqInt(t, 4, len(table.Cells))
+
+	title := table.Cell(0, 0)
+	assertEqString(t, long+"title", title.Text)
+	assertEqString(t, "STANDARD", title.TextStyleName)
+	assert(t, table.Cell(0, 1).IsMerged, "expected a merged cell")
+	ranges := table.MergedRanges()
+	assertEqInt(t, 1, len(ranges))
+	assertEqInt(t, 1, ranges[0].RightColumn)
+	assertEqInt(t, 0, ranges[0].BottomRow)
+
+	field := table.Cell(1, 0)
+	assertEqUInt64(t, 0xC3, uint64(field.FieldHandle))
+	assertEqString(t, "field value", field.Text)
+
+	block := table.Cell(1, 1)
+	assert(t, block.Type == TableCellTypeBlock, "expected a block cell")
+	assertEqUInt64(t, 0xD4, uint64(block.BlockRecordHandle))
+	assertEqFloat64(t, 0.5, block.BlockScale)
+	assertEqInt(t, 1, len(block.BlockAttributes))
+	assertEqUInt64(t, 0xE5, uint64(block.BlockAttributes[0].DefinitionHandle))
+	assertEqString(t, "attribute value", block.BlockAttributes[0].Value)
+}
+
+func newTestTable() *Table {
+	table := NewTable()
+	table.AddColumn(2.0)
+	table.AddColumn(3.0)
+	table.AddRow(0.5)
+	table.AddRow(0.25)
+	return table
+}
+
+func TestTableCellEditing(t *testing.T) {
+	table := newTestTable()
+	assertEqInt(t, 4, len(table.Cells))
+	if err := table.SetCellText(1, 1, "value"); err != nil {
+		t.Fatal(err)
+	}
+	assertEqString(t, "value", table.Cell(1, 1).Text)
+	assert(t, table.SetCellText(2, 0, "outside") != nil, "expected an error outside the table")
+
+	table.AddColumn(1.0)
+	assertEqInt(t, 6, len(table.Cells))
+	assertEqString(t, "value", table.Cell(1, 1).Text)
+	assertEqString(t, "", table.Cell(1, 2).Text)
+
+	if err := table.MergeCells(TableCellRange{TopRow: 0, LeftColumn: 0, BottomRow: 0, RightColumn: 2}); err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, table.Cell(0, 0).MergedColumnCount)
+	assert(t, table.Cell(0, 2).IsMerged, "expected a merged cell")
+	assert(t, table.MergeCells(TableCellRange{TopRow: 0, LeftColumn: 1, BottomRow: 1, RightColumn: 1}) != nil, "expected an overlap error")
+}
+
+func TestWriteTable(t *testing.T) {
+	table := newTestTable()
+	table.SetCellText(0, 0, strings.Repeat("b", 260))
+	actual := allCodePairs(table, R2004)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbBlockReference"),
+		NewStringCodePair(2, ""),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewIntCodePair(90, 0),
+		NewIntCodePair(91, 2),
+		NewIntCodePair(92, 2),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewDoubleCodePair(141, 0.5),
+		NewDoubleCodePair(141, 0.25),
+		NewDoubleCodePair(142, 2.0),
+		NewDoubleCodePair(142, 3.0),
+		NewShortCodePair(171, 1),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(2, strings.Repeat("b", 250)),
+		NewStringCodePair(1, strings.Repeat("b", 10)),
+	}, actual)
+}
+
+func TestRoundTripTableRegeneratesBlock(t *testing.T) {
+	table := newTestTable()
+	table.SetCellText(0, 0, "header")
+	table.MergeCells(TableCellRange{TopRow: 0, LeftColumn: 0, BottomRow: 0, RightColumn: 1})
+	table.SetCellText(1, 1, "value")
+
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2004
+	drawing.Entities = append(drawing.Entities, table)
+	pairs := drawingCodePairs(t, drawing)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "BLOCK"),
+	}, pairs)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(2, "*T1"),
+	}, pairs)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(1, "header"),
+	}, pairs)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(1, "value"),
+	}, pairs)
+
+	drawing = roundTripDrawing(t, &drawing)
+	rt := drawing.Entities[0].(*Table)
+	assertEqString(t, "*T1", rt.BlockName)
+	assertEqString(t, "header", rt.Cell(0, 0).Text)
+	assertEqInt(t, 1, len(rt.MergedRanges()))
+
+	// editing the text updates the block on the next save
+	rt.SetCellText(1, 1, "edited")
+	block := drawing.TableBlock(rt)
+	texts := []string{}
+	for _, e := range block.Entities {
+		if mtext, ok := e.(*MText); ok {
+			texts = append(texts, mtext.Text)
+		}
+	}
+	assertEqInt(t, 2, len(texts))
+	assertEqString(t, "edited", texts[1])
+}
+
+func TestTableBlockLayout(t *testing.T) {
+	table := newTestTable()
+	table.SetCellText(1, 1, "value")
+	table.Cell(1, 1).Alignment = AttachmentPointMiddleCenter
+	drawing := NewDrawing()
+	block := drawing.TableBlock(table)
+
+	// a 2x2 grid without duplicated borders
+	lines := 0
+	var text *MText
+	for _, e := range block.Entities {
+		switch ent := e.(type) {
+		case *Line:
+			lines++
+		case *MText:
+			text = ent
+		}
+	}
+	assertEqInt(t, 12, lines)
+	assertNearPoint(t, Point{X: 3.5, Y: -0.625, Z: 0.0}, text.InsertionPoint)
+	assert(t, text.AttachmentPoint == AttachmentPointMiddleCenter, "expected middle center attachment")
+
+	b := BoundingBox(table)
+	assertNearPoint(t, Point{X: 0.0, Y: -0.75, Z: 0.0}, b.Min)
+	assertNearPoint(t, Point{X: 5.0, Y: 0.0, Z: 0.0}, b.Max)
+}
+
+func TestTableNotWrittenBeforeR2004(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Header.Version = R2000
+	drawing.Entities = append(drawing.Entities, newTestTable())
+	pairs := drawingCodePairs(t, drawing)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "ACAD_TABLE"),
+	}, pairs)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(2, "*T1"),
+	}, pairs)
+}
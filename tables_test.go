@@ -95,6 +95,42 @@ func TestReadTableWithHandle(t *testing.T) {
 	assertEqString(t, "vport-name", drawing.ViewPorts[0].Name)
 }
 
+func TestReadTableItemHandle(t *testing.T) {
+	drawing := parseTableItem(t, "LAYER",
+		NewStringCodePair(5, "30"),
+		NewStringCodePair(2, "layer-name"),
+	)
+	assertEqUInt64(t, 0x30, uint64(drawing.Layers[0].Handle()))
+	drawing = parseTableItem(t, "DIMSTYLE",
+		NewStringCodePair(105, "31"),
+		NewStringCodePair(2, "dimstyle-name"),
+	)
+	assertEqUInt64(t, 0x31, uint64(drawing.DimStyles[0].Handle()))
+}
+
+func TestWriteTableItemKeepsHandle(t *testing.T) {
+	drawing := parseTableItem(t, "LAYER",
+		NewStringCodePair(5, "3"),
+		NewStringCodePair(2, "layer-name"),
+	)
+	drawing.Header.Version = R2000
+	actual := drawingCodePairs(t, drawing)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(0, "LAYER"),
+		NewStringCodePair(5, "3"),
+		NewStringCodePair(100, "AcDbSymbolTableRecord"),
+	}, actual)
+
+	// new handles don't reuse the kept one
+	count := 0
+	for _, pair := range actual {
+		if pair.Code == 5 && pair.Value.(StringCodePairValue).Value == "3" {
+			count++
+		}
+	}
+	assertEqInt(t, 1, count)
+}
+
 func TestUnsupportedTable(t *testing.T) {
 	drawing := parseFromCodePairs(t,
 		NewStringCodePair(0, "SECTION"),
@@ -0,0 +1,238 @@
+package dxf
+
+import (
+	"math"
+)
+
+// defaultTessellationTolerance is used when a non-positive tolerance is requested.
+const defaultTessellationTolerance = 0.01
+
+// maxTessellationSegments limits the number of segments produced for a single curve.
+const maxTessellationSegments = 100000
+
+// Tessellate approximates the geometry of an entity as polylines in world coordinates.  Each returned path is a
+// continuous series of points; closed paths repeat their first point at the end.  The distance between a curve and
+// each segment approximating it, the chord height, doesn't exceed `tolerance`.  Entities without curve geometry
+// return nil.
+func Tessellate(e Entity, tolerance float64) [][]Point {
+	if tolerance <= 0.0 {
+		tolerance = defaultTessellationTolerance
+	}
+
+	switch ent := e.(type) {
+	case *Line:
+		return [][]Point{{ent.P1, ent.P2}}
+	case *Circle:
+		return [][]Point{tessellateOcsArc(ent.Center, ent.Radius, ent.Normal, 0.0, 2.0*math.Pi, tolerance)}
+	case *Arc:
+		start := ent.StartAngle * math.Pi / 180.0
+		end := ent.EndAngle * math.Pi / 180.0
+		for end <= start {
+			end += 2.0 * math.Pi
+		}
+		return [][]Point{tessellateOcsArc(ent.Center, ent.Radius, ent.Normal, start, end, tolerance)}
+	case *Ellipse:
+		minorAxis := ent.Normal.Normalize().Cross(ent.MajorAxis).Scale(ent.MinorAxisRatio)
+		end := ent.EndAngle
+		for end <= ent.StartAngle {
+			end += 2.0 * math.Pi
+		}
+		return [][]Point{tessellateEllipticalArc(ent.Center, ent.MajorAxis, minorAxis, ent.StartAngle, end, tolerance)}
+	case *LWPolyline:
+		points := make([]Point, len(ent.Vertices))
+		bulges := make([]float64, len(ent.Vertices))
+		for i, v := range ent.Vertices {
+			points[i] = Point{X: v.X, Y: v.Y, Z: ent.Elevation()}
+			bulges[i] = v.Bulge
+		}
+		return [][]Point{tessellateBulgePolyline(points, bulges, ent.IsClosed(), ent.ExtrusionDirection, tolerance)}
+	case *Polyline:
+		if ent.IsPolyfaceMesh() || ent.Is3DPolygonMesh() {
+			return nil
+		}
+		if ent.Is3DPolyline() {
+			points := make([]Point, 0, len(ent.Vertices)+1)
+			for _, v := range ent.Vertices {
+				points = append(points, v.Location)
+			}
+			if ent.IsClosed() && len(points) > 0 {
+				points = append(points, points[0])
+			}
+			return [][]Point{points}
+		}
+		points := make([]Point, len(ent.Vertices))
+		bulges := make([]float64, len(ent.Vertices))
+		for i, v := range ent.Vertices {
+			points[i] = Point{X: v.Location.X, Y: v.Location.Y, Z: ent.Location.Z}
+			bulges[i] = v.Bulge
+		}
+		return [][]Point{tessellateBulgePolyline(points, bulges, ent.IsClosed(), ent.Normal, tolerance)}
+	case *Spline:
+		points := tessellateSpline(ent, tolerance)
+		if len(points) == 0 {
+			return nil
+		}
+		return [][]Point{points}
+	case *Helix:
+		return [][]Point{tessellateHelix(ent, tolerance)}
+	case *Leader:
+		if ent.PathType == LeaderPathTypeSpline && len(ent.Vertices) > 2 {
+			spline := NewSpline()
+			spline.DegreeOfCurve = 3
+			spline.KnotValues, spline.ControlPoints = interpolateCubic(ent.Vertices, Vector{}, Vector{})
+			return [][]Point{tessellateSpline(spline, tolerance)}
+		}
+		return [][]Point{append([]Point{}, ent.Vertices...)}
+	}
+
+	return nil
+}
+
+// arcSegmentAngle returns the largest angle an arc of the given radius can span per segment within the chord height
+// tolerance.
+func arcSegmentAngle(radius, tolerance float64) float64 {
+	if tolerance >= radius {
+		return math.Pi / 2.0
+	}
+	return math.Min(math.Pi/2.0, 2.0*math.Acos(1.0-tolerance/radius))
+}
+
+func segmentCount(sweep, maxAngle float64) int {
+	count := int(math.Ceil(math.Abs(sweep)/maxAngle - transformTolerance))
+	if count < 1 {
+		count = 1
+	}
+	if count > maxTessellationSegments {
+		count = maxTessellationSegments
+	}
+	return count
+}
+
+// tessellateEllipticalArc approximates the curve `center + u*cos(t) + v*sin(t)` for `t` from `start` to `end`.
+func tessellateEllipticalArc(center Point, u, v Vector, start, end, tolerance float64) []Point {
+	radius := math.Max(u.Length(), v.Length())
+	count := segmentCount(end-start, arcSegmentAngle(radius, tolerance))
+	points := make([]Point, count+1)
+	for i := 0; i <= count; i++ {
+		t := start + (end-start)*float64(i)/float64(count)
+		points[i] = center.Add(u.Scale(math.Cos(t)).Add(v.Scale(math.Sin(t))))
+	}
+	return points
+}
+
+func tessellateOcsArc(center Point, radius float64, normal Vector, start, end, tolerance float64) []Point {
+	xAxis, yAxis := normal.ArbitraryAxes()
+	return tessellateEllipticalArc(center.OcsToWcs(normal), xAxis.Scale(radius), yAxis.Scale(radius), start, end, tolerance)
+}
+
+func tessellateBulgePolyline(points []Point, bulges []float64, closed bool, normal Vector, tolerance float64) []Point {
+	result := make([]Point, 0, len(points)+1)
+	if len(points) == 0 {
+		return result
+	}
+
+	result = append(result, points[0].OcsToWcs(normal))
+	segments := len(points) - 1
+	if closed {
+		segments = len(points)
+	}
+	for i := 0; i < segments; i++ {
+		start := points[i]
+		end := points[(i+1)%len(points)]
+		if bulges[i] != 0.0 && start.DistanceTo(end) > 0.0 {
+			center, radius, startAngle, sweep := bulgeArc(start, end, bulges[i])
+			arc := tessellateOcsArc(center, radius, normal, startAngle, startAngle+sweep, tolerance)
+			result = append(result, arc[1:len(arc)-1]...)
+		}
+		result = append(result, end.OcsToWcs(normal))
+	}
+	return result
+}
+
+// tessellateSpline subdivides each knot span until the midpoint of every segment is within the tolerance.
+func tessellateSpline(s *Spline, tolerance float64) []Point {
+	if !s.hasValidKnots() {
+		if len(s.FitPoints) < 2 {
+			return s.samplePoints(1)
+		}
+
+		// only the fit points are known; pass a cubic curve through them
+		fitted := *s
+		if err := fitted.ComputeControlPoints(); err != nil {
+			return s.samplePoints(1)
+		}
+		s = &fitted
+	}
+
+	pointAt := func(t float64) Point {
+		return nurbsPoint(s.DegreeOfCurve, s.KnotValues, s.ControlPoints, t)
+	}
+	var subdivide func(t0, t1 float64, p0, p1 Point, depth int, points []Point) []Point
+	subdivide = func(t0, t1 float64, p0, p1 Point, depth int, points []Point) []Point {
+		tm := (t0 + t1) / 2.0
+		pm := pointAt(tm)
+		if depth >= 20 || distanceToSegment(pm, p0, p1) <= tolerance {
+			return append(points, p1)
+		}
+		points = subdivide(t0, tm, p0, pm, depth+1, points)
+		return subdivide(tm, t1, pm, p1, depth+1, points)
+	}
+
+	// start from a few pieces per span so symmetric bulges can't hide from the midpoint test
+	start, end := s.parameterRange()
+	points := []Point{pointAt(start)}
+	pieces := 2 * s.DegreeOfCurve
+	for i := s.DegreeOfCurve; i < len(s.ControlPoints); i++ {
+		t0 := math.Max(s.KnotValues[i], start)
+		t1 := math.Min(s.KnotValues[i+1], end)
+		if t1 <= t0 {
+			continue
+		}
+		for j := 0; j < pieces; j++ {
+			a := t0 + (t1-t0)*float64(j)/float64(pieces)
+			b := t0 + (t1-t0)*float64(j+1)/float64(pieces)
+			points = subdivide(a, b, points[len(points)-1], pointAt(b), 0, points)
+		}
+	}
+	return points
+}
+
+func tessellateHelix(h *Helix, tolerance float64) []Point {
+	axis := h.AxisVector.Normalize()
+	if axis.IsZero(0.0) {
+		return []Point{h.StartPoint}
+	}
+
+	radial := h.StartPoint.Sub(h.AxisBasePoint)
+	height := radial.Dot(axis)
+	xAxis := radial.Sub(axis.Scale(height))
+	radius := xAxis.Length()
+	if radius == 0.0 {
+		return []Point{h.StartPoint}
+	}
+	yAxis := axis.Cross(xAxis)
+	if !h.IsRightHanded {
+		yAxis = yAxis.Scale(-1.0)
+	}
+
+	sweep := 2.0 * math.Pi * h.NumberOfTurns
+	count := segmentCount(sweep, arcSegmentAngle(radius, tolerance))
+	points := make([]Point, count+1)
+	for i := 0; i <= count; i++ {
+		t := sweep * float64(i) / float64(count)
+		offset := xAxis.Scale(math.Cos(t)).Add(yAxis.Scale(math.Sin(t))).Add(axis.Scale(height + h.TurnHeight*t/(2.0*math.Pi)))
+		points[i] = h.AxisBasePoint.Add(offset)
+	}
+	return points
+}
+
+// distanceToSegment returns the distance from `p` to the line segment between `a` and `b`.
+func distanceToSegment(p, a, b Point) float64 {
+	ab := b.Sub(a)
+	lengthSquared := ab.Dot(ab)
+	if lengthSquared == 0.0 {
+		return p.DistanceTo(a)
+	}
+	t := math.Max(0.0, math.Min(1.0, p.Sub(a).Dot(ab)/lengthSquared))
+	return p.DistanceTo(a.Add(ab.Scale(t)))
+}
@@ -0,0 +1,127 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+func assertWithinTolerance(t *testing.T, points []Point, tolerance float64, onCurve func(p Point) float64) {
+	for i := 1; i < len(points); i++ {
+		mid := points[i-1].Lerp(points[i], 0.5)
+		if deviation := onCurve(mid); deviation > tolerance*1.0001 {
+			t.Errorf("segment %d deviates by %f", i, deviation)
+		}
+	}
+}
+
+func TestTessellateCircle(t *testing.T) {
+	circle := NewCircle()
+	circle.Center = Point{1.0, 1.0, 0.0}
+	circle.Radius = 10.0
+	paths := Tessellate(circle, 0.01)
+	assertEqInt(t, 1, len(paths))
+	points := paths[0]
+	assertNearPoint(t, points[0], points[len(points)-1])
+	assertWithinTolerance(t, points, 0.01, func(p Point) float64 {
+		return 10.0 - p.DistanceTo(circle.Center)
+	})
+}
+
+func TestTessellateMirroredArc(t *testing.T) {
+	arc := NewArc()
+	arc.Radius = 1.0
+	arc.Normal = Vector{0.0, 0.0, -1.0}
+	arc.StartAngle = 0.0
+	arc.EndAngle = 90.0
+	points := Tessellate(arc, 0.001)[0]
+	assertNearPoint(t, Point{-1.0, 0.0, 0.0}, points[0])
+	assertNearPoint(t, Point{0.0, 1.0, 0.0}, points[len(points)-1])
+}
+
+func TestTessellateEllipseParameters(t *testing.T) {
+	ellipse := NewEllipse()
+	ellipse.MajorAxis = Vector{2.0, 0.0, 0.0}
+	ellipse.MinorAxisRatio = 0.5
+	ellipse.StartAngle = 0.0
+	ellipse.EndAngle = math.Pi
+	points := Tessellate(ellipse, 0.001)[0]
+	assertNearPoint(t, Point{2.0, 0.0, 0.0}, points[0])
+	assertNearPoint(t, Point{-2.0, 0.0, 0.0}, points[len(points)-1])
+}
+
+func TestTessellateBulgedLWPolyline(t *testing.T) {
+	poly := NewLWPolyline()
+	poly.Vertices = []LwVertex{{X: 0.0, Y: 0.0}, {X: 2.0, Y: 0.0, Bulge: 1.0}, {X: 2.0, Y: 2.0}}
+	points := Tessellate(poly, 0.001)[0]
+	assertNearPoint(t, Point{0.0, 0.0, 0.0}, points[0])
+	assertNearPoint(t, Point{2.0, 0.0, 0.0}, points[1])
+	assertNearPoint(t, Point{2.0, 2.0, 0.0}, points[len(points)-1])
+	for _, p := range points[2 : len(points)-1] {
+		assertNearFloat64(t, 1.0, p.DistanceTo(Point{2.0, 1.0, 0.0}))
+		assert(t, p.X > 2.0, "expected the counter-clockwise arc to bulge to the right")
+	}
+}
+
+func TestTessellateSpline(t *testing.T) {
+	spline := NewSpline()
+	spline.DegreeOfCurve = 2
+	spline.KnotValues = []float64{0.0, 0.0, 0.0, 1.0, 1.0, 1.0}
+	spline.ControlPoints = []ControlPoint{
+		{Point{1.0, 0.0, 0.0}, 1.0},
+		{Point{1.0, 1.0, 0.0}, math.Sqrt(0.5)},
+		{Point{0.0, 1.0, 0.0}, 1.0},
+	}
+	points := Tessellate(spline, 0.0001)[0]
+	assertNearPoint(t, Point{1.0, 0.0, 0.0}, points[0])
+	assertNearPoint(t, Point{0.0, 1.0, 0.0}, points[len(points)-1])
+	for _, p := range points {
+		assertNearFloat64(t, 1.0, p.DistanceTo(*NewOrigin()))
+	}
+	assertWithinTolerance(t, points, 0.0001, func(p Point) float64 {
+		return 1.0 - p.DistanceTo(*NewOrigin())
+	})
+}
+
+func TestTessellateHelix(t *testing.T) {
+	helix := NewHelix()
+	helix.AxisVector = *NewZAxis()
+	helix.StartPoint = Point{1.0, 0.0, 0.0}
+	helix.NumberOfTurns = 2.0
+	helix.TurnHeight = 3.0
+	helix.IsRightHanded = true
+	points := Tessellate(helix, 0.001)[0]
+	assertNearPoint(t, Point{1.0, 0.0, 0.0}, points[0])
+	assertNearPoint(t, Point{1.0, 0.0, 6.0}, points[len(points)-1])
+	assert(t, points[1].Y > 0.0, "expected a right handed helix to turn counter-clockwise")
+}
+
+func TestTessellateLeaderSplinePassesThroughVertices(t *testing.T) {
+	leader := NewLeader()
+	leader.PathType = LeaderPathTypeSpline
+	leader.Vertices = []Point{{0.0, 0.0, 0.0}, {1.0, 1.0, 0.0}, {3.0, 1.0, 0.0}, {4.0, 0.0, 0.0}}
+	points := Tessellate(leader, 0.001)[0]
+	assertNearPoint(t, leader.Vertices[0], points[0])
+	assertNearPoint(t, leader.Vertices[3], points[len(points)-1])
+	for _, vertex := range leader.Vertices {
+		closest := math.MaxFloat64
+		for _, p := range points {
+			closest = math.Min(closest, p.DistanceTo(vertex))
+		}
+		assert(t, closest < 0.01, "expected the path to pass through every vertex")
+	}
+}
+
+func TestTessellateSplineFromFitPoints(t *testing.T) {
+	spline := NewSpline()
+	spline.FitPoints = []Point{{0.0, 0.0, 0.0}, {1.0, 2.0, 0.0}, {3.0, 2.0, 0.0}, {4.0, 0.0, 0.0}}
+	spline.StartTangent = Vector{0.0, 1.0, 0.0}
+	spline.EndTangent = Vector{0.0, -1.0, 0.0}
+	points := Tessellate(spline, 0.001)[0]
+	assertNearPoint(t, spline.FitPoints[0], points[0])
+	assertNearPoint(t, spline.FitPoints[3], points[len(points)-1])
+	assert(t, points[1].Y > 0.0 && math.Abs(points[1].X) < 0.1*points[1].Y, "expected the curve to leave along the start tangent")
+}
+
+func TestTessellateEmptySpline(t *testing.T) {
+	assertEqInt(t, 0, len(Tessellate(NewSpline(), 0.001)))
+}
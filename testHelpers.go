@@ -2,6 +2,7 @@ package dxf
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
 	"testing"
@@ -125,6 +126,24 @@ func assertEqVector(t *testing.T, expected, actual Vector) {
 	}
 }
 
+func assertNearFloat64(t *testing.T, expected, actual float64) {
+	if math.Abs(expected-actual) > 1.0e-9 {
+		t.Errorf("Expected: %f\nActual: %f", expected, actual)
+	}
+}
+
+func assertNearPoint(t *testing.T, expected, actual Point) {
+	if expected.DistanceTo(actual) > 1.0e-9 {
+		t.Errorf("Expected: %s\nActual: %s", expected.String(), actual.String())
+	}
+}
+
+func assertNearVector(t *testing.T, expected, actual Vector) {
+	if !expected.Sub(actual).IsZero(1.0e-9) {
+		t.Errorf("Expected: %s\nActual: %s", expected.String(), actual.String())
+	}
+}
+
 func assertEqString(t *testing.T, expected, actual string) {
 	if expected != actual {
 		t.Errorf("Expected: %s\nActual: %s", expected, actual)
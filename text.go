@@ -0,0 +1,163 @@
+package dxf
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextRun is a piece of TEXT or ATTRIB content sharing its underline and overline state.
+type TextRun struct {
+	Text         string
+	IsUnderlined bool
+	IsOverlined  bool
+}
+
+// ParseTextRuns decodes the `%%` and `\U+XXXX` codes of a TEXT value, splitting it where `%%u` and `%%o` toggle
+// the underline and the overline.
+func ParseTextRuns(value string) []TextRun {
+	runs := []TextRun{}
+	current := TextRun{}
+	var builder strings.Builder
+	flush := func() {
+		if builder.Len() > 0 {
+			current.Text = builder.String()
+			runs = append(runs, current)
+			builder.Reset()
+		}
+	}
+
+	for i := 0; i < len(value); {
+		switch {
+		case strings.HasPrefix(value[i:], "%%") && i+2 < len(value):
+			code := value[i+2]
+			if r, ok := specialCharacter(code); ok {
+				builder.WriteRune(r)
+				i += 3
+				continue
+			}
+			switch code {
+			case 'u', 'U':
+				flush()
+				current.IsUnderlined = !current.IsUnderlined
+				i += 3
+				continue
+			case 'o', 'O':
+				flush()
+				current.IsOverlined = !current.IsOverlined
+				i += 3
+				continue
+			}
+			if i+5 <= len(value) && isDecimalDigits(value[i+2:i+5]) {
+				n, _ := strconv.Atoi(value[i+2 : i+5])
+				builder.WriteRune(rune(n))
+				i += 5
+				continue
+			}
+		case strings.HasPrefix(value[i:], "\\U+") && i+7 <= len(value):
+			if n, err := strconv.ParseUint(value[i+3:i+7], 16, 32); err == nil {
+				builder.WriteRune(rune(n))
+				i += 7
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(value[i:])
+		builder.WriteRune(r)
+		i += size
+	}
+	flush()
+	return runs
+}
+
+// DecodeText returns a TEXT value with its special codes replaced by the characters they stand for, e.g.,
+// `%%c25 %%p0.1` becomes `Ø25 ±0.1`.  Underline and overline toggles are dropped.
+func DecodeText(value string) string {
+	var builder strings.Builder
+	for _, run := range ParseTextRuns(value) {
+		builder.WriteString(run.Text)
+	}
+	return builder.String()
+}
+
+// EncodeText returns the TEXT value showing `text`, using the special codes for the diameter, degree and
+// plus/minus signs.
+func EncodeText(text string) string {
+	return encodeText(text, false)
+}
+
+// isDecimalDigits returns whether `s` is made only of ASCII digits.
+func isDecimalDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeText encodes `text`; `isFollowedByCode` tells if a `%%` code comes next, so a final percent sign needs
+// escaping.
+func encodeText(text string, isFollowedByCode bool) string {
+	var builder strings.Builder
+	for i, r := range text {
+		switch r {
+		case 'Ø':
+			builder.WriteString("%%c")
+		case '°':
+			builder.WriteString("%%d")
+		case '±':
+			builder.WriteString("%%p")
+		case '%':
+			// only a percent sign starting a code needs escaping
+			next, _ := utf8.DecodeRuneInString(text[i+1:])
+			if strings.ContainsRune("%Ø°±", next) || (i+1 == len(text) && isFollowedByCode) {
+				builder.WriteString("%%%")
+			} else {
+				builder.WriteRune(r)
+			}
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// FormatTextRuns returns the TEXT value showing `runs`, toggling the underline and the overline between them.
+func FormatTextRuns(runs []TextRun) string {
+	toggles := make([]string, len(runs))
+	isUnderlined, isOverlined := false, false
+	for i, run := range runs {
+		if run.IsUnderlined != isUnderlined {
+			toggles[i] += "%%u"
+			isUnderlined = run.IsUnderlined
+		}
+		if run.IsOverlined != isOverlined {
+			toggles[i] += "%%o"
+			isOverlined = run.IsOverlined
+		}
+	}
+
+	// encode from the end to know what follows each run
+	parts := make([]string, len(runs))
+	next := ""
+	for i := len(runs) - 1; i >= 0; i-- {
+		parts[i] = toggles[i] + encodeText(runs[i].Text, strings.HasPrefix(next, "%"))
+		next = parts[i]
+	}
+	return strings.Join(parts, "")
+}
+
+// PlainText returns the value of the text with its special codes decoded.
+func (t *Text) PlainText() string {
+	return DecodeText(t.Value)
+}
+
+// PlainText returns the value of the attribute with its special codes decoded.
+func (a *Attribute) PlainText() string {
+	return DecodeText(a.Value)
+}
+
+// PlainText returns the value of the attribute definition with its special codes decoded.
+func (ad *AttributeDefinition) PlainText() string {
+	return DecodeText(ad.Value)
+}
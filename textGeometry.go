@@ -0,0 +1,306 @@
+package dxf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// GlyphMetrics measures the characters of a font for text layout.  Values are relative to a text height of 1.
+type GlyphMetrics interface {
+	// Advance returns how far the baseline advances past `r` in the font `fontName`.
+	Advance(fontName string, r rune) float64
+	// Descent returns how far the glyphs of the font `fontName` reach below the baseline.
+	Descent(fontName string) float64
+}
+
+// ApproximateGlyphMetrics gives every character the same advance and no descent, as `BoundingBox` does.
+type ApproximateGlyphMetrics struct{}
+
+func (ApproximateGlyphMetrics) Advance(fontName string, r rune) float64 {
+	return textCharacterWidth
+}
+
+func (ApproximateGlyphMetrics) Descent(fontName string) float64 {
+	return 0.0
+}
+
+// TextGeometry is the placement of a text in world coordinates.
+type TextGeometry struct {
+	Anchor  Point    // the start of the first baseline
+	XAxis   Vector   // the unit direction of the baseline
+	YAxis   Vector   // the unit direction up the text
+	Width   float64  // the width of the box along XAxis
+	Height  float64  // the height of the box along YAxis, including the descent
+	Corners [4]Point // the oriented box around the text, counterclockwise from the bottom left
+}
+
+// textFrame places a box given in local coordinates at `origin` along the axes.
+type textFrame struct {
+	origin Point
+	xAxis  Vector
+	yAxis  Vector
+}
+
+func (f textFrame) at(x, y float64) Point {
+	return f.origin.Add(f.xAxis.Scale(x)).Add(f.yAxis.Scale(y))
+}
+
+// textLine is a line of a text and the local start of its baseline.
+type textLine struct {
+	text string
+	x    float64
+	y    float64
+}
+
+// textLayout places the lines of a text in the local coordinates of its frame, with the box spanning x0..x1 and
+// y0..y1 before the oblique angle slants it.
+type textLayout struct {
+	frame        textFrame
+	fontName     string
+	bigFontName  string
+	height       float64
+	widthFactor  float64
+	obliqueAngle float64
+	lines        []textLine
+	x0, x1       float64
+	y0, y1       float64
+}
+
+// slant returns how far the text leans along its baseline per unit of height.
+func (l textLayout) slant() float64 {
+	return math.Tan(l.obliqueAngle * math.Pi / 180.0)
+}
+
+// geometry returns the placement of the box, widened by the oblique angle, and the start of the first baseline.
+func (l textLayout) geometry() TextGeometry {
+	first := l.lines[0]
+	top := (l.y1 - first.y) * l.slant()
+	bottom := (l.y0 - first.y) * l.slant()
+	x0 := l.x0 + math.Min(0.0, math.Min(top, bottom))
+	x1 := l.x1 + math.Max(0.0, math.Max(top, bottom))
+	f := l.frame
+	return TextGeometry{
+		Anchor:  f.at(first.x, first.y),
+		XAxis:   f.xAxis,
+		YAxis:   f.yAxis,
+		Width:   x1 - x0,
+		Height:  l.y1 - l.y0,
+		Corners: [4]Point{f.at(x0, l.y0), f.at(x1, l.y0), f.at(x1, l.y1), f.at(x0, l.y1)},
+	}
+}
+
+// textStyle returns the text style named `name`, or the defaults when the drawing has no such style.
+func (d *Drawing) textStyle(name string) Style {
+	for _, style := range d.Styles {
+		if strings.EqualFold(style.Name, name) {
+			return style
+		}
+	}
+	style := *NewStyle()
+	style.Name = name
+	return style
+}
+
+// measureText returns the width of `text` for the given height and width factor.
+func measureText(metrics GlyphMetrics, fontName, text string, height, widthFactor float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		width += metrics.Advance(fontName, r)
+	}
+	return width * height * widthFactor
+}
+
+// TextGeometry returns the oriented box and the baseline anchor of a `Text`, `Attribute`, `AttributeDefinition`
+// or `MText` in world coordinates.  Characters are measured with `metrics`, or approximated when it's nil.
+func (d *Drawing) TextGeometry(e Entity, metrics GlyphMetrics) (TextGeometry, error) {
+	layout, err := d.textLayout(e, metrics)
+	if err != nil {
+		return TextGeometry{}, err
+	}
+	return layout.geometry(), nil
+}
+
+func (d *Drawing) textLayout(e Entity, metrics GlyphMetrics) (textLayout, error) {
+	if metrics == nil {
+		metrics = ApproximateGlyphMetrics{}
+	}
+	switch ent := e.(type) {
+	case *Text:
+		return d.singleLineTextLayout(metrics, ent.PlainText(), ent.TextStyleName, ent.Location, ent.SecondAlignmentPoint, ent.Normal, ent.Height, ent.Rotation,
+			ent.RelativeXScaleFactor, ent.ObliqueAngle, ent.TextGenerationFlags, ent.HorizontalTextJustification, ent.VerticalTextJustification), nil
+	case *Attribute:
+		return d.singleLineTextLayout(metrics, ent.PlainText(), ent.TextStyleName, ent.Location, ent.SecondAlignmentPoint, ent.Normal, ent.TextHeight, ent.Rotation,
+			ent.RelativeXScaleFactor, ent.ObliqueAngle, ent.TextGenerationFlags, ent.HorizontalTextJustification, ent.VerticalTextJustification), nil
+	case *AttributeDefinition:
+		return d.singleLineTextLayout(metrics, DecodeText(ent.TextTag), ent.TextStyleName, ent.Location, ent.SecondAlignmentPoint, ent.Normal, ent.TextHeight, ent.Rotation,
+			ent.RelativeXScaleFactor, ent.ObliqueAngle, ent.TextGenerationFlags, ent.HorizontalTextJustification, ent.VerticalTextJustification), nil
+	case *MText:
+		return d.mtextLayout(metrics, ent), nil
+	}
+	return textLayout{}, fmt.Errorf("no text layout for %s", e.typeString())
+}
+
+func (d *Drawing) singleLineTextLayout(metrics GlyphMetrics, value, styleName string, location, secondAlignment Point, normal Vector, height, rotation, widthFactor, obliqueAngle float64, generationFlags int, horizontal HorizontalTextJustification, vertical VerticalTextJustification) textLayout {
+	style := d.textStyle(styleName)
+	if widthFactor == 0.0 {
+		widthFactor = style.WidthFactor
+	}
+	if widthFactor == 0.0 {
+		widthFactor = 1.0
+	}
+	fontName := style.PrimaryFontFileName
+	descent := metrics.Descent(fontName)
+	width := measureText(metrics, fontName, value, height, widthFactor)
+
+	// the alignment points are in OCS; the justification picks the authoritative one
+	reference := location
+	x0, baselineY := 0.0, 0.0
+	switch horizontal {
+	case HorizontalTextJustificationAligned, HorizontalTextJustificationFit:
+		// the text fills the baseline between the two alignment points; aligned text keeps its proportions
+		delta := secondAlignment.Sub(location)
+		distance := math.Hypot(delta.X, delta.Y)
+		if width > 0.0 {
+			if horizontal == HorizontalTextJustificationAligned {
+				height *= distance / width
+			} else {
+				widthFactor *= distance / width
+			}
+		}
+		width = distance
+		rotation = math.Atan2(delta.Y, delta.X) * 180.0 / math.Pi
+	default:
+		if horizontal != HorizontalTextJustificationLeft || vertical != VerticalTextJustificationBaseline {
+			reference = secondAlignment
+		}
+		switch horizontal {
+		case HorizontalTextJustificationCenter, HorizontalTextJustificationMiddle:
+			x0 = -width / 2.0
+		case HorizontalTextJustificationRight:
+			x0 = -width
+		}
+		switch vertical {
+		case VerticalTextJustificationBottom:
+			baselineY = descent * height
+		case VerticalTextJustificationMiddle:
+			baselineY = -height / 2.0
+		case VerticalTextJustificationTop:
+			baselineY = -height
+		}
+		if horizontal == HorizontalTextJustificationMiddle && vertical == VerticalTextJustificationBaseline {
+			baselineY = -height / 2.0
+		}
+	}
+
+	radians := rotation * math.Pi / 180.0
+	xAxis := Vector{X: math.Cos(radians), Y: math.Sin(radians), Z: 0.0}.OcsToWcs(normal)
+	yAxis := normal.Normalize().Cross(xAxis).Normalize()
+	if generationFlags&2 != 0 {
+		// mirrored in X
+		xAxis = xAxis.Scale(-1.0)
+	}
+	if generationFlags&4 != 0 {
+		// upside down
+		yAxis = yAxis.Scale(-1.0)
+	}
+
+	return textLayout{
+		frame:        textFrame{origin: reference.OcsToWcs(normal), xAxis: xAxis, yAxis: yAxis},
+		fontName:     fontName,
+		bigFontName:  style.BigFontFileName,
+		height:       height,
+		widthFactor:  widthFactor,
+		obliqueAngle: obliqueAngle,
+		lines:        []textLine{{text: value, x: x0, y: baselineY}},
+		x0:           x0,
+		x1:           x0 + width,
+		y0:           baselineY - descent*height,
+		y1:           baselineY + height,
+	}
+}
+
+// mtextLines splits the plain text of an MText into lines, wrapping words at `width` when it's positive.
+func mtextLines(text string, width float64, measure func(string) float64) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(text, "\n") {
+		if width <= 0.0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+		line := ""
+		for _, word := range strings.Split(paragraph, " ") {
+			candidate := word
+			if len(line) > 0 {
+				candidate = line + " " + word
+			}
+			if len(line) > 0 && measure(candidate) > width {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (d *Drawing) mtextLayout(metrics GlyphMetrics, t *MText) textLayout {
+	style := d.textStyle(t.TextStyleName)
+	widthFactor := style.WidthFactor
+	if widthFactor == 0.0 {
+		widthFactor = 1.0
+	}
+	fontName := style.PrimaryFontFileName
+	height := t.InitialTextHeight
+	descent := metrics.Descent(fontName) * height
+	measure := func(s string) float64 {
+		return measureText(metrics, fontName, s, height, widthFactor)
+	}
+
+	lines := mtextLines(t.PlainText(), t.ReferenceRectangleWidth, measure)
+	// a word longer than the reference width overflows it
+	width := t.ReferenceRectangleWidth
+	for _, line := range lines {
+		width = math.Max(width, measure(line))
+	}
+	spacingFactor := t.LineSpacingFactor
+	if spacingFactor == 0.0 {
+		spacingFactor = 1.0
+	}
+	boxHeight := height + float64(len(lines)-1)*height*5.0/3.0*spacingFactor + descent
+
+	// attachment points are numbered left to right, then top to bottom
+	attachment := int(t.AttachmentPoint) - 1
+	if attachment < 0 || attachment > 8 {
+		attachment = 0
+	}
+	x0 := -width * float64(attachment%3) / 2.0
+	top := boxHeight * float64(attachment/3) / 2.0
+
+	normal := t.ExtrusionDirection.Normalize()
+	xAxis := t.xAxis()
+
+	// each line is justified within the box like the attachment point
+	placed := make([]textLine, len(lines))
+	for i, line := range lines {
+		placed[i] = textLine{
+			text: line,
+			x:    x0 + (width-measure(line))*float64(attachment%3)/2.0,
+			y:    top - height - float64(i)*height*5.0/3.0*spacingFactor,
+		}
+	}
+	return textLayout{
+		frame:        textFrame{origin: t.InsertionPoint, xAxis: xAxis, yAxis: normal.Cross(xAxis).Normalize()},
+		fontName:     fontName,
+		bigFontName:  style.BigFontFileName,
+		height:       height,
+		widthFactor:  widthFactor,
+		obliqueAngle: style.ObliqueAngle,
+		lines:        placed,
+		x0:           x0,
+		x1:           x0 + width,
+		y0:           top - boxHeight,
+		y1:           top,
+	}
+}
@@ -0,0 +1,159 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+type fixedGlyphMetrics struct {
+	advance float64
+	descent float64
+}
+
+func (m fixedGlyphMetrics) Advance(fontName string, r rune) float64 {
+	return m.advance
+}
+
+func (m fixedGlyphMetrics) Descent(fontName string) float64 {
+	return m.descent
+}
+
+func TestTextGeometryLeftBaseline(t *testing.T) {
+	drawing := *NewDrawing()
+	text := NewText()
+	text.Location = Point{1.0, 2.0, 0.0}
+	text.Height = 2.0
+	text.Value = "abc"
+	geometry, err := drawing.TextGeometry(text, nil)
+	assert(t, err == nil, "expected no error")
+	assertNearPoint(t, Point{1.0, 2.0, 0.0}, geometry.Anchor)
+	assertNearFloat64(t, 6.0, geometry.Width)
+	assertNearFloat64(t, 2.0, geometry.Height)
+	assertNearPoint(t, Point{7.0, 4.0, 0.0}, geometry.Corners[2])
+}
+
+func TestTextGeometryStyleAndRotation(t *testing.T) {
+	drawing := *NewDrawing()
+	style := *NewStyle()
+	style.Name = "NARROW"
+	style.WidthFactor = 0.5
+	drawing.Styles = append(drawing.Styles, style)
+
+	text := NewText()
+	text.Height = 1.0
+	text.Value = "%%c10"
+	text.TextStyleName = "narrow"
+	text.RelativeXScaleFactor = 0.0
+	text.Rotation = 90.0
+	geometry, _ := drawing.TextGeometry(text, nil)
+	assertNearFloat64(t, 1.5, geometry.Width)
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, geometry.XAxis)
+	assertNearVector(t, Vector{-1.0, 0.0, 0.0}, geometry.YAxis)
+	assertNearPoint(t, Point{-1.0, 1.5, 0.0}, geometry.Corners[2])
+}
+
+func TestTextGeometryJustification(t *testing.T) {
+	drawing := *NewDrawing()
+	text := NewText()
+	text.Height = 1.0
+	text.Value = "abcd"
+	text.SecondAlignmentPoint = Point{10.0, 0.0, 0.0}
+	text.HorizontalTextJustification = HorizontalTextJustificationRight
+	text.VerticalTextJustification = VerticalTextJustificationBottom
+	metrics := fixedGlyphMetrics{advance: 0.5, descent: 0.25}
+	geometry, _ := drawing.TextGeometry(text, metrics)
+	assertNearPoint(t, Point{8.0, 0.25, 0.0}, geometry.Anchor)
+	assertNearPoint(t, Point{8.0, 0.0, 0.0}, geometry.Corners[0])
+	assertNearPoint(t, Point{10.0, 1.25, 0.0}, geometry.Corners[2])
+
+	// the text is stretched between the alignment points, keeping its proportions
+	text.Location = Point{0.0, 0.0, 0.0}
+	text.HorizontalTextJustification = HorizontalTextJustificationAligned
+	text.VerticalTextJustification = VerticalTextJustificationBaseline
+	geometry, _ = drawing.TextGeometry(text, nil)
+	assertNearFloat64(t, 10.0, geometry.Width)
+	assertNearFloat64(t, 2.5, geometry.Height)
+
+	text.HorizontalTextJustification = HorizontalTextJustificationFit
+	geometry, _ = drawing.TextGeometry(text, nil)
+	assertNearFloat64(t, 10.0, geometry.Width)
+	assertNearFloat64(t, 1.0, geometry.Height)
+}
+
+func TestTextGeometryObliqueAndMirrored(t *testing.T) {
+	drawing := *NewDrawing()
+	text := NewText()
+	text.Height = 1.0
+	text.Value = "a"
+	text.ObliqueAngle = 45.0
+	geometry, _ := drawing.TextGeometry(text, nil)
+	assertNearFloat64(t, 2.0, geometry.Width)
+
+	text.ObliqueAngle = 0.0
+	text.TextGenerationFlags = 2
+	geometry, _ = drawing.TextGeometry(text, nil)
+	assertNearPoint(t, Point{-1.0, 1.0, 0.0}, geometry.Corners[2])
+}
+
+func TestTextGeometryAttributeDefinition(t *testing.T) {
+	drawing := *NewDrawing()
+	attdef := NewAttributeDefinition()
+	attdef.TextHeight = 1.0
+	attdef.TextTag = "TAG"
+	geometry, _ := drawing.TextGeometry(attdef, nil)
+	assertNearFloat64(t, 3.0, geometry.Width)
+}
+
+func TestTextGeometryMText(t *testing.T) {
+	drawing := *NewDrawing()
+	mtext := NewMText()
+	mtext.InsertionPoint = Point{0.0, 10.0, 0.0}
+	mtext.InitialTextHeight = 1.0
+	mtext.LineSpacingFactor = 1.0
+	mtext.ReferenceRectangleWidth = 0.0
+	mtext.AttachmentPoint = AttachmentPointTopLeft
+	mtext.SetFullText("ab\\Pcdef")
+	geometry, _ := drawing.TextGeometry(mtext, nil)
+	assertNearPoint(t, Point{0.0, 9.0, 0.0}, geometry.Anchor)
+	assertNearFloat64(t, 4.0, geometry.Width)
+	assertNearFloat64(t, 1.0+5.0/3.0, geometry.Height)
+	assertNearPoint(t, Point{4.0, 10.0, 0.0}, geometry.Corners[2])
+
+	// wrapped at the reference width
+	mtext.SetFullText("aa bb cc")
+	mtext.ReferenceRectangleWidth = 5.0
+	mtext.AttachmentPoint = AttachmentPointBottomCenter
+	geometry, _ = drawing.TextGeometry(mtext, nil)
+	assertNearFloat64(t, 5.0, geometry.Width)
+	assertNearFloat64(t, 1.0+5.0/3.0, geometry.Height)
+	assertNearPoint(t, Point{-2.5, 10.0, 0.0}, geometry.Corners[0])
+
+	mtext.XAxisDirection = Vector{0.0, 0.0, 0.0}
+	mtext.RotationAngle = math.Pi / 2.0
+	geometry, _ = drawing.TextGeometry(mtext, nil)
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, geometry.XAxis)
+}
+
+func TestTextGeometryMTextRotationAngleOnly(t *testing.T) {
+	drawing := *NewDrawing()
+	mtext := parseEntity(t, "MTEXT",
+		NewStringCodePair(100, "AcDbMText"),
+		NewDoubleCodePair(10, 0.0),
+		NewDoubleCodePair(20, 0.0),
+		NewDoubleCodePair(30, 0.0),
+		NewDoubleCodePair(40, 1.0),
+		NewShortCodePair(71, 1),
+		NewStringCodePair(1, "ab"),
+		NewDoubleCodePair(50, math.Pi/2.0),
+	).(*MText)
+	geometry, _ := drawing.TextGeometry(mtext, nil)
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, geometry.XAxis)
+	assertNearPoint(t, Point{0.0, 2.0, 0.0}, geometry.Corners[2])
+	assertNearBounds(t, Point{0.0, 0.0, 0.0}, Point{1.0, 1.0, 0.0}, BoundingBox(mtext))
+}
+
+func TestTextGeometryUnsupportedEntity(t *testing.T) {
+	drawing := *NewDrawing()
+	_, err := drawing.TextGeometry(NewLine(), nil)
+	assert(t, err != nil, "expected an error")
+}
@@ -0,0 +1,410 @@
+package dxf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FontDirectory loads the fonts named by text styles from a local directory.  It implements `GlyphMetrics`,
+// approximating the characters of fonts it can't load.
+type FontDirectory struct {
+	Path       string
+	fonts      map[string]*TrueTypeFont
+	shapeFonts map[string]*ShapeFont
+	failures   map[string]error
+}
+
+// NewFontDirectory creates a new FontDirectory reading fonts from `path`.
+func NewFontDirectory(path string) *FontDirectory {
+	return &FontDirectory{
+		Path:       path,
+		fonts:      map[string]*TrueTypeFont{},
+		shapeFonts: map[string]*ShapeFont{},
+		failures:   map[string]error{},
+	}
+}
+
+// trueTypeFontExtensions are tried in order when a text style names a TrueType font without its extension.
+var trueTypeFontExtensions = []string{".ttf", ".otf", ".ttc"}
+
+// readFontFile returns the name and the contents of the file in the directory matching `fileName`.  Any directory
+// part of the name is ignored, files are matched case-insensitively and, when the name has no extension,
+// `extensions` are tried.
+func (f *FontDirectory) readFontFile(fileName string, extensions []string) (string, []byte, error) {
+	// style font names often carry a Windows path
+	name := fileName[strings.LastIndexAny(fileName, `/\`)+1:]
+	candidates := []string{name}
+	if filepath.Ext(name) == "" {
+		for _, extension := range extensions {
+			candidates = append(candidates, name+extension)
+		}
+	}
+	entries, err := os.ReadDir(f.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, candidate := range candidates {
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(entry.Name(), candidate) {
+				data, err := os.ReadFile(filepath.Join(f.Path, entry.Name()))
+				return entry.Name(), data, err
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("font '%s' not found in '%s'", fileName, f.Path)
+}
+
+// Font returns the TrueType font stored in the directory as `fileName`, trying the `.ttf`, `.otf` and `.ttc`
+// extensions when the name has none.
+func (f *FontDirectory) Font(fileName string) (*TrueTypeFont, error) {
+	key := "ttf:" + strings.ToLower(fileName)
+	if font, ok := f.fonts[key]; ok {
+		return font, nil
+	}
+	if err, ok := f.failures[key]; ok {
+		return nil, err
+	}
+	name, data, err := f.readFontFile(fileName, trueTypeFontExtensions)
+	var font *TrueTypeFont
+	if err == nil {
+		font, err = ParseTrueTypeFont(data)
+		if err != nil {
+			err = fmt.Errorf("font '%s': %s", name, err)
+		}
+	}
+	if err != nil {
+		f.failures[key] = err
+		return nil, err
+	}
+	f.fonts[key] = font
+	return font, nil
+}
+
+// ShapeFont returns the SHX font or shape file stored in the directory as `fileName`, trying the `.shx` extension
+// when the name has none.
+func (f *FontDirectory) ShapeFont(fileName string) (*ShapeFont, error) {
+	key := "shx:" + strings.ToLower(fileName)
+	if font, ok := f.shapeFonts[key]; ok {
+		return font, nil
+	}
+	if err, ok := f.failures[key]; ok {
+		return nil, err
+	}
+	name, data, err := f.readFontFile(fileName, []string{".shx"})
+	var font *ShapeFont
+	if err == nil {
+		font, err = ParseShapeFont(data)
+		if err != nil {
+			err = fmt.Errorf("font '%s': %s", name, err)
+		}
+	}
+	if err != nil {
+		f.failures[key] = err
+		return nil, err
+	}
+	f.shapeFonts[key] = font
+	return font, nil
+}
+
+// isShapeFontName returns whether a style's font file name may refer to an SHX font.
+func isShapeFontName(fileName string) bool {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	return extension == "" || extension == ".shx"
+}
+
+func (f *FontDirectory) Advance(fontName string, r rune) float64 {
+	if font, err := f.Font(fontName); err == nil {
+		return font.Advance(r)
+	}
+	if !isShapeFontName(fontName) {
+		return ApproximateGlyphMetrics{}.Advance(fontName, r)
+	}
+	if font, err := f.ShapeFont(fontName); err == nil {
+		if code, ok := font.characterCode(r); ok {
+			if glyph, err := font.Glyph(code); err == nil {
+				return glyph.Advance.X * font.unitScale(1.0)
+			}
+		}
+	}
+	return ApproximateGlyphMetrics{}.Advance(fontName, r)
+}
+
+func (f *FontDirectory) Descent(fontName string) float64 {
+	if font, err := f.Font(fontName); err == nil {
+		return font.Descent()
+	}
+	if !isShapeFontName(fontName) {
+		return ApproximateGlyphMetrics{}.Descent(fontName)
+	}
+	if font, err := f.ShapeFont(fontName); err == nil {
+		return font.Below * font.unitScale(1.0)
+	}
+	return ApproximateGlyphMetrics{}.Descent(fontName)
+}
+
+// ExplodeText returns the outlines of the characters of a `Text`, `Attribute`, `AttributeDefinition` or `MText`
+// laid out with its height, width factor, oblique angle and justification, all on the layer of the text.  The font
+// is the one named by the text style, loaded from `fonts`.  For TrueType fonts, contours made of straight segments
+// become closed `LWPolyline`s and curved ones closed quadratic `Spline`s, or cubic ones for fonts with CFF outlines;
+// SHX fonts give an open `LWPolyline` per stroke, falling back to the style's big font.  MText formatting other than
+// line breaks is ignored.  The returned entities have no handle assigned and the drawing is not modified.
+func (d *Drawing) ExplodeText(e Entity, fonts *FontDirectory) ([]Entity, error) {
+	if fonts == nil {
+		return nil, fmt.Errorf("fonts must not be nil")
+	}
+	layout, err := d.textLayout(e, fonts)
+	if err != nil {
+		return nil, err
+	}
+
+	font, err := fonts.Font(layout.fontName)
+	if err == nil {
+		return explodeTrueTypeText(layout, font, e.Layer())
+	}
+	if !isShapeFontName(layout.fontName) {
+		return nil, err
+	}
+	shapeFont, err := fonts.ShapeFont(layout.fontName)
+	if err != nil {
+		return nil, err
+	}
+	var bigFont *ShapeFont
+	if layout.bigFontName != "" {
+		bigFont, err = fonts.ShapeFont(layout.bigFontName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return explodeShapeText(layout, shapeFont, bigFont, d.Header.DrawingCodePage, e.Layer())
+}
+
+func explodeTrueTypeText(layout textLayout, font *TrueTypeFont, layer string) ([]Entity, error) {
+	normal := layout.frame.xAxis.Cross(layout.frame.yAxis).Normalize()
+	scale := layout.height / font.unitsPerEm
+	slant := layout.slant()
+	entities := []Entity{}
+	for _, line := range layout.lines {
+		x := line.x
+		for _, r := range line.text {
+			contours, err := font.glyphContours(r)
+			if err != nil {
+				return nil, err
+			}
+			for _, contour := range contours {
+				points := make([]Point, len(contour))
+				onCurve := make([]bool, len(contour))
+				for i, p := range contour {
+					points[i] = layout.frame.at(x+(p.x*layout.widthFactor+p.y*slant)*scale, line.y+p.y*scale)
+					onCurve[i] = p.onCurve
+				}
+				var outline Entity
+				if font.cff != nil {
+					outline = cubicContourOutline(points, onCurve, normal)
+				} else {
+					outline = contourOutline(points, onCurve, normal)
+				}
+				if outline != nil {
+					outline.SetLayer(layer)
+					entities = append(entities, outline)
+				}
+			}
+			x += font.Advance(r) * layout.height * layout.widthFactor
+		}
+	}
+	return entities, nil
+}
+
+func explodeShapeText(layout textLayout, font, bigFont *ShapeFont, codePage, layer string) ([]Entity, error) {
+	normal := layout.frame.xAxis.Cross(layout.frame.yAxis).Normalize()
+	slant := layout.slant()
+	entities := []Entity{}
+	for _, line := range layout.lines {
+		x := line.x
+		for _, r := range line.text {
+			glyphFont := font
+			code, ok := font.characterCode(r)
+			if !ok && bigFont != nil {
+				glyphFont, code = bigFont, bigFontCode(r, codePage)
+				ok = bigFont.HasShape(code)
+			}
+			if !ok {
+				x += textCharacterWidth * layout.height * layout.widthFactor
+				continue
+			}
+			glyph, err := glyphFont.Glyph(code)
+			if err != nil {
+				return nil, err
+			}
+
+			scale := glyphFont.unitScale(layout.height)
+			for _, stroke := range glyph.Strokes {
+				points := make([]Point, len(stroke))
+				for i, p := range stroke {
+					points[i] = layout.frame.at(x+(p.X*layout.widthFactor+p.Y*slant)*scale, line.y+p.Y*scale)
+				}
+				polyline := planarPolyline(points, normal, false)
+				polyline.SetLayer(layer)
+				entities = append(entities, polyline)
+			}
+			x += glyph.Advance.X * scale * layout.widthFactor
+		}
+	}
+	return entities, nil
+}
+
+// planarPolyline returns an `LWPolyline` through world points lying in a plane perpendicular to `normal`.
+func planarPolyline(points []Point, normal Vector, closed bool) *LWPolyline {
+	polyline := NewLWPolyline()
+	polyline.ExtrusionDirection = normal
+	for _, point := range points {
+		p := point.WcsToOcs(normal)
+		vertex := *NewLwVertex()
+		vertex.X, vertex.Y = p.X, p.Y
+		polyline.Vertices = append(polyline.Vertices, vertex)
+		polyline.SetElevation(p.Z)
+	}
+	polyline.SetIsClosed(closed)
+	return polyline
+}
+
+// contourOutline converts a closed TrueType contour of on-curve points and quadratic control points to an
+// `LWPolyline` when it's made of straight segments, or to a piecewise quadratic `Spline` otherwise.
+func contourOutline(points []Point, onCurve []bool, normal Vector) Entity {
+	if len(points) < 2 {
+		return nil
+	}
+
+	// start on the curve; two control points in a row imply the on-curve point between them
+	start := -1
+	for i, on := range onCurve {
+		if on {
+			start = i
+			break
+		}
+	}
+	var first Point
+	rest, restOnCurve := []Point{}, []bool{}
+	if start >= 0 {
+		first = points[start]
+		for n := 1; n < len(points); n++ {
+			i := (start + n) % len(points)
+			rest, restOnCurve = append(rest, points[i]), append(restOnCurve, onCurve[i])
+		}
+	} else {
+		first = points[0].Add(points[1].Sub(points[0]).Scale(0.5))
+		for n := 1; n <= len(points); n++ {
+			i := n % len(points)
+			rest, restOnCurve = append(rest, points[i]), append(restOnCurve, false)
+		}
+	}
+	rest, restOnCurve = append(rest, first), append(restOnCurve, true)
+
+	curved := false
+	controlPoints := []Point{first}
+	previous, previousOnCurve := first, true
+	for i, p := range rest {
+		on := restOnCurve[i]
+		switch {
+		case on && previousOnCurve:
+			// a straight segment is a quadratic with its control point halfway
+			controlPoints = append(controlPoints, previous.Add(p.Sub(previous).Scale(0.5)), p)
+		case on:
+			controlPoints = append(controlPoints, p)
+		case previousOnCurve:
+			controlPoints = append(controlPoints, p)
+			curved = true
+		default:
+			controlPoints = append(controlPoints, previous.Add(p.Sub(previous).Scale(0.5)), p)
+			curved = true
+		}
+		previous, previousOnCurve = p, on
+	}
+
+	if !curved {
+		vertices := []Point{}
+		for i := 0; i+2 < len(controlPoints); i += 2 {
+			vertices = append(vertices, controlPoints[i])
+		}
+		return planarPolyline(vertices, normal, true)
+	}
+
+	// each quadratic piece ends at a double knot
+	segments := (len(controlPoints) - 1) / 2
+	spline := NewSpline()
+	spline.Normal = normal
+	spline.DegreeOfCurve = 2
+	spline.KnotValues = []float64{0.0, 0.0, 0.0}
+	for i := 1; i < segments; i++ {
+		spline.KnotValues = append(spline.KnotValues, float64(i), float64(i))
+	}
+	spline.KnotValues = append(spline.KnotValues, float64(segments), float64(segments), float64(segments))
+	for _, p := range controlPoints {
+		cp := *NewControlPoint()
+		cp.Point = p
+		spline.ControlPoints = append(spline.ControlPoints, cp)
+	}
+	spline.SetIsPlanar(true)
+	spline.SetIsClosed(true)
+	return spline
+}
+
+// cubicContourOutline joins a closed contour of CFF outline points, which starts on the curve and has pairs of cubic
+// control points between the points on it, into a polyline or a cubic spline.
+func cubicContourOutline(points []Point, onCurve []bool, normal Vector) Entity {
+	if len(points) < 2 || !onCurve[0] {
+		return nil
+	}
+
+	curved := false
+	controlPoints := []Point{points[0]}
+	previous := points[0]
+	pending := []Point{}
+	for i := 1; i <= len(points); i++ {
+		p, on := points[0], true
+		if i < len(points) {
+			p, on = points[i], onCurve[i]
+		}
+		if !on {
+			pending = append(pending, p)
+			continue
+		}
+		if len(pending) == 2 {
+			controlPoints = append(controlPoints, pending[0], pending[1], p)
+			curved = true
+		} else {
+			// a straight segment is a cubic with its control points at the thirds
+			controlPoints = append(controlPoints, previous.Add(p.Sub(previous).Scale(1.0/3.0)), previous.Add(p.Sub(previous).Scale(2.0/3.0)), p)
+		}
+		previous = p
+		pending = pending[:0]
+	}
+
+	if !curved {
+		vertices := []Point{}
+		for i := 0; i+3 < len(controlPoints); i += 3 {
+			vertices = append(vertices, controlPoints[i])
+		}
+		return planarPolyline(vertices, normal, true)
+	}
+
+	// each cubic piece ends at a triple knot
+	segments := (len(controlPoints) - 1) / 3
+	spline := NewSpline()
+	spline.Normal = normal
+	spline.DegreeOfCurve = 3
+	spline.KnotValues = []float64{0.0, 0.0, 0.0, 0.0}
+	for i := 1; i < segments; i++ {
+		spline.KnotValues = append(spline.KnotValues, float64(i), float64(i), float64(i))
+	}
+	spline.KnotValues = append(spline.KnotValues, float64(segments), float64(segments), float64(segments), float64(segments))
+	for _, p := range controlPoints {
+		cp := *NewControlPoint()
+		cp.Point = p
+		spline.ControlPoints = append(spline.ControlPoints, cp)
+	}
+	spline.SetIsPlanar(true)
+	spline.SetIsClosed(true)
+	return spline
+}
@@ -0,0 +1,315 @@
+package dxf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func bigEndianBytes(values ...interface{}) []byte {
+	var buf bytes.Buffer
+	for _, v := range values {
+		binary.Write(&buf, binary.BigEndian, v)
+	}
+	return buf.Bytes()
+}
+
+// testFontTables returns the tables shared by the test fonts, which have 1000 units per em and map ' ' to glyph 4,
+// 'A' to glyph 1, 'O' to glyph 2 and 'B' to glyph 3.
+func testFontTables() map[string][]byte {
+	be := bigEndianBytes
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	binary.BigEndian.PutUint16(head[50:], 1)
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint16(hhea[4:], 800)
+	binary.BigEndian.PutUint16(hhea[6:], uint16(0xff38)) // -200
+	binary.BigEndian.PutUint16(hhea[34:], 4)
+	maxp := be(uint32(0x00005000), uint16(5))
+	hmtx := be([8]uint16{500, 0, 600, 0, 700, 0, 800, 0}, int16(0))
+	subtable := be(uint16(4), uint16(0), uint16(0), uint16(8), uint16(0), uint16(0), uint16(0),
+		[4]uint16{0x20, 0x42, 0x4f, 0xffff}, uint16(0),
+		[4]uint16{0x20, 0x41, 0x4f, 0xffff},
+		[4]int16{4 - 0x20, 0, 2 - 0x4f, 1},
+		[4]uint16{0, 6, 0, 0},
+		[2]uint16{1, 3})
+	cmap := append(be(uint16(0), uint16(1), uint16(3), uint16(1), uint32(12)), subtable...)
+	return map[string][]byte{"cmap": cmap, "head": head, "hhea": hhea, "hmtx": hmtx, "maxp": maxp}
+}
+
+// testFontFile writes the table directory and the tables of a font, ordered by tag and aligned to four bytes.
+func testFontFile(version uint32, tables map[string][]byte) []byte {
+	tags := []string{}
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	font := bigEndianBytes(version, uint16(len(tags)), [3]uint16{})
+	offset := len(font) + len(tags)*16
+	var data []byte
+	for _, tag := range tags {
+		font = append(font, tag...)
+		font = append(font, bigEndianBytes(uint32(0), uint32(offset+len(data)), uint32(len(tables[tag])))...)
+		data = append(data, tables[tag]...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+	return append(font, data...)
+}
+
+// testTrueTypeFont builds a font with 1000 units per em mapping ' ' to an empty glyph, 'A' to a square, 'O' to a
+// diamond of control points and 'B' to the square shifted by a composite glyph.
+func testTrueTypeFont() []byte {
+	be := bigEndianBytes
+	square := be(int16(1), [4]int16{0, 0, 500, 500}, uint16(3), uint16(0), uint8(0x09), uint8(3),
+		[4]int16{0, 500, 0, -500}, [4]int16{0, 0, 500, 0})
+	diamond := be(int16(1), [4]int16{0, 0, 500, 500}, uint16(3), uint16(0), uint8(0x08), uint8(3),
+		[4]int16{250, 250, -250, -250}, [4]int16{0, 250, 250, -250})
+	composite := be(int16(-1), [4]int16{100, 0, 600, 500}, uint16(0x0003), uint16(1), int16(100), int16(0))
+	glyphs := [][]byte{{}, square, diamond, composite, {}}
+	var glyf []byte
+	loca := []uint32{0}
+	for _, glyph := range glyphs {
+		glyf = append(glyf, glyph...)
+		loca = append(loca, uint32(len(glyf)))
+	}
+
+	tables := testFontTables()
+	tables["glyf"] = glyf
+	tables["loca"] = be(loca)
+	return testFontFile(0x00010000, tables)
+}
+
+// cffNumber encodes an integer operand of a Type 2 charstring.
+func cffNumber(v int) []byte {
+	switch {
+	case v >= -107 && v <= 107:
+		return []byte{byte(v + 139)}
+	case v >= 108 && v <= 1131:
+		return []byte{byte((v-108)/256 + 247), byte((v - 108) % 256)}
+	case v >= -1131 && v <= -108:
+		return []byte{byte((-v-108)/256 + 251), byte((-v - 108) % 256)}
+	}
+	return append([]byte{28}, bigEndianBytes(int16(v))...)
+}
+
+// testCffFont builds a font with CFF outlines and the glyph mapping of `testFontTables`; 'A' and 'B' are squares
+// and 'O' has a curved side.
+func testCffFont() []byte {
+	charString := func(values ...int) []byte {
+		// operators are negated to tell them apart
+		var data []byte
+		for _, v := range values {
+			if v < 0 && v > -32 {
+				data = append(data, byte(-v))
+			} else {
+				data = append(data, cffNumber(v)...)
+			}
+		}
+		return data
+	}
+	const rmoveto, rlineto, rrcurveto, endchar = -21, -5, -8, -14
+	// an operand of 0 can't be negated, so moves start at (0, 0) with a width argument
+	square := charString(500, 0, 0, rmoveto, 500, 0, 500, 500, rlineto, -500, 0, rlineto, endchar)
+	curved := charString(700, 0, 0, rmoveto, 0, 250, 250, 250, 250, 0, rrcurveto, 0, -500, rlineto, endchar)
+	empty := charString(endchar)
+	charStrings := [][]byte{empty, square, curved, square, empty}
+
+	index := func(items ...[]byte) []byte {
+		offsets := []uint16{1}
+		var data []byte
+		for _, item := range items {
+			data = append(data, item...)
+			offsets = append(offsets, uint16(len(data)+1))
+		}
+		return append(bigEndianBytes(uint16(len(items)), uint8(2), offsets), data...)
+	}
+	header := []byte{1, 0, 4, 2}
+	names := index([]byte("Test"))
+	// the top dict locates the charstrings after itself and the empty string and subroutine indices
+	topDictLength := len(index(append([]byte{29, 0, 0, 0, 0}, 17)))
+	charStringsOffset := len(header) + len(names) + topDictLength + 4
+	topDict := index(append(append([]byte{29}, bigEndianBytes(int32(charStringsOffset))...), 17))
+	cff := append(append(append(header, names...), topDict...), 0, 0, 0, 0)
+	cff = append(cff, index(charStrings...)...)
+
+	tables := testFontTables()
+	tables["CFF "] = cff
+	tables["post"] = bigEndianBytes(uint32(0x00030000), [28]byte{})
+	return testFontFile(0x4f54544f, tables)
+}
+
+func testFontDirectory(t *testing.T) *FontDirectory {
+	directory := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(directory, "Test.TTF"), testTrueTypeFont(), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewFontDirectory(directory)
+}
+
+func TestParseTrueTypeFont(t *testing.T) {
+	font, err := ParseTrueTypeFont(testTrueTypeFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, font.GlyphIndex('A'))
+	assertEqInt(t, 3, font.GlyphIndex('B'))
+	assertEqInt(t, 2, font.GlyphIndex('O'))
+	assertEqInt(t, 0, font.GlyphIndex('Z'))
+	assertNearFloat64(t, 0.6, font.Advance('A'))
+	assertNearFloat64(t, 0.8, font.Advance(' ')) // past the metrics table the last advance repeats
+	assertNearFloat64(t, 0.8, font.Ascent())
+	assertNearFloat64(t, 0.2, font.Descent())
+
+	contours, err := font.glyphContours('B')
+	assert(t, err == nil, "expected no error")
+	assertEqInt(t, 1, len(contours))
+	assertEqFloat64(t, 600.0, contours[0][2].x)
+
+	_, err = ParseTrueTypeFont([]byte("OTTO\x00\x00\x00\x00\x00\x00\x00\x00"))
+	assert(t, err != nil, "expected a font without tables to be rejected")
+}
+
+func TestParseCffFont(t *testing.T) {
+	font, err := ParseTrueTypeFont(testCffFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 2, font.GlyphIndex('O'))
+	assertNearFloat64(t, 0.6, font.Advance('A'))
+	assertNearFloat64(t, 0.8, font.Ascent())
+
+	contours, err := font.glyphContours('O')
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 1, len(contours))
+	assertEqInt(t, 5, len(contours[0]))
+	assert(t, !contours[0][1].onCurve && !contours[0][2].onCurve, "expected two cubic control points")
+	assertEqFloat64(t, 250.0, contours[0][2].x)
+	assertEqFloat64(t, 500.0, contours[0][2].y)
+	assertEqFloat64(t, 0.0, contours[0][4].y)
+}
+
+func TestExplodeTextCffFont(t *testing.T) {
+	fonts := testFontDirectory(t)
+	err := ioutil.WriteFile(filepath.Join(fonts.Path, "Outlines.otf"), testCffFont(), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drawing := *NewDrawing()
+	style := *NewStyle()
+	style.Name = "OUTLINES"
+	style.PrimaryFontFileName = "outlines"
+	drawing.Styles = append(drawing.Styles, style)
+
+	text := NewText()
+	text.TextStyleName = "OUTLINES"
+	text.Height = 10.0
+	text.Value = "AO"
+	entities, err := drawing.ExplodeText(text, fonts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 2, len(entities))
+	square := entities[0].(*LWPolyline)
+	assertEqInt(t, 4, len(square.Vertices))
+	assertNearFloat64(t, 5.0, square.Vertices[2].Y)
+
+	curved := entities[1].(*Spline)
+	assertEqInt(t, 3, curved.DegreeOfCurve)
+	assertEqInt(t, 10, len(curved.ControlPoints))
+	middle, err := curved.PointAt(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// halfway along the curve from (6, 0) to (11, 5)
+	assertNearPoint(t, Point{6.0 + 5.0*(0.375*0.5+0.125), 5.0 * (0.375 + 0.375*0.5 + 0.125), 0.0}, middle)
+}
+
+func TestFontDirectoryMetricsOnlyTryShapeFontNames(t *testing.T) {
+	fonts := testFontDirectory(t)
+	assertNearFloat64(t, 0.6, fonts.Advance("test", 'A'))
+	assertNearFloat64(t, ApproximateGlyphMetrics{}.Advance("arial.ttf", 'A'), fonts.Advance("arial.ttf", 'A'))
+	assertNearFloat64(t, ApproximateGlyphMetrics{}.Descent("arial.ttf"), fonts.Descent("arial.ttf"))
+	_, triedShapeFont := fonts.failures["shx:arial.ttf"]
+	assert(t, !triedShapeFont, "expected no SHX font lookup for a TrueType font name")
+}
+
+func TestExplodeText(t *testing.T) {
+	drawing := *NewDrawing()
+	style := *NewStyle()
+	style.Name = "ENGRAVING"
+	style.PrimaryFontFileName = `C:\Fonts\test.ttf`
+	drawing.Styles = append(drawing.Styles, style)
+
+	text := NewText()
+	text.SetLayer("ENGRAVE")
+	text.TextStyleName = "ENGRAVING"
+	text.Height = 10.0
+	text.Value = "AO B"
+	entities, err := drawing.ExplodeText(text, testFontDirectory(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, len(entities))
+	for _, e := range entities {
+		assertEqString(t, "ENGRAVE", e.Layer())
+	}
+
+	square := entities[0].(*LWPolyline)
+	assert(t, square.IsClosed(), "expected a closed polyline")
+	assertEqInt(t, 4, len(square.Vertices))
+	assertEqFloat64(t, 5.0, square.Vertices[2].X)
+	assertEqFloat64(t, 5.0, square.Vertices[2].Y)
+
+	diamond := entities[1].(*Spline)
+	assertEqInt(t, 2, diamond.DegreeOfCurve)
+	assertEqInt(t, 9, len(diamond.ControlPoints))
+	start, err := diamond.PointAt(0.0)
+	assert(t, err == nil, "expected no error")
+	assertNearPoint(t, Point{9.75, 1.25, 0.0}, start)
+
+	// the space advances by the last metric and the composite shifts its component
+	shifted := entities[2].(*LWPolyline)
+	assertNearFloat64(t, 22.0, shifted.Vertices[0].X)
+}
+
+func TestExplodeTextLayout(t *testing.T) {
+	drawing := *NewDrawing()
+	fonts := testFontDirectory(t)
+	mtext := NewMText()
+	mtext.InitialTextHeight = 10.0
+	mtext.ReferenceRectangleWidth = 0.0
+	mtext.AttachmentPoint = AttachmentPointTopRight
+	mtext.TextStyleName = "STANDARD"
+	drawing.Styles = []Style{*NewStyle()}
+	drawing.Styles[0].Name = "STANDARD"
+	drawing.Styles[0].PrimaryFontFileName = "test"
+	drawing.Styles[0].ObliqueAngle = 45.0
+	mtext.SetFullText("AA\\PA")
+	entities, err := drawing.ExplodeText(mtext, fonts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqInt(t, 3, len(entities))
+
+	// the second line is right justified and slanted
+	last := entities[2].(*LWPolyline)
+	baseline := -10.0 - 50.0/3.0
+	assertNearFloat64(t, -6.0, last.Vertices[0].X)
+	assertNearFloat64(t, baseline, last.Vertices[0].Y)
+	assertNearFloat64(t, -6.0+5.0, last.Vertices[3].X)
+
+	geometry, _ := drawing.TextGeometry(mtext, fonts)
+	assertNearFloat64(t, 10.0+50.0/3.0+2.0, geometry.Height)
+
+	drawing.Styles[0].PrimaryFontFileName = "missing.ttf"
+	_, err = drawing.ExplodeText(mtext, fonts)
+	assert(t, err != nil, "expected a missing font to fail")
+}
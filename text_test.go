@@ -0,0 +1,52 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	assertEqString(t, "Ø25 ±0.1", DecodeText("%%c25 %%p0.1"))
+	assertEqString(t, "90° 5% A Ω", DecodeText("90%%D 5%%% %%065 \\U+03A9"))
+	assertEqString(t, "underlined", DecodeText("%%uunder%%olined"))
+	assertEqString(t, "100%%x", DecodeText("100%%x"))
+	assertEqString(t, "%%+12", DecodeText("%%+12"))
+	assertEqString(t, "%%1a", DecodeText("%%1a"))
+	assertEqString(t, "%% 12", DecodeText("%% 12"))
+
+	text := NewText()
+	text.Value = "%%c10"
+	assertEqString(t, "Ø10", text.PlainText())
+	attribute := NewAttribute()
+	attribute.Value = "%%d"
+	assertEqString(t, "°", attribute.PlainText())
+}
+
+func TestParseTextRuns(t *testing.T) {
+	runs := ParseTextRuns("a%%ub%%oc%%ud")
+	assertEqInt(t, 4, len(runs))
+	assertEqString(t, "b", runs[1].Text)
+	assert(t, runs[1].IsUnderlined && !runs[1].IsOverlined, "expected only an underline")
+	assert(t, runs[2].IsUnderlined && runs[2].IsOverlined, "expected an underline and an overline")
+	assert(t, !runs[3].IsUnderlined && runs[3].IsOverlined, "expected only an overline")
+}
+
+func TestEncodeText(t *testing.T) {
+	assertEqString(t, "%%c25 %%p0.1", EncodeText("Ø25 ±0.1"))
+	assertEqString(t, "50% off", EncodeText("50% off"))
+	assertEqString(t, "a%%%%d", EncodeText("a%%d"))
+	assertEqString(t, "a%%d", DecodeText(EncodeText("a%%d")))
+	assertEqString(t, "5%%%%%d", EncodeText("5%°"))
+
+	runs := []TextRun{
+		{Text: "50%"},
+		{Text: "under", IsUnderlined: true},
+		{Text: "Ø"},
+	}
+	value := FormatTextRuns(runs)
+	assertEqString(t, "50%%%%%uunder%%u%%c", value)
+	parsed := ParseTextRuns(value)
+	assertEqInt(t, 3, len(parsed))
+	assertEqString(t, "50%", parsed[0].Text)
+	assert(t, parsed[1].IsUnderlined, "expected an underline")
+	assertEqString(t, "Ø", parsed[2].Text)
+}
@@ -0,0 +1,1077 @@
+package dxf
+
+import (
+	"fmt"
+	"math"
+)
+
+const transformTolerance = 1.0e-9
+
+// Transform applies the transformation `m` to the entity.  The entity is updated in place when its type can represent
+// the result and is returned; otherwise a replacement entity with the same common properties is returned:
+//
+// - `Circle` and `Arc` become an `Ellipse` when the transformation doesn't scale their plane uniformly.
+// - `LWPolyline` and 2D `Polyline` become a rational `Spline` when the transformation doesn't scale their plane
+// uniformly and they contain arc segments; segment widths are lost in that case.
+//
+// An `Insert` or underlay can only be transformed when the result can be expressed as a rotation and a scale per
+// axis.  The ACIS payload of the modeler geometry entities is transformed through the transform record of each body
+// and stored as SAT text.  Proxy entities and legacy OLE frames store their geometry in an opaque form and can't be
+// transformed.  The block of a dimension isn't updated.
+func Transform(e Entity, m Matrix4) (Entity, error) {
+	switch ent := e.(type) {
+	case *Line:
+		ent.P1 = m.TransformPoint(ent.P1)
+		ent.P2 = m.TransformPoint(ent.P2)
+		ent.Thickness, ent.ExtrusionDirection = transformExtrusion(m, ent.Thickness, ent.ExtrusionDirection)
+	case *ModelPoint:
+		ent.Location = m.TransformPoint(ent.Location)
+		ent.Thickness, ent.ExtrusionDirection = transformExtrusion(m, ent.Thickness, ent.ExtrusionDirection)
+	case *Ray:
+		ent.StartPoint = m.TransformPoint(ent.StartPoint)
+		ent.UnitDirectionVector = m.TransformVector(ent.UnitDirectionVector).Normalize()
+	case *XLine:
+		ent.FirstPoint = m.TransformPoint(ent.FirstPoint)
+		ent.UnitDirectionVector = m.TransformVector(ent.UnitDirectionVector).Normalize()
+	case *Face:
+		ent.FirstCorner = m.TransformPoint(ent.FirstCorner)
+		ent.SecondCorner = m.TransformPoint(ent.SecondCorner)
+		ent.ThirdCorner = m.TransformPoint(ent.ThirdCorner)
+		ent.FourthCorner = m.TransformPoint(ent.FourthCorner)
+	case *Solid:
+		ent.Thickness, ent.ExtrusionDirection = transformOcsPoints(m, ent.Thickness, ent.ExtrusionDirection,
+			&ent.FirstCorner, &ent.SecondCorner, &ent.ThirdCorner, &ent.FourthCorner)
+	case *Trace:
+		ent.Thickness, ent.ExtrusionDirection = transformOcsPoints(m, ent.Thickness, ent.ExtrusionDirection,
+			&ent.FirstCorner, &ent.SecondCorner, &ent.ThirdCorner, &ent.FourthCorner)
+	case *Circle:
+		return transformCircle(ent, m), nil
+	case *Arc:
+		return transformArc(ent, m), nil
+	case *Ellipse:
+		transformEllipse(ent, m)
+	case *Helix:
+		transformHelix(ent, m)
+	case *LWPolyline:
+		return transformLWPolyline(ent, m), nil
+	case *Polyline:
+		return transformPolyline(ent, m), nil
+	case *Vertex:
+		ent.Location = m.TransformPoint(ent.Location)
+	case *Spline:
+		transformSpline(ent, m)
+	case *MLine:
+		transformMLine(ent, m)
+	case *Leader:
+		transformLeader(ent, m)
+	case *Text:
+		transformTextGeometry(m, textGeometry{&ent.Location, &ent.SecondAlignmentPoint, &ent.Normal, &ent.Height,
+			&ent.Rotation, &ent.RelativeXScaleFactor, &ent.ObliqueAngle, &ent.Thickness})
+	case *Attribute:
+		transformTextGeometry(m, textGeometry{&ent.Location, &ent.SecondAlignmentPoint, &ent.Normal, &ent.TextHeight,
+			&ent.Rotation, &ent.RelativeXScaleFactor, &ent.ObliqueAngle, &ent.Thickness})
+		ent.AlignmentPoint = m.TransformPoint(ent.AlignmentPoint)
+		transformMText(&ent.MText, m)
+	case *AttributeDefinition:
+		transformTextGeometry(m, textGeometry{&ent.Location, &ent.SecondAlignmentPoint, &ent.Normal, &ent.TextHeight,
+			&ent.Rotation, &ent.RelativeXScaleFactor, &ent.ObliqueAngle, &ent.Thickness})
+		ent.AlignmentPoint = m.TransformPoint(ent.AlignmentPoint)
+		transformMText(&ent.MText, m)
+	case *Shape:
+		var secondPoint Point
+		transformTextGeometry(m, textGeometry{&ent.Location, &secondPoint, &ent.ExtrusionDirection, &ent.Size,
+			&ent.RotationAngle, &ent.RelativeXScaleFactor, &ent.ObliqueAngle, &ent.Thickness})
+	case *RText:
+		var secondPoint Point
+		var widthFactor, obliqueAngle, thickness float64
+		transformTextGeometry(m, textGeometry{&ent.InsertionPoint, &secondPoint, &ent.ExtrusionDirection,
+			&ent.TextHeight, &ent.RotationAngle, &widthFactor, &obliqueAngle, &thickness})
+	case *ArcAlignedText:
+		return ent, transformArcAlignedText(ent, m)
+	case *MText:
+		transformMText(ent, m)
+	case *Tolerance:
+		_, _, normal := transformPlane(m, ent.ExtrusionDirection)
+		ent.InsertionPoint = m.TransformPoint(ent.InsertionPoint)
+		ent.DirectionVector = m.TransformVector(ent.DirectionVector)
+		ent.ExtrusionDirection = normal
+	case Dimension:
+		transformDimension(ent, m)
+	case *Insert:
+		return ent, transformInsert(ent, m)
+	case Underlay:
+		return e, transformUnderlay(ent, m)
+	case RasterImage:
+		ent.SetLocation(m.TransformPoint(ent.Location()))
+		ent.SetUVector(m.TransformVector(ent.UVector()))
+		ent.SetVVector(m.TransformVector(ent.VVector()))
+	case *Light:
+		ent.Position = m.TransformPoint(ent.Position)
+		ent.TargetLocation = m.TransformPoint(ent.TargetLocation)
+	case *Ole2Frame:
+		ent.UpperLeftCorner = m.TransformPoint(ent.UpperLeftCorner)
+		ent.LowerRightCorner = m.TransformPoint(ent.LowerRightCorner)
+	case *Section:
+		transformSection(ent, m)
+	case *Mesh:
+		for i := range ent.Vertices {
+			ent.Vertices[i] = m.TransformPoint(ent.Vertices[i])
+		}
+	case *Table:
+		ent.InsertionPoint = m.TransformPoint(ent.InsertionPoint)
+		ent.HorizontalDirection = m.TransformVector(ent.HorizontalDirection)
+	case *Hatch:
+		transformHatch(ent, m)
+	case *MLeader:
+		transformMLeader(ent, m)
+	case *Viewport:
+		ent.Center = m.TransformPoint(ent.Center)
+		ent.ViewTargetPoint = m.TransformPoint(ent.ViewTargetPoint)
+		ent.Width *= m.TransformVector(*NewXAxis()).Length()
+		ent.Height *= m.TransformVector(*NewYAxis()).Length()
+	case *Seqend:
+		// no geometry
+	case ModelerGeometry:
+		return e, transformModelerGeometry(ent, m)
+	case *ProxyEntity, *OleFrame:
+		return e, fmt.Errorf("unable to transform the opaque geometry of entity of type %s", e.typeString())
+	default:
+		return e, fmt.Errorf("unable to transform entity of type %s", e.typeString())
+	}
+
+	return e, nil
+}
+
+// transformExtrusion returns the thickness and extrusion direction of a WCS entity after transformation.
+func transformExtrusion(m Matrix4, thickness float64, extrusion Vector) (float64, Vector) {
+	v := m.TransformVector(extrusion.Normalize())
+	length := v.Length()
+	if length < transformTolerance {
+		return thickness, extrusion
+	}
+
+	return thickness * length, v.Normalize()
+}
+
+// transformPlane returns the transformed OCS axes of the plane with the given normal and the resulting normal.
+func transformPlane(m Matrix4, normal Vector) (u, v, newNormal Vector) {
+	xAxis, yAxis := normal.ArbitraryAxes()
+	u = m.TransformVector(xAxis)
+	v = m.TransformVector(yAxis)
+	newNormal = u.Cross(v).Normalize()
+	if newNormal.IsZero(0.0) {
+		newNormal = normal.Normalize()
+	}
+	return
+}
+
+// thicknessScale returns the factor applied to a thickness extruded along `normal` when it is re-expressed along
+// `newNormal`.
+func thicknessScale(m Matrix4, normal, newNormal Vector) float64 {
+	return m.TransformVector(normal.Normalize()).Dot(newNormal)
+}
+
+// isConformal determines whether two transformed orthonormal axes are still orthogonal and equally scaled.
+func isConformal(u, v Vector) bool {
+	lu := u.Length()
+	lv := v.Length()
+	scale := math.Max(lu, lv)
+	return math.Abs(lu-lv) <= transformTolerance*scale && math.Abs(u.Dot(v)) <= transformTolerance*scale*scale
+}
+
+// ocsAngle returns the angle in radians of `direction` measured in the OCS defined by `normal`.
+func ocsAngle(direction, normal Vector) float64 {
+	xAxis, yAxis := normal.ArbitraryAxes()
+	return math.Atan2(direction.Dot(yAxis), direction.Dot(xAxis))
+}
+
+// normalizeAngle returns the equivalent angle in the range [0, 360).
+func normalizeAngle(degrees float64) float64 {
+	result := math.Mod(degrees, 360.0)
+	if result < 0.0 {
+		result += 360.0
+	}
+	return result
+}
+
+func transformOcsPoints(m Matrix4, thickness float64, normal Vector, points ...*Point) (float64, Vector) {
+	_, _, newNormal := transformPlane(m, normal)
+	for _, p := range points {
+		*p = m.TransformPoint(p.OcsToWcs(normal)).WcsToOcs(newNormal)
+	}
+	return thickness * thicknessScale(m, normal, newNormal), newNormal
+}
+
+func transformCircle(c *Circle, m Matrix4) Entity {
+	u, v, newNormal := transformPlane(m, c.Normal)
+	center := m.TransformPoint(c.Center.OcsToWcs(c.Normal))
+	if !isConformal(u, v) {
+		ellipse := ellipseFromConjugateDiameters(center, u.Scale(c.Radius), v.Scale(c.Radius), 0.0, 2.0*math.Pi)
+		copyEntityProperties(ellipse, c)
+		return ellipse
+	}
+
+	c.Thickness *= thicknessScale(m, c.Normal, newNormal)
+	c.Center = center.WcsToOcs(newNormal)
+	c.Radius *= u.Length()
+	c.Normal = newNormal
+	return c
+}
+
+func transformArc(a *Arc, m Matrix4) Entity {
+	u, v, newNormal := transformPlane(m, a.Normal)
+	center := m.TransformPoint(a.Center.OcsToWcs(a.Normal))
+	if !isConformal(u, v) {
+		startAngle := a.StartAngle * math.Pi / 180.0
+		endAngle := a.EndAngle * math.Pi / 180.0
+		for endAngle <= startAngle {
+			endAngle += 2.0 * math.Pi
+		}
+		ellipse := ellipseFromConjugateDiameters(center, u.Scale(a.Radius), v.Scale(a.Radius), startAngle, endAngle)
+		copyEntityProperties(ellipse, a)
+		return ellipse
+	}
+
+	// the transformed OCS X axis determines how far the angles rotate
+	offset := ocsAngle(u, newNormal) * 180.0 / math.Pi
+	a.Thickness *= thicknessScale(m, a.Normal, newNormal)
+	a.Center = center.WcsToOcs(newNormal)
+	a.Radius *= u.Length()
+	a.Normal = newNormal
+	a.StartAngle = normalizeAngle(a.StartAngle + offset)
+	a.EndAngle = normalizeAngle(a.EndAngle + offset)
+	return a
+}
+
+func transformEllipse(e *Ellipse, m Matrix4) {
+	center := m.TransformPoint(e.Center)
+	minorAxis := e.Normal.Normalize().Cross(e.MajorAxis).Scale(e.MinorAxisRatio)
+	u := m.TransformVector(e.MajorAxis)
+	v := m.TransformVector(minorAxis)
+	result := ellipseFromConjugateDiameters(center, u, v, e.StartAngle, e.EndAngle)
+	e.Center = result.Center
+	e.MajorAxis = result.MajorAxis
+	e.Normal = result.Normal
+	e.MinorAxisRatio = result.MinorAxisRatio
+	e.StartAngle = result.StartAngle
+	e.EndAngle = result.EndAngle
+}
+
+// ellipseFromConjugateDiameters creates the ellipse `center + u*cos(t) + v*sin(t)` for `t` in the range
+// [startParameter, endParameter].
+func ellipseFromConjugateDiameters(center Point, u, v Vector, startParameter, endParameter float64) *Ellipse {
+	// find the parameter at which the conjugate diameters become the principal axes
+	t0 := 0.5 * math.Atan2(2.0*u.Dot(v), u.Dot(u)-v.Dot(v))
+	major := u.Scale(math.Cos(t0)).Add(v.Scale(math.Sin(t0)))
+	minor := u.Scale(-math.Sin(t0)).Add(v.Scale(math.Cos(t0)))
+	if minor.Length() > major.Length() {
+		major, minor = minor, major.Scale(-1.0)
+		t0 += 0.5 * math.Pi
+	}
+
+	ellipse := NewEllipse()
+	ellipse.Center = center
+	ellipse.MajorAxis = major
+	ellipse.Normal = major.Cross(minor).Normalize()
+	if ellipse.Normal.IsZero(0.0) {
+		ellipse.Normal = u.Cross(v).Normalize()
+	}
+	if major.Length() > 0.0 {
+		ellipse.MinorAxisRatio = minor.Length() / major.Length()
+	}
+	if endParameter-startParameter >= 2.0*math.Pi-transformTolerance {
+		ellipse.StartAngle = 0.0
+		ellipse.EndAngle = 2.0 * math.Pi
+	} else {
+		ellipse.StartAngle = normalizeRadians(startParameter - t0)
+		ellipse.EndAngle = normalizeRadians(endParameter - t0)
+	}
+	return ellipse
+}
+
+// normalizeRadians returns the equivalent angle in the range [0, 2π).
+func normalizeRadians(radians float64) float64 {
+	result := math.Mod(radians, 2.0*math.Pi)
+	if result < 0.0 {
+		result += 2.0 * math.Pi
+	}
+	return result
+}
+
+func transformLWPolyline(p *LWPolyline, m Matrix4) Entity {
+	u, v, newNormal := transformPlane(m, p.ExtrusionDirection)
+	elevation := p.Elevation()
+	if !isConformal(u, v) {
+		points := make([]Point, len(p.Vertices))
+		bulges := make([]float64, len(p.Vertices))
+		hasArcs := false
+		for i, vertex := range p.Vertices {
+			points[i] = Point{X: vertex.X, Y: vertex.Y, Z: elevation}
+			bulges[i] = vertex.Bulge
+			hasArcs = hasArcs || vertex.Bulge != 0.0
+		}
+		if hasArcs {
+			spline := bulgeSpline(points, bulges, p.IsClosed(), p.ExtrusionDirection, m)
+			copyEntityProperties(spline, p)
+			return spline
+		}
+	}
+
+	widthScale := math.Sqrt(u.Cross(v).Length())
+	for i := range p.Vertices {
+		vertex := &p.Vertices[i]
+		location := Point{X: vertex.X, Y: vertex.Y, Z: elevation}.OcsToWcs(p.ExtrusionDirection)
+		location = m.TransformPoint(location).WcsToOcs(newNormal)
+		vertex.X = location.X
+		vertex.Y = location.Y
+		vertex.StartingWidth *= widthScale
+		vertex.EndingWidth *= widthScale
+	}
+
+	p.SetElevation(m.TransformPoint(Point{Z: elevation}.OcsToWcs(p.ExtrusionDirection)).WcsToOcs(newNormal).Z)
+	p.ConstantWidth *= widthScale
+	p.Thickness *= thicknessScale(m, p.ExtrusionDirection, newNormal)
+	p.ExtrusionDirection = newNormal
+	return p
+}
+
+func transformPolyline(p *Polyline, m Matrix4) Entity {
+	if p.Is3DPolyline() || p.Is3DPolygonMesh() || p.IsPolyfaceMesh() {
+		// vertices are already in world coordinates
+		for i := range p.Vertices {
+			p.Vertices[i].Location = m.TransformPoint(p.Vertices[i].Location)
+		}
+		return p
+	}
+
+	u, v, newNormal := transformPlane(m, p.Normal)
+	elevation := p.Location.Z
+	if !isConformal(u, v) {
+		points := make([]Point, len(p.Vertices))
+		bulges := make([]float64, len(p.Vertices))
+		hasArcs := false
+		for i, vertex := range p.Vertices {
+			points[i] = Point{X: vertex.Location.X, Y: vertex.Location.Y, Z: elevation}
+			bulges[i] = vertex.Bulge
+			hasArcs = hasArcs || vertex.Bulge != 0.0
+		}
+		if hasArcs {
+			spline := bulgeSpline(points, bulges, p.IsClosed(), p.Normal, m)
+			copyEntityProperties(spline, p)
+			return spline
+		}
+	}
+
+	widthScale := math.Sqrt(u.Cross(v).Length())
+	p.Location = m.TransformPoint(Point{Z: elevation}.OcsToWcs(p.Normal)).WcsToOcs(newNormal)
+	for i := range p.Vertices {
+		vertex := &p.Vertices[i]
+		location := Point{X: vertex.Location.X, Y: vertex.Location.Y, Z: elevation}
+		vertex.Location = m.TransformPoint(location.OcsToWcs(p.Normal)).WcsToOcs(newNormal)
+		vertex.Location.Z = p.Location.Z
+		vertex.StartingWidth *= widthScale
+		vertex.EndingWidth *= widthScale
+	}
+
+	p.DefaultStartingWidth *= widthScale
+	p.DefaultEndingWidth *= widthScale
+	p.Thickness *= thicknessScale(m, p.Normal, newNormal)
+	p.Normal = newNormal
+	return p
+}
+
+// bulgeArc returns the circle through two OCS points of a polyline segment with the specified bulge.  The sweep is
+// positive for a counter-clockwise segment.  The bulge must not be zero.
+func bulgeArc(p1, p2 Point, bulge float64) (center Point, radius, startAngle, sweep float64) {
+	sweep = 4.0 * math.Atan(bulge)
+	dx := p2.X - p1.X
+	dy := p2.Y - p1.Y
+	chord := math.Hypot(dx, dy)
+	signedRadius := chord / (2.0 * math.Sin(sweep/2.0))
+
+	// the center lies on the perpendicular bisector of the chord, to the left for a counter-clockwise segment
+	rotation := math.Pi/2.0 - sweep/2.0
+	cos := math.Cos(rotation)
+	sin := math.Sin(rotation)
+	center = Point{
+		X: p1.X + (dx*cos-dy*sin)*signedRadius/chord,
+		Y: p1.Y + (dx*sin+dy*cos)*signedRadius/chord,
+		Z: p1.Z,
+	}
+	radius = math.Abs(signedRadius)
+	startAngle = math.Atan2(p1.Y-center.Y, p1.X-center.X)
+	return
+}
+
+// bulgeSpline creates a rational quadratic spline that exactly represents the OCS polyline described by `points` and
+// `bulges` after transformation by `m`.  Arc segments are split into spans of at most 90 degrees.
+func bulgeSpline(points []Point, bulges []float64, closed bool, normal Vector, m Matrix4) *Spline {
+	segmentCount := len(points) - 1
+	if closed {
+		segmentCount = len(points)
+	}
+
+	controlPoints := make([]ControlPoint, 0)
+	addControlPoint := func(p Point, weight float64) {
+		controlPoints = append(controlPoints, ControlPoint{Point: m.TransformPoint(p.OcsToWcs(normal)), Weight: weight})
+	}
+	if len(points) > 0 {
+		addControlPoint(points[0], 1.0)
+	}
+	for i := 0; i < segmentCount; i++ {
+		start := points[i]
+		end := points[(i+1)%len(points)]
+		if bulges[i] == 0.0 || start.DistanceTo(end) == 0.0 {
+			addControlPoint(start.Lerp(end, 0.5), 1.0)
+			addControlPoint(end, 1.0)
+			continue
+		}
+
+		center, radius, startAngle, sweep := bulgeArc(start, end, bulges[i])
+		spans := int(math.Ceil(math.Abs(sweep)/(math.Pi/2.0) - transformTolerance))
+		if spans < 1 {
+			spans = 1
+		}
+		delta := sweep / float64(spans)
+		weight := math.Cos(delta / 2.0)
+		for span := 0; span < spans; span++ {
+			middle := startAngle + delta*(float64(span)+0.5)
+			distance := radius / weight
+			addControlPoint(Point{X: center.X + distance*math.Cos(middle), Y: center.Y + distance*math.Sin(middle), Z: start.Z}, weight)
+			if span == spans-1 {
+				addControlPoint(end, 1.0)
+			} else {
+				angle := startAngle + delta*float64(span+1)
+				addControlPoint(Point{X: center.X + radius*math.Cos(angle), Y: center.Y + radius*math.Sin(angle), Z: start.Z}, 1.0)
+			}
+		}
+	}
+
+	// every span is a quadratic piece joined with a double knot
+	spanCount := (len(controlPoints) - 1) / 2
+	knots := []float64{0.0, 0.0, 0.0}
+	for i := 1; i < spanCount; i++ {
+		knots = append(knots, float64(i), float64(i))
+	}
+	knots = append(knots, float64(spanCount), float64(spanCount), float64(spanCount))
+
+	_, _, newNormal := transformPlane(m, normal)
+	spline := NewSpline()
+	spline.Normal = newNormal
+	spline.DegreeOfCurve = 2
+	spline.KnotValues = knots
+	spline.ControlPoints = controlPoints
+	spline.SetIsRational(true)
+	spline.SetIsPlanar(true)
+	spline.SetIsClosed(closed)
+	return spline
+}
+
+func transformSpline(s *Spline, m Matrix4) {
+	for i := range s.ControlPoints {
+		s.ControlPoints[i].Point = m.TransformPoint(s.ControlPoints[i].Point)
+	}
+	for i := range s.FitPoints {
+		s.FitPoints[i] = m.TransformPoint(s.FitPoints[i])
+	}
+	s.StartTangent = m.TransformVector(s.StartTangent)
+	s.EndTangent = m.TransformVector(s.EndTangent)
+	if !s.Normal.IsZero(0.0) {
+		_, _, s.Normal = transformPlane(m, s.Normal)
+	}
+}
+
+// textGeometry references the fields shared by the single-line text entities.
+type textGeometry struct {
+	location        *Point
+	secondAlignment *Point
+	normal          *Vector
+	height          *float64
+	rotation        *float64
+	widthFactor     *float64
+	obliqueAngle    *float64
+	thickness       *float64
+}
+
+func transformTextGeometry(m Matrix4, g textGeometry) {
+	normal := *g.normal
+	xAxis, yAxis := normal.ArbitraryAxes()
+	rotation := *g.rotation * math.Pi / 180.0
+	direction := xAxis.Scale(math.Cos(rotation)).Add(yAxis.Scale(math.Sin(rotation)))
+	up := normal.Normalize().Cross(direction)
+
+	// the transformed baseline and up directions define the new text plane
+	e1 := m.TransformVector(direction)
+	e2 := m.TransformVector(up)
+	newNormal := e1.Cross(e2).Normalize()
+	if newNormal.IsZero(0.0) {
+		newNormal = normal
+	}
+	newDirection := e1.Normalize()
+	newUp := newNormal.Cross(newDirection)
+	heightScale := e2.Dot(newUp)
+
+	*g.location = m.TransformPoint(g.location.OcsToWcs(normal)).WcsToOcs(newNormal)
+	*g.secondAlignment = m.TransformPoint(g.secondAlignment.OcsToWcs(normal)).WcsToOcs(newNormal)
+	*g.thickness *= thicknessScale(m, normal, newNormal)
+	*g.normal = newNormal
+	*g.rotation = normalizeAngle(ocsAngle(newDirection, newNormal) * 180.0 / math.Pi)
+	if heightScale > transformTolerance {
+		oblique := math.Tan(*g.obliqueAngle * math.Pi / 180.0)
+		*g.height *= heightScale
+		*g.widthFactor *= e1.Length() / heightScale
+		*g.obliqueAngle = math.Atan((oblique*e1.Length()+e2.Dot(newDirection))/heightScale) * 180.0 / math.Pi
+	}
+}
+
+func transformMText(t *MText, m Matrix4) {
+	normal := t.ExtrusionDirection.Normalize()
+	if normal.IsZero(0.0) {
+		normal = *NewZAxis()
+	}
+	direction := t.xAxis()
+	up := normal.Cross(direction)
+
+	e1 := m.TransformVector(direction)
+	e2 := m.TransformVector(up)
+	newNormal := e1.Cross(e2).Normalize()
+	if newNormal.IsZero(0.0) {
+		newNormal = normal
+	}
+	newDirection := e1.Normalize()
+	heightScale := e2.Dot(newNormal.Cross(newDirection))
+
+	t.InsertionPoint = m.TransformPoint(t.InsertionPoint)
+	t.InitialTextHeight *= heightScale
+	t.ReferenceRectangleWidth *= e1.Length()
+	t.ExtrusionDirection = newNormal
+	t.XAxisDirection = newDirection
+	t.RotationAngle = ocsAngle(newDirection, newNormal)
+}
+
+func transformInsert(insert *Insert, m Matrix4) error {
+	combined := m.Multiply(insertMatrix(insert))
+	normal, rotation, xScale, yScale, zScale, ok := decomposeScaledAxes(combined)
+	if !ok {
+		return fmt.Errorf("unable to represent the transformation of insert '%s'", insert.Name)
+	}
+
+	// array spacing follows the insert's own rotated axes
+	angle := insert.Rotation * math.Pi / 180.0
+	xAxis, yAxis := insert.ExtrusionDirection.ArbitraryAxes()
+	columnDirection := xAxis.Scale(math.Cos(angle)).Add(yAxis.Scale(math.Sin(angle)))
+	rowDirection := insert.ExtrusionDirection.Normalize().Cross(columnDirection)
+
+	insert.Location = Point{X: combined[0][3], Y: combined[1][3], Z: combined[2][3]}.WcsToOcs(normal)
+	insert.XScaleFactor = xScale
+	insert.YScaleFactor = yScale
+	insert.ZScaleFactor = zScale
+	insert.Rotation = rotation
+	insert.ExtrusionDirection = normal
+	insert.ColumnSpacing *= m.TransformVector(columnDirection).Length()
+	insert.RowSpacing *= m.TransformVector(rowDirection).Length()
+	for i := range insert.Attributes {
+		if _, err := Transform(&insert.Attributes[i], m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decomposeScaledAxes expresses the linear part of `combined` as an OCS normal, a rotation in degrees within that OCS
+// and a scale along each axis.  This is only possible when the columns of the matrix are orthogonal.
+func decomposeScaledAxes(combined Matrix4) (normal Vector, rotation, xScale, yScale, zScale float64, ok bool) {
+	c1 := Vector{X: combined[0][0], Y: combined[1][0], Z: combined[2][0]}
+	c2 := Vector{X: combined[0][1], Y: combined[1][1], Z: combined[2][1]}
+	c3 := Vector{X: combined[0][2], Y: combined[1][2], Z: combined[2][2]}
+	scale := math.Max(c1.Length(), math.Max(c2.Length(), c3.Length()))
+	limit := transformTolerance * scale * scale
+	if math.Abs(c1.Dot(c2)) > limit || math.Abs(c1.Dot(c3)) > limit || math.Abs(c2.Dot(c3)) > limit {
+		return
+	}
+
+	normal = c1.Cross(c2).Normalize()
+	if normal.IsZero(0.0) {
+		return
+	}
+
+	rotation = normalizeAngle(ocsAngle(c1, normal) * 180.0 / math.Pi)
+	xScale = c1.Length()
+	yScale = c2.Length()
+	zScale = c3.Dot(normal)
+	ok = true
+	return
+}
+
+func transformUnderlay(u Underlay, m Matrix4) error {
+	local := *newOcsMatrix4(u.Normal())
+	local = local.Multiply(*NewRotationMatrix4(*NewZAxis(), u.RotationAngle()))
+	local = local.Multiply(*NewScaleMatrix4(u.XScale(), u.YScale(), u.ZScale()))
+	normal, rotation, xScale, yScale, zScale, ok := decomposeScaledAxes(m.Multiply(local))
+	if !ok {
+		return fmt.Errorf("unable to represent the transformation of an underlay")
+	}
+
+	u.SetInsertionPoint(m.TransformPoint(u.InsertionPoint()))
+	u.SetNormal(normal)
+	u.SetRotationAngle(rotation)
+	u.SetXScale(xScale)
+	u.SetYScale(yScale)
+	u.SetZScale(zScale)
+	return nil
+}
+
+// transformOcsAngle returns the angle in degrees, measured in the OCS of `newNormal`, of the direction at `angle`
+// degrees in the OCS of `normal` after transformation.
+func transformOcsAngle(m Matrix4, angle float64, normal, newNormal Vector) float64 {
+	xAxis, yAxis := normal.ArbitraryAxes()
+	radians := angle * math.Pi / 180.0
+	direction := m.TransformVector(xAxis.Scale(math.Cos(radians)).Add(yAxis.Scale(math.Sin(radians))))
+	return normalizeAngle(ocsAngle(direction, newNormal) * 180.0 / math.Pi)
+}
+
+func transformDimension(d Dimension, m Matrix4) {
+	normal := d.Normal()
+	u, v, newNormal := transformPlane(m, normal)
+	scale := math.Sqrt(u.Cross(v).Length())
+	transformOcs := func(p Point) Point {
+		return m.TransformPoint(p.OcsToWcs(normal)).WcsToOcs(newNormal)
+	}
+
+	d.SetDefinitionPoint1(m.TransformPoint(d.DefinitionPoint1()))
+	d.SetTextMidPoint(transformOcs(d.TextMidPoint()))
+	if d.TextRotationAngle() != 0.0 {
+		d.SetTextRotationAngle(transformOcsAngle(m, d.TextRotationAngle(), normal, newNormal))
+	}
+	if d.HorizontalDirectionAngle() != 0.0 {
+		d.SetHorizontalDirectionAngle(transformOcsAngle(m, d.HorizontalDirectionAngle(), normal, newNormal))
+	}
+	switch dim := d.(type) {
+	case *AlignedDimension:
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.DefinitionPoint3 = m.TransformPoint(dim.DefinitionPoint3)
+	case *RotatedDimension:
+		dim.InsertionPoint = transformOcs(dim.InsertionPoint)
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.DefinitionPoint3 = m.TransformPoint(dim.DefinitionPoint3)
+		dim.RotationAngle = transformOcsAngle(m, dim.RotationAngle, normal, newNormal)
+		if dim.ExtensionLineAngle != 0.0 {
+			dim.ExtensionLineAngle = transformOcsAngle(m, dim.ExtensionLineAngle, normal, newNormal)
+		}
+	case *RadialDimension:
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.LeaderLength *= scale
+	case *DiameterDimension:
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.LeaderLength *= scale
+	case *AngularThreePointDimension:
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.DefinitionPoint3 = m.TransformPoint(dim.DefinitionPoint3)
+		dim.DefinitionPoint4 = m.TransformPoint(dim.DefinitionPoint4)
+		dim.DefinitionPoint5 = transformOcs(dim.DefinitionPoint5)
+	case *OrdinateDimension:
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.DefinitionPoint3 = m.TransformPoint(dim.DefinitionPoint3)
+	case *AngularTwoLineDimension:
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.DefinitionPoint3 = m.TransformPoint(dim.DefinitionPoint3)
+		dim.DefinitionPoint4 = m.TransformPoint(dim.DefinitionPoint4)
+		dim.DefinitionPoint5 = transformOcs(dim.DefinitionPoint5)
+	case *ArcDimension:
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.DefinitionPoint3 = m.TransformPoint(dim.DefinitionPoint3)
+		dim.ArcCenter = m.TransformPoint(dim.ArcCenter)
+		dim.StartAngle = transformOcsAngle(m, dim.StartAngle*180.0/math.Pi, normal, newNormal) * math.Pi / 180.0
+		dim.EndAngle = transformOcsAngle(m, dim.EndAngle*180.0/math.Pi, normal, newNormal) * math.Pi / 180.0
+		dim.LeaderPoint1 = m.TransformPoint(dim.LeaderPoint1)
+		dim.LeaderPoint2 = m.TransformPoint(dim.LeaderPoint2)
+	case *LargeRadialDimension:
+		dim.OverrideCenter = m.TransformPoint(dim.OverrideCenter)
+		dim.JogPoint = m.TransformPoint(dim.JogPoint)
+		dim.DefinitionPoint2 = m.TransformPoint(dim.DefinitionPoint2)
+		dim.LeaderLength *= scale
+		dim.JogAngle = transformOcsAngle(m, dim.JogAngle, normal, newNormal)
+	}
+	d.SetNormal(newNormal)
+}
+
+func transformHelix(h *Helix, m Matrix4) {
+	u, _, _ := transformPlane(m, h.AxisVector)
+	axis := m.TransformVector(h.AxisVector)
+	if h.AxisVector.Length() > 0.0 {
+		h.TurnHeight *= axis.Length() / h.AxisVector.Length()
+	}
+	h.Radius *= u.Length()
+	h.AxisBasePoint = m.TransformPoint(h.AxisBasePoint)
+	h.StartPoint = m.TransformPoint(h.StartPoint)
+	h.AxisVector = axis
+	if m.Determinant() < 0.0 {
+		h.IsRightHanded = !h.IsRightHanded
+	}
+}
+
+func transformMLine(l *MLine, m Matrix4) {
+	u, v, newNormal := transformPlane(m, l.Normal)
+	scale := math.Sqrt(u.Cross(v).Length())
+	transformDirection := func(p Point) Point {
+		d := m.TransformVector(Vector{X: p.X, Y: p.Y, Z: p.Z}).Normalize()
+		return Point{X: d.X, Y: d.Y, Z: d.Z}
+	}
+
+	l.StartPoint = m.TransformPoint(l.StartPoint)
+	for i := range l.Vertices {
+		l.Vertices[i] = m.TransformPoint(l.Vertices[i])
+	}
+	for i := range l.SegmentDirections {
+		l.SegmentDirections[i] = transformDirection(l.SegmentDirections[i])
+	}
+	for i := range l.MiterDirections {
+		l.MiterDirections[i] = transformDirection(l.MiterDirections[i])
+	}
+	for i := range l.Parameters {
+		l.Parameters[i] *= scale
+	}
+	l.ScaleFactor *= scale
+	l.Normal = newNormal
+}
+
+func transformLeader(l *Leader, m Matrix4) {
+	u, v, newNormal := transformPlane(m, l.Normal)
+	scale := math.Sqrt(u.Cross(v).Length())
+	for i := range l.Vertices {
+		l.Vertices[i] = m.TransformPoint(l.Vertices[i])
+	}
+	l.Right = m.TransformVector(l.Right).Normalize()
+	l.BlockOffset = m.TransformVector(l.BlockOffset)
+	l.AnnotationOffset = m.TransformVector(l.AnnotationOffset)
+	l.TextAnnotationHeight *= scale
+	l.TextAnnotationWidth *= scale
+	l.Normal = newNormal
+}
+
+func transformMLeader(l *MLeader, m Matrix4) {
+	c := &l.Context
+	u := m.TransformVector(c.PlaneXAxis)
+	v := m.TransformVector(c.PlaneYAxis)
+	scale := math.Sqrt(u.Cross(v).Length())
+	c.PlaneOrigin = m.TransformPoint(c.PlaneOrigin)
+	c.PlaneXAxis = u.Normalize()
+	c.PlaneYAxis = v.Normalize()
+	c.ContentBasePoint = m.TransformPoint(c.ContentBasePoint)
+	c.ContentScale *= scale
+	c.TextHeight *= scale
+	c.ArrowheadSize *= scale
+	c.LandingGap *= scale
+	l.ArrowheadSize *= scale
+	l.DoglegLength *= scale
+
+	text := &c.MText
+	_, _, text.Normal = transformPlane(m, text.Normal)
+	text.Location = m.TransformPoint(text.Location)
+	text.Direction = m.TransformVector(text.Direction).Normalize()
+	text.Rotation = ocsAngle(text.Direction, text.Normal)
+	text.BoundaryWidth *= scale
+	text.BoundaryHeight *= scale
+
+	// the block content keeps its rotation relative to the transformed OCS X axis
+	block := &c.Block
+	xAxis, yAxis := block.Normal.ArbitraryAxes()
+	direction := xAxis.Scale(math.Cos(block.Rotation)).Add(yAxis.Scale(math.Sin(block.Rotation)))
+	_, _, block.Normal = transformPlane(m, block.Normal)
+	rotation := ocsAngle(m.TransformVector(direction), block.Normal)
+	l.BlockContentRotation = normalizeRadians(l.BlockContentRotation + rotation - block.Rotation)
+	block.Rotation = rotation
+	block.Location = m.TransformPoint(block.Location)
+	block.Scale = block.Scale.Scale(scale)
+	block.TransformationMatrix = m.Multiply(block.TransformationMatrix)
+	l.BlockContentScale = l.BlockContentScale.Scale(scale)
+
+	for i := range c.Roots {
+		root := &c.Roots[i]
+		root.ConnectionPoint = m.TransformPoint(root.ConnectionPoint)
+		root.DoglegVector = m.TransformVector(root.DoglegVector).Normalize()
+		root.DoglegLength *= scale
+		transformMLeaderBreaks(root.Breaks, m)
+		for j := range root.Lines {
+			line := &root.Lines[j]
+			for k := range line.Vertices {
+				line.Vertices[k] = m.TransformPoint(line.Vertices[k])
+			}
+			transformMLeaderBreaks(line.Breaks, m)
+		}
+	}
+}
+
+func transformMLeaderBreaks(breaks []MLeaderBreak, m Matrix4) {
+	for i := range breaks {
+		breaks[i].Start = m.TransformPoint(breaks[i].Start)
+		breaks[i].End = m.TransformPoint(breaks[i].End)
+	}
+}
+
+func transformArcAlignedText(t *ArcAlignedText, m Matrix4) error {
+	u, v, newNormal := transformPlane(m, t.ExtrusionDirection)
+	if !isConformal(u, v) {
+		return fmt.Errorf("unable to apply a non-uniform scale to arc aligned text")
+	}
+
+	scale := u.Length()
+	offset := ocsAngle(u, newNormal) * 180.0 / math.Pi
+	t.CenterPoint = m.TransformPoint(t.CenterPoint.OcsToWcs(t.ExtrusionDirection)).WcsToOcs(newNormal)
+	t.ArcRadius *= scale
+	t.TextHeight *= scale
+	t.CharacterSpacing *= scale
+	t.OffsetFromArc *= scale
+	t.RightOffset *= scale
+	t.LeftOffset *= scale
+	t.StartAngle = normalizeAngle(t.StartAngle + offset)
+	t.EndAngle = normalizeAngle(t.EndAngle + offset)
+	t.ExtrusionDirection = newNormal
+	return nil
+}
+
+func transformSection(s *Section, m Matrix4) {
+	vertical := m.TransformVector(s.VerticalDirection.Normalize())
+	for i := range s.Vertices {
+		s.Vertices[i] = m.TransformPoint(s.Vertices[i])
+	}
+	for i := range s.BackLineVertices {
+		s.BackLineVertices[i] = m.TransformPoint(s.BackLineVertices[i])
+	}
+	s.TopHeight *= vertical.Length()
+	s.BottomHeight *= vertical.Length()
+	s.VerticalDirection = vertical.Normalize()
+}
+
+// ocsPlaneMap maps the 2D coordinates of an OCS plane to the OCS coordinates of the plane after a transformation.
+type ocsPlaneMap struct {
+	origin Point
+	xAxis  Vector
+	yAxis  Vector
+}
+
+func (p ocsPlaneMap) point(x, y float64) Point {
+	return Point{X: p.origin.X + p.xAxis.X*x + p.yAxis.X*y, Y: p.origin.Y + p.xAxis.Y*x + p.yAxis.Y*y}
+}
+
+func (p ocsPlaneMap) vector(x, y float64) Vector {
+	return p.xAxis.Scale(x).Add(p.yAxis.Scale(y))
+}
+
+func transformHatch(h *Hatch, m Matrix4) {
+	u, v, newNormal := transformPlane(m, h.Normal)
+	newX, newY := newNormal.ArbitraryAxes()
+	plane := ocsPlaneMap{
+		origin: m.TransformPoint(Point{Z: h.ElevationPoint.Z}.OcsToWcs(h.Normal)).WcsToOcs(newNormal),
+		xAxis:  Vector{X: u.Dot(newX), Y: u.Dot(newY)},
+		yAxis:  Vector{X: v.Dot(newX), Y: v.Dot(newY)},
+	}
+	conformal := isConformal(u, v)
+	for i := range h.BoundaryPaths {
+		transformHatchBoundaryPath(&h.BoundaryPaths[i], plane, conformal)
+	}
+	for i, p := range h.SeedPoints {
+		h.SeedPoints[i] = plane.point(p.X, p.Y)
+	}
+
+	// the pattern follows the rotation of the OCS X axis and the average scale of the plane
+	offset := math.Atan2(plane.xAxis.Y, plane.xAxis.X)
+	scale := math.Sqrt(math.Abs(plane.xAxis.Cross(plane.yAxis).Z))
+	h.PatternAngle = normalizeAngle(h.PatternAngle + offset*180.0/math.Pi)
+	h.PatternScale *= scale
+	for i := range h.PatternDefinitionLines {
+		line := &h.PatternDefinitionLines[i]
+		line.Angle = normalizeAngle(line.Angle + offset*180.0/math.Pi)
+		line.BasePoint = plane.point(line.BasePoint.X, line.BasePoint.Y)
+		line.Offset = plane.vector(line.Offset.X, line.Offset.Y)
+		for j := range line.DashLengths {
+			line.DashLengths[j] *= scale
+		}
+	}
+	h.GradientAngle = normalizeRadians(h.GradientAngle + offset)
+	h.ElevationPoint = Point{Z: plane.origin.Z}
+	h.Normal = newNormal
+}
+
+func transformHatchBoundaryPath(p *HatchBoundaryPath, plane ocsPlaneMap, conformal bool) {
+	if p.IsPolyline() {
+		hasArcs := false
+		for _, vertex := range p.Vertices {
+			hasArcs = hasArcs || vertex.Bulge != 0.0
+		}
+		if conformal || !hasArcs {
+			for i := range p.Vertices {
+				location := plane.point(p.Vertices[i].X, p.Vertices[i].Y)
+				p.Vertices[i].X = location.X
+				p.Vertices[i].Y = location.Y
+			}
+			return
+		}
+
+		// arcs become elliptical so the path is converted to edges
+		p.Edges = hatchPolylineEdges(p.Vertices, p.IsClosed)
+		p.Vertices = []HatchVertex{}
+		p.IsClosed = false
+		p.SetIsPolyline(false)
+	}
+
+	for i, edge := range p.Edges {
+		p.Edges[i] = transformHatchEdge(edge, plane, conformal)
+	}
+}
+
+// hatchPolylineEdges returns the line and arc edges equivalent to a polyline boundary path.
+func hatchPolylineEdges(vertices []HatchVertex, closed bool) []HatchEdge {
+	edges := []HatchEdge{}
+	for i, vertex := range vertices {
+		if i == len(vertices)-1 && !closed {
+			break
+		}
+		next := vertices[(i+1)%len(vertices)]
+		p1 := Point{X: vertex.X, Y: vertex.Y}
+		p2 := Point{X: next.X, Y: next.Y}
+		if vertex.Bulge == 0.0 || p1.DistanceTo(p2) == 0.0 {
+			edges = append(edges, &HatchLineEdge{Start: p1, End: p2})
+			continue
+		}
+
+		center, radius, startAngle, sweep := bulgeArc(p1, p2, vertex.Bulge)
+		arc := &HatchArcEdge{
+			Center:             center,
+			Radius:             radius,
+			StartAngle:         startAngle * 180.0 / math.Pi,
+			EndAngle:           (startAngle + sweep) * 180.0 / math.Pi,
+			IsCounterClockwise: sweep > 0.0,
+		}
+		if !arc.IsCounterClockwise {
+			arc.StartAngle = -arc.StartAngle
+			arc.EndAngle = -arc.EndAngle
+		}
+		edges = append(edges, arc)
+	}
+	return edges
+}
+
+// hatchEdgeRange returns the counter-clockwise range in radians covered by the angles of an arc or ellipse edge.
+func hatchEdgeRange(startAngle, endAngle float64, isCounterClockwise bool) (start, end float64) {
+	start = startAngle * math.Pi / 180.0
+	end = endAngle * math.Pi / 180.0
+	if !isCounterClockwise {
+		start, end = -end, -start
+	}
+	for end < start {
+		end += 2.0 * math.Pi
+	}
+	return
+}
+
+func transformHatchEdge(edge HatchEdge, plane ocsPlaneMap, conformal bool) HatchEdge {
+	switch e := edge.(type) {
+	case *HatchLineEdge:
+		e.Start = plane.point(e.Start.X, e.Start.Y)
+		e.End = plane.point(e.End.X, e.End.Y)
+	case *HatchArcEdge:
+		center := plane.point(e.Center.X, e.Center.Y)
+		if !conformal {
+			start, end := hatchEdgeRange(e.StartAngle, e.EndAngle, e.IsCounterClockwise)
+			return hatchEllipseEdge(center, plane.vector(e.Radius, 0.0), plane.vector(0.0, e.Radius), start, end, e.IsCounterClockwise)
+		}
+
+		offset := math.Atan2(plane.xAxis.Y, plane.xAxis.X) * 180.0 / math.Pi
+		if !e.IsCounterClockwise {
+			offset = -offset
+		}
+		e.Center = center
+		e.Radius *= plane.xAxis.Length()
+		e.StartAngle += offset
+		e.EndAngle += offset
+	case *HatchEllipseEdge:
+		minorAxis := Vector{X: -e.MajorAxis.Y, Y: e.MajorAxis.X}.Scale(e.MinorAxisRatio)
+		start, end := hatchEdgeRange(e.StartAngle, e.EndAngle, e.IsCounterClockwise)
+		return hatchEllipseEdge(
+			plane.point(e.Center.X, e.Center.Y),
+			plane.vector(e.MajorAxis.X, e.MajorAxis.Y),
+			plane.vector(minorAxis.X, minorAxis.Y),
+			start,
+			end,
+			e.IsCounterClockwise)
+	case *HatchSplineEdge:
+		for i := range e.ControlPoints {
+			p := e.ControlPoints[i].Point
+			e.ControlPoints[i].Point = plane.point(p.X, p.Y)
+		}
+		for i, p := range e.FitPoints {
+			e.FitPoints[i] = plane.point(p.X, p.Y)
+		}
+		e.StartTangent = plane.vector(e.StartTangent.X, e.StartTangent.Y)
+		e.EndTangent = plane.vector(e.EndTangent.X, e.EndTangent.Y)
+	}
+	return edge
+}
+
+// hatchEllipseEdge creates the edge `center + u*cos(t) + v*sin(t)` for `t` in the counter-clockwise range [start, end].
+func hatchEllipseEdge(center Point, u, v Vector, start, end float64, isCounterClockwise bool) *HatchEllipseEdge {
+	ellipse := ellipseFromConjugateDiameters(center, u, v, start, end)
+	edge := &HatchEllipseEdge{
+		Center:             center,
+		MajorAxis:          Vector{X: ellipse.MajorAxis.X, Y: ellipse.MajorAxis.Y},
+		MinorAxisRatio:     ellipse.MinorAxisRatio,
+		StartAngle:         ellipse.StartAngle * 180.0 / math.Pi,
+		EndAngle:           ellipse.EndAngle * 180.0 / math.Pi,
+		IsCounterClockwise: isCounterClockwise,
+	}
+	if !isCounterClockwise {
+		edge.StartAngle, edge.EndAngle = 360.0-edge.EndAngle, 360.0-edge.StartAngle
+	}
+	return edge
+}
+
+// transformModelerGeometry applies `m` to the ACIS payload of an entity and to the placement of the entities a surface
+// was created from.
+func transformModelerGeometry(g ModelerGeometry, m Matrix4) error {
+	_, _, _, binaryData := g.modelerData()
+	lines := g.AcisLines()
+	if len(lines) > 0 || len(*binaryData) > 0 {
+		sat, err := AcisSatText(g)
+		if err != nil {
+			return err
+		}
+		file, err := readSatText(sat)
+		if err != nil {
+			return err
+		}
+		file.transformBodies(m)
+
+		// keep encrypted text encrypted
+		version := acisEncryptedBefore
+		if len(lines) > 0 && isEncryptedSat(lines[0]) {
+			version = R2000
+		}
+		SetAcisSatText(g, file.satText(), version)
+	}
+
+	switch s := g.(type) {
+	case *ExtrudedSurface:
+		s.SweepVector = m.TransformVector(s.SweepVector)
+		s.ExtrudeEntityTransform = m.Multiply(s.ExtrudeEntityTransform)
+		transformSweepOptions(&s.SweepOptions, m)
+	case *LoftedSurface:
+		s.Transform = m.Multiply(s.Transform)
+	case *RevolvedSurface:
+		s.AxisPoint = m.TransformPoint(s.AxisPoint)
+		s.AxisDirection = m.TransformVector(s.AxisDirection)
+		s.RevolvedEntityTransform = m.Multiply(s.RevolvedEntityTransform)
+	case *SweptSurface:
+		s.SweptEntityTransform = m.Multiply(s.SweptEntityTransform)
+		s.PathTransform = m.Multiply(s.PathTransform)
+		transformSweepOptions(&s.SweepOptions, m)
+	}
+	return nil
+}
+
+func transformSweepOptions(o *SweepOptions, m Matrix4) {
+	o.SweepEntityTransform = m.Multiply(o.SweepEntityTransform)
+	o.PathEntityTransform = m.Multiply(o.PathEntityTransform)
+	o.ReferenceVector = m.TransformVector(o.ReferenceVector)
+}
@@ -0,0 +1,225 @@
+package dxf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransformCircleNonUniformScaleBecomesEllipse(t *testing.T) {
+	circle := NewCircle()
+	circle.Center = Point{1.0, 1.0, 0.0}
+	circle.Radius = 1.0
+	result, err := Transform(circle, *NewScaleMatrix4(1.0, 2.0, 1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ellipse := result.(*Ellipse)
+	assertNearPoint(t, Point{1.0, 2.0, 0.0}, ellipse.Center)
+	assertNearFloat64(t, 2.0, ellipse.MajorAxis.Length())
+	assertNearFloat64(t, 0.5, ellipse.MinorAxisRatio)
+}
+
+func TestTransformArcMirror(t *testing.T) {
+	arc := NewArc()
+	arc.Radius = 1.0
+	arc.StartAngle = 0.0
+	arc.EndAngle = 90.0
+	result, err := Transform(arc, *NewScaleMatrix4(-1.0, 1.0, 1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirrored := result.(*Arc)
+	assertNearVector(t, Vector{0.0, 0.0, -1.0}, mirrored.Normal)
+
+	// the start and end points swap sides of the Y axis
+	start := Point{1.0, 0.0, 0.0}.OcsToWcs(mirrored.Normal)
+	assertNearPoint(t, Point{-1.0, 0.0, 0.0}, NewRotationMatrix4(mirrored.Normal, mirrored.StartAngle).TransformPoint(start))
+}
+
+func TestTransformText(t *testing.T) {
+	text := NewText()
+	text.Height = 1.0
+	m := NewRotationMatrix4(*NewZAxis(), 30.0).Multiply(*NewScaleMatrix4(2.0, 3.0, 1.0))
+	_, err := Transform(text, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearFloat64(t, 30.0, text.Rotation)
+	assertNearFloat64(t, 3.0, text.Height)
+	assertNearFloat64(t, 2.0/3.0, text.RelativeXScaleFactor)
+}
+
+func TestTransformLWPolylineNonUniformScaleBecomesSpline(t *testing.T) {
+	poly := NewLWPolyline()
+	poly.SetLayer("outline")
+	poly.Vertices = []LwVertex{{X: 0.0, Y: 0.0, Bulge: 1.0}, {X: 2.0, Y: 0.0}}
+	result, err := Transform(poly, *NewScaleMatrix4(1.0, 2.0, 1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spline := result.(*Spline)
+	assertEqString(t, "outline", spline.Layer())
+	assertEqInt(t, 2, spline.DegreeOfCurve)
+	assertEqInt(t, 5, len(spline.ControlPoints))
+	assertEqInt(t, 8, len(spline.KnotValues))
+	assertNearPoint(t, Point{0.0, 0.0, 0.0}, spline.ControlPoints[0].Point)
+	assertNearPoint(t, Point{1.0, -2.0, 0.0}, spline.ControlPoints[2].Point)
+	assertNearPoint(t, Point{2.0, 0.0, 0.0}, spline.ControlPoints[4].Point)
+	assertNearFloat64(t, math.Sqrt(0.5), spline.ControlPoints[1].Weight)
+}
+
+func TestTransformLWPolylineKeepsBulgesUnderMirror(t *testing.T) {
+	poly := NewLWPolyline()
+	poly.Vertices = []LwVertex{{X: 1.0, Y: 0.0, Bulge: 1.0, StartingWidth: 1.0}, {X: 3.0, Y: 0.0}}
+	result, err := Transform(poly, *NewScaleMatrix4(-2.0, 2.0, 2.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirrored := result.(*LWPolyline)
+	assertNearFloat64(t, 1.0, mirrored.Vertices[0].Bulge)
+	assertNearFloat64(t, 2.0, mirrored.Vertices[0].StartingWidth)
+	assertNearPoint(t, Point{-2.0, 0.0, 0.0}, Point{mirrored.Vertices[0].X, mirrored.Vertices[0].Y, 0.0}.OcsToWcs(mirrored.ExtrusionDirection))
+}
+
+func TestTransformInsert(t *testing.T) {
+	insert := NewInsert()
+	insert.Location = Point{1.0, 0.0, 0.0}
+	insert.XScaleFactor = 2.0
+	m := NewRotationMatrix4(*NewZAxis(), 90.0).Multiply(*NewScaleMatrix4(3.0, 3.0, 3.0))
+	_, err := Transform(insert, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearPoint(t, Point{0.0, 3.0, 0.0}, insert.Location)
+	assertNearFloat64(t, 90.0, insert.Rotation)
+	assertNearFloat64(t, 6.0, insert.XScaleFactor)
+	assertNearFloat64(t, 3.0, insert.YScaleFactor)
+
+	insert.Rotation = 45.0
+	_, err = Transform(insert, *NewScaleMatrix4(1.0, 2.0, 1.0))
+	assert(t, err != nil, "expected a skewed insert to fail")
+}
+
+func TestTransformDimension(t *testing.T) {
+	dim := NewRotatedDimension()
+	dim.SetDefinitionPoint1(Point{1.0, 1.0, 0.0})
+	dim.DefinitionPoint2 = Point{2.0, 0.0, 0.0}
+	dim.RotationAngle = 0.0
+	_, err := Transform(dim, *NewRotationMatrix4(*NewZAxis(), 90.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearPoint(t, Point{-1.0, 1.0, 0.0}, dim.DefinitionPoint1())
+	assertNearPoint(t, Point{0.0, 2.0, 0.0}, dim.DefinitionPoint2)
+	assertNearFloat64(t, 90.0, dim.RotationAngle)
+}
+
+func TestTransformHatch(t *testing.T) {
+	hatch := halfDiskHatch(true)
+	hatch.SeedPoints = append(hatch.SeedPoints, Point{0.0, 0.5, 0.0})
+	m := NewTranslationMatrix4(Vector{10.0, 0.0, 0.0}).Multiply(*NewRotationMatrix4(*NewZAxis(), 90.0))
+	_, err := Transform(hatch, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arc := hatch.BoundaryPaths[0].Edges[1].(*HatchArcEdge)
+	assertNearPoint(t, Point{10.0, 0.0, 0.0}, arc.Center)
+	assertNearFloat64(t, 90.0, arc.StartAngle)
+	assertNearFloat64(t, 270.0, arc.EndAngle)
+	assertNearPoint(t, Point{9.5, 0.0, 0.0}, hatch.SeedPoints[0])
+	assertNearFloat64(t, 90.0, hatch.PatternAngle)
+	assertNearBounds(t, Point{9.0, -1.0, 0.0}, Point{10.0, 1.0, 0.0}, BoundingBox(hatch))
+}
+
+func TestTransformHatchNonUniformScale(t *testing.T) {
+	hatch := halfDiskHatch(false)
+	vertices := []HatchVertex{{X: 0.0, Y: 0.0, Bulge: 1.0}, {X: 2.0, Y: 0.0}}
+	hatch.BoundaryPaths = append(hatch.BoundaryPaths, *NewHatchPolylineBoundaryPath(vertices, true))
+	_, err := Transform(hatch, *NewScaleMatrix4(2.0, 1.0, 1.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ellipse := hatch.BoundaryPaths[0].Edges[1].(*HatchEllipseEdge)
+	assertNearVector(t, Vector{2.0, 0.0, 0.0}, ellipse.MajorAxis)
+	assertNearFloat64(t, 0.5, ellipse.MinorAxisRatio)
+	assert(t, !ellipse.IsCounterClockwise, "expected a clockwise edge")
+
+	// a polyline path with arcs becomes an edge path
+	path := hatch.BoundaryPaths[1]
+	assert(t, !path.IsPolyline(), "expected an edge path")
+	assertEqInt(t, 2, len(path.Edges))
+	assertNearBounds(t, Point{-2.0, -1.0, 0.0}, Point{4.0, 0.0, 0.0}, BoundingBox(hatch))
+}
+
+func TestTransformMLeader(t *testing.T) {
+	l := NewMLeader()
+	l.Context.MText.Location = Point{5.0, 1.0, 0.0}
+	l.Context.Block.TransformationMatrix = *NewTranslationMatrix4(Vector{5.0, 1.0, 0.0})
+	l.Context.Roots = append(l.Context.Roots, MLeaderRoot{
+		HasConnectionPoint: true,
+		ConnectionPoint:    Point{4.0, 1.0, 0.0},
+		DoglegVector:       Vector{1.0, 0.0, 0.0},
+		DoglegLength:       1.0,
+		Lines:              []MLeaderLine{{Vertices: []Point{{0.0, 0.0, 0.0}, {3.0, 1.0, 0.0}}}},
+	})
+	m := NewTranslationMatrix4(Vector{10.0, 0.0, 0.0}).Multiply(*NewRotationMatrix4(*NewZAxis(), 90.0)).Multiply(*NewScaleMatrix4(2.0, 2.0, 2.0))
+	_, err := Transform(l, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := l.Context.Roots[0]
+	assertNearPoint(t, Point{10.0, 0.0, 0.0}, root.Lines[0].Vertices[0])
+	assertNearPoint(t, Point{8.0, 6.0, 0.0}, root.Lines[0].Vertices[1])
+	assertNearPoint(t, Point{8.0, 8.0, 0.0}, root.ConnectionPoint)
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, root.DoglegVector)
+	assertNearFloat64(t, 2.0, root.DoglegLength)
+	assertNearPoint(t, Point{8.0, 10.0, 0.0}, l.Context.MText.Location)
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, l.Context.MText.Direction)
+	assertNearFloat64(t, math.Pi/2.0, l.Context.MText.Rotation)
+	assertNearFloat64(t, 8.0, l.Context.TextHeight)
+	assertNearFloat64(t, math.Pi/2.0, l.Context.Block.Rotation)
+	assertNearPoint(t, Point{8.0, 10.0, 0.0}, l.Context.Block.TransformationMatrix.TransformPoint(Point{}))
+}
+
+func TestTransformUnsupportedEntity(t *testing.T) {
+	_, err := Transform(NewProxyEntity(), *NewIdentityMatrix4())
+	assert(t, err != nil, "expected an error for a proxy entity")
+}
+
+func TestTransformModelerGeometryAddsBodyTransform(t *testing.T) {
+	solid := NewSolid3D()
+	SetAcisSatText(solid, sphereSat, R2004)
+	_, err := Transform(solid, *NewTranslationMatrix4(Vector{10.0, 0.0, 0.0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := BoundingBox(solid)
+	assertNearPoint(t, Point{6.0, -3.0, -2.0}, b.Min)
+	assertNearPoint(t, Point{16.0, 7.0, 8.0}, b.Max)
+	assertEqString(t, "700 0 1 0", solid.AcisLines()[0])
+}
+
+func TestTransformModelerGeometryComposesBodyTransform(t *testing.T) {
+	region := NewRegion()
+	SetAcisSatText(region, diskSat, R2000)
+	_, err := Transform(region, *NewRotationMatrix4(*NewZAxis(), 90.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, isEncryptedSat(region.AcisLines()[0]), "expected the text to stay encrypted")
+	b := BoundingBox(region)
+	assertNearPoint(t, Point{-2.0, 8.0, 0.0}, b.Min)
+	assertNearPoint(t, Point{2.0, 12.0, 0.0}, b.Max)
+}
+
+func TestTransformRevolvedSurface(t *testing.T) {
+	surface := NewRevolvedSurface()
+	surface.AxisPoint = Point{1.0, 0.0, 0.0}
+	surface.AxisDirection = Vector{1.0, 0.0, 0.0}
+	_, err := Transform(surface, *NewRotationMatrix4(*NewZAxis(), 90.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearPoint(t, Point{0.0, 1.0, 0.0}, surface.AxisPoint)
+	assertNearVector(t, Vector{0.0, 1.0, 0.0}, surface.AxisDirection)
+}
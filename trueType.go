@@ -0,0 +1,515 @@
+package dxf
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// maxCompositeGlyphDepth bounds the nesting of composite glyphs so a malformed font can't recurse forever.
+const maxCompositeGlyphDepth = 8
+
+// TrueTypeFont is a TrueType or OpenType font with TrueType or CFF outlines.
+type TrueTypeFont struct {
+	tables           map[string][]byte
+	unitsPerEm       float64
+	ascent           float64
+	descent          float64
+	glyphCount       int
+	longLocaOffsets  bool
+	hMetricCount     int
+	cmap             []byte
+	isSymbolEncoding bool
+	cff              *sfnt.Font // the outlines of fonts with CFF outlines
+}
+
+// glyphPoint is a point of a glyph outline in font units.  Points off the curve are quadratic control points, or for
+// fonts with CFF outlines pairs of cubic control points.
+type glyphPoint struct {
+	x       float64
+	y       float64
+	onCurve bool
+}
+
+// ParseTrueTypeFont parses the contents of a `.ttf`, `.otf` or `.ttc` file; the first font of a collection is used.
+func ParseTrueTypeFont(data []byte) (*TrueTypeFont, error) {
+	offset := 0
+	if len(data) >= 16 && string(data[0:4]) == "ttcf" {
+		offset = int(binary.BigEndian.Uint32(data[12:16]))
+	}
+	if offset+12 > len(data) {
+		return nil, fmt.Errorf("font data is too short")
+	}
+	outlineTables := []string{"loca", "glyf"}
+	switch binary.BigEndian.Uint32(data[offset:]) {
+	case 0x00010000, 0x74727565: // 1.0 and 'true'
+	case 0x4f54544f: // 'OTTO'
+		outlineTables = []string{"CFF "}
+	default:
+		return nil, fmt.Errorf("unrecognized font format")
+	}
+
+	f := &TrueTypeFont{tables: map[string][]byte{}}
+	tableCount := int(binary.BigEndian.Uint16(data[offset+4:]))
+	for i := 0; i < tableCount; i++ {
+		record := offset + 12 + i*16
+		if record+16 > len(data) {
+			return nil, fmt.Errorf("font table directory is truncated")
+		}
+		tag := string(data[record : record+4])
+		start := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if start < 0 || length < 0 || start+length > len(data) {
+			return nil, fmt.Errorf("font table '%s' is out of range", tag)
+		}
+		f.tables[tag] = data[start : start+length]
+	}
+	for _, tag := range append([]string{"head", "hhea", "hmtx", "maxp", "cmap"}, outlineTables...) {
+		if _, ok := f.tables[tag]; !ok {
+			return nil, fmt.Errorf("font table '%s' is missing", tag)
+		}
+	}
+
+	head := f.tables["head"]
+	hhea := f.tables["hhea"]
+	maxp := f.tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, fmt.Errorf("font header is truncated")
+	}
+	f.unitsPerEm = float64(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0.0 {
+		return nil, fmt.Errorf("font has no units per em")
+	}
+	f.longLocaOffsets = int16(binary.BigEndian.Uint16(head[50:])) != 0
+	f.ascent = float64(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = -float64(int16(binary.BigEndian.Uint16(hhea[6:])))
+	f.hMetricCount = int(binary.BigEndian.Uint16(hhea[34:]))
+	f.glyphCount = int(binary.BigEndian.Uint16(maxp[4:]))
+	if f.hMetricCount == 0 || len(f.tables["hmtx"]) < f.hMetricCount*4 {
+		return nil, fmt.Errorf("font horizontal metrics are truncated")
+	}
+
+	if err := f.selectCharacterMap(); err != nil {
+		return nil, err
+	}
+	if _, ok := f.tables["CFF "]; ok {
+		if err := f.parseCff(data, offset); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// parseCff reads the CFF outlines of the font at `offset` in `data`.
+func (f *TrueTypeFont) parseCff(data []byte, offset int) (err error) {
+	if offset > 0 {
+		var collection *sfnt.Collection
+		if collection, err = sfnt.ParseCollection(data); err == nil {
+			f.cff, err = collection.Font(0)
+		}
+	} else {
+		f.cff, err = sfnt.Parse(data)
+	}
+	if err != nil {
+		return fmt.Errorf("font CFF outlines: %s", err)
+	}
+	return nil
+}
+
+// selectCharacterMap picks the Unicode subtable of the character map, preferring the full repertoire.
+func (f *TrueTypeFont) selectCharacterMap() error {
+	cmap := f.tables["cmap"]
+	if len(cmap) < 4 {
+		return fmt.Errorf("font character map is truncated")
+	}
+	best := -1
+	for i := 0; i < int(binary.BigEndian.Uint16(cmap[2:])); i++ {
+		record := 4 + i*8
+		if record+8 > len(cmap) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(cmap[record:])
+		encoding := binary.BigEndian.Uint16(cmap[record+2:])
+		start := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if start+2 > len(cmap) {
+			continue
+		}
+		format := binary.BigEndian.Uint16(cmap[start:])
+		if format != 4 && format != 12 {
+			continue
+		}
+
+		priority := -1
+		switch {
+		case platform == 3 && encoding == 10, platform == 0 && (encoding == 4 || encoding == 6):
+			priority = 3
+		case platform == 3 && encoding == 1, platform == 0:
+			priority = 2
+		case platform == 3 && encoding == 0:
+			priority = 1
+		}
+		if priority > best {
+			best = priority
+			f.cmap = cmap[start:]
+			f.isSymbolEncoding = priority == 1
+		}
+	}
+	if best < 0 {
+		return fmt.Errorf("font has no Unicode character map")
+	}
+	return nil
+}
+
+// GlyphIndex returns the index of the glyph showing `r`, or 0 for the missing glyph.
+func (f *TrueTypeFont) GlyphIndex(r rune) int {
+	index := f.lookupCharacter(uint32(r))
+	if index == 0 && f.isSymbolEncoding && r < 0x100 {
+		// symbol fonts map single bytes into the private use area
+		index = f.lookupCharacter(0xf000 + uint32(r))
+	}
+	if index >= f.glyphCount {
+		return 0
+	}
+	return index
+}
+
+func (f *TrueTypeFont) lookupCharacter(c uint32) int {
+	cmap := f.cmap
+	switch binary.BigEndian.Uint16(cmap) {
+	case 4:
+		if c > 0xffff || len(cmap) < 14 {
+			return 0
+		}
+		segmentCount := int(binary.BigEndian.Uint16(cmap[6:])) / 2
+		ends := 14
+		starts := ends + segmentCount*2 + 2
+		deltas := starts + segmentCount*2
+		rangeOffsets := deltas + segmentCount*2
+		if rangeOffsets+segmentCount*2 > len(cmap) {
+			return 0
+		}
+		for i := 0; i < segmentCount; i++ {
+			if uint32(binary.BigEndian.Uint16(cmap[ends+i*2:])) < c {
+				continue
+			}
+			start := uint32(binary.BigEndian.Uint16(cmap[starts+i*2:]))
+			if start > c {
+				return 0
+			}
+			delta := uint32(binary.BigEndian.Uint16(cmap[deltas+i*2:]))
+			rangeOffset := int(binary.BigEndian.Uint16(cmap[rangeOffsets+i*2:]))
+			if rangeOffset == 0 {
+				return int((c + delta) & 0xffff)
+			}
+			address := rangeOffsets + i*2 + rangeOffset + int(c-start)*2
+			if address+2 > len(cmap) {
+				return 0
+			}
+			glyph := uint32(binary.BigEndian.Uint16(cmap[address:]))
+			if glyph == 0 {
+				return 0
+			}
+			return int((glyph + delta) & 0xffff)
+		}
+	case 12:
+		if len(cmap) < 16 {
+			return 0
+		}
+		groupCount := int(binary.BigEndian.Uint32(cmap[12:]))
+		for i := 0; i < groupCount && 16+i*12+12 <= len(cmap); i++ {
+			group := cmap[16+i*12:]
+			start := binary.BigEndian.Uint32(group)
+			end := binary.BigEndian.Uint32(group[4:])
+			if start <= c && c <= end {
+				return int(binary.BigEndian.Uint32(group[8:]) + c - start)
+			}
+		}
+	}
+	return 0
+}
+
+// Advance returns the advance width of the glyph showing `r`, relative to the em size.
+func (f *TrueTypeFont) Advance(r rune) float64 {
+	return f.glyphAdvance(f.GlyphIndex(r)) / f.unitsPerEm
+}
+
+func (f *TrueTypeFont) glyphAdvance(glyph int) float64 {
+	if glyph >= f.hMetricCount {
+		// monospaced runs at the end of the table repeat the last advance
+		glyph = f.hMetricCount - 1
+	}
+	return float64(binary.BigEndian.Uint16(f.tables["hmtx"][glyph*4:]))
+}
+
+// Ascent returns how far the font reaches above the baseline, relative to the em size.
+func (f *TrueTypeFont) Ascent() float64 {
+	return f.ascent / f.unitsPerEm
+}
+
+// Descent returns how far the font reaches below the baseline, relative to the em size.
+func (f *TrueTypeFont) Descent() float64 {
+	return f.descent / f.unitsPerEm
+}
+
+// glyphData returns the outline data of a glyph, which is empty for glyphs without contours, e.g., a space.
+func (f *TrueTypeFont) glyphData(glyph int) ([]byte, error) {
+	loca := f.tables["loca"]
+	var start, end int
+	if f.longLocaOffsets {
+		if glyph*4+8 > len(loca) {
+			return nil, fmt.Errorf("glyph %d is out of range", glyph)
+		}
+		start = int(binary.BigEndian.Uint32(loca[glyph*4:]))
+		end = int(binary.BigEndian.Uint32(loca[glyph*4+4:]))
+	} else {
+		if glyph*2+4 > len(loca) {
+			return nil, fmt.Errorf("glyph %d is out of range", glyph)
+		}
+		start = int(binary.BigEndian.Uint16(loca[glyph*2:])) * 2
+		end = int(binary.BigEndian.Uint16(loca[glyph*2+2:])) * 2
+	}
+	glyf := f.tables["glyf"]
+	if start > end || end > len(glyf) {
+		return nil, fmt.Errorf("glyph %d is out of range", glyph)
+	}
+	return glyf[start:end], nil
+}
+
+// glyphContours returns the closed contours of the glyph showing `r` in font units.
+func (f *TrueTypeFont) glyphContours(r rune) ([][]glyphPoint, error) {
+	if f.cff != nil {
+		return f.cffContours(f.GlyphIndex(r))
+	}
+	return f.contours(f.GlyphIndex(r), 0)
+}
+
+// cffContours returns the contours of a glyph with CFF outlines, made of lines and cubic curves.
+func (f *TrueTypeFont) cffContours(glyph int) ([][]glyphPoint, error) {
+	// one pixel per font unit
+	ppem := fixed.Int26_6(f.unitsPerEm * 64.0)
+	var buffer sfnt.Buffer
+	segments, err := f.cff.LoadGlyph(&buffer, sfnt.GlyphIndex(glyph), ppem, nil)
+	if err != nil {
+		return nil, fmt.Errorf("glyph %d: %s", glyph, err)
+	}
+
+	point := func(p fixed.Point26_6, onCurve bool) glyphPoint {
+		// the loaded y axis points down
+		return glyphPoint{x: float64(p.X) / 64.0, y: -float64(p.Y) / 64.0, onCurve: onCurve}
+	}
+	contours := [][]glyphPoint{}
+	closeContour := func(contour []glyphPoint) {
+		if len(contour) > 1 && contour[len(contour)-1] == contour[0] {
+			contour = contour[:len(contour)-1]
+		}
+		if len(contour) > 1 {
+			contours = append(contours, contour)
+		}
+	}
+	var contour []glyphPoint
+	for _, segment := range segments {
+		switch segment.Op {
+		case sfnt.SegmentOpMoveTo:
+			closeContour(contour)
+			contour = []glyphPoint{point(segment.Args[0], true)}
+		case sfnt.SegmentOpLineTo:
+			contour = append(contour, point(segment.Args[0], true))
+		case sfnt.SegmentOpQuadTo:
+			// raise the degree so every curve of the contour is cubic
+			start := contour[len(contour)-1]
+			control, end := point(segment.Args[0], false), point(segment.Args[1], true)
+			contour = append(contour,
+				glyphPoint{x: start.x + (control.x-start.x)*2.0/3.0, y: start.y + (control.y-start.y)*2.0/3.0},
+				glyphPoint{x: end.x + (control.x-end.x)*2.0/3.0, y: end.y + (control.y-end.y)*2.0/3.0},
+				end)
+		case sfnt.SegmentOpCubeTo:
+			contour = append(contour, point(segment.Args[0], false), point(segment.Args[1], false), point(segment.Args[2], true))
+		}
+	}
+	closeContour(contour)
+	return contours, nil
+}
+
+func (f *TrueTypeFont) contours(glyph, depth int) ([][]glyphPoint, error) {
+	data, err := f.glyphData(glyph)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	if len(data) < 10 {
+		return nil, fmt.Errorf("glyph %d is truncated", glyph)
+	}
+	contourCount := int(int16(binary.BigEndian.Uint16(data)))
+	if contourCount < 0 {
+		if depth >= maxCompositeGlyphDepth {
+			return nil, fmt.Errorf("glyph %d nests too deeply", glyph)
+		}
+		return f.compositeContours(glyph, data[10:], depth)
+	}
+	return simpleContours(glyph, data[10:], contourCount)
+}
+
+func simpleContours(glyph int, data []byte, contourCount int) ([][]glyphPoint, error) {
+	truncated := fmt.Errorf("glyph %d is truncated", glyph)
+	if len(data) < contourCount*2+2 {
+		return nil, truncated
+	}
+	ends := make([]int, contourCount)
+	for i := range ends {
+		ends[i] = int(binary.BigEndian.Uint16(data[i*2:]))
+	}
+	pointCount := 0
+	if contourCount > 0 {
+		pointCount = ends[contourCount-1] + 1
+	}
+	instructionLength := int(binary.BigEndian.Uint16(data[contourCount*2:]))
+	offset := contourCount*2 + 2 + instructionLength
+
+	// flags may repeat
+	flags := make([]byte, 0, pointCount)
+	for len(flags) < pointCount {
+		if offset >= len(data) {
+			return nil, truncated
+		}
+		flag := data[offset]
+		offset++
+		flags = append(flags, flag)
+		if flag&0x08 != 0 {
+			if offset >= len(data) {
+				return nil, truncated
+			}
+			for n := int(data[offset]); n > 0 && len(flags) < pointCount; n-- {
+				flags = append(flags, flag)
+			}
+			offset++
+		}
+	}
+
+	// coordinates are deltas, either a signed word or a byte whose sign comes from the flags
+	readCoordinates := func(shortFlag, sameOrPositiveFlag byte) ([]float64, error) {
+		values := make([]float64, pointCount)
+		value := 0
+		for i, flag := range flags {
+			switch {
+			case flag&shortFlag != 0:
+				if offset >= len(data) {
+					return nil, truncated
+				}
+				if flag&sameOrPositiveFlag != 0 {
+					value += int(data[offset])
+				} else {
+					value -= int(data[offset])
+				}
+				offset++
+			case flag&sameOrPositiveFlag == 0:
+				if offset+2 > len(data) {
+					return nil, truncated
+				}
+				value += int(int16(binary.BigEndian.Uint16(data[offset:])))
+				offset += 2
+			}
+			values[i] = float64(value)
+		}
+		return values, nil
+	}
+	xs, err := readCoordinates(0x02, 0x10)
+	if err != nil {
+		return nil, err
+	}
+	ys, err := readCoordinates(0x04, 0x20)
+	if err != nil {
+		return nil, err
+	}
+
+	contours := make([][]glyphPoint, 0, contourCount)
+	start := 0
+	for _, end := range ends {
+		if end < start || end >= pointCount {
+			return nil, fmt.Errorf("glyph %d has invalid contours", glyph)
+		}
+		contour := make([]glyphPoint, 0, end-start+1)
+		for i := start; i <= end; i++ {
+			contour = append(contour, glyphPoint{x: xs[i], y: ys[i], onCurve: flags[i]&0x01 != 0})
+		}
+		contours = append(contours, contour)
+		start = end + 1
+	}
+	return contours, nil
+}
+
+func (f *TrueTypeFont) compositeContours(glyph int, data []byte, depth int) ([][]glyphPoint, error) {
+	truncated := fmt.Errorf("glyph %d is truncated", glyph)
+	contours := [][]glyphPoint{}
+	offset := 0
+	for {
+		if offset+4 > len(data) {
+			return nil, truncated
+		}
+		flags := binary.BigEndian.Uint16(data[offset:])
+		component := int(binary.BigEndian.Uint16(data[offset+2:]))
+		offset += 4
+
+		var dx, dy float64
+		if flags&0x0001 != 0 {
+			if offset+4 > len(data) {
+				return nil, truncated
+			}
+			dx = float64(int16(binary.BigEndian.Uint16(data[offset:])))
+			dy = float64(int16(binary.BigEndian.Uint16(data[offset+2:])))
+			offset += 4
+		} else {
+			if offset+2 > len(data) {
+				return nil, truncated
+			}
+			dx = float64(int8(data[offset]))
+			dy = float64(int8(data[offset+1]))
+			offset += 2
+		}
+		if flags&0x0002 == 0 {
+			// the arguments match points instead of offsetting the component
+			dx, dy = 0.0, 0.0
+		}
+
+		f2dot14 := func(i int) float64 {
+			return float64(int16(binary.BigEndian.Uint16(data[offset+i*2:]))) / 16384.0
+		}
+		xx, xy, yx, yy := 1.0, 0.0, 0.0, 1.0
+		switch {
+		case flags&0x0008 != 0:
+			if offset+2 > len(data) {
+				return nil, truncated
+			}
+			xx = f2dot14(0)
+			yy = xx
+			offset += 2
+		case flags&0x0040 != 0:
+			if offset+4 > len(data) {
+				return nil, truncated
+			}
+			xx, yy = f2dot14(0), f2dot14(1)
+			offset += 4
+		case flags&0x0080 != 0:
+			if offset+8 > len(data) {
+				return nil, truncated
+			}
+			xx, xy, yx, yy = f2dot14(0), f2dot14(1), f2dot14(2), f2dot14(3)
+			offset += 8
+		}
+
+		componentContours, err := f.contours(component, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, contour := range componentContours {
+			for i, p := range contour {
+				contour[i] = glyphPoint{x: p.x*xx + p.y*yx + dx, y: p.x*xy + p.y*yy + dy, onCurve: p.onCurve}
+			}
+			contours = append(contours, contour)
+		}
+
+		if flags&0x0020 == 0 {
+			return contours, nil
+		}
+	}
+}
@@ -2,6 +2,7 @@ package dxf
 
 import (
 	"fmt"
+	"math"
 )
 
 // The Vector struct represents a vector in 3D space.
@@ -50,3 +51,51 @@ func NewZeroVector() *Vector {
 func (v *Vector) String() string {
 	return fmt.Sprintf("(%s, %s, %s)", formatFloat64Text(v.X), formatFloat64Text(v.Y), formatFloat64Text(v.Z))
 }
+
+// Add returns the sum of two vectors.
+func (v Vector) Add(other Vector) Vector {
+	return Vector{X: v.X + other.X, Y: v.Y + other.Y, Z: v.Z + other.Z}
+}
+
+// Sub returns the difference of two vectors.
+func (v Vector) Sub(other Vector) Vector {
+	return Vector{X: v.X - other.X, Y: v.Y - other.Y, Z: v.Z - other.Z}
+}
+
+// Scale returns the vector multiplied by a scalar.
+func (v Vector) Scale(factor float64) Vector {
+	return Vector{X: v.X * factor, Y: v.Y * factor, Z: v.Z * factor}
+}
+
+// Dot returns the dot product of two vectors.
+func (v Vector) Dot(other Vector) float64 {
+	return v.X*other.X + v.Y*other.Y + v.Z*other.Z
+}
+
+// Cross returns the cross product of two vectors.
+func (v Vector) Cross(other Vector) Vector {
+	return Vector{
+		X: v.Y*other.Z - v.Z*other.Y,
+		Y: v.Z*other.X - v.X*other.Z,
+		Z: v.X*other.Y - v.Y*other.X,
+	}
+}
+
+// Length returns the length of the vector.
+func (v Vector) Length() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+// Normalize returns a unit vector in the same direction, or the zero vector if the vector has no length.
+func (v Vector) Normalize() Vector {
+	length := v.Length()
+	if length == 0.0 {
+		return *NewZeroVector()
+	}
+	return v.Scale(1.0 / length)
+}
+
+// IsZero returns true if every component of the vector is within `tolerance` of zero.
+func (v Vector) IsZero(tolerance float64) bool {
+	return math.Abs(v.X) <= tolerance && math.Abs(v.Y) <= tolerance && math.Abs(v.Z) <= tolerance
+}
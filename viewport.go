@@ -0,0 +1,266 @@
+package dxf
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// extendedDataCollector is implemented by entities that keep the XDATA following their own code pairs.
+type extendedDataCollector interface {
+	addExtendedDataPair(codePair CodePair)
+}
+
+func (v *Viewport) addExtendedDataPair(codePair CodePair) {
+	v.extendedDataPairs = append(v.extendedDataPairs, codePair)
+}
+
+// The R12 view settings are stored as XDATA in the order below; the values past the frozen layers were added in
+// later releases and are ignored.
+const viewportR12ExtendedDataVersion = 16
+
+// afterRead applies the view settings that R12 stores as XDATA.
+func (v *Viewport) afterRead() {
+	pairs := v.extendedDataPairs
+	v.extendedDataPairs = []CodePair{}
+
+	// find the ACAD application's MVIEW data
+	start := -1
+	for i := 0; i+1 < len(pairs); i++ {
+		if pairs[i].Code == 1001 && pairs[i].Value.(StringCodePairValue).Value == "ACAD" &&
+			pairs[i+1].Code == 1000 && pairs[i+1].Value.(StringCodePairValue).Value == "MVIEW" {
+			start = i + 2
+			break
+		}
+	}
+	if start < 0 {
+		return
+	}
+
+	var doubles []float64
+	var shorts []int16
+	var points []Point
+	depth := 0
+	for _, pair := range pairs[start:] {
+		if pair.Code == 1001 {
+			// another application's data
+			break
+		}
+		switch {
+		case pair.Code == 1002:
+			if pair.Value.(StringCodePairValue).Value == "{" {
+				depth++
+			} else {
+				depth--
+			}
+		case pair.Code == 1003 && depth == 2:
+			v.FrozenLayerNames = append(v.FrozenLayerNames, pair.Value.(StringCodePairValue).Value)
+		case pair.Code == 1010:
+			points = append(points, Point{X: pair.Value.(DoubleCodePairValue).Value})
+		case pair.Code == 1020 && len(points) > 0:
+			points[len(points)-1].Y = pair.Value.(DoubleCodePairValue).Value
+		case pair.Code == 1030 && len(points) > 0:
+			points[len(points)-1].Z = pair.Value.(DoubleCodePairValue).Value
+		case pair.Code == 1040 && depth == 1:
+			doubles = append(doubles, pair.Value.(DoubleCodePairValue).Value)
+		case pair.Code == 1070 && depth == 1:
+			shorts = append(shorts, pair.Value.(ShortCodePairValue).Value)
+		}
+	}
+
+	if len(points) < 2 || len(doubles) < 14 || len(shorts) < 10 {
+		// incomplete view data
+		return
+	}
+
+	v.ViewTargetPoint = points[0]
+	v.ViewDirection = Vector{X: points[1].X, Y: points[1].Y, Z: points[1].Z}
+	v.TwistAngle = doubles[0]
+	v.ViewHeight = doubles[1]
+	v.ViewCenter = Point{X: doubles[2], Y: doubles[3], Z: 0.0}
+	v.LensLength = doubles[4]
+	v.FrontClipPlane = doubles[5]
+	v.BackClipPlane = doubles[6]
+	v.SnapAngle = doubles[7]
+	v.SnapBasePoint = Point{X: doubles[8], Y: doubles[9], Z: 0.0}
+	v.SnapSpacing = Vector{X: doubles[10], Y: doubles[11], Z: 0.0}
+	v.GridSpacing = Vector{X: doubles[12], Y: doubles[13], Z: 0.0}
+
+	// shorts[0] is the data version
+	v.StatusFlags = int(shorts[1]) & 0x1F
+	v.CircleZoomPercent = shorts[2]
+	v.SetIsFastZoom(shorts[3] != 0)
+	v.SetIsUcsIconVisible(shorts[4]&1 != 0)
+	v.SetIsUcsIconAtOrigin(shorts[4]&2 != 0)
+	v.SetIsSnapOn(shorts[5] != 0)
+	v.SetIsGridOn(shorts[6] != 0)
+	v.SetIsIsometricSnapStyle(shorts[7] != 0)
+	v.SetIsIsometricPairTop(shorts[8] == 1)
+	v.SetIsIsometricPairRight(shorts[8] == 2)
+	v.SetIsHiddenInPlot(shorts[9] != 0)
+}
+
+// extendedDataCodePairs returns the view settings in the XDATA form used by R12.
+func (v *Viewport) extendedDataCodePairs() (pairs []CodePair) {
+	isometricPair := int16(0)
+	if v.IsIsometricPairTop() {
+		isometricPair = 1
+	} else if v.IsIsometricPairRight() {
+		isometricPair = 2
+	}
+	ucsIcon := int16(0)
+	if v.IsUcsIconVisible() {
+		ucsIcon |= 1
+	}
+	if v.IsUcsIconAtOrigin() {
+		ucsIcon |= 2
+	}
+
+	pairs = append(pairs, NewStringCodePair(1001, "ACAD"))
+	pairs = append(pairs, NewStringCodePair(1000, "MVIEW"))
+	pairs = append(pairs, NewStringCodePair(1002, "{"))
+	pairs = append(pairs, NewShortCodePair(1070, viewportR12ExtendedDataVersion))
+	pairs = append(pairs, NewDoubleCodePair(1010, v.ViewTargetPoint.X))
+	pairs = append(pairs, NewDoubleCodePair(1020, v.ViewTargetPoint.Y))
+	pairs = append(pairs, NewDoubleCodePair(1030, v.ViewTargetPoint.Z))
+	pairs = append(pairs, NewDoubleCodePair(1010, v.ViewDirection.X))
+	pairs = append(pairs, NewDoubleCodePair(1020, v.ViewDirection.Y))
+	pairs = append(pairs, NewDoubleCodePair(1030, v.ViewDirection.Z))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.TwistAngle))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.ViewHeight))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.ViewCenter.X))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.ViewCenter.Y))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.LensLength))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.FrontClipPlane))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.BackClipPlane))
+	pairs = append(pairs, NewShortCodePair(1070, int16(v.StatusFlags&0x1F)))
+	pairs = append(pairs, NewShortCodePair(1070, v.CircleZoomPercent))
+	pairs = append(pairs, NewShortCodePair(1070, shortFromBool(v.IsFastZoom())))
+	pairs = append(pairs, NewShortCodePair(1070, ucsIcon))
+	pairs = append(pairs, NewShortCodePair(1070, shortFromBool(v.IsSnapOn())))
+	pairs = append(pairs, NewShortCodePair(1070, shortFromBool(v.IsGridOn())))
+	pairs = append(pairs, NewShortCodePair(1070, shortFromBool(v.IsIsometricSnapStyle())))
+	pairs = append(pairs, NewShortCodePair(1070, isometricPair))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.SnapAngle))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.SnapBasePoint.X))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.SnapBasePoint.Y))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.SnapSpacing.X))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.SnapSpacing.Y))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.GridSpacing.X))
+	pairs = append(pairs, NewDoubleCodePair(1040, v.GridSpacing.Y))
+	pairs = append(pairs, NewShortCodePair(1070, shortFromBool(v.IsHiddenInPlot())))
+	pairs = append(pairs, NewStringCodePair(1002, "{"))
+	for _, name := range v.FrozenLayerNames {
+		pairs = append(pairs, NewStringCodePair(1003, name))
+	}
+	pairs = append(pairs, NewStringCodePair(1002, "}"))
+	pairs = append(pairs, NewStringCodePair(1002, "}"))
+	return
+}
+
+// updateViewportFrozenLayers fills in the frozen layers of each viewport in the form the drawing's version writes:
+// layer handles for R13 and later, layer names for R12.  Layers that can't be found are skipped.
+func (d *Drawing) updateViewportFrozenLayers() {
+	var viewports []*Viewport
+	for _, e := range d.Entities {
+		if v, ok := e.(*Viewport); ok {
+			viewports = append(viewports, v)
+		}
+	}
+	for _, b := range d.Blocks {
+		for _, e := range b.Entities {
+			if v, ok := e.(*Viewport); ok {
+				viewports = append(viewports, v)
+			}
+		}
+	}
+
+	for _, v := range viewports {
+		if d.Header.Version >= R13 {
+			for _, name := range v.FrozenLayerNames {
+				for i := range d.Layers {
+					layer := &d.Layers[i]
+					if strings.EqualFold(layer.Name, name) && !containsHandle(v.FrozenLayerHandles, layer.Handle()) {
+						v.FrozenLayerHandles = append(v.FrozenLayerHandles, layer.Handle())
+					}
+				}
+			}
+		} else {
+			for _, handle := range v.FrozenLayerHandles {
+				for i := range d.Layers {
+					layer := &d.Layers[i]
+					if layer.Handle() == handle && !containsFold(v.FrozenLayerNames, layer.Name) {
+						v.FrozenLayerNames = append(v.FrozenLayerNames, layer.Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func containsHandle(handles []Handle, handle Handle) bool {
+	for _, h := range handles {
+		if h == handle {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scale returns the number of paper space units per model space unit shown in the viewport.
+func (v *Viewport) Scale() float64 {
+	if v.ViewHeight == 0.0 {
+		return 0.0
+	}
+	return v.Height / v.ViewHeight
+}
+
+// viewAxes returns the world directions of the display X and Y axes, including the twist.
+func (v *Viewport) viewAxes() (xAxis, yAxis Vector) {
+	xAxis, yAxis = v.ViewDirection.ArbitraryAxes()
+	twist := v.TwistAngle * math.Pi / 180.0
+	cos, sin := math.Cos(twist), math.Sin(twist)
+
+	// the view is rotated by the twist, so the display axes turn the other way in the world
+	twistedX := xAxis.Scale(cos).Sub(yAxis.Scale(sin))
+	twistedY := xAxis.Scale(sin).Add(yAxis.Scale(cos))
+	return twistedX, twistedY
+}
+
+// PaperToModel maps a point in paper space to the model space point shown beneath it in the viewport.  The result
+// lies in the plane through the view target that's perpendicular to the view direction.
+func (v *Viewport) PaperToModel(p Point) (Point, error) {
+	scale := v.Scale()
+	if scale == 0.0 || v.ViewDirection.IsZero(0.0) {
+		return Point{}, errors.New("the viewport has no view")
+	}
+
+	xAxis, yAxis := v.viewAxes()
+	dx := (p.X-v.Center.X)/scale + v.ViewCenter.X
+	dy := (p.Y-v.Center.Y)/scale + v.ViewCenter.Y
+	return v.ViewTargetPoint.Add(xAxis.Scale(dx).Add(yAxis.Scale(dy))), nil
+}
+
+// ModelToPaper maps a model space point to where it appears in paper space through the viewport, projecting along
+// the view direction.
+func (v *Viewport) ModelToPaper(p Point) (Point, error) {
+	scale := v.Scale()
+	if scale == 0.0 || v.ViewDirection.IsZero(0.0) {
+		return Point{}, errors.New("the viewport has no view")
+	}
+
+	xAxis, yAxis := v.viewAxes()
+	offset := p.Sub(v.ViewTargetPoint)
+	dx := offset.Dot(xAxis) - v.ViewCenter.X
+	dy := offset.Dot(yAxis) - v.ViewCenter.Y
+	return Point{X: v.Center.X + dx*scale, Y: v.Center.Y + dy*scale, Z: v.Center.Z}, nil
+}
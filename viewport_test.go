@@ -0,0 +1,326 @@
+package dxf
+
+import (
+	"testing"
+)
+
+func TestReadViewport(t *testing.T) {
+	v := parseEntity(t, "VIEWPORT",
+		NewStringCodePair(100, "AcDbViewport"),
+		NewDoubleCodePair(10, 5.0),
+		NewDoubleCodePair(20, 6.0),
+		NewDoubleCodePair(40, 8.0),
+		NewDoubleCodePair(41, 4.0),
+		NewShortCodePair(68, 2),
+		NewShortCodePair(69, 3),
+		NewDoubleCodePair(12, 1.0),
+		NewDoubleCodePair(22, 2.0),
+		NewDoubleCodePair(45, 2.0),
+		NewDoubleCodePair(51, 30.0),
+		NewStringCodePair(331, "A1"),
+		NewStringCodePair(331, "A2"),
+		NewIntCodePair(90, 256|512),
+		NewStringCodePair(340, "FF"),
+	).(*Viewport)
+	assertEqPoint(t, Point{X: 5.0, Y: 6.0, Z: 0.0}, v.Center)
+	assertEqFloat64(t, 8.0, v.Width)
+	assertEqFloat64(t, 4.0, v.Height)
+	assertEqInt(t, 2, int(v.Status))
+	assertEqInt(t, 3, int(v.ID))
+	assertEqPoint(t, Point{X: 1.0, Y: 2.0, Z: 0.0}, v.ViewCenter)
+	assertEqFloat64(t, 2.0, v.ViewHeight)
+	assertEqFloat64(t, 30.0, v.TwistAngle)
+	assertEqInt(t, 2, len(v.FrozenLayerHandles))
+	assertEqUInt64(t, 0xA2, uint64(v.FrozenLayerHandles[1]))
+	assert(t, v.IsSnapOn(), "expected snap on")
+	assert(t, v.IsGridOn(), "expected grid on")
+	assert(t, !v.IsPerspective(), "expected no perspective")
+	assertEqUInt64(t, 0xFF, uint64(v.pointerClippingBoundary.handle))
+}
+
+func TestWriteViewport(t *testing.T) {
+	v := NewViewport()
+	v.Center = Point{X: 5.0, Y: 6.0, Z: 0.0}
+	v.ViewHeight = 2.0
+	v.FrozenLayerHandles = []Handle{0xA1}
+	v.SetIsGridOn(true)
+	actual := allCodePairs(v, R2000)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbViewport"),
+		NewDoubleCodePair(10, 5.0),
+		NewDoubleCodePair(20, 6.0),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewDoubleCodePair(45, 2.0),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(331, "A1"),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewIntCodePair(90, 512),
+	}, actual)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(1001, "ACAD"),
+	}, actual)
+}
+
+func TestReadViewportR12ExtendedData(t *testing.T) {
+	v := parseEntity(t, "VIEWPORT",
+		NewDoubleCodePair(10, 5.0),
+		NewDoubleCodePair(20, 6.0),
+		NewDoubleCodePair(40, 8.0),
+		NewDoubleCodePair(41, 4.0),
+		NewShortCodePair(68, 1),
+		NewShortCodePair(69, 2),
+		NewStringCodePair(1001, "ACAD"),
+		NewStringCodePair(1000, "MVIEW"),
+		NewStringCodePair(1002, "{"),
+		NewShortCodePair(1070, 16),
+		NewDoubleCodePair(1010, 1.0), // target
+		NewDoubleCodePair(1020, 2.0),
+		NewDoubleCodePair(1030, 3.0),
+		NewDoubleCodePair(1010, 0.0), // direction
+		NewDoubleCodePair(1020, 0.0),
+		NewDoubleCodePair(1030, 1.0),
+		NewDoubleCodePair(1040, 45.0), // twist
+		NewDoubleCodePair(1040, 2.0),  // view height
+		NewDoubleCodePair(1040, 7.0),  // view center x
+		NewDoubleCodePair(1040, 8.0),  // view center y
+		NewDoubleCodePair(1040, 50.0), // lens length
+		NewDoubleCodePair(1040, 0.0),  // front clip
+		NewDoubleCodePair(1040, 0.0),  // back clip
+		NewShortCodePair(1070, 1),     // view mode
+		NewShortCodePair(1070, 100),   // circle zoom
+		NewShortCodePair(1070, 1),     // fast zoom
+		NewShortCodePair(1070, 3),     // ucs icon
+		NewShortCodePair(1070, 0),     // snap
+		NewShortCodePair(1070, 1),     // grid
+		NewShortCodePair(1070, 1),     // snap style
+		NewShortCodePair(1070, 2),     // snap isopair
+		NewDoubleCodePair(1040, 0.0),  // snap angle
+		NewDoubleCodePair(1040, 0.0),  // snap base
+		NewDoubleCodePair(1040, 0.0),
+		NewDoubleCodePair(1040, 0.5), // snap spacing
+		NewDoubleCodePair(1040, 0.5),
+		NewDoubleCodePair(1040, 10.0), // grid spacing
+		NewDoubleCodePair(1040, 10.0),
+		NewShortCodePair(1070, 1), // hidden in plot
+		NewStringCodePair(1002, "{"),
+		NewStringCodePair(1003, "frozen-1"),
+		NewStringCodePair(1003, "frozen-2"),
+		NewStringCodePair(1002, "}"),
+		NewStringCodePair(1002, "}"),
+	).(*Viewport)
+	assertEqPoint(t, Point{X: 1.0, Y: 2.0, Z: 3.0}, v.ViewTargetPoint)
+	assertEqVector(t, Vector{X: 0.0, Y: 0.0, Z: 1.0}, v.ViewDirection)
+	assertEqFloat64(t, 45.0, v.TwistAngle)
+	assertEqFloat64(t, 2.0, v.ViewHeight)
+	assertEqPoint(t, Point{X: 7.0, Y: 8.0, Z: 0.0}, v.ViewCenter)
+	assertEqInt(t, 100, int(v.CircleZoomPercent))
+	assertEqVector(t, Vector{X: 10.0, Y: 10.0, Z: 0.0}, v.GridSpacing)
+	assert(t, v.IsPerspective(), "expected perspective")
+	assert(t, v.IsFastZoom(), "expected fast zoom")
+	assert(t, v.IsUcsIconVisible() && v.IsUcsIconAtOrigin(), "expected ucs icon visible at origin")
+	assert(t, !v.IsSnapOn(), "expected snap off")
+	assert(t, v.IsGridOn(), "expected grid on")
+	assert(t, v.IsIsometricSnapStyle(), "expected isometric snap")
+	assert(t, v.IsIsometricPairRight() && !v.IsIsometricPairTop(), "expected right isometric pair")
+	assert(t, v.IsHiddenInPlot(), "expected hidden in plot")
+	assertEqInt(t, 2, len(v.FrozenLayerNames))
+	assertEqString(t, "frozen-2", v.FrozenLayerNames[1])
+	assertEqInt(t, 0, len(v.extendedDataPairs))
+}
+
+func TestWriteViewportR12ExtendedData(t *testing.T) {
+	v := NewViewport()
+	v.ViewHeight = 3.0
+	v.TwistAngle = 15.0
+	v.FrozenLayerNames = []string{"frozen"}
+	v.SetIsSnapOn(true)
+	actual := allCodePairs(v, R12)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(1001, "ACAD"),
+		NewStringCodePair(1000, "MVIEW"),
+		NewStringCodePair(1002, "{"),
+		NewShortCodePair(1070, 16),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewDoubleCodePair(1040, 15.0),
+		NewDoubleCodePair(1040, 3.0),
+	}, actual)
+	assertContainsCodePairs(t, []CodePair{
+		NewStringCodePair(1002, "{"),
+		NewStringCodePair(1003, "frozen"),
+		NewStringCodePair(1002, "}"),
+		NewStringCodePair(1002, "}"),
+	}, actual)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewStringCodePair(100, "AcDbViewport"),
+	}, actual)
+	assertNotContainsCodePairs(t, []CodePair{
+		NewDoubleCodePair(45, 3.0),
+	}, actual)
+}
+
+func TestRoundTripViewportR12(t *testing.T) {
+	drawing := *NewDrawing()
+	drawing.Header.Version = R12
+	v := NewViewport()
+	v.ViewTargetPoint = Point{X: 1.0, Y: 2.0, Z: 3.0}
+	v.ViewHeight = 3.0
+	v.TwistAngle = 15.0
+	v.FrozenLayerNames = []string{"frozen"}
+	v.SetIsGridOn(true)
+	v.SetIsIsometricPairTop(true)
+	drawing.Entities = append(drawing.Entities, v)
+	drawing = roundTripDrawing(t, &drawing)
+	assertEqInt(t, 1, len(drawing.Entities))
+	rt := drawing.Entities[0].(*Viewport)
+	assertEqPoint(t, v.ViewTargetPoint, rt.ViewTargetPoint)
+	assertEqFloat64(t, 3.0, rt.ViewHeight)
+	assertEqFloat64(t, 15.0, rt.TwistAngle)
+	assert(t, rt.IsGridOn(), "expected grid on")
+	assert(t, rt.IsIsometricPairTop(), "expected top isometric pair")
+	assertEqInt(t, 1, len(rt.FrozenLayerNames))
+	assertEqString(t, "frozen", rt.FrozenLayerNames[0])
+}
+
+func viewportFrozenLayerDrawing(version AcadVersion) (Drawing, *Viewport) {
+	drawing := *NewDrawing()
+	drawing.Header.Version = version
+	layer := *NewLayer()
+	layer.Name = "frozen"
+	drawing.Layers = append(drawing.Layers, layer)
+	v := NewViewport()
+	drawing.Entities = append(drawing.Entities, v)
+	return drawing, v
+}
+
+func findLayerHandle(t *testing.T, drawing Drawing, name string) Handle {
+	for i := range drawing.Layers {
+		if drawing.Layers[i].Name == name {
+			return drawing.Layers[i].Handle()
+		}
+	}
+	t.Fatalf("layer '%s' not found", name)
+	return 0
+}
+
+func TestConvertViewportFrozenLayerNamesToHandles(t *testing.T) {
+	drawing, v := viewportFrozenLayerDrawing(R12)
+	v.FrozenLayerNames = []string{"FROZEN", "missing"}
+	drawing = roundTripDrawing(t, &drawing)
+
+	drawing.Header.Version = R2000
+	drawing = roundTripDrawing(t, &drawing)
+	rt := drawing.Entities[0].(*Viewport)
+	assertEqInt(t, 1, len(rt.FrozenLayerHandles))
+	assertEqUInt64(t, uint64(findLayerHandle(t, drawing, "frozen")), uint64(rt.FrozenLayerHandles[0]))
+}
+
+func TestConvertViewportFrozenLayerHandlesToNames(t *testing.T) {
+	drawing, v := viewportFrozenLayerDrawing(R2000)
+	assignHandles(&drawing)
+	v.FrozenLayerHandles = []Handle{findLayerHandle(t, drawing, "frozen")}
+	drawing = roundTripDrawing(t, &drawing)
+
+	drawing.Header.Version = R12
+	drawing = roundTripDrawing(t, &drawing)
+	rt := drawing.Entities[0].(*Viewport)
+	assertEqInt(t, 1, len(rt.FrozenLayerNames))
+	assertEqString(t, "frozen", rt.FrozenLayerNames[0])
+}
+
+func TestViewportPaperToModel(t *testing.T) {
+	v := NewViewport()
+	v.Center = Point{X: 10.0, Y: 10.0, Z: 0.0}
+	v.Height = 4.0
+	v.ViewHeight = 8.0
+	v.ViewCenter = Point{X: 100.0, Y: 50.0, Z: 0.0}
+
+	// the viewport center shows the view center
+	p, err := v.PaperToModel(v.Center)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqPoint(t, Point{X: 100.0, Y: 50.0, Z: 0.0}, p)
+
+	// one paper unit is two model units
+	p, err = v.PaperToModel(Point{X: 11.0, Y: 10.0, Z: 0.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqPoint(t, Point{X: 102.0, Y: 50.0, Z: 0.0}, p)
+
+	// twisting the view a quarter turn lays the model's negative Y axis along paper X
+	v.TwistAngle = 90.0
+	v.ViewCenter = Point{X: 0.0, Y: 0.0, Z: 0.0}
+	p, err = v.PaperToModel(Point{X: 11.0, Y: 10.0, Z: 0.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearPoint(t, Point{X: 0.0, Y: -2.0, Z: 0.0}, p)
+}
+
+func TestViewportModelToPaperRoundTrip(t *testing.T) {
+	v := NewViewport()
+	v.Center = Point{X: 10.0, Y: 10.0, Z: 0.0}
+	v.Height = 4.0
+	v.ViewHeight = 8.0
+	v.ViewCenter = Point{X: 3.0, Y: -2.0, Z: 0.0}
+	v.ViewTargetPoint = Point{X: 1.0, Y: 1.0, Z: 1.0}
+	v.ViewDirection = Vector{X: 1.0, Y: 1.0, Z: 1.0}
+	v.TwistAngle = 30.0
+
+	paper := Point{X: 12.5, Y: 7.25, Z: 0.0}
+	model, err := v.PaperToModel(paper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := v.ModelToPaper(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearPoint(t, paper, back)
+}
+
+func TestViewportMappingWithoutView(t *testing.T) {
+	v := NewViewport()
+	v.ViewHeight = 0.0
+	_, err := v.PaperToModel(Point{})
+	assert(t, err != nil, "expected an error for a viewport without a view height")
+	_, err = v.ModelToPaper(Point{})
+	assert(t, err != nil, "expected an error for a viewport without a view height")
+}
+
+func TestTransformViewport(t *testing.T) {
+	v := NewViewport()
+	v.Center = Point{5.0, 5.0, 0.0}
+	v.Width = 4.0
+	v.Height = 2.0
+	v.ViewTargetPoint = Point{1.0, 0.0, 0.0}
+	m := NewTranslationMatrix4(Vector{1.0, 0.0, 0.0}).Multiply(*NewScaleMatrix4(2.0, 3.0, 1.0))
+	_, err := Transform(v, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNearPoint(t, Point{11.0, 15.0, 0.0}, v.Center)
+	assertNearPoint(t, Point{3.0, 0.0, 0.0}, v.ViewTargetPoint)
+	assertNearFloat64(t, 8.0, v.Width)
+	assertNearFloat64(t, 6.0, v.Height)
+	assertNearBounds(t, Point{7.0, 12.0, 0.0}, Point{15.0, 18.0, 0.0}, BoundingBox(v))
+}
+
+func TestCloneViewportKeepsFrozenLayers(t *testing.T) {
+	v := NewViewport()
+	v.FrozenLayerNames = []string{"frozen"}
+	clone, err := cloneEntity(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := clone.(*Viewport)
+	assertEqInt(t, 1, len(rt.FrozenLayerNames))
+	assertEqString(t, "frozen", rt.FrozenLayerNames[0])
+	rt.FrozenLayerNames[0] = "changed"
+	assertEqString(t, "frozen", v.FrozenLayerNames[0])
+}